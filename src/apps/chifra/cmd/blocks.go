@@ -54,7 +54,8 @@ Notes:
   - Multiple topics match on topic0, topic1, and so on, not on different topic0's.
   - The --decache option removes the block(s), all transactions in those block(s), and all traces in those transactions from the cache.
   - The --withdrawals option is only available on certain chains. It is ignored otherwise.
-  - The --traces option requires your RPC to provide trace data. See the README for more information.`
+  - The --traces option requires your RPC to provide trace data. See the README for more information.
+  - The --blobs option is only available on chains that have activated Cancun. It reports no transactions otherwise.`
 
 func init() {
 	var capabilities caps.Capability // capabilities for chifra blocks
@@ -75,6 +76,7 @@ One of [ from | to | reward ]`)
 	blocksCmd.Flags().StringSliceVarP(&blocksPkg.GetOptions().Emitter, "emitter", "m", nil, `for the --logs option only, filter logs to show only those logs emitted by the given address(es)`)
 	blocksCmd.Flags().StringSliceVarP(&blocksPkg.GetOptions().Topic, "topic", "B", nil, `for the --logs option only, filter logs to show only those with this topic(s)`)
 	blocksCmd.Flags().BoolVarP(&blocksPkg.GetOptions().Withdrawals, "withdrawals", "i", false, `export the withdrawals from the block as opposed to the block data`)
+	blocksCmd.Flags().BoolVarP(&blocksPkg.GetOptions().Blobs, "blobs", "b", false, `export only the blob transactions (EIP-4844) from the block along with their blob gas`)
 	blocksCmd.Flags().BoolVarP(&blocksPkg.GetOptions().Articulate, "articulate", "a", false, `for the --logs option only, articulate the retrieved data if ABIs can be found`)
 	blocksCmd.Flags().BoolVarP(&blocksPkg.GetOptions().Count, "count", "U", false, `display only the count of appearances for --addrs or --uniq`)
 	blocksCmd.Flags().BoolVarP(&blocksPkg.GetOptions().CacheTxs, "cache_txs", "X", false, `force a write of the block's transactions to the cache (slow)`)
@@ -55,9 +55,10 @@ Notes:
   - The --belongs option is only available in the index mode.
   - The --first_block and --last_block options apply only to addresses, appearances, and index --belongs mode.
   - The --pin option requires a locally running IPFS node or a pinning service API key.
-  - The --publish option requires a private key.
+  - The --publish option requires a private key in TB_PUBLISH_PRIVATE_KEY or a keystore file named in the [unchained] section of the config. The keystore's password is read from TB_PUBLISH_PASSWORD or prompted for.
   - The --publisher option is ignored with the --publish option since the sender of the transaction is recorded as the publisher.
-  - Without --rewrite, the manifest is written to the temporary cache. With it, the manifest is rewritten to the index folder.`
+  - Without --rewrite, the manifest is written to the temporary cache. With it, the manifest is rewritten to the index folder.
+  - In index mode without --pin, --rewrite re-reads each chunk's blocks from a tracing archive node and rewrites the chunk's index file in the newer format that records why each address appears (see chifra list --reasons). Addresses, appearances, and blooms are unchanged, so rewritten chunks are not re-downloaded by chifra init. Chunks that already carry a reason for every appearance are skipped.`
 
 func init() {
 	var capabilities caps.Capability // capabilities for chifra chunks
@@ -67,7 +68,7 @@ func init() {
 
 	chunksCmd.Flags().BoolVarP(&chunksPkg.GetOptions().Check, "check", "c", false, `check the manifest, index, or blooms for internal consistency`)
 	chunksCmd.Flags().BoolVarP(&chunksPkg.GetOptions().Pin, "pin", "i", false, `pin the manifest or each index chunk and bloom`)
-	chunksCmd.Flags().BoolVarP(&chunksPkg.GetOptions().Publish, "publish", "p", false, `publish the manifest to the Unchained Index smart contract`)
+	chunksCmd.Flags().BoolVarP(&chunksPkg.GetOptions().Publish, "publish", "p", false, `pin the manifest and publish its CID to the Unchained Index smart contract`)
 	chunksCmd.Flags().StringVarP(&chunksPkg.GetOptions().Publisher, "publisher", "P", "", `for some query options, the publisher of the index (hidden)`)
 	chunksCmd.Flags().Uint64VarP((*uint64)(&chunksPkg.GetOptions().Truncate), "truncate", "n", 0, `truncate the entire index at this block (requires a block identifier) (hidden)`)
 	chunksCmd.Flags().BoolVarP(&chunksPkg.GetOptions().Remote, "remote", "r", false, `prior to processing, retrieve the manifest from the Unchained Index smart contract`)
@@ -77,12 +78,13 @@ func init() {
 	chunksCmd.Flags().Uint64VarP((*uint64)(&chunksPkg.GetOptions().LastBlock), "last_block", "L", 0, `last block to process (inclusive)`)
 	chunksCmd.Flags().Uint64VarP(&chunksPkg.GetOptions().MaxAddrs, "max_addrs", "m", 0, `the max number of addresses to process in a given chunk`)
 	chunksCmd.Flags().BoolVarP(&chunksPkg.GetOptions().Deep, "deep", "d", false, `if true, dig more deeply during checking (manifest only)`)
-	chunksCmd.Flags().BoolVarP(&chunksPkg.GetOptions().Rewrite, "rewrite", "e", false, `for the --pin --deep mode only, writes the manifest back to the index folder (see notes)`)
+	chunksCmd.Flags().BoolVarP(&chunksPkg.GetOptions().Rewrite, "rewrite", "e", false, `with --pin --deep, writes the manifest back to the index folder, in index mode alone, adds reasons to each chunk (see notes)`)
 	chunksCmd.Flags().BoolVarP(&chunksPkg.GetOptions().List, "list", "l", false, `for the pins mode only, list the remote pins (hidden)`)
 	chunksCmd.Flags().BoolVarP(&chunksPkg.GetOptions().Unpin, "unpin", "u", false, `for the pins mode only, if true reads local ./unpins file for valid CIDs and remotely unpins each (skips non-CIDs) (hidden)`)
 	chunksCmd.Flags().BoolVarP(&chunksPkg.GetOptions().Count, "count", "U", false, `for certain modes only, display the count of records`)
 	chunksCmd.Flags().StringVarP(&chunksPkg.GetOptions().Tag, "tag", "t", "", `visits each chunk and updates the headers with the supplied version string (vX.Y.Z-str) (hidden)`)
 	chunksCmd.Flags().Float64VarP(&chunksPkg.GetOptions().Sleep, "sleep", "s", 0.0, `for --remote pinning only, seconds to sleep between API calls`)
+	chunksCmd.Flags().BoolVarP(&chunksPkg.GetOptions().DryRun, "dry_run", "", false, `for --publish only, pin nothing and print the signed transaction rather than sending it`)
 	if os.Getenv("TEST_MODE") != "true" {
 		_ = chunksCmd.Flags().MarkHidden("publisher")
 		_ = chunksCmd.Flags().MarkHidden("truncate")
@@ -45,8 +45,10 @@ const notesDaemon = `
 Notes:
   - To start API open terminal window and run chifra daemon.
   - See the API documentation (https://trueblocks.io/api) for more information.
+  - The export, logs, traces, and blocks commands stream their records through the gRPC API (see chifra.proto). The same methods are served at the --url using the Connect protocol.
+  - Clients may subscribe at /websocket to new appearances (of given addresses or of any monitored address) and to newly written chunks, resuming from a block after a reconnect. To feed them, run chifra scrape --notify with [settings.notify] url set to this server's /notify and the same [settings.notify] secret set for both.
+  - With --share, the daemon acts as an IPFS gateway for the chunks and blooms (and the manifest) held locally. To share them on a local network, start it with --url 0.0.0.0:8080 and have the other machines add http://HOST:8080/ipfs/ to their gateways.
   - The --port option is deprecated, use --url instead.
-  - The --grpc option is deprecated, there is no replacement.
   - The --api option is deprecated, there is no replacement.
   - The --scrape option is deprecated, use chifra scrape instead.
   - The --monitor option is deprecated, use chifra monitors --watch instead.`
@@ -62,8 +64,9 @@ func init() {
 
 	daemonCmd.Flags().StringVarP(&daemonPkg.GetOptions().Url, "url", "u", "localhost:8080", `specify the API server's url and optionally its port`)
 	daemonCmd.Flags().BoolVarP(&daemonPkg.GetOptions().Silent, "silent", "", false, `disable logging (for use in SDK for example)`)
+	daemonCmd.Flags().BoolVarP(&daemonPkg.GetOptions().Share, "share", "", false, `serve the chunks and blooms of the local index by CID at /ipfs/ so other machines may download from this server`)
 	daemonCmd.Flags().StringVarP(&daemonPkg.GetOptions().Port, "port", "p", ":8080", `deprecated, use --url instead (hidden)`)
-	daemonCmd.Flags().BoolVarP(&daemonPkg.GetOptions().Grpc, "grpc", "g", false, `deprecated, there is no replacement (hidden)`)
+	daemonCmd.Flags().StringVarP(&daemonPkg.GetOptions().Grpc, "grpc", "g", "", `also serve the streaming gRPC API at this address (for example localhost:8081)`)
 	daemonCmd.Flags().StringVarP(&daemonPkg.GetOptions().Api, "api", "a", "on", `deprecated, there is no replacement (hidden)
 One of [ off | on ]`)
 	daemonCmd.Flags().StringVarP(&daemonPkg.GetOptions().Scrape, "scrape", "s", "", `deprecated, use chifra scrape instead (hidden)
@@ -71,13 +74,11 @@ One of [ off | blooms | index ]`)
 	daemonCmd.Flags().BoolVarP(&daemonPkg.GetOptions().Monitor, "monitor", "m", false, `deprecated, use chifra monitors --watch instead (hidden)`)
 	if os.Getenv("TEST_MODE") != "true" {
 		_ = daemonCmd.Flags().MarkHidden("port")
-		_ = daemonCmd.Flags().MarkHidden("grpc")
 		_ = daemonCmd.Flags().MarkHidden("api")
 		_ = daemonCmd.Flags().MarkHidden("scrape")
 		_ = daemonCmd.Flags().MarkHidden("monitor")
 	}
 	_ = daemonCmd.Flags().MarkDeprecated("port", "The --port option has been deprecated.")
-	_ = daemonCmd.Flags().MarkDeprecated("grpc", "The --grpc option has been deprecated.")
 	_ = daemonCmd.Flags().MarkDeprecated("api", "The --api option has been deprecated.")
 	_ = daemonCmd.Flags().MarkDeprecated("scrape", "The --scrape option has been deprecated.")
 	_ = daemonCmd.Flags().MarkDeprecated("monitor", "The --monitor option has been deprecated.")
@@ -59,7 +59,8 @@ Notes:
   - If the --reversed option is present, the appearance list is reversed prior to all processing (including filtering).
   - The --decache option will remove all cache items (blocks, transactions, traces, etc.) for the given address(es).
   - The --withdrawals option is only available on certain chains. It is ignored otherwise.
-  - The --traces option requires your RPC to provide trace data. See the README for more information.`
+  - The --traces option requires your RPC to provide trace data. See the README for more information.
+  - A --cursor advances only after the output has been closed and flushed successfully and only if no record reported an error. A run that fails may be repeated without skipping rows.`
 
 func init() {
 	var capabilities caps.Capability // capabilities for chifra export
@@ -77,6 +78,10 @@ func init() {
 	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().Neighbors, "neighbors", "n", false, `export the neighbors of the given address`)
 	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().Accounting, "accounting", "C", false, `attach accounting records to the exported data (applies to transactions export only)`)
 	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().Statements, "statements", "A", false, `for the accounting options only, export only statements`)
+	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().Gains, "gains", "g", false, `for the accounting options only, export realized gains, unrealized positions, and a yearly summary computed from tax lots`)
+	exportCmd.Flags().StringVarP(&exportPkg.GetOptions().LotMethod, "lot_method", "M", "fifo", `for the --gains option only, the method used to choose which lots are disposed of first
+One of [ fifo | lifo | hifo | specific ]`)
+	exportCmd.Flags().StringVarP(&exportPkg.GetOptions().Lots, "lots", "", "", `for --lot_method specific only, a csv file of disposalHash,acquisitionHash pairs identifying the lots to dispose of`)
 	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().Balances, "balances", "b", false, `traverse the transaction history and show each change in ETH balances`)
 	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().Withdrawals, "withdrawals", "i", false, `export withdrawals for the given address`)
 	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().Articulate, "articulate", "a", false, `articulate transactions, traces, logs, and outputs`)
@@ -93,6 +98,7 @@ func init() {
 One of [ in | out | zero ]`)
 	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().Factory, "factory", "y", false, `for --traces only, report addresses created by (or self-destructed by) the given address(es)`)
 	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().Unripe, "unripe", "u", false, `export transactions labeled unripe (i.e. less than 28 blocks old)`)
+	exportCmd.Flags().StringVarP(&exportPkg.GetOptions().Cursor, "cursor", "", "", `resume the export from (and then advance) the named cursor which is stored per address and output mode`)
 	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().Reversed, "reversed", "E", false, `produce results in reverse chronological order`)
 	exportCmd.Flags().BoolVarP(&exportPkg.GetOptions().NoZero, "no_zero", "z", false, `for the --count option only, suppress the display of zero appearance accounts`)
 	exportCmd.Flags().Uint64VarP((*uint64)(&exportPkg.GetOptions().FirstBlock), "first_block", "F", 0, `first block to process (inclusive)`)
@@ -107,6 +113,13 @@ One of [ in | out | zero ]`)
 	var unused bool
 	exportCmd.Flags().BoolVarP(&unused, "txs", "", false, "no-op options shows transactions (same as default)")
 	_ = exportCmd.Flags().MarkHidden("txs")
+
+	// Cursors advance only once PostRun has closed the output
+	postRun := exportCmd.PostRun
+	exportCmd.PostRun = func(cmd *cobra.Command, args []string) {
+		postRun(cmd, args)
+		exportPkg.StoreCursors()
+	}
 	// EXISTING_CODE
 
 	chifraCmd.AddCommand(exportCmd)
@@ -45,7 +45,9 @@ const notesInit = `
 Notes:
   - If run with no options, this tool will download or freshen only the Bloom filters.
   - The --first_block option will fall back to the start of the containing chunk.
-  - You may re-run the tool as often as you wish. It will repair or freshen the index.`
+  - You may re-run the tool as often as you wish. It will repair or freshen the index.
+  - Each downloaded file is checked against its CID in the manifest. A file that does not match is moved to the quarantine folder in the index and downloaded again from the next gateway.
+  - Files are downloaded from the chain's ipfsGateway and from any gateways listed in its [ipfs] settings. Each gateway serves at most its own number of downloads at a time (its concurrency setting) and a gateway that fails is passed over for the next one.`
 
 func init() {
 	var capabilities caps.Capability // capabilities for chifra init
@@ -47,7 +47,8 @@ const longList = `Purpose:
 const notesList = `
 Notes:
   - An address must be either an ENS name or start with '0x' and be forty-two characters long.
-  - No other options are permitted when --silent is selected.`
+  - No other options are permitted when --silent is selected.
+  - The --reasons and --as options need index chunks that carry reasons (see the reasons setting of chifra scrape). Run chifra chunks index --rewrite to add them to existing chunks. Appearances in chunks without reasons have an empty reason. With --as they are not listed and an error reports how many were left out.`
 
 func init() {
 	var capabilities caps.Capability // capabilities for chifra list
@@ -64,6 +65,9 @@ func init() {
 	listCmd.Flags().Uint64VarP(&listPkg.GetOptions().FirstRecord, "first_record", "c", 0, `the first record to process`)
 	listCmd.Flags().Uint64VarP(&listPkg.GetOptions().MaxRecords, "max_records", "e", 250, `the maximum number of records to process`)
 	listCmd.Flags().BoolVarP(&listPkg.GetOptions().Reversed, "reversed", "E", false, `produce results in reverse chronological order`)
+	listCmd.Flags().BoolVarP(&listPkg.GetOptions().Reasons, "reasons", "", false, `include the reasons the address appears in each appearance (from the index)`)
+	listCmd.Flags().StringSliceVarP(&listPkg.GetOptions().As, "as", "", nil, `list only appearances in which the address appears for one of these reasons (implies --reasons)
+One or more of [ from | to | creation | input | generator | topic | data | trace | miner | uncle | withdrawal | genesis ]`)
 	listCmd.Flags().StringVarP(&listPkg.GetOptions().Publisher, "publisher", "P", "", `for some query options, the publisher of the index (hidden)`)
 	listCmd.Flags().Uint64VarP((*uint64)(&listPkg.GetOptions().FirstBlock), "first_block", "F", 0, `first block to export (inclusive, ignored when freshening)`)
 	listCmd.Flags().Uint64VarP((*uint64)(&listPkg.GetOptions().LastBlock), "last_block", "L", 0, `last block to export (inclusive, ignored when freshening)`)
@@ -70,6 +70,8 @@ func init() {
 	scrapeCmd.Flags().Uint64VarP(&scrapePkg.GetOptions().Settings.UnripeDist, "unripe_dist", "", 28, `the distance (in blocks) from the front of the chain under which (inclusive) a block is considered unripe (hidden)`)
 	scrapeCmd.Flags().Uint64VarP(&scrapePkg.GetOptions().Settings.ChannelCount, "channel_count", "", 20, `number of concurrent processing channels (hidden)`)
 	scrapeCmd.Flags().BoolVarP(&scrapePkg.GetOptions().Settings.AllowMissing, "allow_missing", "", false, `do not report errors for blockchains that contain blocks with zero addresses (hidden)`)
+	scrapeCmd.Flags().StringVarP(&scrapePkg.GetOptions().Settings.BloomFormat, "bloom_format", "", "", `the format of the filter written with each chunk, either bloom (an adaptive bloom filter) or fuse (a smaller binary fuse filter) (hidden)`)
+	scrapeCmd.Flags().BoolVarP(&scrapePkg.GetOptions().Settings.Reasons, "reasons", "", false, `write the reason for each appearance to the index file of each chunk (a newer format) (hidden)`)
 	if os.Getenv("TEST_MODE") != "true" {
 		_ = scrapeCmd.Flags().MarkHidden("publisher")
 		_ = scrapeCmd.Flags().MarkHidden("apps_per_chunk")
@@ -78,6 +80,8 @@ func init() {
 		_ = scrapeCmd.Flags().MarkHidden("unripe_dist")
 		_ = scrapeCmd.Flags().MarkHidden("channel_count")
 		_ = scrapeCmd.Flags().MarkHidden("allow_missing")
+		_ = scrapeCmd.Flags().MarkHidden("bloom_format")
+		_ = scrapeCmd.Flags().MarkHidden("reasons")
 	}
 	globals.InitGlobals("scrape", scrapeCmd, &scrapePkg.GetOptions().Globals, capabilities)
 
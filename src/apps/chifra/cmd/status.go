@@ -48,7 +48,8 @@ const longStatus = `Purpose:
 const notesStatus = `
 Notes:
   - The some mode includes index, monitors, names, slurps, and abis.
-  - If no mode is supplied, a terse report is generated.`
+  - If no mode is supplied, a terse report is generated.
+  - Quotas (for example, 10GB) are set per cache (or for all caches with default) in the [settings.cacheQuotas] section of the config file.`
 
 func init() {
 	var capabilities caps.Capability // capabilities for chifra status
@@ -61,6 +62,7 @@ func init() {
 	statusCmd.Flags().Uint64VarP(&statusPkg.GetOptions().MaxRecords, "max_records", "e", 10000, `the maximum number of records to process`)
 	statusCmd.Flags().BoolVarP(&statusPkg.GetOptions().Chains, "chains", "a", false, `include a list of chain configurations in the output`)
 	statusCmd.Flags().BoolVarP(&statusPkg.GetOptions().Healthcheck, "healthcheck", "k", false, `an alias for the diagnose endpoint`)
+	statusCmd.Flags().BoolVarP(&statusPkg.GetOptions().Gc, "gc", "g", false, `evict the least recently used items from each binary cache until it is under its configured quota`)
 	globals.InitGlobals("status", statusCmd, &statusPkg.GetOptions().Globals, capabilities)
 
 	statusCmd.SetUsageTemplate(UsageWithNotes(notesStatus))
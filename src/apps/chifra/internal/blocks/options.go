@@ -39,6 +39,7 @@ type BlocksOptions struct {
 	Emitter     []string                 `json:"emitter,omitempty"`     // For the --logs option only, filter logs to show only those logs emitted by the given address(es)
 	Topic       []string                 `json:"topic,omitempty"`       // For the --logs option only, filter logs to show only those with this topic(s)
 	Withdrawals bool                     `json:"withdrawals,omitempty"` // Export the withdrawals from the block as opposed to the block data
+	Blobs       bool                     `json:"blobs,omitempty"`       // Export only the blob transactions (EIP-4844) from the block along with their blob gas
 	Articulate  bool                     `json:"articulate,omitempty"`  // For the --logs option only, articulate the retrieved data if ABIs can be found
 	Count       bool                     `json:"count,omitempty"`       // Display only the count of appearances for --addrs or --uniq
 	CacheTxs    bool                     `json:"cacheTxs,omitempty"`    // Force a write of the block's transactions to the cache (slow)
@@ -64,6 +65,7 @@ func (opts *BlocksOptions) testLog() {
 	logger.TestLog(len(opts.Emitter) > 0, "Emitter: ", opts.Emitter)
 	logger.TestLog(len(opts.Topic) > 0, "Topic: ", opts.Topic)
 	logger.TestLog(opts.Withdrawals, "Withdrawals: ", opts.Withdrawals)
+	logger.TestLog(opts.Blobs, "Blobs: ", opts.Blobs)
 	logger.TestLog(opts.Articulate, "Articulate: ", opts.Articulate)
 	logger.TestLog(opts.Count, "Count: ", opts.Count)
 	logger.TestLog(opts.CacheTxs, "CacheTxs: ", opts.CacheTxs)
@@ -122,6 +124,8 @@ func BlocksFinishParseInternal(w io.Writer, values url.Values) *BlocksOptions {
 			}
 		case "withdrawals":
 			opts.Withdrawals = true
+		case "blobs":
+			opts.Blobs = true
 		case "articulate":
 			opts.Articulate = true
 		case "count":
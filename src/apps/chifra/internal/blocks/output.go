@@ -62,6 +62,8 @@ func (opts *BlocksOptions) BlocksInternal(rCtx *output.RenderCtx) error {
 		err = opts.HandleLogs(rCtx)
 	} else if opts.Withdrawals {
 		err = opts.HandleWithdrawals(rCtx)
+	} else if opts.Blobs {
+		err = opts.HandleBlobs(rCtx)
 	} else if opts.Traces {
 		err = opts.HandleTraces(rCtx)
 	} else if opts.Uncles {
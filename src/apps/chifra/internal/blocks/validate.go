@@ -84,6 +84,9 @@ func (opts *BlocksOptions) validateBlocks() error {
 		if opts.Traces && opts.Hashes {
 			return validate.Usage("The {0} option is not available{1}.", "--traces", " with the --hashes option")
 		}
+		if opts.Blobs && opts.Hashes {
+			return validate.Usage("The {0} option is not available{1}.", "--blobs", " with the --hashes option")
+		}
 		if !validate.HasArticulationKey(opts.Articulate) {
 			return validate.Usage("The {0} option requires an Etherscan API key.", "--articulate")
 		}
@@ -144,5 +147,8 @@ func (opts *BlocksOptions) tooMany() bool {
 	if opts.Withdrawals {
 		cnt++
 	}
+	if opts.Blobs {
+		cnt++
+	}
 	return !opts.Count && cnt > 1
 }
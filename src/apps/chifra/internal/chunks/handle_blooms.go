@@ -31,6 +31,11 @@ func (opts *ChunksOptions) HandleBlooms(rCtx *output.RenderCtx, blockNums []base
 			for _, bl := range bl.Blooms {
 				nInserted += int(bl.NInserted)
 			}
+			byteWidth := uint64(index.BLOOM_WIDTH_IN_BYTES)
+			if bl.Fuse != nil {
+				n, width := bl.FuseStats()
+				nInserted, byteWidth = int(n), uint64(width)
+			}
 
 			if opts.Globals.Verbose {
 				displayBloom(&bl, 1)
@@ -51,7 +56,7 @@ func (opts *ChunksOptions) HandleBlooms(rCtx *output.RenderCtx, blockNums []base
 				Size:      stats.BloomSz,
 				Range:     rng.String(),
 				NBlooms:   stats.NBlooms,
-				ByteWidth: index.BLOOM_WIDTH_IN_BYTES,
+				ByteWidth: byteWidth,
 				NInserted: uint64(nInserted),
 			}
 			rd := tslib.RangeToBounds(chain, &rng)
@@ -82,6 +87,15 @@ func displayBloom(bl *index.Bloom, verbose int) {
 		bytesPerLine = 32
 	}
 
+	if bl.Fuse != nil {
+		nInserted, width := bl.FuseStats()
+		fmt.Println("range:", bl.Range)
+		fmt.Println("format:", index.BloomFormatFuse)
+		fmt.Println("byteWidth:", width)
+		fmt.Println("nInserted:", nInserted)
+		return
+	}
+
 	nInserted := uint32(0)
 	for i := uint32(0); i < bl.Count; i++ {
 		nInserted += bl.Blooms[i].NInserted
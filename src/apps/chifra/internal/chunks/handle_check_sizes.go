@@ -43,8 +43,8 @@ func (opts *ChunksOptions) CheckSizes(fileNames []string, blockNums []base.Blknu
 		if !rng.LaterThan(maxInManifest) {
 			okay := true // the test passes only if both pass unless there's only one
 			if file.FileExists(indexFn) {
-				indexSize := file.FileSize(indexFn)
-				if indexSize != idxSizeInMan[rng] {
+				if !index.SizeMatches(indexFn, idxSizeInMan[rng]) {
+					indexSize := file.FileSize(indexFn)
 					report.MsgStrings = append(report.MsgStrings, fmt.Sprintf("Size of index %s (%d) not as expected in manifest (%d)", rng, indexSize, idxSizeInMan[rng]))
 					okay = false
 				}
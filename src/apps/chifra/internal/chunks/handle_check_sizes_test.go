@@ -0,0 +1,90 @@
+package chunksPkg
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/index"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/manifest"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/pinning"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// TestCheckSizesPinnedReasons pins a chunk carrying reasons to a stand-in for the local IPFS daemon
+// and checks the chunk against the sizes recorded while pinning it and against the sizes of a
+// manifest published before the reasons were added
+func TestCheckSizesPinnedReasons(t *testing.T) {
+	ipfs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, `{"Name":"chunk","Hash":"QmPQEgUm7nzQuW9HYyWp5Ff3aoUwg2rsxDngyuyddJTvrv","Size":"1"}`)
+	}))
+	defer ipfs.Close()
+
+	// The configuration is read once, so these must be set before anything reads it
+	t.Setenv("TB_PINNING_LOCALPINURL", ipfs.URL)
+	t.Setenv("TB_CHAINS_MAINNET_SCRAPE_REASONS", "true")
+
+	dir := t.TempDir()
+	for _, sub := range []string{"finalized", "blooms"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	indexPath := filepath.Join(dir, "finalized", "000000010-000000012.bin")
+	appMap := map[string][]types.AppRecord{
+		"0x1111111111111111111111111111111111111111": {{BlockNumber: 10, TransactionIndex: 1}, {BlockNumber: 12, TransactionIndex: 0}},
+		"0x2222222222222222222222222222222222222222": {{BlockNumber: 11, TransactionIndex: 3}},
+	}
+	reasonMap := map[string][]types.Reason{
+		"0x1111111111111111111111111111111111111111": {types.ReasonFrom, types.ReasonTo},
+		"0x2222222222222222222222222222222222222222": {types.ReasonTopic},
+	}
+	var chunk index.Chunk
+	if _, err := chunk.Write("mainnet", base.ZeroAddr, indexPath, appMap, reasonMap, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	localPin, _, err := pinning.PinOneChunk("mainnet", indexPath, false /* remote */)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if localPin.IndexSize != file.FileSize(indexPath) {
+		t.Fatal("expected the recorded size to be the size of the pinned file", localPin.IndexSize, file.FileSize(indexPath))
+	}
+
+	published := localPin
+	published.IndexSize = index.SizeWithoutReasons(indexPath)
+	if published.IndexSize == localPin.IndexSize {
+		t.Fatal("expected the chunk to carry reasons")
+	}
+
+	wrong := localPin
+	wrong.IndexSize = localPin.IndexSize - 1
+
+	for _, test := range []struct {
+		name   string
+		record types.ChunkRecord
+		passes bool
+	}{
+		{"pinned", localPin, true},
+		{"published without reasons", published, true},
+		{"wrong", wrong, false},
+	} {
+		man := &manifest.Manifest{Chunks: []types.ChunkRecord{test.record}}
+		report := types.ReportCheck{}
+		opts := ChunksOptions{}
+		if err := opts.CheckSizes([]string{index.ToBloomPath(indexPath)}, []base.Blknum{}, man, man, &report); err != nil {
+			t.Fatal(err)
+		}
+		passed := report.CheckedCnt > 0 && report.PassedCnt == report.CheckedCnt && len(report.MsgStrings) == 0
+		if passed != test.passes {
+			t.Error("unexpected size check for the", test.name, "chunk", report.CheckedCnt, report.PassedCnt, report.MsgStrings)
+		}
+	}
+}
@@ -35,11 +35,11 @@ func (opts *ChunksOptions) CheckStaging(lastBlock uint64, allow_missing bool, re
 		return err
 	}
 
-	const asciiAppearanceSize = 59
-	nApps := int(file.FileSize(stageFn) / asciiAppearanceSize)
+	// Count lines rather than bytes since records may have been written with or without reasons
+	appearances := file.AsciiFileToLines(stageFn)
 
 	// It's okay for the file to be empty
-	if nApps == 0 {
+	if len(appearances) == 0 {
 		return nil
 	}
 
@@ -61,12 +61,6 @@ func (opts *ChunksOptions) CheckStaging(lastBlock uint64, allow_missing bool, re
 		report.MsgStrings = append(report.MsgStrings, fmt.Sprintf("First block (%d) > last block (%d)", fileRange.First, fileRange.Last))
 	}
 
-	appearances := file.AsciiFileToLines(stageFn)
-	if len(appearances) == 0 {
-		// should never happen, but bail if it does
-		return nil
-	}
-
 	//  3. Makes sure that the first block inside is == first if allow_missing == false, > otherwise
 	report.CheckedCnt++
 	trimmed := strings.TrimLeft(strings.Split(appearances[0], "\t")[1], "0")
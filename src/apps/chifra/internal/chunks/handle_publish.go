@@ -1,11 +1,106 @@
 package chunksPkg
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/colors"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/index"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/manifest"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
-	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/validate"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/pinning"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	"golang.org/x/term"
 )
 
+// HandlePublish pins the local manifest and publishes its CID to the Unchained Index smart
+// contract. The sender of the transaction is recorded as the publisher.
 func (opts *ChunksOptions) HandlePublish(rCtx *output.RenderCtx, blockNums []base.Blknum) error {
-	return validate.Usage("Handler for {0} --publish not yet implemented.", opts.Mode)
+	_ = blockNums // linter
+	chain := opts.Globals.Chain
+
+	signer, err := manifest.PublisherFromConfig(getPassword)
+	if err != nil {
+		rCtx.Cancel()
+		return err
+	}
+
+	fetchData := func(modelChan chan types.Modeler, errorChan chan error) {
+		manPath := config.PathToManifest(chain)
+		var cid string
+		if opts.DryRun {
+			// A dry run pins nothing. The CID is the one pinning would produce.
+			cid, err = index.ChunkCid(manPath)
+		} else {
+			var hash base.IpfsHash
+			hash, _, err = pinning.PinOneFile(chain, "manifest", manPath, opts.Remote)
+			cid = hash.String()
+		}
+		if err != nil {
+			errorChan <- err
+			rCtx.Cancel()
+			return
+		}
+
+		pub, err := manifest.NewPublication(chain, cid, signer)
+		if err != nil {
+			errorChan <- err
+			rCtx.Cancel()
+			return
+		}
+
+		if opts.DryRun {
+			if raw, err := pub.Raw(); err != nil {
+				errorChan <- err
+			} else {
+				logger.Info("Publisher:", pub.Publisher.Hex(), "Nonce:", pub.Tx.Nonce(), "Gas:", pub.Tx.Gas())
+				modelChan <- &types.Message{
+					Msg: "0x" + base.Bytes2Hex(raw),
+				}
+			}
+			return
+		}
+
+		logger.Info("Publishing", cid, "for", chain, "from", pub.Publisher.Hex())
+		ctx, cancel := context.WithTimeout(rCtx.Ctx, publishTimeout)
+		defer cancel()
+		if hash, err := pub.Send(ctx); err != nil {
+			if !hash.IsZero() {
+				logger.Warn("Transaction:", hash.Hex())
+			}
+			errorChan <- err
+		} else {
+			logger.Info(colors.BrightGreen+"Published and verified", cid, "in transaction", hash.Hex()+colors.Off)
+			modelChan <- &types.Message{
+				Msg: fmt.Sprintf("%s %s %s %s", chain, cid, pub.Publisher.Hex(), hash.Hex()),
+			}
+		}
+	}
+
+	return output.StreamMany(rCtx, fetchData, opts.Globals.OutputOpts())
+}
+
+// publishTimeout is how long we wait for the publishing transaction to be mined
+const publishTimeout = 5 * time.Minute
+
+// getPassword returns the keystore's password from TB_PUBLISH_PASSWORD or, failing that,
+// prompts for it without echoing
+func getPassword(path string) (string, error) {
+	if password, ok := os.LookupEnv("TB_PUBLISH_PASSWORD"); ok {
+		return password, nil
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("the password for %s must be provided in TB_PUBLISH_PASSWORD", path)
+	}
+
+	fmt.Fprintf(os.Stderr, colors.Yellow+"Password for %s: "+colors.Off, path)
+	password, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	return string(password), err
 }
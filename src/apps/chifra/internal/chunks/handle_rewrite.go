@@ -0,0 +1,157 @@
+package chunksPkg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/colors"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/index"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/sigintTrap"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/uniq"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/utils"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/walk"
+)
+
+// HandleRewrite rewrites each chunk's index file adding the reasons for each appearance. The reasons are
+// found by re-reading every block in the chunk's range from the node.
+func (opts *ChunksOptions) HandleRewrite(rCtx *output.RenderCtx, blockNums []base.Blknum) error {
+	chain := opts.Globals.Chain
+	if opts.Globals.TestMode {
+		logger.Warn("Rewrite option not tested.")
+		return nil
+	}
+
+	userHitCtrlC := false
+
+	fetchData := func(modelChan chan types.Modeler, errorChan chan error) {
+		nChunksRewritten := 0
+		nChunksSkipped := 0
+		rewriteIndex := func(walker *walk.CacheWalker, path string, first bool) (bool, error) {
+			if path != index.ToBloomPath(path) {
+				logger.Fatal("should not happen ==> we're spinning through the bloom filters")
+			}
+
+			if rCtx.WasCanceled() {
+				return false, nil
+			}
+
+			rng := base.RangeFromFilename(path)
+			reasonsMap, err := opts.reasonsInRange(rCtx.Ctx, rng)
+			if err != nil {
+				return false, err
+			}
+
+			reasonFunc := func(address base.Address, app types.AppRecord) (types.Reason, error) {
+				if app.BlockNumber == 0 {
+					return types.ReasonGenesis, nil
+				}
+				key := fmt.Sprintf(uniq.AppearanceFmt, address.Hex(), app.BlockNumber, app.TransactionIndex)
+				if blockMap := reasonsMap[base.Blknum(app.BlockNumber)]; blockMap != nil {
+					return (*blockMap)[key], nil
+				}
+				return 0, nil
+			}
+
+			if rewritten, err := index.RewriteWithReasons(chain, path, reasonFunc); err != nil {
+				return false, err
+			} else if rewritten {
+				nChunksRewritten++
+				if opts.Globals.Verbose {
+					logger.Info(colors.Green+"Rewrote chunk at "+rng.String()+" with reasons"+strings.Repeat(" ", 20), colors.Off)
+				}
+			} else {
+				nChunksSkipped++
+			}
+
+			return true, nil
+		}
+
+		walker := walk.NewCacheWalker(
+			chain,
+			opts.Globals.TestMode,
+			100, /* maxTests */
+			rewriteIndex,
+		)
+
+		if err := walker.WalkBloomFilters(blockNums); err != nil {
+			errorChan <- err
+			rCtx.Cancel()
+
+		} else {
+			// All that's left to do is report on what happened.
+			msg := fmt.Sprintf("%d chunks were rewritten with reasons, %d already had them.", nChunksRewritten, nChunksSkipped)
+			if userHitCtrlC {
+				msg += colors.Yellow + " Finishing work. please wait..." + colors.Off
+			}
+			if opts.Globals.Format == "json" {
+				s := types.Message{
+					Msg: msg,
+				}
+				modelChan <- &s
+			} else {
+				logger.Info(msg)
+			}
+		}
+	}
+
+	cleanOnQuit := func() {
+		userHitCtrlC = true
+		logger.Warn("Rewriting stopped. Chunks already rewritten keep their reasons. Rerun the command to continue.")
+	}
+	trapChannel := sigintTrap.Enable(rCtx.Ctx, rCtx.Cancel, cleanOnQuit)
+	defer sigintTrap.Disable(trapChannel)
+
+	opts.Globals.NoHeader = true
+	return output.StreamMany(rCtx, fetchData, opts.Globals.OutputOpts())
+}
+
+// reasonsInRange returns the reasons for every appearance in each block of the range (other than block zero)
+func (opts *ChunksOptions) reasonsInRange(ctx context.Context, rng base.FileRange) (map[base.Blknum]*uniq.AddressReasonMap, error) {
+	chain := opts.Globals.Chain
+	reasonsMap := make(map[base.Blknum]*uniq.AddressReasonMap, rng.Last-rng.First+1)
+	for bn := base.Max(rng.First, 1); bn <= rng.Last && rng.Last != 0; bn++ {
+		reasonsMap[bn] = new(uniq.AddressReasonMap)
+	}
+	if len(reasonsMap) == 0 {
+		return reasonsMap, nil
+	}
+
+	bar := logger.NewBar(logger.BarOptions{
+		Prefix:  "Reading " + rng.String(),
+		Enabled: opts.Globals.ShowProgress(),
+		Total:   int64(len(reasonsMap)),
+	})
+
+	iterFunc := func(bn base.Blknum, value *uniq.AddressReasonMap) error {
+		addrMap, err := uniq.GetReasonsInBlock(chain, opts.Conn, bn)
+		if err != nil {
+			return fmt.Errorf("block %d returned an error: %w", bn, err)
+		}
+		*value = addrMap
+		bar.Tick()
+		return nil
+	}
+
+	iterErrorChan := make(chan error)
+	iterCtx, iterCancel := context.WithCancel(ctx)
+	defer iterCancel()
+	go utils.IterateOverMap(iterCtx, iterErrorChan, reasonsMap, iterFunc)
+	var firstErr error
+	for err := range iterErrorChan {
+		if firstErr == nil {
+			firstErr = err
+			iterCancel()
+		}
+	}
+	bar.Finish(false /* newLine */)
+
+	if firstErr == nil && ctx.Err() != nil {
+		firstErr = ctx.Err()
+	}
+	return reasonsMap, firstErr
+}
@@ -41,7 +41,7 @@ type ChunksOptions struct {
 	BlockIds   []identifiers.Identifier `json:"blockIds,omitempty"`   // Block identifiers
 	Check      bool                     `json:"check,omitempty"`      // Check the manifest, index, or blooms for internal consistency
 	Pin        bool                     `json:"pin,omitempty"`        // Pin the manifest or each index chunk and bloom
-	Publish    bool                     `json:"publish,omitempty"`    // Publish the manifest to the Unchained Index smart contract
+	Publish    bool                     `json:"publish,omitempty"`    // Pin the manifest and publish its CID to the Unchained Index smart contract
 	Publisher  string                   `json:"publisher,omitempty"`  // For some query options, the publisher of the index
 	Truncate   base.Blknum              `json:"truncate,omitempty"`   // Truncate the entire index at this block (requires a block identifier)
 	Remote     bool                     `json:"remote,omitempty"`     // Prior to processing, retrieve the manifest from the Unchained Index smart contract
@@ -51,12 +51,13 @@ type ChunksOptions struct {
 	LastBlock  base.Blknum              `json:"lastBlock,omitempty"`  // Last block to process (inclusive)
 	MaxAddrs   uint64                   `json:"maxAddrs,omitempty"`   // The max number of addresses to process in a given chunk
 	Deep       bool                     `json:"deep,omitempty"`       // If true, dig more deeply during checking (manifest only)
-	Rewrite    bool                     `json:"rewrite,omitempty"`    // For the --pin --deep mode only, writes the manifest back to the index folder (see notes)
+	Rewrite    bool                     `json:"rewrite,omitempty"`    // With --pin --deep, writes the manifest back to the index folder, in index mode alone, adds reasons to each chunk (see notes)
 	List       bool                     `json:"list,omitempty"`       // For the pins mode only, list the remote pins
 	Unpin      bool                     `json:"unpin,omitempty"`      // For the pins mode only, if true reads local ./unpins file for valid CIDs and remotely unpins each (skips non-CIDs)
 	Count      bool                     `json:"count,omitempty"`      // For certain modes only, display the count of records
 	Tag        string                   `json:"tag,omitempty"`        // Visits each chunk and updates the headers with the supplied version string (vX.Y.Z-str)
 	Sleep      float64                  `json:"sleep,omitempty"`      // For --remote pinning only, seconds to sleep between API calls
+	DryRun     bool                     `json:"dryRun,omitempty"`     // For --publish only, pin nothing and print the signed transaction rather than sending it
 	Globals    globals.GlobalOptions    `json:"globals,omitempty"`    // The global options
 	Conn       *rpc.Connection          `json:"conn,omitempty"`       // The connection to the RPC server
 	BadFlag    error                    `json:"badFlag,omitempty"`    // An error flag if needed
@@ -93,6 +94,7 @@ func (opts *ChunksOptions) testLog() {
 	logger.TestLog(opts.Count, "Count: ", opts.Count)
 	logger.TestLog(len(opts.Tag) > 0, "Tag: ", opts.Tag)
 	logger.TestLog(opts.Sleep != float64(0.0), "Sleep: ", opts.Sleep)
+	logger.TestLog(opts.DryRun, "DryRun: ", opts.DryRun)
 	opts.Conn.TestLog(opts.getCaches())
 	opts.Globals.TestLog()
 }
@@ -167,6 +169,8 @@ func ChunksFinishParseInternal(w io.Writer, values url.Values) *ChunksOptions {
 			opts.Tag = value[0]
 		case "sleep":
 			opts.Sleep = base.MustParseFloat64(value[0])
+		case "dryRun":
+			opts.DryRun = true
 		default:
 			if !copy.Globals.Caps.HasKey(key) {
 				err := validate.Usage("Invalid key ({0}) in {1} route.", key, "chunks")
@@ -312,6 +316,11 @@ func GetChunkStats(chain, path string) (s types.ChunkStats, err error) {
 		ChunkSz: uint64(file.FileSize(index.ToIndexPath(path))),
 		RecWid:  4 + index.BLOOM_WIDTH_IN_BYTES,
 	}
+	if chunk.Bloom.Fuse != nil {
+		_, width := chunk.Bloom.FuseStats()
+		s.NBlooms = 1
+		s.RecWid = uint64(width)
+	}
 	rd := tslib.RangeToBounds(chain, &rng)
 	s.RangeDates = &rd
 
@@ -83,6 +83,8 @@ func (opts *ChunksOptions) ChunksInternal(rCtx *output.RenderCtx) error {
 		err = opts.HandlePublish(rCtx, blockNums)
 	} else if opts.Truncate != base.NOPOSN {
 		err = opts.HandleTruncate(rCtx, blockNums)
+	} else if opts.Rewrite {
+		err = opts.HandleRewrite(rCtx, blockNums)
 	} else {
 		err = opts.HandleShow(rCtx, blockNums)
 	}
@@ -16,6 +16,7 @@ import (
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/index"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/manifest"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/pinning"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/validate"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/version"
@@ -137,7 +138,25 @@ func (opts *ChunksOptions) validateChunks() error {
 		return validate.Usage("The {0} options require {1}.", "--remote and --deep", "--pin or --check")
 	}
 
-	if isPin {
+	if isPublish {
+		if isPin {
+			return validate.Usage("Choose either {0} or {1}, not both.", "--pin", "--publish")
+		}
+		if opts.Mode != "manifest" {
+			return validate.Usage("The {0} option is only available in {1} mode.", "--publish", "manifest")
+		}
+		if !manifest.HasSigner() {
+			return validate.Usage("The {0} option requires {1}.", "--publish", "a private key or a keystore (see notes)")
+		}
+	} else if opts.DryRun {
+		return validate.Usage("The {0} option requires {1}.", "--dry_run", "--publish")
+	}
+
+	if isPin || isPublish {
+		which := "--pin"
+		if isPublish {
+			which = "--publish"
+		}
 		if isRemote {
 			apiKey, secret, jwt := config.GetKey("pinata").ApiKey, config.GetKey("pinata").Secret, config.GetKey("pinata").Jwt
 			if secret == "" && jwt == "" {
@@ -147,22 +166,31 @@ func (opts *ChunksOptions) validateChunks() error {
 				return validate.Usage("If the {0} key is present, so must be the {1}.", "secret", "apiKey")
 			}
 			if config.GetPinning().RemotePinUrl == "" {
-				return validate.Usage("The {0} option requires {1}.", "--pin --remote", "a remotePinUrl")
+				return validate.Usage("The {0} option requires {1}.", which+" --remote", "a remotePinUrl")
 			}
 		} else {
 			if !config.IpfsRunning() {
-				return validate.Usage("The {0} option requires {1}.", "--pin", "a locally running IPFS daemon")
+				return validate.Usage("The {0} option requires {1}.", which, "a locally running IPFS daemon")
 			}
 			if config.GetPinning().LocalPinUrl == "" {
-				return validate.Usage("The {0} option requires {1}.", "--pin", "a localPinUrl")
+				return validate.Usage("The {0} option requires {1}.", which, "a localPinUrl")
 			}
 		}
-	} else if isRewrite {
-		return validate.Usage("The {0} option requires {1}.", "--rewrite", "--pin")
 	}
 
-	if opts.Publish {
-		return validate.Usage("The {0} option is currenlty unavailable.", "--publish")
+	if isRewrite && !isPin {
+		if opts.Mode != "index" {
+			return validate.Usage("The {0} option requires {1}.", "--rewrite", "--pin or the index mode")
+		}
+		if !opts.Globals.TestMode {
+			err, ok := opts.Conn.IsNodeTracing()
+			if !ok {
+				return validate.Usage("{0} requires {1}. Error: {2}", "chifra chunks index --rewrite", "tracing", err.Error())
+			}
+			if !opts.Conn.IsNodeArchive() {
+				return validate.Usage("{0} requires {1}.", "chifra chunks index --rewrite", "an archive node")
+			}
+		}
 	}
 
 	if opts.Mode != "index" {
@@ -0,0 +1,57 @@
+// Copyright 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package daemonPkg
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/grpcapi"
+	"google.golang.org/grpc"
+)
+
+// grpcServer serves the streaming API both over gRPC (at --grpc) and over the Connect
+// protocol (at --url, see routes.go)
+var grpcServer = grpcapi.NewServer(runGrpc)
+
+// serveGrpc serves the streaming API over gRPC at addr. It returns only on error.
+func serveGrpc(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	server := grpc.NewServer()
+	if err := grpcServer.Register(server); err != nil {
+		return err
+	}
+	return server.Serve(listener)
+}
+
+// ServeConnect serves the streaming API's methods over the Connect protocol
+func ServeConnect(w http.ResponseWriter, r *http.Request) {
+	grpcServer.ServeConnect(w, r)
+}
+
+// discardResponseWriter is handed to the commands run by the streaming API so that they
+// behave as they do when serving the API. Because the commands send their records to the
+// render context's model channel, nothing is written to it.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header {
+	if w.header == nil {
+		w.header = http.Header{}
+	}
+	return w.header
+}
+
+func (w *discardResponseWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (w *discardResponseWriter) WriteHeader(int) {
+}
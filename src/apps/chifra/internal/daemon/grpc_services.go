@@ -0,0 +1,48 @@
+// Copyright 2016, 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were auto generated. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package daemonPkg
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	// EXISTING_CODE
+	// EXISTING_CODE
+
+	blocksPkg "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/blocks"
+	exportPkg "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/export"
+	logsPkg "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/logs"
+	tracesPkg "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/traces"
+)
+
+// runGrpc runs the command behind a method of the gRPC API with the given (query) options
+func runGrpc(rCtx *output.RenderCtx, route string, values url.Values) error {
+	w := &discardResponseWriter{}
+	switch route {
+	case "export":
+		opts := exportPkg.ExportFinishParseInternal(w, values)
+		return opts.ExportInternal(rCtx)
+	case "blocks":
+		opts := blocksPkg.BlocksFinishParseInternal(w, values)
+		return opts.BlocksInternal(rCtx)
+	case "logs":
+		opts := logsPkg.LogsFinishParseInternal(w, values)
+		return opts.LogsInternal(rCtx)
+	case "traces":
+		opts := tracesPkg.TracesFinishParseInternal(w, values)
+		return opts.TracesInternal(rCtx)
+	}
+	// EXISTING_CODE
+	// EXISTING_CODE
+	return fmt.Errorf("the %s command is not served by the gRPC API", route)
+}
+
+// EXISTING_CODE
+// EXISTING_CODE
@@ -0,0 +1,159 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package daemonPkg
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/index"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/manifest"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	"github.com/gorilla/mux"
+)
+
+// sharedFile is a local file served by its CID
+type sharedFile struct {
+	path        string
+	size        int64 // zero if unknown
+	contentType string
+}
+
+// share maps the CIDs found in the local manifests of the configured chains to the local files.
+// The map is rebuilt when a CID is not found and one of the manifests has changed since last read.
+type share struct {
+	mutex    sync.Mutex
+	chains   []string
+	modTimes map[string]time.Time
+	files    map[string]sharedFile
+}
+
+// shared is nil unless the daemon was started with --share
+var shared *share
+
+func newShare(chains []string) *share {
+	return &share{
+		chains:   chains,
+		modTimes: map[string]time.Time{},
+		files:    map[string]sharedFile{},
+	}
+}
+
+// lookup returns the local file with the given CID, if there is one
+func (s *share) lookup(cid string) (sharedFile, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if f, ok := s.files[cid]; ok {
+		return f, true
+	}
+	if s.changed() {
+		s.rebuild()
+	}
+	f, ok := s.files[cid]
+	return f, ok
+}
+
+// changed returns true if any chain's manifest was modified since it was last read
+func (s *share) changed() bool {
+	for _, chain := range s.chains {
+		if modTime, err := file.GetModTime(config.PathToManifest(chain)); err == nil && !modTime.Equal(s.modTimes[chain]) {
+			return true
+		}
+	}
+	return false
+}
+
+// rebuild re-reads each chain's local manifest
+func (s *share) rebuild() {
+	s.files = map[string]sharedFile{}
+	for _, chain := range s.chains {
+		manifestPath := config.PathToManifest(chain)
+		modTime, err := file.GetModTime(manifestPath)
+		if err != nil {
+			continue
+		}
+		s.modTimes[chain] = modTime
+
+		man, err := manifest.LoadManifest(chain, base.ZeroAddr, manifest.LocalCache)
+		if err != nil {
+			logger.Warn("Could not share the manifest of", chain, "-", err)
+			continue
+		}
+		s.addChunks(filepath.Join(config.PathToIndex(chain), "finalized"), man.Chunks)
+
+		// The manifest is shared only if it is byte for byte the one published
+		if cid, err := index.ChunkCid(manifestPath); err == nil {
+			s.files[cid] = sharedFile{path: manifestPath, contentType: "application/json"}
+		}
+	}
+}
+
+// addChunks shares the index and bloom files of the given chunks found in the folder
+func (s *share) addChunks(folder string, chunks []types.ChunkRecord) {
+	for _, chunk := range chunks {
+		fileName := filepath.Join(folder, chunk.Range+".bin")
+		if len(chunk.IndexHash) > 0 {
+			s.files[chunk.IndexHash.String()] = sharedFile{
+				path:        index.ToIndexPath(fileName),
+				size:        chunk.IndexSize,
+				contentType: "application/octet-stream",
+			}
+		}
+		if len(chunk.BloomHash) > 0 {
+			s.files[chunk.BloomHash.String()] = sharedFile{
+				path:        index.ToBloomPath(fileName),
+				size:        chunk.BloomSize,
+				contentType: "application/octet-stream",
+			}
+		}
+	}
+}
+
+// ServeIpfs serves a locally held chunk, bloom, or manifest by its CID as an IPFS gateway would. It
+// responds with not found if the file is not held locally (or if the daemon is not sharing), so the
+// downloader moves on to its next gateway. Downloaders check the CID of what they receive.
+func ServeIpfs(w http.ResponseWriter, r *http.Request) {
+	if shared == nil {
+		http.NotFound(w, r)
+		return
+	}
+	shared.serve(w, r)
+}
+
+func (s *share) serve(w http.ResponseWriter, r *http.Request) {
+	cid := mux.Vars(r)["cid"]
+	f, ok := s.lookup(cid)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	fp, err := os.Open(f.path)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer fp.Close()
+
+	info, err := fp.Stat()
+	if err != nil || (f.size > 0 && info.Size() != f.size) {
+		// A partial or different file than the one in the manifest
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", f.contentType)
+	w.Header().Set("Cache-Control", "public, max-age=29030400, immutable")
+	w.Header().Set("Etag", "\""+cid+"\"")
+	http.ServeContent(w, r, "", info.ModTime(), fp)
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package daemonPkg
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	"github.com/gorilla/mux"
+)
+
+func TestShareServe(t *testing.T) {
+	folder := t.TempDir()
+
+	rng := "000000000-000000001"
+	indexPath := filepath.Join(folder, rng+".bin")
+	if err := os.WriteFile(indexPath, []byte("index bytes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := newShare(nil)
+	s.addChunks(folder, []types.ChunkRecord{{
+		Range:     rng,
+		IndexHash: base.IpfsHash("QmIndex"),
+		IndexSize: int64(len("index bytes")),
+		BloomHash: base.IpfsHash("QmBloom"),
+		BloomSize: 100,
+	}})
+
+	router := mux.NewRouter()
+	router.HandleFunc("/ipfs/{cid}", s.serve)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	get := func(cid string) (int, string, string) {
+		resp, err := http.Get(server.URL + "/ipfs/" + cid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, resp.Header.Get("Content-Type"), string(body)
+	}
+
+	if status, contentType, body := get("QmIndex"); status != http.StatusOK || contentType != "application/octet-stream" || body != "index bytes" {
+		t.Error("wrong response for a shared file", status, contentType, body)
+	}
+
+	// The bloom is in the manifest, but not on disc
+	if status, _, _ := get("QmBloom"); status != http.StatusNotFound {
+		t.Error("expected not found for a missing file", status)
+	}
+
+	if status, _, _ := get("QmUnknown"); status != http.StatusNotFound {
+		t.Error("expected not found for an unknown CID", status)
+	}
+
+	// A file whose size differs from the manifest is not served
+	if err := os.WriteFile(indexPath, []byte("other"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if status, _, _ := get("QmIndex"); status != http.StatusNotFound {
+		t.Error("expected not found for a file of the wrong size", status)
+	}
+}
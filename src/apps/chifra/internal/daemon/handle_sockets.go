@@ -5,11 +5,15 @@
 package daemonPkg
 
 import (
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
+	"sync/atomic"
 
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/notify"
 	"github.com/gorilla/websocket"
 )
 
@@ -23,8 +27,22 @@ const (
 	CommandOutputMessage MessageType = "output"
 	// ProgressMessage is a message carried on the stderr stream
 	ProgressMessage MessageType = "progress"
+	// SubscribeMessage is sent by a client to subscribe to events (see handle_subscriptions.go)
+	SubscribeMessage MessageType = "subscribe"
+	// UnsubscribeMessage is sent by a client to end one of its subscriptions
+	UnsubscribeMessage MessageType = "unsubscribe"
+	// SubscribedMessage acknowledges a subscription (or carries the reason it was refused)
+	SubscribedMessage MessageType = "subscribed"
+	// EventMessage carries a notification matching one of the client's subscriptions
+	EventMessage MessageType = "event"
+	// GapMessage tells a resuming client that some of the events it asked for are no longer available
+	GapMessage MessageType = "gap"
 )
 
+// sendBufferSize is the number of messages queued for a client before it is considered to have
+// fallen behind and is disconnected
+const sendBufferSize = 256
+
 var upgrader = websocket.Upgrader{}
 
 // Message is a structure used to send messages via websockets
@@ -36,9 +54,11 @@ type Message struct {
 
 // Connection is a structure representing a websocket connection
 type Connection struct {
-	connection *websocket.Conn
-	pool       *ConnectionPool
-	send       chan *Message
+	connection    *websocket.Conn
+	pool          *ConnectionPool
+	send          chan any
+	subscriptions map[string]*Subscription
+	closeReason   string
 }
 
 // write the message to the connection
@@ -54,7 +74,11 @@ func (c *Connection) write() {
 		case message, ok := <-c.send:
 			if !ok {
 				c.Log("Connection closed")
-				_ = c.connection.WriteMessage(websocket.CloseMessage, []byte{})
+				closeMsg := []byte{}
+				if len(c.closeReason) > 0 {
+					closeMsg = websocket.FormatCloseMessage(websocket.CloseTryAgainLater, c.closeReason)
+				}
+				_ = c.connection.WriteMessage(websocket.CloseMessage, closeMsg)
 				return
 			}
 
@@ -67,6 +91,26 @@ func (c *Connection) write() {
 	}
 }
 
+// read the client's requests from the connection until it is closed
+func (c *Connection) read() {
+	defer func() {
+		c.pool.unregister <- c
+	}()
+
+	for {
+		_, data, err := c.connection.ReadMessage()
+		if err != nil {
+			return
+		}
+		var req Request
+		if err := json.Unmarshal(data, &req); err != nil {
+			c.pool.requests <- &clientRequest{connection: c, err: err}
+			continue
+		}
+		c.pool.requests <- &clientRequest{connection: c, request: &req}
+	}
+}
+
 // RemoteAddr is the other end of the connection
 func (c *Connection) RemoteAddr() net.Addr {
 	return c.connection.RemoteAddr()
@@ -85,6 +129,12 @@ type ConnectionPool struct {
 	broadcast   chan *Message
 	register    chan *Connection
 	unregister  chan *Connection
+	requests    chan *clientRequest
+	events      chan *event
+	dropped     atomic.Uint64
+	history     eventHistory
+	watched     watchedMonitors
+	secret      string
 }
 
 // closeAndDelete cleans up a connection
@@ -93,13 +143,34 @@ func closeAndDelete(pool *ConnectionPool, connection *Connection) {
 	close(connection.send)
 }
 
+// deliver queues the message for the connection without blocking. A client whose queue is
+// full has fallen behind, so it is disconnected. It may reconnect and resume from the last
+// block it received.
+func (pool *ConnectionPool) deliver(connection *Connection, message any) {
+	if _, ok := pool.connections[connection]; !ok {
+		return
+	}
+	select {
+	case connection.send <- message:
+	default:
+		connection.Log("Client fell behind, dropping connection")
+		connection.closeReason = "client fell behind, reconnect and resume from the last block received"
+		closeAndDelete(pool, connection)
+	}
+}
+
 // newConnectionPool returns a new connection structure
-func newConnectionPool() *ConnectionPool {
+func newConnectionPool(chain string) *ConnectionPool {
 	return &ConnectionPool{
 		connections: make(map[*Connection]bool),
 		broadcast:   make(chan *Message),
 		register:    make(chan *Connection),
 		unregister:  make(chan *Connection),
+		requests:    make(chan *clientRequest),
+		events:      make(chan *event, eventBufferSize),
+		history:     eventHistory{max: historySize},
+		watched:     watchedMonitors{chain: chain, list: listMonitors},
+		secret:      config.GetSettings().Notify.Secret,
 	}
 }
 
@@ -121,8 +192,14 @@ func (pool *ConnectionPool) run() {
 		// handle a signal to broadcast a message
 		case message := <-pool.broadcast:
 			for connection := range pool.connections {
-				connection.send <- message
+				pool.deliver(connection, message)
 			}
+		// handle a client's request to subscribe or unsubscribe
+		case req := <-pool.requests:
+			pool.handleRequest(req)
+		// handle a monitor or scraper event
+		case ev := <-pool.events:
+			pool.publish(ev)
 		}
 	}
 }
@@ -139,15 +216,24 @@ func HandleWebsockets(pool *ConnectionPool, w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	connection := &Connection{connection: c, send: make(chan *Message), pool: pool}
+	connection := &Connection{
+		connection:    c,
+		send:          make(chan any, sendBufferSize),
+		pool:          pool,
+		subscriptions: make(map[string]*Subscription),
+	}
 	pool.register <- connection
 
 	go connection.write()
+	go connection.read()
 }
 
-var connectionPool = newConnectionPool()
+var connectionPool *ConnectionPool
 
-// RunWebsocketPool runs the websocket pool
-func RunWebsocketPool() {
+// RunWebsocketPool runs the websocket pool, which carries the chain's monitor and scraper events
+// to the clients that subscribe to them
+func RunWebsocketPool(chain string) {
+	connectionPool = newConnectionPool(chain)
+	notify.AddListener(connectionPool.listen)
 	go connectionPool.run()
 }
@@ -0,0 +1,292 @@
+// Copyright 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package daemonPkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/notify"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	watchedAddr = "0xf503017d7baf7fbc0fff7492b751025c6a78179b"
+	otherAddr   = "0x054993ab0f2b1acc0fdc65405ee203b4271bebe6"
+	thirdAddr   = "0x00000000000000000000000000000000000000aa"
+	testSecret  = "s3cret"
+)
+
+func newTestPool(t *testing.T) (*ConnectionPool, string) {
+	pool := newConnectionPool("mainnet")
+	pool.secret = testSecret
+	pool.watched.list = func(string) []base.Address {
+		return []base.Address{base.HexToAddress(watchedAddr)}
+	}
+	go pool.run()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		HandleWebsockets(pool, w, r)
+	}))
+	t.Cleanup(server.Close)
+	return pool, "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func dial(t *testing.T, url string) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// receive reads the next message sent to the client
+func receive(t *testing.T, conn *websocket.Conn) map[string]any {
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var msg map[string]any
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func subscribe(t *testing.T, conn *websocket.Conn, req Request) {
+	req.Action = SubscribeMessage
+	if err := conn.WriteJSON(req); err != nil {
+		t.Fatal(err)
+	}
+	if msg := receive(t, conn); msg["action"] != string(SubscribedMessage) || msg["id"] != req.ID {
+		t.Fatal("expected the subscription to be acknowledged, got", msg)
+	}
+}
+
+func appearances(bn string, addrs ...string) notify.Notification[[]notify.NotificationPayloadAppearance] {
+	n := notify.Notification[[]notify.NotificationPayloadAppearance]{Msg: notify.MessageAppearance}
+	for i, addr := range addrs {
+		n.Payload = append(n.Payload, notify.NotificationPayloadAppearance{
+			Address:          addr,
+			BlockNumber:      bn,
+			TransactionIndex: uint32(i),
+		})
+	}
+	return n
+}
+
+// postNotify posts the body to the pool's /notify endpoint with the given secret
+func postNotify(pool *ConnectionPool, body io.Reader, secret string) int {
+	r := httptest.NewRequest("POST", "/notify", body)
+	if secret != "" {
+		r.Header.Set(notify.SecretHeader, secret)
+	}
+	w := httptest.NewRecorder()
+	HandleNotify(pool, w, r)
+	return w.Code
+}
+
+func payloadAddresses(msg map[string]any) []string {
+	ret := []string{}
+	notification := msg["notification"].(map[string]any)
+	for _, item := range notification["payload"].([]any) {
+		ret = append(ret, item.(map[string]any)["address"].(string))
+	}
+	return ret
+}
+
+func TestSubscriptionFilters(t *testing.T) {
+	pool, url := newTestPool(t)
+
+	byAddress := dial(t, url)
+	subscribe(t, byAddress, Request{ID: "addr", Addresses: []string{otherAddr}})
+	byMonitor := dial(t, url)
+	subscribe(t, byMonitor, Request{ID: "mon", Monitors: true, Chunks: true})
+
+	pool.listen(appearances("100", watchedAddr, otherAddr, thirdAddr))
+	pool.listen(appearances("101", thirdAddr))
+	pool.listen(notify.Notification[[]notify.NotificationPayloadChunkWritten]{
+		Msg:     notify.MessageChunkWritten,
+		Payload: []notify.NotificationPayloadChunkWritten{{Cid: "Qm", Range: "000000100-000000101"}},
+	})
+
+	msg := receive(t, byAddress)
+	if msg["id"] != "addr" || msg["blockNumber"] != float64(100) {
+		t.Fatal("unexpected event", msg)
+	}
+	if got := payloadAddresses(msg); len(got) != 1 || got[0] != otherAddr {
+		t.Error("expected only the subscribed address, got", got)
+	}
+
+	msg = receive(t, byMonitor)
+	if got := payloadAddresses(msg); len(got) != 1 || got[0] != watchedAddr {
+		t.Error("expected only the monitored address, got", got)
+	}
+	msg = receive(t, byMonitor)
+	notification := msg["notification"].(map[string]any)
+	if notification["msg"] != string(notify.MessageChunkWritten) || msg["blockNumber"] != float64(101) {
+		t.Error("expected the written chunk, got", msg)
+	}
+}
+
+func TestSubscriptionResume(t *testing.T) {
+	pool, url := newTestPool(t)
+
+	for _, bn := range []string{"10", "11", "12"} {
+		pool.listen(appearances(bn, otherAddr))
+	}
+
+	conn := dial(t, url)
+	subscribe(t, conn, Request{ID: "resume", Addresses: []string{otherAddr}, FromBlock: 11})
+	for _, expected := range []float64{11, 12} {
+		if msg := receive(t, conn); msg["blockNumber"] != expected {
+			t.Error("expected block", expected, "got", msg)
+		}
+	}
+
+	subscribe(t, conn, Request{ID: "gap", Addresses: []string{otherAddr}, FromBlock: 5})
+	msg := receive(t, conn)
+	if msg["action"] != string(GapMessage) || msg["firstBlock"] != float64(5) || msg["lastBlock"] != float64(9) {
+		t.Error("expected a gap from 5 to 9, got", msg)
+	}
+	if msg := receive(t, conn); msg["blockNumber"] != float64(10) {
+		t.Error("expected block 10 after the gap, got", msg)
+	}
+}
+
+func TestSubscriptionReorg(t *testing.T) {
+	pool, url := newTestPool(t)
+
+	for _, bn := range []string{"10", "11", "12"} {
+		pool.listen(appearances(bn, otherAddr))
+	}
+	body, _ := json.Marshal(notify.Notification[notify.NotificationPayloadReorg]{
+		Msg:     notify.MessageReorg,
+		Payload: notify.NotificationPayloadReorg{ForkBlock: "10", Orphaned: "000000011-000000012"},
+	})
+	if code := postNotify(pool, bytes.NewReader(body), testSecret); code != http.StatusOK {
+		t.Fatal("unexpected status", code)
+	}
+	pool.listen(appearances("11", otherAddr))
+
+	// A subscriber to other addresses also learns of the reorg, and the orphaned events are not replayed
+	conn := dial(t, url)
+	subscribe(t, conn, Request{ID: "resume", Addresses: []string{thirdAddr}, FromBlock: 10})
+	msg := receive(t, conn)
+	notification := msg["notification"].(map[string]any)
+	if notification["msg"] != string(notify.MessageReorg) || msg["blockNumber"] != float64(10) {
+		t.Fatal("expected the reorg, got", msg)
+	}
+	if payload := notification["payload"].(map[string]any); payload["orphaned"] != "000000011-000000012" {
+		t.Error("unexpected payload", payload)
+	}
+
+	subscribe(t, conn, Request{ID: "other", Addresses: []string{otherAddr}, FromBlock: 11})
+	if msg := receive(t, conn); msg["id"] != "other" || msg["blockNumber"] != float64(11) || len(payloadAddresses(msg)) != 1 {
+		t.Error("expected only the new chain's block 11, got", msg)
+	}
+	// Nothing else (such as the orphaned block 12) was sent before this acknowledgement
+	subscribe(t, conn, Request{ID: "done", Chunks: true})
+}
+
+func TestSubscriptionRefused(t *testing.T) {
+	_, url := newTestPool(t)
+
+	conn := dial(t, url)
+	for _, req := range []string{
+		`{"action": "subscribe", "id": "none"}`,
+		`{"action": "subscribe", "id": "bad", "addresses": ["0xbad"]}`,
+		`{"action": "unsubscribe", "id": "missing"}`,
+		`not json`,
+	} {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(req)); err != nil {
+			t.Fatal(err)
+		}
+		if msg := receive(t, conn); msg["action"] != string(CommandErrorMessage) {
+			t.Error("expected", req, "to be refused, got", msg)
+		}
+	}
+}
+
+func TestSlowClientDropped(t *testing.T) {
+	pool := newConnectionPool("mainnet")
+	conns := make(chan *websocket.Conn, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conns <- c
+	}))
+	defer server.Close()
+	_ = dial(t, "ws"+strings.TrimPrefix(server.URL, "http"))
+
+	// The connection's writer is not running, so its queue fills up
+	connection := &Connection{connection: <-conns, pool: pool, send: make(chan any, 1)}
+	pool.connections[connection] = true
+	message := &Message{Action: ProgressMessage}
+	pool.deliver(connection, message)
+	pool.deliver(connection, message)
+
+	if len(pool.connections) != 0 || len(connection.closeReason) == 0 {
+		t.Fatal("expected the slow client to be dropped")
+	}
+	if m := <-connection.send; m != message {
+		t.Error("expected the queued message to be kept")
+	}
+	if _, ok := <-connection.send; ok {
+		t.Error("expected the queue to be closed")
+	}
+}
+
+func TestNotifyEndpoint(t *testing.T) {
+	pool, url := newTestPool(t)
+	conn := dial(t, url)
+	subscribe(t, conn, Request{ID: "addr", Addresses: []string{otherAddr}})
+
+	body, _ := json.Marshal(appearances("200", thirdAddr, otherAddr))
+	if code := postNotify(pool, bytes.NewReader(body), ""); code != http.StatusUnauthorized {
+		t.Fatal("expected a post without the secret to be refused, got", code)
+	}
+	if code := postNotify(pool, bytes.NewReader(body), "wrong"); code != http.StatusUnauthorized {
+		t.Fatal("expected a post with the wrong secret to be refused, got", code)
+	}
+	if code := postNotify(pool, bytes.NewReader(body), testSecret); code != http.StatusOK {
+		t.Fatal("unexpected status", code)
+	}
+
+	msg := receive(t, conn)
+	if got := payloadAddresses(msg); msg["blockNumber"] != float64(200) || len(got) != 1 || got[0] != otherAddr {
+		t.Error("expected the posted appearance, got", msg)
+	}
+
+	if code := postNotify(pool, strings.NewReader("not json"), testSecret); code != http.StatusBadRequest {
+		t.Error("expected a bad request, got", code)
+	}
+
+	// Without a configured secret, nothing is accepted
+	pool.secret = ""
+	if code := postNotify(pool, bytes.NewReader(body), testSecret); code != http.StatusForbidden {
+		t.Error("expected the endpoint to be closed, got", code)
+	}
+}
+
+func TestListenDoesNotBlock(t *testing.T) {
+	// The pool is not running, so nothing drains its queue
+	pool := newConnectionPool("mainnet")
+	for bn := 0; bn < eventBufferSize+10; bn++ {
+		pool.listen(appearances(fmt.Sprint(bn), otherAddr))
+	}
+	if len(pool.events) != eventBufferSize || pool.dropped.Load() != 10 {
+		t.Errorf("queued %d and dropped %d events", len(pool.events), pool.dropped.Load())
+	}
+}
@@ -0,0 +1,421 @@
+// Copyright 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package daemonPkg
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/monitor"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/notify"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+const (
+	// historySize is the number of recent events kept so that reconnecting clients may resume
+	historySize = 10000
+	// eventBufferSize is the number of events queued for the pool before further events are dropped
+	eventBufferSize = 1024
+	// monitorRefresh is how often the list of watched monitors is re-read from the cache
+	monitorRefresh = 30 * time.Second
+)
+
+// Request is a message sent by a client over the websocket. A client may hold any number of
+// subscriptions, each named by its id:
+//
+//	{"action": "subscribe", "id": "mine", "addresses": ["0x..."], "monitors": true, "chunks": true, "fromBlock": 18000000}
+//	{"action": "unsubscribe", "id": "mine"}
+//
+// A subscription receives the appearances of the given addresses, the appearances of any address
+// with a monitor in the cache (monitors), and each newly written index chunk (chunks). Every
+// subscription receives the scraper's reorg notices, after which events for blocks past the fork
+// may be sent again. If fromBlock is given, recent events at or after that block are sent before
+// any new ones.
+type Request struct {
+	Action    MessageType `json:"action"`
+	ID        string      `json:"id"`
+	Addresses []string    `json:"addresses,omitempty"`
+	Monitors  bool        `json:"monitors,omitempty"`
+	Chunks    bool        `json:"chunks,omitempty"`
+	FromBlock base.Blknum `json:"fromBlock,omitempty"`
+}
+
+// Subscription is the filter of one of a client's subscriptions
+type Subscription struct {
+	addresses  map[base.Address]bool
+	monitors   bool
+	chunks     bool
+	resumeFrom base.Blknum
+}
+
+// Event is sent to a client for each notification matching one of its subscriptions. The
+// notification carries only the matching appearances.
+type Event struct {
+	Action       MessageType `json:"action"`
+	ID           string      `json:"id"`
+	BlockNumber  base.Blknum `json:"blockNumber"`
+	Notification any         `json:"notification"`
+}
+
+// Gap is sent to a resuming client if events it asked for are no longer available. The client
+// may recover them with chifra export (appearances) or chifra chunks (chunks).
+type Gap struct {
+	Action     MessageType `json:"action"`
+	ID         string      `json:"id"`
+	FirstBlock base.Blknum `json:"firstBlock"`
+	LastBlock  base.Blknum `json:"lastBlock"`
+}
+
+// clientRequest is a request (or the error reading it) as received from a connection
+type clientRequest struct {
+	connection *Connection
+	request    *Request
+	err        error
+}
+
+// event is a single block's appearances, a single written chunk, or a reorg (in which case
+// blockNumber is the fork block)
+type event struct {
+	blockNumber base.Blknum
+	meta        *types.MetaData
+	appearances []notify.NotificationPayloadAppearance
+	chunk       *notify.NotificationPayloadChunkWritten
+	reorg       *notify.NotificationPayloadReorg
+}
+
+// listen receives the notifications published by the scraper (in this process or, through
+// HandleNotify, in another). It splits them into events
+// and queues them for the pool.
+func (pool *ConnectionPool) listen(notification any) {
+	switch n := notification.(type) {
+	case notify.Notification[[]notify.NotificationPayloadAppearance]:
+		byBlock := make(map[base.Blknum][]notify.NotificationPayloadAppearance)
+		for _, app := range n.Payload {
+			bn, err := strconv.ParseUint(app.BlockNumber, 10, 64)
+			if err != nil {
+				continue
+			}
+			byBlock[base.Blknum(bn)] = append(byBlock[base.Blknum(bn)], app)
+		}
+		blocks := make([]base.Blknum, 0, len(byBlock))
+		for bn := range byBlock {
+			blocks = append(blocks, bn)
+		}
+		sort.Slice(blocks, func(i, j int) bool { return blocks[i] < blocks[j] })
+		for _, bn := range blocks {
+			pool.queue(&event{blockNumber: bn, meta: n.Meta, appearances: byBlock[bn]})
+		}
+	case notify.Notification[[]notify.NotificationPayloadChunkWritten]:
+		for i := range n.Payload {
+			chunk := n.Payload[i]
+			rng := base.RangeFromRangeString(chunk.Range)
+			pool.queue(&event{blockNumber: rng.Last, meta: n.Meta, chunk: &chunk})
+		}
+	case notify.Notification[notify.NotificationPayloadReorg]:
+		if fork, err := strconv.ParseUint(n.Payload.ForkBlock, 10, 64); err == nil {
+			reorg := n.Payload
+			pool.queue(&event{blockNumber: base.Blknum(fork), meta: n.Meta, reorg: &reorg})
+		}
+	}
+}
+
+// queue queues the event for the pool without blocking the publisher (which may be the scraper).
+// If the pool has fallen behind and its queue is full, the event is dropped and counted.
+func (pool *ConnectionPool) queue(ev *event) {
+	select {
+	case pool.events <- ev:
+	default:
+		if n := pool.dropped.Add(1); n == 1 || n%eventBufferSize == 0 {
+			logger.Warn(fmt.Sprintf("the websocket pool is behind, %d event(s) dropped so far", n))
+		}
+	}
+}
+
+// HandleNotify receives the notifications chifra scrape --notify posts to the configured endpoint
+// (set [settings.notify] url to this server's /notify) and passes them to the pool. The poster
+// must send the [settings.notify] secret, which both sides share, in the SecretHeader.
+func HandleNotify(pool *ConnectionPool, w http.ResponseWriter, r *http.Request) {
+	if len(pool.secret) == 0 {
+		RespondWithError(w, http.StatusForbidden, errors.New("set [settings.notify] secret to accept notifications"))
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get(notify.SecretHeader)), []byte(pool.secret)) != 1 {
+		RespondWithError(w, http.StatusUnauthorized, errors.New("missing or incorrect notify secret"))
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var header struct {
+		Msg notify.Message `json:"msg"`
+	}
+	if err = json.Unmarshal(data, &header); err == nil {
+		switch header.Msg {
+		case notify.MessageAppearance:
+			var n notify.Notification[[]notify.NotificationPayloadAppearance]
+			if err = json.Unmarshal(data, &n); err == nil {
+				pool.listen(n)
+			}
+		case notify.MessageChunkWritten:
+			var n notify.Notification[[]notify.NotificationPayloadChunkWritten]
+			if err = json.Unmarshal(data, &n); err == nil {
+				pool.listen(n)
+			}
+		case notify.MessageReorg:
+			var n notify.Notification[notify.NotificationPayloadReorg]
+			if err = json.Unmarshal(data, &n); err == nil {
+				pool.listen(n)
+			}
+		}
+	}
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// publish sends the event to each subscription it matches and keeps it for resuming clients
+func (pool *ConnectionPool) publish(ev *event) {
+	if ev.reorg != nil {
+		// The events after the fork came from orphaned blocks, so they are not replayed
+		pool.history.rollBack(ev.blockNumber)
+	}
+	pool.history.add(ev)
+	for connection := range pool.connections {
+		for id, sub := range connection.subscriptions {
+			if sub.resumeFrom > 0 {
+				if ev.blockNumber > sub.resumeFrom {
+					pool.gap(connection, id, sub.resumeFrom, ev.blockNumber-1)
+				}
+				sub.resumeFrom = 0
+			}
+			if msg := pool.match(id, sub, ev); msg != nil {
+				pool.deliver(connection, msg)
+			}
+		}
+	}
+}
+
+// match returns the message to send to the subscription for the event, or nil if the event
+// does not match the subscription's filter
+func (pool *ConnectionPool) match(id string, sub *Subscription, ev *event) *Event {
+	if ev.reorg != nil {
+		return &Event{
+			Action:      EventMessage,
+			ID:          id,
+			BlockNumber: ev.blockNumber,
+			Notification: notify.Notification[notify.NotificationPayloadReorg]{
+				Msg:     notify.MessageReorg,
+				Meta:    ev.meta,
+				Payload: *ev.reorg,
+			},
+		}
+	}
+
+	if ev.chunk != nil {
+		if !sub.chunks {
+			return nil
+		}
+		return &Event{
+			Action:      EventMessage,
+			ID:          id,
+			BlockNumber: ev.blockNumber,
+			Notification: notify.Notification[[]notify.NotificationPayloadChunkWritten]{
+				Msg:     notify.MessageChunkWritten,
+				Meta:    ev.meta,
+				Payload: []notify.NotificationPayloadChunkWritten{*ev.chunk},
+			},
+		}
+	}
+
+	matched := []notify.NotificationPayloadAppearance{}
+	for _, app := range ev.appearances {
+		addr := base.HexToAddress(app.Address)
+		if sub.addresses[addr] || (sub.monitors && pool.watched.contains(addr)) {
+			matched = append(matched, app)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+	return &Event{
+		Action:      EventMessage,
+		ID:          id,
+		BlockNumber: ev.blockNumber,
+		Notification: notify.Notification[[]notify.NotificationPayloadAppearance]{
+			Msg:     notify.MessageAppearance,
+			Meta:    ev.meta,
+			Payload: matched,
+		},
+	}
+}
+
+// handleRequest subscribes or unsubscribes the client. Subscribing with fromBlock replays the
+// matching recent events before any new ones are sent.
+func (pool *ConnectionPool) handleRequest(req *clientRequest) {
+	connection := req.connection
+	if _, ok := pool.connections[connection]; !ok {
+		return
+	}
+
+	refuse := func(id string, err error) {
+		pool.deliver(connection, &Message{Action: CommandErrorMessage, ID: id, Content: err.Error()})
+	}
+
+	if req.err != nil {
+		refuse("", fmt.Errorf("could not read request: %w", req.err))
+		return
+	}
+
+	r := req.request
+	switch r.Action {
+	case SubscribeMessage:
+		sub, err := newSubscription(r)
+		if err != nil {
+			refuse(r.ID, err)
+			return
+		}
+		connection.subscriptions[r.ID] = sub
+		pool.deliver(connection, &Message{Action: SubscribedMessage, ID: r.ID})
+		if r.FromBlock > 0 {
+			pool.replay(connection, r.ID, sub, r.FromBlock)
+		}
+	case UnsubscribeMessage:
+		if _, ok := connection.subscriptions[r.ID]; !ok {
+			refuse(r.ID, fmt.Errorf("no subscription with id %s", r.ID))
+			return
+		}
+		delete(connection.subscriptions, r.ID)
+	default:
+		refuse(r.ID, fmt.Errorf("unknown action %s", r.Action))
+	}
+}
+
+// replay sends the recent events at or after fromBlock that match the subscription. If some
+// of them are no longer available (or the daemon has not seen them), the client is told which
+// blocks it may have missed first.
+func (pool *ConnectionPool) replay(connection *Connection, id string, sub *Subscription, fromBlock base.Blknum) {
+	events := pool.history.events
+	if len(events) == 0 {
+		// Nothing has been seen yet, so anything before the next event may have been missed
+		sub.resumeFrom = fromBlock
+		return
+	}
+
+	if first := events[0].blockNumber; fromBlock < first {
+		pool.gap(connection, id, fromBlock, first-1)
+	}
+	for _, ev := range events {
+		if ev.blockNumber < fromBlock {
+			continue
+		}
+		if msg := pool.match(id, sub, ev); msg != nil {
+			pool.deliver(connection, msg)
+		}
+	}
+}
+
+func (pool *ConnectionPool) gap(connection *Connection, id string, first, last base.Blknum) {
+	pool.deliver(connection, &Gap{Action: GapMessage, ID: id, FirstBlock: first, LastBlock: last})
+}
+
+func newSubscription(r *Request) (*Subscription, error) {
+	if len(r.ID) == 0 {
+		return nil, errors.New("a subscription requires an id")
+	}
+
+	sub := &Subscription{
+		addresses: make(map[base.Address]bool, len(r.Addresses)),
+		monitors:  r.Monitors,
+		chunks:    r.Chunks,
+	}
+	for _, addr := range r.Addresses {
+		if !base.IsValidAddress(addr) {
+			return nil, fmt.Errorf("invalid address %s", addr)
+		}
+		sub.addresses[base.HexToAddress(addr)] = true
+	}
+
+	if len(sub.addresses) == 0 && !sub.monitors && !sub.chunks {
+		return nil, errors.New("a subscription requires addresses, monitors, or chunks")
+	}
+	return sub, nil
+}
+
+// eventHistory keeps the most recent events in block order
+type eventHistory struct {
+	max    int
+	events []*event
+}
+
+func (h *eventHistory) add(ev *event) {
+	h.events = append(h.events, ev)
+	if len(h.events) > h.max {
+		h.events = h.events[len(h.events)-h.max:]
+	}
+}
+
+// rollBack drops the events after the fork block
+func (h *eventHistory) rollBack(fork base.Blknum) {
+	kept := h.events[:0]
+	for _, ev := range h.events {
+		if ev.blockNumber <= fork {
+			kept = append(kept, ev)
+		}
+	}
+	h.events = kept
+}
+
+// watchedMonitors is the (periodically refreshed) set of addresses with a monitor in the cache
+type watchedMonitors struct {
+	chain     string
+	list      func(chain string) []base.Address
+	addresses map[base.Address]bool
+	loaded    time.Time
+}
+
+func (w *watchedMonitors) contains(addr base.Address) bool {
+	if w.addresses == nil || time.Since(w.loaded) > monitorRefresh {
+		w.addresses = make(map[base.Address]bool)
+		for _, a := range w.list(w.chain) {
+			w.addresses[a] = true
+		}
+		w.loaded = time.Now()
+	}
+	return w.addresses[addr]
+}
+
+// listMonitors returns the addresses of the monitors in the chain's cache
+func listMonitors(chain string) []base.Address {
+	monitorChan := make(chan monitor.Monitor)
+	go monitor.ListExistingMonitors(chain, monitorChan)
+
+	addrs := []base.Address{}
+	for mon := range monitorChan {
+		switch mon.Address {
+		case base.NotAMonitor:
+			close(monitorChan)
+		default:
+			if !mon.Staged {
+				addrs = append(addrs, mon.Address)
+			}
+		}
+	}
+	return addrs
+}
@@ -28,8 +28,9 @@ import (
 type DaemonOptions struct {
 	Url     string                `json:"url,omitempty"`     // Specify the API server's url and optionally its port
 	Silent  bool                  `json:"silent,omitempty"`  // Disable logging (for use in SDK for example)
+	Share   bool                  `json:"share,omitempty"`   // Serve the chunks and blooms of the local index by CID at /ipfs/ so other machines may download from this server
 	Port    string                `json:"port,omitempty"`    // Deprecated, use --url instead
-	Grpc    bool                  `json:"grpc,omitempty"`    // Deprecated, there is no replacement
+	Grpc    string                `json:"grpc,omitempty"`    // Also serve the streaming gRPC API at this address (for example localhost:8081)
 	Api     string                `json:"api,omitempty"`     // Deprecated, there is no replacement
 	Scrape  string                `json:"scrape,omitempty"`  // Deprecated, use chifra scrape instead
 	Monitor bool                  `json:"monitor,omitempty"` // Deprecated, use chifra monitors --watch instead
@@ -50,6 +51,8 @@ var defaultDaemonOptions = DaemonOptions{
 func (opts *DaemonOptions) testLog() {
 	logger.TestLog(len(opts.Url) > 0 && opts.Url != "localhost:8080", "Url: ", opts.Url)
 	logger.TestLog(opts.Silent, "Silent: ", opts.Silent)
+	logger.TestLog(opts.Share, "Share: ", opts.Share)
+	logger.TestLog(len(opts.Grpc) > 0, "Grpc: ", opts.Grpc)
 	opts.Conn.TestLog(opts.getCaches())
 	opts.Globals.TestLog()
 }
@@ -82,10 +85,12 @@ func DaemonFinishParseInternal(w io.Writer, values url.Values) *DaemonOptions {
 			opts.Url = value[0]
 		case "silent":
 			opts.Silent = true
+		case "share":
+			opts.Share = true
 		case "port":
 			opts.Port = value[0]
 		case "grpc":
-			opts.Grpc = true
+			opts.Grpc = value[0]
 		case "api":
 			opts.Api = value[0]
 		case "scrape":
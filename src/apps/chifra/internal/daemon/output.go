@@ -94,8 +94,26 @@ func (opts *DaemonOptions) DaemonInternal(rCtx *output.RenderCtx) error {
 	// do not remove, this fixes a lint warning that happens in the boilerplate because of the Fatal just below
 	timer.Report(msg)
 
+	// Start serving the streaming gRPC API
+	if len(opts.Grpc) > 0 {
+		logger.InfoTable("gRPC URL:          ", opts.Grpc)
+		go func() {
+			logger.Fatal(serveGrpc(opts.Grpc))
+		}()
+	}
+
+	// Share the local index with other machines by CID
+	if opts.Share {
+		chains := []string{}
+		for _, ch := range config.GetChains() {
+			chains = append(chains, ch.Chain)
+		}
+		shared = newShare(chains)
+		logger.InfoTable("Sharing at:        ", opts.Url+"/ipfs/")
+	}
+
 	// Start listening to the web sockets
-	RunWebsocketPool()
+	RunWebsocketPool(chain)
 	// Start listening for requests
 	logger.Fatal(http.ListenAndServe(opts.Url, NewRouter(opts.Silent)))
 
@@ -13,6 +13,8 @@ import (
 	"errors"
 	"net/http"
 
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/grpcapi"
+
 	// EXISTING_CODE
 
 	abisPkg "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/internal/abis"
@@ -154,6 +156,9 @@ var routes = []Route{
 	{"Websockets", "GET", "/websocket", func(w http.ResponseWriter, r *http.Request) {
 		HandleWebsockets(connectionPool, w, r)
 	}},
+	{"Notify", "POST", "/notify", func(w http.ResponseWriter, r *http.Request) {
+		HandleNotify(connectionPool, w, r)
+	}},
 	{"DeleteMonitors", "DELETE", "/monitors", func(w http.ResponseWriter, r *http.Request) {
 		if err := monitorsPkg.ServeMonitors(w, r); err != nil {
 			RespondWithError(w, http.StatusInternalServerError, err)
@@ -184,6 +189,9 @@ var routes = []Route{
 			RespondWithError(w, http.StatusInternalServerError, err)
 		}
 	}},
+	{"Connect", "POST", "/" + grpcapi.ServiceName + "/{method}", ServeConnect},
+	{"Ipfs", "GET", "/ipfs/{cid}", ServeIpfs},
+	{"IpfsHead", "HEAD", "/ipfs/{cid}", ServeIpfs},
 	// EXISTING_CODE
 }
 
@@ -79,6 +79,12 @@ func ContentTypeHandler(next http.Handler) http.Handler {
 			contentType = "text/plain"
 		case "csv":
 			contentType = "text/csv"
+		case "ndjson":
+			contentType = "application/x-ndjson"
+		case "parquet":
+			contentType = "application/vnd.apache.parquet"
+		case "arrow":
+			contentType = "application/vnd.apache.arrow.stream"
 		default:
 			contentType = "application/json"
 		}
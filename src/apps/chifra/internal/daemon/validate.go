@@ -26,8 +26,8 @@ func (opts *DaemonOptions) validateDaemon() error {
 		return validate.Usage("The {0} option is not available{1}.", "daemon", " in api mode")
 	}
 
-	if opts.Grpc {
-		return validate.Usage("The {0} option is deprecated. There is no replacement.", "--grpc")
+	if len(opts.Grpc) > 0 && opts.Grpc == opts.Url {
+		return validate.Usage("The {0} option must be different from the {1} option.", "--grpc", "--url")
 	}
 
 	// validate.ValidateEnum("scrape", opts.Scrape, "[off|blooms|index]")
@@ -0,0 +1,132 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package exportPkg
+
+import (
+	"io"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/filter"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/monitor"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// cursorMode returns the name of the output mode under which the cursor's positions are kept
+func (opts *ExportOptions) cursorMode() string {
+	switch {
+	case opts.Receipts:
+		return "receipts"
+	case opts.Logs:
+		return "logs"
+	case opts.Traces:
+		return "traces"
+	case opts.Withdrawals:
+		return "withdrawals"
+	case opts.Appearances:
+		return "appearances"
+	case opts.Balances:
+		return "balances"
+	case opts.Neighbors:
+		return "neighbors"
+	case opts.Statements:
+		return "statements"
+	case opts.Accounting:
+		return "accounting"
+	default:
+		return "transactions"
+	}
+}
+
+// applyCursor positions each monitor at the named cursor and returns the cursor advanced to the
+// last appearance each monitor will export during this run
+func (opts *ExportOptions) applyCursor(monitorArray []monitor.Monitor) (monitor.Cursors, error) {
+	chain := opts.Globals.Chain
+	cursors, err := monitor.ReadCursors(chain, opts.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := opts.cursorMode()
+	advanced := make(monitor.Cursors, len(cursors))
+	for key, app := range cursors {
+		advanced[key] = app
+	}
+
+	for i := range monitorArray {
+		mon := &monitorArray[i]
+		key := monitor.CursorKey{Address: mon.Address, Mode: mode}
+		if app, ok := cursors[key]; ok {
+			mon.Cursor = &types.AppRecord{BlockNumber: app.BlockNumber, TransactionIndex: app.TransactionIndex}
+		}
+
+		filter := filter.NewFilter(
+			false,
+			false,
+			[]string{},
+			base.BlockRange{First: opts.FirstBlock, Last: opts.LastBlock},
+			base.RecordRange{First: 0, Last: base.NOPOS},
+		)
+		apps, cnt, err := mon.ReadAndFilterAppearances(filter, false /* withCount */)
+		if err != nil {
+			return nil, err
+		}
+		if cnt > 0 {
+			last := apps[cnt-1]
+			advanced[key] = types.AppRecord{BlockNumber: last.BlockNumber, TransactionIndex: last.TransactionIndex}
+		}
+	}
+
+	return advanced, nil
+}
+
+// queuedCursor is an advanced cursor waiting for the command's output to be closed
+type queuedCursor struct {
+	chain    string
+	name     string
+	advanced monitor.Cursors
+}
+
+// queuedCursors holds the cursors advanced on the command line (there may be more than one with --file)
+var queuedCursors []queuedCursor
+
+// queueCursor holds on to the run's advanced cursor, if any, until StoreCursors is called
+func queueCursor(opts *ExportOptions) {
+	if opts.advanced != nil {
+		queuedCursors = append(queuedCursors, queuedCursor{opts.Globals.Chain, opts.Cursor, opts.advanced})
+	}
+}
+
+// StoreCursors stores the cursors advanced on the command line. It is called once the output has
+// been closed so that a cursor never moves past rows that were not delivered.
+func StoreCursors() {
+	storeCursors(GetOptions().Globals.Writer, queuedCursors)
+	queuedCursors = nil
+}
+
+// storeCursor stores the run's advanced cursor, if any, once the API response has been closed
+func (opts *ExportOptions) storeCursor() {
+	if opts.advanced != nil {
+		storeCursors(opts.Globals.Writer, []queuedCursor{{opts.Globals.Chain, opts.Cursor, opts.advanced}})
+	}
+}
+
+// storeCursors stores the cursors once everything written to w has been delivered. If the output
+// cannot be flushed, the cursors are left where they were.
+func storeCursors(w io.Writer, cursors []queuedCursor) {
+	if len(cursors) == 0 {
+		return
+	}
+	if err := output.Flush(w); err != nil {
+		logger.Error("could not advance cursor:", err)
+		return
+	}
+	for _, q := range cursors {
+		if err := monitor.WriteCursors(q.chain, q.name, q.advanced); err != nil {
+			logger.Error("could not advance cursor", q.name+":", err)
+		}
+	}
+}
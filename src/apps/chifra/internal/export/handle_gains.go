@@ -0,0 +1,96 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package exportPkg
+
+import (
+	"sort"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/gains"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/monitor"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// HandleGains builds tax lots from each monitor's statements and reports realized gains as
+// they happen followed by the unrealized positions and a yearly summary per asset.
+func (opts *ExportOptions) HandleGains(rCtx *output.RenderCtx, monitorArray []monitor.Monitor) error {
+	var specific gains.SpecificLots
+	if opts.LotMethod == "specific" {
+		var err error
+		if specific, err = gains.ReadSpecificLots(opts.Lots); err != nil {
+			return err
+		}
+	}
+
+	fetchData := func(modelChan chan types.Modeler, errorChan chan error) {
+		for _, mon := range monitorArray {
+			statements, ok := opts.collectStatements(rCtx, mon, errorChan)
+			if !ok {
+				return
+			}
+
+			tracker := gains.NewTracker(mon.Address, opts.LotMethod, specific)
+			for _, stmt := range statements {
+				for _, gain := range tracker.Add(stmt) {
+					gain := gain
+					modelChan <- &gain
+				}
+			}
+
+			for _, gain := range tracker.Unrealized() {
+				gain := gain
+				modelChan <- &gain
+			}
+
+			for _, gain := range tracker.Summary() {
+				gain := gain
+				modelChan <- &gain
+			}
+		}
+	}
+
+	extraOpts := map[string]any{
+		"ether": opts.Globals.Ether,
+	}
+
+	return output.StreamMany(rCtx, fetchData, opts.Globals.OutputOptsWithExtra(extraOpts))
+}
+
+// collectStatements runs the statements export for a single monitor and returns the
+// statements in chronological order. It returns false if the caller's context is canceled.
+func (opts *ExportOptions) collectStatements(rCtx *output.RenderCtx, mon monitor.Monitor, errorChan chan error) ([]*types.Statement, bool) {
+	sCtx := output.NewStreamingContext()
+	done := make(chan bool)
+	go func() {
+		_ = opts.HandleStatements(sCtx, []monitor.Monitor{mon})
+		close(done)
+	}()
+
+	statements := make([]*types.Statement, 0)
+	for {
+		select {
+		case model := <-sCtx.ModelChan:
+			if stmt, ok := model.(*types.Statement); ok {
+				statements = append(statements, stmt)
+			}
+		case err := <-sCtx.ErrorChan:
+			errorChan <- err
+		case <-done:
+			sort.SliceStable(statements, func(i, j int) bool {
+				if statements[i].BlockNumber == statements[j].BlockNumber {
+					if statements[i].TransactionIndex == statements[j].TransactionIndex {
+						return statements[i].LogIndex < statements[j].LogIndex
+					}
+					return statements[i].TransactionIndex < statements[j].TransactionIndex
+				}
+				return statements[i].BlockNumber < statements[j].BlockNumber
+			})
+			return statements, true
+		case <-rCtx.Ctx.Done():
+			sCtx.Cancel()
+			return nil, false
+		}
+	}
+}
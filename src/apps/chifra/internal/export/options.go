@@ -20,6 +20,7 @@ import (
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/caps"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/monitor"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/validate"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/walk"
@@ -38,6 +39,9 @@ type ExportOptions struct {
 	Neighbors   bool                  `json:"neighbors,omitempty"`   // Export the neighbors of the given address
 	Accounting  bool                  `json:"accounting,omitempty"`  // Attach accounting records to the exported data (applies to transactions export only)
 	Statements  bool                  `json:"statements,omitempty"`  // For the accounting options only, export only statements
+	Gains       bool                  `json:"gains,omitempty"`       // For the accounting options only, export realized gains, unrealized positions, and a yearly summary computed from tax lots
+	LotMethod   string                `json:"lotMethod,omitempty"`   // For the --gains option only, the method used to choose which lots are disposed of first
+	Lots        string                `json:"lots,omitempty"`        // For --lot_method specific only, a csv file of disposalHash,acquisitionHash pairs identifying the lots to dispose of
 	Balances    bool                  `json:"balances,omitempty"`    // Traverse the transaction history and show each change in ETH balances
 	Withdrawals bool                  `json:"withdrawals,omitempty"` // Export withdrawals for the given address
 	Articulate  bool                  `json:"articulate,omitempty"`  // Articulate transactions, traces, logs, and outputs
@@ -53,6 +57,7 @@ type ExportOptions struct {
 	Flow        string                `json:"flow,omitempty"`        // For the accounting options only, export statements with incoming, outgoing, or zero value
 	Factory     bool                  `json:"factory,omitempty"`     // For --traces only, report addresses created by (or self-destructed by) the given address(es)
 	Unripe      bool                  `json:"unripe,omitempty"`      // Export transactions labeled unripe (i.e. less than 28 blocks old)
+	Cursor      string                `json:"cursor,omitempty"`      // Resume the export from (and then advance) the named cursor which is stored per address and output mode
 	Reversed    bool                  `json:"reversed,omitempty"`    // Produce results in reverse chronological order
 	NoZero      bool                  `json:"noZero,omitempty"`      // For the --count option only, suppress the display of zero appearance accounts
 	FirstBlock  base.Blknum           `json:"firstBlock,omitempty"`  // First block to process (inclusive)
@@ -61,10 +66,12 @@ type ExportOptions struct {
 	Conn        *rpc.Connection       `json:"conn,omitempty"`        // The connection to the RPC server
 	BadFlag     error                 `json:"badFlag,omitempty"`     // An error flag if needed
 	// EXISTING_CODE
+	advanced monitor.Cursors // The cursor to store once this run's output has been delivered
 	// EXISTING_CODE
 }
 
 var defaultExportOptions = ExportOptions{
+	LotMethod:  "fifo",
 	MaxRecords: 250,
 	LastBlock:  base.NOPOSN,
 }
@@ -81,6 +88,9 @@ func (opts *ExportOptions) testLog() {
 	logger.TestLog(opts.Neighbors, "Neighbors: ", opts.Neighbors)
 	logger.TestLog(opts.Accounting, "Accounting: ", opts.Accounting)
 	logger.TestLog(opts.Statements, "Statements: ", opts.Statements)
+	logger.TestLog(opts.Gains, "Gains: ", opts.Gains)
+	logger.TestLog(len(opts.LotMethod) > 0 && opts.LotMethod != "fifo", "LotMethod: ", opts.LotMethod)
+	logger.TestLog(len(opts.Lots) > 0, "Lots: ", opts.Lots)
 	logger.TestLog(opts.Balances, "Balances: ", opts.Balances)
 	logger.TestLog(opts.Withdrawals, "Withdrawals: ", opts.Withdrawals)
 	logger.TestLog(opts.Articulate, "Articulate: ", opts.Articulate)
@@ -96,6 +106,7 @@ func (opts *ExportOptions) testLog() {
 	logger.TestLog(len(opts.Flow) > 0, "Flow: ", opts.Flow)
 	logger.TestLog(opts.Factory, "Factory: ", opts.Factory)
 	logger.TestLog(opts.Unripe, "Unripe: ", opts.Unripe)
+	logger.TestLog(len(opts.Cursor) > 0, "Cursor: ", opts.Cursor)
 	logger.TestLog(opts.Reversed, "Reversed: ", opts.Reversed)
 	logger.TestLog(opts.NoZero, "NoZero: ", opts.NoZero)
 	logger.TestLog(opts.FirstBlock != 0, "FirstBlock: ", opts.FirstBlock)
@@ -123,6 +134,7 @@ func ExportFinishParseInternal(w io.Writer, values url.Values) *ExportOptions {
 	copy := defaultExportOptions
 	copy.Globals.Caps = getCaps()
 	opts := &copy
+	opts.LotMethod = "fifo"
 	opts.MaxRecords = 250
 	opts.LastBlock = base.NOPOSN
 	for key, value := range values {
@@ -156,6 +168,12 @@ func ExportFinishParseInternal(w io.Writer, values url.Values) *ExportOptions {
 			opts.Accounting = true
 		case "statements":
 			opts.Statements = true
+		case "gains":
+			opts.Gains = true
+		case "lotMethod":
+			opts.LotMethod = value[0]
+		case "lots":
+			opts.Lots = value[0]
 		case "balances":
 			opts.Balances = true
 		case "withdrawals":
@@ -195,6 +213,8 @@ func ExportFinishParseInternal(w io.Writer, values url.Values) *ExportOptions {
 			opts.Factory = true
 		case "unripe":
 			opts.Unripe = true
+		case "cursor":
+			opts.Cursor = value[0]
 		case "reversed":
 			opts.Reversed = true
 		case "noZero":
@@ -303,6 +323,7 @@ func ResetOptions(testMode bool) {
 	opts.Globals.TestMode = testMode
 	opts.Globals.Writer = w
 	opts.Globals.Caps = getCaps()
+	opts.LotMethod = "fifo"
 	opts.MaxRecords = 250
 	opts.LastBlock = base.NOPOSN
 	defaultExportOptions = opts
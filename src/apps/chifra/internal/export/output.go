@@ -27,6 +27,7 @@ func RunExport(cmd *cobra.Command, args []string) error {
 	opts := exportFinishParse(args)
 	rCtx := output.NewRenderContext()
 	// EXISTING_CODE
+	defer queueCursor(opts)
 	// EXISTING_CODE
 	outputHelpers.SetWriterForCommand("export", &opts.Globals)
 	return opts.ExportInternal(rCtx)
@@ -37,6 +38,7 @@ func ServeExport(w http.ResponseWriter, r *http.Request) error {
 	opts := exportFinishParseApi(w, r)
 	rCtx := output.NewRenderContext()
 	// EXISTING_CODE
+	defer opts.storeCursor() // runs after the response has been closed
 	// EXISTING_CODE
 	outputHelpers.InitJsonWriterApi("export", w, &opts.Globals)
 	err := opts.ExportInternal(rCtx)
@@ -58,6 +60,19 @@ func (opts *ExportOptions) ExportInternal(rCtx *output.RenderCtx) error {
 	if canceled, err := opts.FreshenMonitorsForExport(rCtx, &monitorArray); err != nil || canceled {
 		return err
 	}
+	if len(opts.Cursor) > 0 {
+		advanced, cursorErr := opts.applyCursor(monitorArray)
+		if cursorErr != nil {
+			return cursorErr
+		}
+		defer func() {
+			// Only a complete, uncanceled run that delivered every record advances the cursor. It is
+			// stored later, once the output has been closed.
+			if err == nil && rCtx.Ctx.Err() == nil && !rCtx.HadErrors() {
+				opts.advanced = advanced
+			}
+		}()
+	}
 	// EXISTING_CODE
 	if opts.Globals.Decache {
 		err = opts.HandleDecache(rCtx, monitorArray)
@@ -77,6 +92,8 @@ func (opts *ExportOptions) ExportInternal(rCtx *output.RenderCtx) error {
 		err = opts.HandleBalances(rCtx, monitorArray)
 	} else if opts.Neighbors {
 		err = opts.HandleNeighbors(rCtx, monitorArray)
+	} else if opts.Gains {
+		err = opts.HandleGains(rCtx, monitorArray)
 	} else if opts.Statements {
 		err = opts.HandleStatements(rCtx, monitorArray)
 	} else if opts.Accounting {
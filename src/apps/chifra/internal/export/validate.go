@@ -11,8 +11,10 @@ import (
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/index"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/monitor"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/validate"
 )
 
@@ -179,13 +181,61 @@ func (opts *ExportOptions) validateExport() error {
 			return validate.Usage("The {0} option is only available with the {1} option.", "--statements", "--accounting")
 		}
 
+		if opts.Gains {
+			return validate.Usage("The {0} option is only available with the {1} option.", "--gains", "--accounting")
+		}
+
 		if opts.Globals.Format == "ofx" {
 			return validate.Usage("The {0} option is only available with the {1} option.", "--fmt ofx", "--accounting")
 		}
 	}
 
-	if len(opts.Asset) > 0 && !opts.Statements {
-		return validate.Usage("The {0} option is only available with the {1} option.", "--asset", "--statements")
+	if opts.Gains {
+		if opts.Statements {
+			return validate.Usage("The {0} and {1} options are mutually exclusive.", "--gains", "--statements")
+		}
+
+		if err := validate.ValidateEnum("--lot_method", opts.LotMethod, "[fifo|lifo|hifo|specific]"); err != nil {
+			return err
+		}
+
+		if opts.LotMethod == "specific" {
+			if len(opts.Lots) == 0 {
+				return validate.Usage("The {0} option requires the {1} option.", "--lot_method specific", "--lots")
+			}
+			if !file.FileExists(opts.Lots) {
+				return validate.Usage("The {0} option ({1}) must {2}", "--lots", opts.Lots, "exist")
+			}
+		} else if len(opts.Lots) > 0 {
+			return validate.Usage("The {0} option is only available with the {1} option.", "--lots", "--lot_method specific")
+		}
+
+	} else if opts.LotMethod != "fifo" {
+		return validate.Usage("The {0} option is only available with the {1} option.", "--lot_method", "--gains")
+
+	} else if len(opts.Lots) > 0 {
+		return validate.Usage("The {0} option is only available with the {1} option.", "--lots", "--gains")
+	}
+
+	if len(opts.Asset) > 0 && !opts.Statements && !opts.Gains {
+		return validate.Usage("The {0} option is only available with the {1} option.", "--asset", "--statements or --gains")
+	}
+
+	if len(opts.Cursor) > 0 {
+		if !monitor.IsValidCursorName(opts.Cursor) {
+			return validate.Usage("The {0} option ({1}) must {2}", "--cursor", opts.Cursor, "contain only letters, digits, dashes, or underscores")
+		}
+		if opts.Globals.Decache || opts.Count || opts.Gains {
+			return validate.Usage("The {0} option is not available{1}.", "--cursor", " with --decache, --count, or --gains")
+		}
+		if opts.Reversed || opts.Unripe {
+			return validate.Usage("The {0} option is not available{1}.", "--cursor", " with --reversed or --unripe")
+		}
+		if opts.FirstRecord != 0 || opts.MaxRecords != 250 {
+			return validate.Usage("The {0} option is not available{1}.", "--cursor", " with --first_record or --max_records")
+		}
+		// A cursor exports everything since the last run, even when serving the API
+		opts.MaxRecords = base.NOPOS
 	}
 
 	if !validate.HasArticulationKey(opts.Articulate) {
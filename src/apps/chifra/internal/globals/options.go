@@ -91,7 +91,7 @@ func InitGlobals(whoAmI string, cmd *cobra.Command, opts *GlobalOptions, c caps.
 	}
 
 	if opts.Caps.Has(caps.Fmt) {
-		cmd.Flags().StringVarP(&opts.Format, "fmt", "x", "", "export format, one of [none|json*|txt|csv]")
+		cmd.Flags().StringVarP(&opts.Format, "fmt", "x", "", "export format, one of [none|json*|txt|csv|ndjson|parquet|arrow]")
 	}
 
 	if opts.Caps.Has(caps.Verbose) {
@@ -188,7 +188,7 @@ func (opts *GlobalOptions) FinishParseApi(w io.Writer, values url.Values, caches
 			parts := strings.Split(opts.OutputFn, ".")
 			if len(parts) > 0 {
 				last := parts[len(parts)-1]
-				if last == "txt" || last == "csv" || last == "json" {
+				if last == "txt" || last == "csv" || last == "json" || last == "ndjson" || last == "parquet" || last == "arrow" {
 					opts.Format = last
 				}
 			}
@@ -218,7 +218,7 @@ func (opts *GlobalOptions) FinishParse(args []string, caches map[walk.CacheType]
 		parts := strings.Split(opts.OutputFn, ".")
 		if len(parts) > 0 {
 			last := parts[len(parts)-1]
-			if last == "txt" || last == "csv" || last == "json" {
+			if last == "txt" || last == "csv" || last == "json" || last == "ndjson" || last == "parquet" || last == "arrow" {
 				opts.Format = last
 			}
 		}
@@ -38,7 +38,7 @@ func (opts *GlobalOptions) Validate() error {
 	// 	}
 	// }
 
-	err := validate.ValidateEnum("--fmt", opts.Format, "[json|txt|csv]")
+	err := validate.ValidateEnum("--fmt", opts.Format, "[json|txt|csv|ndjson|parquet|arrow]")
 	if err != nil {
 		return err
 	}
@@ -13,6 +13,7 @@ import (
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/history"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/index"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/manifest"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
@@ -65,6 +66,18 @@ func (opts *InitOptions) HandleInit(rCtx *output.RenderCtx) error {
 	logger.InfoTable("Files deleted:", fmt.Sprintf("%d", nDeleted))
 	logger.InfoTable("Files downloaded:", fmt.Sprintf("%d", nToDownload))
 
+	// Each bloom file's header identifies its format, so a mix is fine, but the user may want to know
+	published, configured := remote.Config.BloomFormat, config.GetScrape(chain).BloomFormat
+	if (published == index.BloomFormatFuse) != (configured == index.BloomFormatFuse) {
+		if published == "" {
+			published = index.BloomFormatBloom
+		}
+		if configured == "" {
+			configured = index.BloomFormatBloom
+		}
+		logger.Warn(fmt.Sprintf("The published blooms are in the %s format, but chunks scraped locally will be in the %s format (see bloomFormat).", published, configured))
+	}
+
 	historyFile := filepath.Join(config.PathToCache(chain), "tmp/history.txt")
 	if opts.All && !history.FromHistoryBool(historyFile, "init") {
 		_ = history.ToHistory(historyFile, "init", "true")
@@ -7,6 +7,7 @@ package initPkg
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"sync"
@@ -28,6 +29,7 @@ var m sync.Mutex
 // TODO: So we can capture both the blooms and the index portions in one summary. Once we move to single stream, this can go local
 var nProcessed int
 var nStarted int
+var nQuarantined int
 
 // downloadAndReportProgress Downloads the chunks and reports progress to the progressChannel
 func (opts *InitOptions) downloadAndReportProgress(chunks []types.ChunkRecord, chunkType walk.CacheType, nTotal int) ([]types.ChunkRecord, bool) {
@@ -61,8 +63,11 @@ func (opts *InitOptions) downloadAndReportProgress(chunks []types.ChunkRecord, c
 		}
 
 		if event.Event == progress.AllDone {
-			msg := fmt.Sprintf("%sCompleted initializing %s files.%s", colors.BrightWhite, chunkType, colors.Off)
+			msg := fmt.Sprintf("%sCompleted initializing %s files. Each download was verified against its CID.%s", colors.BrightWhite, chunkType, colors.Off)
 			logger.Info(msg, strings.Repeat(" ", 60))
+			if nQuarantined > 0 {
+				logger.Warn(nQuarantined, "downloads did not match their CIDs and were quarantined in", filepath.Join(config.PathToIndex(chain), "quarantine"))
+			}
 			break
 		}
 
@@ -93,6 +98,10 @@ func (opts *InitOptions) downloadAndReportProgress(chunks []types.ChunkRecord, c
 			msg := fmt.Sprintf("%s%s%s", colors.Yellow, event.Message, colors.Off)
 			logger.Info(msg, spaces)
 
+		case progress.Quarantined:
+			nQuarantined++
+			logger.Warn(event.Message)
+
 		case progress.Finished:
 			nProcessed++
 			col := colors.Yellow
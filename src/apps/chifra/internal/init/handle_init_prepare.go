@@ -244,7 +244,7 @@ func isValidChunk(path string, bloomSize, indexSize int64, indexRequired bool) (
 			idx = FILE_MISSING
 		}
 	} else {
-		idx = checkSize(indexPath, indexSize)
+		idx = checkIndexSize(indexPath, indexSize)
 		if idx == OKAY {
 			idx, err = checkHeader(indexPath)
 		}
@@ -265,6 +265,19 @@ func checkSize(path string, expected int64) InitReason {
 	return OKAY
 }
 
+// checkIndexSize is checkSize for index files, which may have been extended with reasons since download
+func checkIndexSize(path string, expected int64) InitReason {
+	if !file.FileExists(path) {
+		logger.Fatal("should not happen ==> file existence already checked")
+	}
+
+	if !index.SizeMatches(path, expected) {
+		return WRONG_SIZE
+	}
+
+	return OKAY
+}
+
 func checkHeader(path string) (InitReason, error) {
 	if !file.FileExists(path) {
 		logger.Fatal("should not happen ==> file existence already checked")
@@ -291,7 +304,8 @@ func checkHeader(path string) (InitReason, error) {
 		if err != nil {
 			return FILE_ERROR, err
 		}
-		if hash != base.BytesToHash(config.HeaderHash(config.ExpectedVersion())) {
+		isFuse := hash == base.BytesToHash(config.HeaderHash(config.FuseVersion))
+		if hash != base.BytesToHash(config.HeaderHash(config.ExpectedVersion())) && !isFuse {
 			return WRONG_HASH, nil
 		}
 
@@ -312,7 +326,7 @@ func checkHeader(path string) (InitReason, error) {
 		if err != nil {
 			return FILE_ERROR, err
 		}
-		if hash != base.BytesToHash(config.HeaderHash(config.ExpectedVersion())) {
+		if !config.IsExpectedHeader(hash.Bytes()) {
 			return WRONG_HASH, nil
 		}
 
@@ -0,0 +1,119 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package listPkg
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/index"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// readReasons returns the reasons the address appears in each of the appearances as recorded in the index (either
+// in a finalized chunk or in the staging file). Appearances in chunks written without reasons are not in the map.
+func (opts *ListOptions) readReasons(address base.Address, apps []types.Appearance) (map[types.AppRecord]types.Reason, error) {
+	chain := opts.Globals.Chain
+	ret := make(map[types.AppRecord]types.Reason, len(apps))
+	if len(apps) == 0 {
+		return ret, nil
+	}
+
+	ranges, err := finalizedRanges(chain)
+	if err != nil {
+		return ret, err
+	}
+
+	// Find the chunks we need to visit. Anything not in a chunk is in the staging file (or is unripe).
+	needed := make(map[base.FileRange]bool)
+	staged := false
+	for _, app := range apps {
+		bn := base.Blknum(app.BlockNumber)
+		i := sort.Search(len(ranges), func(i int) bool { return ranges[i].Last >= bn })
+		if i < len(ranges) && ranges[i].First <= bn {
+			needed[ranges[i]] = true
+		} else {
+			staged = true
+		}
+	}
+
+	for rng := range needed {
+		indexChunk, err := index.OpenIndex(rng.RangeToFilename(chain), true /* check */)
+		if err != nil {
+			return ret, err
+		}
+		if indexChunk.HasReasons() {
+			appRecs, reasons, err := indexChunk.ReadReasons(address)
+			if err != nil {
+				indexChunk.Close()
+				return ret, err
+			}
+			for i, app := range appRecs {
+				ret[app] = reasons[i]
+			}
+		}
+		indexChunk.Close()
+	}
+
+	if staged {
+		readStagedReasons(chain, address, ret)
+	}
+
+	return ret, nil
+}
+
+// finalizedRanges returns the ranges of the chunks in the finalized folder sorted by block
+func finalizedRanges(chain string) ([]base.FileRange, error) {
+	entries, err := os.ReadDir(filepath.Join(config.PathToIndex(chain), "finalized"))
+	if err != nil {
+		return nil, err
+	}
+
+	ranges := make([]base.FileRange, 0, len(entries))
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".bin") {
+			continue
+		}
+		if rng, err := base.RangeFromFilenameE(entry.Name()); err == nil {
+			ranges = append(ranges, rng)
+		}
+	}
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].First < ranges[j].First
+	})
+	return ranges, nil
+}
+
+// readStagedReasons adds the reasons for the address found in the staging file to the map
+func readStagedReasons(chain string, address base.Address, reasons map[types.AppRecord]types.Reason) {
+	stageFn, _ := file.LatestFileInFolder(filepath.Join(config.PathToIndex(chain), "staging"))
+	if !file.FileExists(stageFn) {
+		return
+	}
+
+	prefix := address.Hex() + "\t"
+	for _, line := range file.AsciiFileToLines(stageFn) {
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+		parts := strings.Split(line, "\t")
+		if len(parts) != 4 {
+			continue // written without reasons
+		}
+		bn, err1 := strconv.ParseUint(parts[1], 10, 32)
+		txid, err2 := strconv.ParseUint(parts[2], 10, 32)
+		reason, err3 := strconv.ParseUint(parts[3], 16, 16)
+		if err1 != nil || err2 != nil || err3 != nil {
+			continue
+		}
+		reasons[types.AppRecord{BlockNumber: uint32(bn), TransactionIndex: uint32(txid)}] = types.Reason(reason)
+	}
+}
@@ -29,6 +29,10 @@ func (opts *ListOptions) HandleShow(rCtx *output.RenderCtx, monitorArray []monit
 		base.RecordRange{First: opts.FirstRecord, Last: opts.GetMax()},
 	)
 
+	// Only appearances in which the address appears for one of these reasons are listed (if any are given)
+	wanted := types.ReasonFromNames(opts.As)
+	withReasons := opts.Reasons || wanted != 0
+
 	fetchData := func(modelChan chan types.Modeler, errorChan chan error) {
 		currentBn := uint32(0)
 		currentTs := base.Timestamp(0)
@@ -49,12 +53,33 @@ func (opts *ListOptions) HandleShow(rCtx *output.RenderCtx, monitorArray []monit
 				errorChan <- err
 				continue // on error
 			} else if !opts.NoZero || cnt > 0 {
+				var reasons map[types.AppRecord]types.Reason
+				if withReasons {
+					if reasons, err = opts.readReasons(mon.Address, apps); err != nil {
+						errorChan <- err
+						continue
+					}
+				}
+				nUnknown := 0
 				for _, app := range apps {
+					if withReasons {
+						reason, known := reasons[types.AppRecord{BlockNumber: app.BlockNumber, TransactionIndex: app.TransactionIndex}]
+						if !known {
+							nUnknown++
+						}
+						if wanted != 0 && reason&wanted == 0 {
+							continue
+						}
+						app.Reason = reason.String()
+					}
 					if err := visitAppearance(&app); err != nil {
 						errorChan <- err
 						return
 					}
 				}
+				if wanted != 0 && nUnknown > 0 {
+					errorChan <- fmt.Errorf("%d appearance(s) of %s are in chunks written without reasons and were not listed (run chifra chunks index --rewrite to add reasons to the index)", nUnknown, mon.Address.Hex())
+				}
 			} else {
 				errorChan <- fmt.Errorf("no appearances found for %s", mon.Address.Hex())
 				continue
@@ -62,7 +87,11 @@ func (opts *ListOptions) HandleShow(rCtx *output.RenderCtx, monitorArray []monit
 		}
 	}
 
-	return output.StreamMany(rCtx, fetchData, opts.Globals.OutputOpts())
+	extraOpts := map[string]any{
+		"reasons": withReasons,
+	}
+
+	return output.StreamMany(rCtx, fetchData, opts.Globals.OutputOptsWithExtra(extraOpts))
 }
 
 func (opts *ListOptions) IsMax(cnt uint64) bool {
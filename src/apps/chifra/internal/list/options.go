@@ -38,6 +38,8 @@ type ListOptions struct {
 	FirstRecord uint64                `json:"firstRecord,omitempty"` // The first record to process
 	MaxRecords  uint64                `json:"maxRecords,omitempty"`  // The maximum number of records to process
 	Reversed    bool                  `json:"reversed,omitempty"`    // Produce results in reverse chronological order
+	Reasons     bool                  `json:"reasons,omitempty"`     // Include the reasons the address appears in each appearance (from the index)
+	As          []string              `json:"as,omitempty"`          // List only appearances in which the address appears for one of these reasons (implies --reasons)
 	Publisher   string                `json:"publisher,omitempty"`   // For some query options, the publisher of the index
 	FirstBlock  base.Blknum           `json:"firstBlock,omitempty"`  // First block to export (inclusive, ignored when freshening)
 	LastBlock   base.Blknum           `json:"lastBlock,omitempty"`   // Last block to export (inclusive, ignored when freshening)
@@ -65,6 +67,8 @@ func (opts *ListOptions) testLog() {
 	logger.TestLog(opts.FirstRecord != 0, "FirstRecord: ", opts.FirstRecord)
 	logger.TestLog(opts.MaxRecords != 250, "MaxRecords: ", opts.MaxRecords)
 	logger.TestLog(opts.Reversed, "Reversed: ", opts.Reversed)
+	logger.TestLog(opts.Reasons, "Reasons: ", opts.Reasons)
+	logger.TestLog(len(opts.As) > 0, "As: ", opts.As)
 	logger.TestLog(len(opts.Publisher) > 0, "Publisher: ", opts.Publisher)
 	logger.TestLog(opts.FirstBlock != 0, "FirstBlock: ", opts.FirstBlock)
 	logger.TestLog(opts.LastBlock != base.NOPOSN && opts.LastBlock != 0, "LastBlock: ", opts.LastBlock)
@@ -116,6 +120,13 @@ func ListFinishParseInternal(w io.Writer, values url.Values) *ListOptions {
 			opts.MaxRecords = base.MustParseUint64(value[0])
 		case "reversed":
 			opts.Reversed = true
+		case "reasons":
+			opts.Reasons = true
+		case "as":
+			for _, val := range value {
+				s := strings.Split(val, " ") // may contain space separated items
+				opts.As = append(opts.As, s...)
+			}
 		case "publisher":
 			opts.Publisher = value[0]
 		case "firstBlock":
@@ -58,6 +58,14 @@ func (opts *ListOptions) validateList() error {
 		return validate.Usage("The {0} option is not available{1}.", "--count", "with the --max_records-"+x+" option")
 	}
 
+	if err := validate.ValidateEnumSlice("--as", opts.As, "[from|to|creation|input|generator|topic|data|trace|miner|uncle|withdrawal|genesis]"); err != nil {
+		return err
+	}
+
+	if (opts.Reasons || len(opts.As) > 0) && (opts.Count || opts.Bounds) {
+		return validate.Usage("The {0} option is not available{1}.", "--reasons and --as", " with the --count or --bounds options")
+	}
+
 	if opts.NoZero && !opts.Count {
 		return validate.Usage("The {0} option is only available with the {1} option.", "--no_zero", "--count")
 	}
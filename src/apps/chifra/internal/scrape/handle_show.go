@@ -108,6 +108,7 @@ func (opts *ScrapeOptions) HandleScrape(rCtx *output.RenderCtx) error {
 		// We create a new manager for each loop...we will populate it in a minute...
 		bm := BlazeManager{
 			chain: chain,
+			opts:  opts,
 		}
 
 		// Fetch the meta data which tells us how far along the index is.
@@ -124,6 +125,18 @@ func (opts *ScrapeOptions) HandleScrape(rCtx *output.RenderCtx) error {
 			goto PAUSE
 		}
 
+		// If the chain reorganized under the blocks we've scraped, roll back what we wrote from the
+		// orphaned blocks. The index then ends at the fork, so this round scrapes from there.
+		if rolledBack, err := bm.HandleReorg(); err != nil {
+			logger.Error(colors.BrightRed+err.Error(), colors.Off)
+			goto PAUSE
+		} else if rolledBack {
+			if bm.meta, err = opts.Conn.GetMetaData(testMode); err != nil {
+				logger.Error(colors.BrightRed+err.Error(), colors.Off)
+				goto PAUSE
+			}
+		}
+
 		// Another rare case, but here the user has reset his/her node but not removed
 		// the index. In this case, the index is ahead of the chain. We go to sleep and
 		// try again later in the hopes that the chain catches up.
@@ -144,6 +157,7 @@ func (opts *ScrapeOptions) HandleScrape(rCtx *output.RenderCtx) error {
 			nRipe:        0,
 			nUnripe:      0,
 			timestamps:   make(map[base.Blknum]tslib.TimestampRecord, opts.BlockCnt),
+			hashes:       make(map[base.Blknum]tslib.HashRecord, opts.BlockCnt),
 			processedMap: make(map[base.Blknum]bool, opts.BlockCnt),
 			meta:         bm.meta,
 			nChannels:    int(opts.Settings.ChannelCount),
@@ -35,8 +35,8 @@ func (opts *ScrapeOptions) HandleTouch(rCtx *output.RenderCtx) error {
 		var chunk index.Chunk
 		var bm = BlazeManager{}
 		_ = file.StringToAsciiFile(stagePath, base.SkippedSender.Hex()+"\t0\t0\n")
-		appMap, _, nAppearances := bm.AsciiFileToAppearanceMap(stagePath)
-		if report, err := chunk.Write(chain, base.ZeroAddr, indexPath, appMap, nAppearances); err != nil {
+		appMap, _, _, nAppearances := bm.AsciiFileToAppearanceMap(stagePath)
+		if report, err := chunk.Write(chain, base.ZeroAddr, indexPath, appMap, nil, nAppearances); err != nil {
 			errorChan <- err
 		} else {
 			modelChan <- &types.Message{Msg: report.Report()}
@@ -40,18 +40,38 @@ func Notify[T notify.NotificationPayload](notification notify.Notification[T]) e
 	return notifyEndpoint(endpoint, notification)
 }
 
+// notifying returns true if there is anyone to notify, either the configured endpoint (with
+// --notify) or a listener in this process (such as chifra daemon's websocket subscribers)
+func (opts *ScrapeOptions) notifying() bool {
+	return opts.Notify || notify.HasListeners()
+}
+
+// publish sends the notification to the listeners in this process and, with --notify, to the
+// configured endpoint
+func publish[T notify.NotificationPayload](opts *ScrapeOptions, notification notify.Notification[T]) error {
+	notify.Publish(notification)
+	if !opts.Notify {
+		return nil
+	}
+	return Notify(notification)
+}
+
 func notifyEndpoint(endpoint string, notification any) error {
 	encoded, err := json.Marshal(notification)
 	if err != nil {
 		return fmt.Errorf("marshalling message: %w", err)
 	}
 
-	resp, err := http.Post(
-		endpoint,
-		"application/json",
-		bytes.NewReader(encoded),
-	)
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(encoded))
+	if err != nil {
+		return fmt.Errorf("sending notification: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if secret := config.GetSettings().Notify.Secret; secret != "" {
+		req.Header.Set(notify.SecretHeader, secret)
+	}
 
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		if errors.Is(err, syscall.ECONNREFUSED) {
 			return ErrConfiguredButNotRunning
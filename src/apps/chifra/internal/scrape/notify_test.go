@@ -57,11 +57,12 @@ func TestNotify(t *testing.T) {
 }
 
 func TestNotificationDataAppearance_FromString(t *testing.T) {
-	addrMap := make(uniq.AddressBooleanMap, 0)
+	addrMap := make(uniq.AddressReasonMap, 0)
 	key := addrMap.Insert(
 		"0xfffd8963efd1fc6a506488495d951d5263988d25",
 		18509161,
 		132,
+		types.ReasonFrom,
 	)
 
 	n := &notify.NotificationPayloadAppearance{}
@@ -125,6 +125,10 @@ func ScrapeFinishParseInternal(w io.Writer, values url.Values) *ScrapeOptions {
 			configs[key] = value[0]
 		case "allowMissing":
 			configs[key] = value[0]
+		case "bloomFormat":
+			configs[key] = value[0]
+		case "reasons":
+			configs[key] = value[0]
 		default:
 			if !copy.Globals.Caps.HasKey(key) {
 				err := validate.Usage("Invalid key ({0}) in {1} route.", key, "scrape")
@@ -270,6 +274,10 @@ func getConfigCmdsFromArgs() map[string]string {
 			configs["channelCount"] = next
 		case "--allow_missing":
 			configs["allowMissing"] = "true"
+		case "--bloom_format":
+			configs["bloomFormat"] = next
+		case "--reasons":
+			configs["reasons"] = "true"
 		}
 	}
 	return configs
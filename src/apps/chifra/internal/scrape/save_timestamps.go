@@ -14,6 +14,8 @@ import (
 
 // TODO: Protect against overwriting files on disc
 
+// WriteTimestamps appends this round's timestamps to the timestamp file and the blocks' hashes to
+// its companion hash file.
 func (bm *BlazeManager) WriteTimestamps(ctx context.Context, blocks []base.Blknum) error {
 	chain := bm.chain
 
@@ -43,24 +45,31 @@ func (bm *BlazeManager) WriteTimestamps(ctx context.Context, blocks []base.Blknu
 		// don't get more than maxBlocks at a time
 		cnt := 0
 		maxBlocks := 2000
+		hashes := make([]tslib.HashRecord, 0, maxBlocks)
 		for block := nTimestamps; block < blocks[0] && cnt < maxBlocks; block++ {
 			if ctx.Err() != nil {
 				// This means the context got cancelled, i.e. we got a SIGINT.
-				return nil
+				break
 			}
+			header, _ := bm.opts.Conn.GetBlockHeaderByNumber(block)
 			ts := tslib.TimestampRecord{
 				Bn: uint32(block),
-				Ts: uint32(bm.opts.Conn.GetBlockTimestamp(block)),
+				Ts: uint32(header.Timestamp),
 			}
 			msg := fmt.Sprintf("Backfilling timestamps (%d-%d) at ", cnt, maxBlocks)
 			logProgressTs(msg, block, blocks[len(blocks)-1])
 			if err := binary.Write(fp, binary.LittleEndian, &ts); err != nil {
 				return err
 			}
+			hashes = append(hashes, tslib.HashRecord{
+				Bn:         uint32(block),
+				Hash:       header.Hash,
+				ParentHash: header.ParentHash,
+			})
 			cnt++
 		}
 		// we must return early here, otherwise there will be skipped records
-		return nil
+		return tslib.WriteHashes(chain, hashes)
 	}
 
 	if ctx.Err() != nil {
@@ -70,6 +79,7 @@ func (bm *BlazeManager) WriteTimestamps(ctx context.Context, blocks []base.Blknu
 
 	// Append to the timestamps file all the new timestamps but as we do that make sure we're
 	// not skipping anything at the front, in the middle, or at the end of the list
+	hashes := make([]tslib.HashRecord, 0, len(blocks))
 	for _, block := range blocks {
 		if block < nTimestamps {
 			// We already have this timestampe, skip out
@@ -87,7 +97,11 @@ func (bm *BlazeManager) WriteTimestamps(ctx context.Context, blocks []base.Blknu
 		if err := binary.Write(fp, binary.LittleEndian, &ts); err != nil {
 			return err
 		}
+		hashes = append(hashes, bm.hashes[block])
+	}
 
+	if err := tslib.WriteHashes(chain, hashes); err != nil {
+		return err
 	}
 
 	if !bm.isHeadless {
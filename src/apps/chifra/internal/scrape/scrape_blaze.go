@@ -30,7 +30,7 @@ func (bm *BlazeManager) HandleBlaze(ctx context.Context, blocks []base.Blknum) (
 	// We need three pipelines...we shove into blocks, blocks shoves into appearances and timestamps
 	blockChannel := make(chan base.Blknum)
 	appearanceChannel := make(chan scrapedData)
-	tsChannel := make(chan tslib.TimestampRecord)
+	tsChannel := make(chan scrapedData)
 
 	// TODO: The go routines below may fail. Question -- how does one respond to an error inside a go routine?
 
@@ -86,16 +86,25 @@ func (bm *BlazeManager) HandleBlaze(ctx context.Context, blocks []base.Blknum) (
 func (bm *BlazeManager) ProcessBlocks(blockChannel chan base.Blknum, blockWg *sync.WaitGroup, appearanceChannel chan scrapedData) (err error) {
 	defer blockWg.Done()
 	for bn := range blockChannel {
+		header, err := bm.opts.Conn.GetBlockHeaderByNumber(bn)
+		if err != nil {
+			bm.errors = append(bm.errors, scrapeError{block: bn, err: err})
+			continue
+		}
 		sd := scrapedData{
 			bn: bn,
 			ts: tslib.TimestampRecord{
 				Bn: uint32(bn),
-				Ts: uint32(bm.opts.Conn.GetBlockTimestamp(bn)),
+				Ts: uint32(header.Timestamp),
+			},
+			hashes: tslib.HashRecord{
+				Bn:         uint32(bn),
+				Hash:       header.Hash,
+				ParentHash: header.ParentHash,
 			},
 		}
 
 		// TODO: BOGUS - we should send in an errorChannel and send the error down that channel and continue here
-		var err error
 		if sd.traces, err = bm.opts.Conn.GetTracesByBlockNumber(bn); err != nil {
 			bm.errors = append(bm.errors, scrapeError{block: bn, err: err})
 		} else if sd.receipts, _, err = bm.opts.Conn.GetReceiptsByNumber(bn, base.Timestamp(sd.ts.Ts)); err != nil {
@@ -112,11 +121,11 @@ func (bm *BlazeManager) ProcessBlocks(blockChannel chan base.Blknum, blockWg *sy
 var blazeMutex sync.Mutex
 
 // ProcessAppearances processes scrapedData objects shoved down the appearanceChannel
-func (bm *BlazeManager) ProcessAppearances(appearanceChannel chan scrapedData, appWg *sync.WaitGroup, tsChannel chan tslib.TimestampRecord) (err error) {
+func (bm *BlazeManager) ProcessAppearances(appearanceChannel chan scrapedData, appWg *sync.WaitGroup, tsChannel chan scrapedData) (err error) {
 	defer appWg.Done()
 
 	for sData := range appearanceChannel {
-		addrMap := make(uniq.AddressBooleanMap)
+		addrMap := make(uniq.AddressReasonMap)
 		if err = uniq.UniqFromTraces(bm.chain, sData.traces, addrMap); err != nil {
 			bm.errors = append(bm.errors, scrapeError{block: sData.bn, err: err})
 
@@ -132,18 +141,19 @@ func (bm *BlazeManager) ProcessAppearances(appearanceChannel chan scrapedData, a
 				bm.errors = append(bm.errors, scrapeError{block: sData.bn, err: err})
 			}
 		}
-		tsChannel <- sData.ts
+		tsChannel <- sData
 	}
 
 	return
 }
 
-// ProcessTimestamps processes timestamp data (currently by printing to a temporary file)
-func (bm *BlazeManager) ProcessTimestamps(tsChannel chan tslib.TimestampRecord, tsWg *sync.WaitGroup) (err error) {
+// ProcessTimestamps collects each block's timestamp and hashes for writing to the timestamp database
+func (bm *BlazeManager) ProcessTimestamps(tsChannel chan scrapedData, tsWg *sync.WaitGroup) (err error) {
 	defer tsWg.Done()
-	for ts := range tsChannel {
+	for sData := range tsChannel {
 		blazeMutex.Lock()
-		bm.timestamps[base.Blknum(ts.Bn)] = ts
+		bm.timestamps[sData.bn] = sData.ts
+		bm.hashes[sData.bn] = sData.hashes
 		bm.nTimestamps++
 		blazeMutex.Unlock()
 	}
@@ -161,7 +171,7 @@ var writeMutex sync.Mutex
 // processedMap (the pointer would serve that purpose).
 
 // WriteAppearances writes the appearance for a chunk to a file
-func (bm *BlazeManager) WriteAppearances(bn base.Blknum, addrMap uniq.AddressBooleanMap) (err error) {
+func (bm *BlazeManager) WriteAppearances(bn base.Blknum, addrMap uniq.AddressReasonMap) (err error) {
 	ripePath := filepath.Join(config.PathToIndex(bm.chain), "ripe")
 	unripePath := filepath.Join(config.PathToIndex(bm.chain), "unripe")
 	appendScrapeError := func(err error) {
@@ -171,8 +181,8 @@ func (bm *BlazeManager) WriteAppearances(bn base.Blknum, addrMap uniq.AddressBoo
 
 	if len(addrMap) > 0 {
 		appearanceArray := make([]string, 0, len(addrMap))
-		for record := range addrMap {
-			appearanceArray = append(appearanceArray, record)
+		for record, reason := range addrMap {
+			appearanceArray = append(appearanceArray, fmt.Sprintf(reasonFmt, record, uint16(reason)))
 			if bn <= bm.ripeBlock {
 				// Only notify about ripe block's appearances
 				payloadItem := notify.NotificationPayloadAppearance{}
@@ -199,8 +209,8 @@ func (bm *BlazeManager) WriteAppearances(bn base.Blknum, addrMap uniq.AddressBoo
 		}
 	}
 
-	if bm.opts.Notify && bn <= bm.ripeBlock {
-		err = Notify(notify.Notification[[]notify.NotificationPayloadAppearance]{
+	if bm.opts.notifying() && bn <= bm.ripeBlock {
+		err = publish(bm.opts, notify.Notification[[]notify.NotificationPayloadAppearance]{
 			Msg:     notify.MessageAppearance,
 			Meta:    bm.meta,
 			Payload: notificationPayload,
@@ -263,6 +273,7 @@ func (bm *BlazeManager) syncedReporting(bn base.Blknum, force bool) {
 type scrapedData struct {
 	bn          base.Blknum
 	ts          tslib.TimestampRecord
+	hashes      tslib.HashRecord
 	traces      []types.Trace
 	receipts    []types.Receipt
 	withdrawals []types.Withdrawal
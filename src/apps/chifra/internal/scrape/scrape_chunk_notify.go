@@ -8,7 +8,7 @@ import (
 )
 
 func (opts *ScrapeOptions) NotifyChunkWritten(chunk index.Chunk, chunkPath string) (err error) {
-	if !opts.Notify {
+	if !opts.notifying() {
 		return nil
 	}
 
@@ -22,7 +22,7 @@ func (opts *ScrapeOptions) NotifyChunkWritten(chunk index.Chunk, chunkPath strin
 
 	// Generate range from path, as chunks sometimes don't have Range set
 	chunkRange := base.RangeFromFilename(index.ToIndexPath(chunkPath))
-	return Notify(notify.Notification[[]notify.NotificationPayloadChunkWritten]{
+	return publish(opts, notify.Notification[[]notify.NotificationPayloadChunkWritten]{
 		Msg:  notify.MessageChunkWritten,
 		Meta: nil,
 		Payload: []notify.NotificationPayloadChunkWritten{
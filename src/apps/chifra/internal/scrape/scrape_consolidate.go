@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
@@ -18,7 +19,8 @@ import (
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
 )
 
-const asciiAppearanceSize = 59
+// reasonFmt appends the reasons (as four hex digits of a uint16) to an appearance record in the ripe, unripe, and staging files
+const reasonFmt = "%s\t%04x"
 
 // Consolidate calls into the block scraper to (a) call Blaze and (b) consolidate if applicable
 func (bm *BlazeManager) Consolidate(ctx context.Context, blocks []base.Blknum) error {
@@ -46,7 +48,7 @@ func (bm *BlazeManager) Consolidate(ctx context.Context, blocks []base.Blknum) e
 
 	// Load the stage into the map...
 	exists := file.FileExists(stageFn) // order matters
-	appMap, chunkRange, nAppearances := bm.AsciiFileToAppearanceMap(stageFn)
+	appMap, reasonMap, chunkRange, nAppearances := bm.AsciiFileToAppearanceMap(stageFn)
 	if !exists {
 		// Brand new stage.
 		chunkRange = base.FileRange{First: bm.meta.Finalized + 1, Last: blocks[0]}
@@ -80,12 +82,13 @@ func (bm *BlazeManager) Consolidate(ctx context.Context, blocks []base.Blknum) e
 		}
 
 		// Read in the ripe file, add it to the appMap and...
-		thisMap, _, thisCount := bm.AsciiFileToAppearanceMap(ripeFn)
+		thisMap, thisReasons, _, thisCount := bm.AsciiFileToAppearanceMap(ripeFn)
 		nAppearances += thisCount
 		nAppsFound += thisCount
 		nAddrsFound += len(thisMap)
 		for addr, apps := range thisMap {
 			appMap[addr] = append(appMap[addr], apps...)
+			reasonMap[addr] = append(reasonMap[addr], thisReasons[addr]...)
 		}
 		chunkRange.Last = block
 
@@ -97,7 +100,7 @@ func (bm *BlazeManager) Consolidate(ctx context.Context, blocks []base.Blknum) e
 			chunkPath := filepath.Join(config.PathToIndex(chain), "finalized", chunkRange.String()+".bin")
 			publisher := base.ZeroAddr
 			var chunk index.Chunk
-			if report, err := chunk.Write(chain, publisher, chunkPath, appMap, nAppearances); err != nil {
+			if report, err := chunk.Write(chain, publisher, chunkPath, appMap, reasonMap, nAppearances); err != nil {
 				// Remove file if it exists, because it might not be correct
 				_ = os.Remove(index.ToIndexPath(chunkPath))
 				return NewCriticalError(err)
@@ -115,6 +118,7 @@ func (bm *BlazeManager) Consolidate(ctx context.Context, blocks []base.Blknum) e
 			// reset for next chunk
 			bm.meta, _ = bm.opts.Conn.GetMetaData(bm.IsTestMode())
 			appMap = make(map[string][]types.AppRecord, 0)
+			reasonMap = make(map[string][]types.Reason, 0)
 			chunkRange.First = chunkRange.Last + 1
 			chunkRange.Last = chunkRange.Last + 1
 			nAppearances = 0
@@ -123,18 +127,20 @@ func (bm *BlazeManager) Consolidate(ctx context.Context, blocks []base.Blknum) e
 	}
 
 	var newRange base.FileRange
+	nAppsNow := 0
 	if len(appMap) > 0 { // are there any appearances in this block range?
 		newRange = base.FileRange{First: bm.meta.Finalized + 1, Last: 0}
 
 		// We need an array because we're going to write it back to disc
 		appearances := make([]string, 0, nAppearances)
 		for addr, apps := range appMap {
-			for _, app := range apps {
+			reasons := reasonMap[addr]
+			for i, app := range apps {
 				if ctx.Err() != nil {
 					// This means the context got cancelled, i.e. we got a SIGINT.
 					return nil
 				}
-				record := fmt.Sprintf("%s\t%09d\t%05d", addr, app.BlockNumber, app.TransactionIndex)
+				record := fmt.Sprintf(reasonFmt, fmt.Sprintf("%s\t%09d\t%05d", addr, app.BlockNumber, app.TransactionIndex), uint16(reasons[i]))
 				appearances = append(appearances, record)
 				newRange.Last = base.Max(newRange.Last, base.Blknum(app.BlockNumber))
 			}
@@ -148,14 +154,14 @@ func (bm *BlazeManager) Consolidate(ctx context.Context, blocks []base.Blknum) e
 			os.Remove(stageFn)
 			return err
 		}
+		nAppsNow = len(appearances)
 	}
 
 	// Let the user know what happened...
-	nAppsNow := int(file.FileSize(stageFn) / asciiAppearanceSize)
 	bm.report(len(blocks), int(bm.PerChunk()), nChunks, nAppsNow, nAppsFound, nAddrsFound)
 
-	if bm.opts.Notify {
-		if err := Notify(notify.Notification[string]{
+	if bm.opts.notifying() {
+		if err := publish(bm.opts, notify.Notification[string]{
 			Msg:     notify.MessageStageUpdated,
 			Meta:    bm.meta,
 			Payload: newRange.String(),
@@ -170,22 +176,24 @@ func (bm *BlazeManager) Consolidate(ctx context.Context, blocks []base.Blknum) e
 	return nil
 }
 
-// AsciiFileToAppearanceMap reads the appearances from the stage file and returns them as a map
-func (bm *BlazeManager) AsciiFileToAppearanceMap(fn string) (map[string][]types.AppRecord, base.FileRange, int) {
+// AsciiFileToAppearanceMap reads the appearances from the stage file and returns them as a map along with
+// a map of the reasons for each appearance (in the same order). Records written without reasons have unknown reasons.
+func (bm *BlazeManager) AsciiFileToAppearanceMap(fn string) (map[string][]types.AppRecord, map[string][]types.Reason, base.FileRange, int) {
 	appearances := file.AsciiFileToLines(fn)
 	os.Remove(fn) // It's okay to remove this. If it fails, we'll just start over.
 
 	appMap := make(map[string][]types.AppRecord, len(appearances))
+	reasonMap := make(map[string][]types.Reason, len(appearances))
 	fileRange := base.FileRange{First: base.NOPOSN, Last: 0}
 
 	if len(appearances) == 0 {
-		return appMap, base.FileRange{First: 0, Last: 0}, 0
+		return appMap, reasonMap, base.FileRange{First: 0, Last: 0}, 0
 	}
 
 	nAdded := 0
 	for _, line := range appearances {
 		parts := strings.Split(line, "\t")
-		if len(parts) == 3 { // shouldn't be needed, but just in case...
+		if len(parts) == 3 || len(parts) == 4 { // shouldn't be needed, but just in case...
 			addr := strings.ToLower(parts[0])
 			bn := base.MustParseBlknum(strings.TrimLeft(parts[1], "0"))
 			txid := base.MustParseTxnum(strings.TrimLeft(parts[2], "0"))
@@ -199,11 +207,18 @@ func (bm *BlazeManager) AsciiFileToAppearanceMap(fn string) (map[string][]types.
 				BlockNumber:      uint32(bn),
 				TransactionIndex: uint32(txid),
 			})
+			var reason types.Reason
+			if len(parts) == 4 {
+				if r, err := strconv.ParseUint(parts[3], 16, 16); err == nil {
+					reason = types.Reason(r)
+				}
+			}
+			reasonMap[addr] = append(reasonMap[addr], reason)
 			nAdded++
 		}
 	}
 
-	return appMap, fileRange, nAdded
+	return appMap, reasonMap, fileRange, nAdded
 }
 
 // hasNoAddresses returns true if (a) the miner is zero, (b) there are no transactions, uncles, or withdrawals.
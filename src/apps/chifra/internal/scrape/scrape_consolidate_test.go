@@ -0,0 +1,46 @@
+package scrapePkg
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/uniq"
+)
+
+func TestAsciiFileToAppearanceMap(t *testing.T) {
+	addrMap := uniq.AddressReasonMap{}
+	key := addrMap.Insert("0x1111111111111111111111111111111111111111", 12, 3, types.ReasonFrom|types.ReasonTopic)
+
+	// Files written before reasons were added to the scraper have no reason column
+	lines := []string{
+		fmt.Sprintf(reasonFmt, key, uint16(addrMap[key])),
+		"0x1111111111111111111111111111111111111111\t000000013\t00000",
+	}
+	fn := filepath.Join(t.TempDir(), "000000012.txt")
+	if err := file.LinesToAsciiFile(fn, lines); err != nil {
+		t.Fatal(err)
+	}
+	if size := file.FileSize(fn); size != 64+59 { // each line's length including its newline
+		t.Errorf("unexpected file size %d", size)
+	}
+
+	bm := BlazeManager{}
+	appMap, reasonMap, rng, n := bm.AsciiFileToAppearanceMap(fn)
+	if n != 2 || rng.First != 12 || rng.Last != 13 {
+		t.Fatal("wrong count or range", n, rng)
+	}
+
+	addr := "0x1111111111111111111111111111111111111111"
+	if len(appMap[addr]) != 2 || len(reasonMap[addr]) != 2 {
+		t.Fatal("wrong number of appearances", appMap[addr], reasonMap[addr])
+	}
+	if appMap[addr][0].BlockNumber != 12 || appMap[addr][0].TransactionIndex != 3 {
+		t.Error("wrong appearance", appMap[addr][0])
+	}
+	if reasonMap[addr][0] != types.ReasonFrom|types.ReasonTopic || reasonMap[addr][1] != 0 {
+		t.Error("wrong reasons", reasonMap[addr])
+	}
+}
@@ -15,6 +15,7 @@ import (
 type BlazeManager struct {
 	chain        string
 	timestamps   map[base.Blknum]tslib.TimestampRecord
+	hashes       map[base.Blknum]tslib.HashRecord
 	processedMap map[base.Blknum]bool
 	opts         *ScrapeOptions
 	meta         *types.MetaData
@@ -26,6 +26,13 @@ func (opts *ScrapeOptions) Prepare() (ok bool, err error) {
 	// We always clean the temporary folders (other than staging) when starting
 	_ = cleanEphemeralIndexFolders(chain)
 
+	// Installs that predate the hash file have timestamps but no hashes for the blocks scraped earlier
+	if nTimestamps, _ := tslib.NTimestamps(chain); nTimestamps > 0 {
+		if hashes, _ := tslib.FromBnToHash(chain, 0); !hashes.IsKnown() {
+			logger.Warn("The timestamp database has no block hashes. Run chifra when --timestamps --update to add them.")
+		}
+	}
+
 	// If the file already exists, we're done.
 	bloomPath := filepath.Join(config.PathToIndex(chain), "blooms/000000000-000000000.bloom")
 	if file.FileExists(bloomPath) {
@@ -40,25 +47,29 @@ func (opts *ScrapeOptions) Prepare() (ok bool, err error) {
 	}
 
 	appMap := make(map[string][]types.AppRecord, len(prefunds))
+	reasonMap := make(map[string][]types.Reason, len(prefunds))
 	for i, prefund := range prefunds {
 		addr := prefund.Address.Hex()
 		appMap[addr] = append(appMap[addr], types.AppRecord{
 			BlockNumber:      0,
 			TransactionIndex: uint32(i),
 		})
+		reasonMap[addr] = append(reasonMap[addr], types.ReasonGenesis)
 	}
 
+	header, _ := opts.Conn.GetBlockHeaderByNumber(0)
 	array := []tslib.TimestampRecord{}
 	array = append(array, tslib.TimestampRecord{
 		Bn: uint32(0),
-		Ts: uint32(opts.Conn.GetBlockTimestamp(0)),
+		Ts: uint32(header.Timestamp),
 	})
 	_ = tslib.Append(chain, array)
+	_ = tslib.WriteHashes(chain, []tslib.HashRecord{{Bn: 0, Hash: header.Hash, ParentHash: header.ParentHash}})
 
 	logger.Info("Writing block zero allocations for", len(prefunds), "prefunds, nAddresses:", len(appMap))
 	indexPath := index.ToIndexPath(bloomPath)
 	var chunk index.Chunk
-	if report, err := chunk.Write(chain, opts.PublisherAddr, indexPath, appMap, len(prefunds)); err != nil {
+	if report, err := chunk.Write(chain, opts.PublisherAddr, indexPath, appMap, reasonMap, len(prefunds)); err != nil {
 		return false, err
 	} else if report == nil {
 		logger.Fatal("should not happen ==> write chunk returned empty report")
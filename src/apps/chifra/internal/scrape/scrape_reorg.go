@@ -0,0 +1,210 @@
+package scrapePkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/cache/locations"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/decache"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/monitor"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/notify"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/tslib"
+)
+
+// recentHashes returns the known hashes of the blocks scraped past the finalized index (the staged
+// and unripe blocks). The timestamp database keeps these for every block the scraper has seen.
+func (bm *BlazeManager) recentHashes() ([]tslib.HashRecord, error) {
+	nHashes, err := tslib.NHashes(bm.chain)
+	if err != nil || nHashes <= bm.meta.Finalized+1 {
+		return nil, err
+	}
+
+	records, err := tslib.ReadHashes(bm.chain, bm.meta.Finalized+1, nHashes-1)
+	if err != nil {
+		return nil, err
+	}
+	known := make([]tslib.HashRecord, 0, len(records))
+	for _, record := range records {
+		if record.IsKnown() {
+			known = append(known, record)
+		}
+	}
+	return known, nil
+}
+
+// findFork compares the recorded hashes (in block order) with the hashes the node now reports and
+// returns the last block the two chains share and true if the chain has reorganized. If the node
+// has not yet reached the last recorded block, there is no telling, so findFork reports no reorg.
+func findFork(records []tslib.HashRecord, latest base.Blknum, hashesAt func(base.Blknum) (base.Hash, base.Hash, error)) (base.Blknum, bool, error) {
+	if len(records) == 0 || base.Blknum(records[len(records)-1].Bn) > latest {
+		return 0, false, nil
+	}
+
+	for i := len(records) - 1; i >= 0; i-- {
+		record := records[i]
+		bn := base.Blknum(record.Bn)
+		hash, parentHash, err := hashesAt(bn)
+		if err != nil {
+			return 0, false, err
+		}
+		if hash == record.Hash {
+			return bn, i < len(records)-1, nil
+		}
+		// The block was replaced. If its parent was not, we've found the fork without asking.
+		if i > 0 && records[i-1].Bn+1 == record.Bn && parentHash == records[i-1].Hash {
+			return bn - 1, true, nil
+		}
+	}
+
+	// Every recorded block was replaced, so the fork is earlier than any of them
+	first := base.Blknum(records[0].Bn)
+	if first > 0 {
+		first--
+	}
+	return first, true, nil
+}
+
+// HandleReorg checks if the chain has reorganized under the blocks scraped so far. If it has, it
+// rolls back everything the scraper (and chifra list or export) wrote from the orphaned blocks: the
+// staged, ripe, and unripe appearances, the timestamps, the monitors, and the cached blocks,
+// transactions, receipts, logs, withdrawals, and traces. It then notifies any listeners so they may
+// roll back as well. It returns true if it rolled back.
+func (bm *BlazeManager) HandleReorg() (bool, error) {
+	records, err := bm.recentHashes()
+	if err != nil {
+		return false, err
+	}
+	fork, reorged, err := findFork(records, bm.meta.ChainHeight(), bm.opts.Conn.GetBlockHashesFromRpc)
+	if err != nil || !reorged {
+		return false, err
+	}
+
+	orphaned := base.FileRange{First: fork + 1, Last: base.Blknum(records[len(records)-1].Bn)}
+	logger.Warn(fmt.Sprintf("The chain reorganized. Blocks %d to %d were orphaned. Rolling back.", orphaned.First, orphaned.Last))
+	if fork < bm.meta.Finalized {
+		logger.Warn(fmt.Sprintf("The reorg reaches into the finalized index (%d). Use chifra chunks --truncate %d to remove it.", bm.meta.Finalized, fork+1))
+	}
+
+	if err := bm.rollBack(fork, orphaned); err != nil {
+		return false, err
+	}
+
+	if bm.opts.notifying() {
+		if err := publish(bm.opts, notify.Notification[notify.NotificationPayloadReorg]{
+			Msg:  notify.MessageReorg,
+			Meta: bm.meta,
+			Payload: notify.NotificationPayloadReorg{
+				ForkBlock: fmt.Sprint(fork),
+				Orphaned:  orphaned.String(),
+			},
+		}); err != nil {
+			return true, err
+		}
+	}
+
+	return true, nil
+}
+
+// rollBack removes what was written from the orphaned blocks
+func (bm *BlazeManager) rollBack(fork base.Blknum, orphaned base.FileRange) error {
+	chain := bm.chain
+
+	// The ripe and unripe blocks are scraped again each round anyway
+	if err := cleanEphemeralIndexFolders(chain); err != nil {
+		return err
+	}
+	if err := rewindStage(bm.StageFolder(), fork); err != nil {
+		return err
+	}
+
+	// The timestamp database holds the timestamp and hashes of each block up to the last one scraped
+	if err := tslib.Truncate(chain, fork+1); err != nil {
+		return err
+	}
+
+	nMonitors := 0
+	monitorChan := make(chan monitor.Monitor)
+	go monitor.ListExistingMonitors(chain, monitorChan)
+	for mon := range monitorChan {
+		if mon.Address == base.NotAMonitor {
+			break
+		}
+		if mon.Staged {
+			continue
+		}
+		err := mon.ReadMonitorHeader()
+		mon.Close()
+		if err != nil {
+			return err
+		} else if base.Blknum(mon.LastScanned) <= fork {
+			continue
+		}
+		if _, err := mon.TruncateTo(chain, uint32(fork)); err != nil {
+			return err
+		}
+		nMonitors++
+	}
+
+	// Without a cache (it may have failed to open), there is nothing to decache
+	nRemoved := 0
+	if bm.opts.Conn.StoreReadable() {
+		blockNums := make([]base.Blknum, 0, orphaned.Last-fork)
+		for bn := orphaned.First; bn <= orphaned.Last; bn++ {
+			blockNums = append(blockNums, bn)
+		}
+		itemsToRemove, err := decache.LocationsFromOrphans(bm.opts.Conn, blockNums)
+		if err != nil {
+			return err
+		}
+		removed := func(*locations.ItemInfo) bool {
+			nRemoved++
+			return true
+		}
+		skipped := func(*locations.ItemInfo) bool {
+			return true
+		}
+		if err := bm.opts.Conn.Store.Decache(itemsToRemove, removed, skipped); err != nil {
+			return err
+		}
+	}
+
+	logger.Info(fmt.Sprintf("Rolled back to block %d: %d monitors truncated, %d cached items removed.", fork, nMonitors, nRemoved))
+	return nil
+}
+
+// rewindStage removes the appearances after the fork from the stage file. The stage is renamed to
+// end at the fork, so the next round scrapes the blocks after it again.
+func rewindStage(stageFolder string, fork base.Blknum) error {
+	stageFn, _ := file.LatestFileInFolder(stageFolder) // it may not exist...
+	if !file.FileExists(stageFn) {
+		return nil
+	}
+
+	rng := base.RangeFromFilename(stageFn)
+	if rng.Last <= fork {
+		return nil
+	}
+
+	lines := file.AsciiFileToLines(stageFn)
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		parts := strings.Split(line, "\t")
+		if len(parts) >= 3 && base.MustParseBlknum(strings.TrimLeft(parts[1], "0")) <= fork {
+			kept = append(kept, line)
+		}
+	}
+
+	if err := os.Remove(stageFn); err != nil {
+		return err
+	}
+	if len(kept) == 0 || fork < rng.First {
+		return nil
+	}
+	rng.Last = fork
+	return file.LinesToAsciiFile(filepath.Join(stageFolder, rng.String()+".txt"), kept)
+}
@@ -0,0 +1,130 @@
+package scrapePkg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/tslib"
+)
+
+// testChain returns the hashes of blocks first to last of a chain. Chains with different names
+// share the blocks up to and including the fork.
+func testChain(name string, fork, first, last base.Blknum) []tslib.HashRecord {
+	hashOf := func(bn base.Blknum) base.Hash {
+		if bn <= fork {
+			return base.HexToHash(fmt.Sprintf("0x%x", bn+1))
+		}
+		return base.HexToHash(fmt.Sprintf("0x%s%x", name, bn+1))
+	}
+	records := []tslib.HashRecord{}
+	for bn := first; bn <= last; bn++ {
+		records = append(records, tslib.HashRecord{Bn: uint32(bn), Hash: hashOf(bn), ParentHash: hashOf(bn - 1)})
+	}
+	return records
+}
+
+func TestFindFork(t *testing.T) {
+	// The scraper saw blocks 101 to 110 of chain a, which forks from the others after block 104
+	scraped := testChain("a", 104, 101, 110)
+	// Chain c replaces only the last block the scraper saw
+	chainC := append(testChain("a", 104, 101, 109),
+		tslib.HashRecord{Bn: 110, Hash: base.HexToHash("0xc111"), ParentHash: scraped[9].ParentHash},
+		tslib.HashRecord{Bn: 111, Hash: base.HexToHash("0xc112"), ParentHash: base.HexToHash("0xc111")},
+	)
+	tests := []struct {
+		name    string
+		node    []tslib.HashRecord
+		fork    base.Blknum
+		reorged bool
+		queries int
+	}{
+		{"no reorg", testChain("a", 104, 101, 112), 110, false, 1},
+		{"reorg", testChain("b", 104, 101, 112), 104, true, 6},
+		{"shorter chain", testChain("b", 104, 101, 108), 0, false, 0},
+		{"reorg at the tip", chainC, 109, true, 1},
+		{"deeper than the records", testChain("b", 95, 96, 115), 100, true, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queries := 0
+			hashesAt := func(bn base.Blknum) (base.Hash, base.Hash, error) {
+				queries++
+				for _, record := range tt.node {
+					if base.Blknum(record.Bn) == bn {
+						return record.Hash, record.ParentHash, nil
+					}
+				}
+				return base.Hash{}, base.Hash{}, errors.New("not found")
+			}
+			latest := base.Blknum(tt.node[len(tt.node)-1].Bn)
+			fork, reorged, err := findFork(scraped, latest, hashesAt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fork != tt.fork || reorged != tt.reorged || queries != tt.queries {
+				t.Errorf("fork %d, reorged %t after %d queries, expected %d, %t after %d", fork, reorged, queries, tt.fork, tt.reorged, tt.queries)
+			}
+		})
+	}
+}
+
+func TestRewindStage(t *testing.T) {
+	lines := []string{
+		"0x1111111111111111111111111111111111111111\t000000101\t00000",
+		"0x1111111111111111111111111111111111111111\t000000106\t00002",
+		"0x2222222222222222222222222222222222222222\t000000103\t00001",
+		"0x2222222222222222222222222222222222222222\t000000110\t00000",
+	}
+	stage := func(t *testing.T) string {
+		folder := t.TempDir()
+		if err := file.LinesToAsciiFile(filepath.Join(folder, "000000101-000000110.txt"), lines); err != nil {
+			t.Fatal(err)
+		}
+		return folder
+	}
+	stageFiles := func(t *testing.T, folder string) []string {
+		entries, err := os.ReadDir(folder)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := []string{}
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		return names
+	}
+
+	folder := stage(t)
+	if err := rewindStage(folder, 105); err != nil {
+		t.Fatal(err)
+	}
+	if names := stageFiles(t, folder); len(names) != 1 || names[0] != "000000101-000000105.txt" {
+		t.Fatalf("stage files %v", names)
+	}
+	kept := file.AsciiFileToLines(filepath.Join(folder, "000000101-000000105.txt"))
+	if !reflect.DeepEqual(kept, []string{lines[0], lines[2]}) {
+		t.Errorf("kept %v", kept)
+	}
+
+	folder = stage(t)
+	if err := rewindStage(folder, 110); err != nil {
+		t.Fatal(err)
+	}
+	if names := stageFiles(t, folder); len(names) != 1 || names[0] != "000000101-000000110.txt" {
+		t.Errorf("a stage before the fork should not change: %v", names)
+	}
+
+	folder = stage(t)
+	if err := rewindStage(folder, 100); err != nil {
+		t.Fatal(err)
+	}
+	if names := stageFiles(t, folder); len(names) != 0 {
+		t.Errorf("a stage after the fork should be removed: %v", names)
+	}
+}
@@ -34,6 +34,10 @@ func (opts *ScrapeOptions) validateScrape() error {
 		return validate.Usage("chain {0} is not properly configured.", chain)
 	}
 
+	if err := validate.ValidateEnum("--bloom_format", config.GetScrape(chain).BloomFormat, "[bloom|fuse]"); err != nil {
+		return err
+	}
+
 	if opts.Notify {
 		if !NotifyConfigured() {
 			return validate.Usage("The {0} feature is {1}.", "--notify", "not properly configured. See the README.md")
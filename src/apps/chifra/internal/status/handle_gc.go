@@ -0,0 +1,117 @@
+package statusPkg
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/cache"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/walk"
+)
+
+// gcCacheTypes are the binary caches subject to quotas. Other caches (monitors, names, abis,
+// and the index) are not caches in the sense that they may be rebuilt on demand.
+var gcCacheTypes = []walk.CacheType{
+	walk.Cache_Blocks,
+	walk.Cache_Logs,
+	walk.Cache_Receipts,
+	walk.Cache_Results,
+	walk.Cache_Slurps,
+	walk.Cache_State,
+	walk.Cache_Statements,
+	walk.Cache_Tokens,
+	walk.Cache_Traces,
+	walk.Cache_Transactions,
+	walk.Cache_Withdrawals,
+}
+
+// HandleGc evicts the least recently used items from each binary cache with a configured quota
+// until the cache is under its quota and reports what was freed
+func (opts *StatusOptions) HandleGc(rCtx *output.RenderCtx) error {
+	chain := opts.Globals.Chain
+	testMode := opts.Globals.TestMode
+
+	fetchData := func(modelChan chan types.Modeler, errorChan chan error) {
+		store, err := cache.NewStore(&cache.StoreOptions{
+			Location: cache.LocationFromString(config.GetSettings().CacheLocation),
+			Chain:    chain,
+		})
+		if err != nil {
+			errorChan <- err
+			return
+		}
+
+		status, err := opts.GetStatus(false)
+		if err != nil {
+			errorChan <- err
+			return
+		}
+
+		now := time.Now()
+		for _, cT := range gcCacheTypes {
+			folder := walk.CacheTypeToFolder[cT]
+			quotaStr := config.GetCacheQuota(folder)
+			if quotaStr == "" {
+				continue
+			}
+
+			quota, err := cache.ParseSize(quotaStr)
+			if err != nil {
+				errorChan <- fmt.Errorf("invalid quota for the %s cache: %w", folder, err)
+				continue
+			}
+
+			evicted, err := store.Evict(folder, quota)
+			if err != nil {
+				errorChan <- err
+			}
+
+			cacheItem := types.CacheItem{
+				CacheItemType: walk.WalkCacheName(cT),
+				Items:         make([]any, 0),
+				LastCached:    now.Format("2006-01-02 15:04:05"),
+				Path:          walk.GetRootPathFromCacheType(chain, cT),
+			}
+			for _, item := range evicted {
+				cacheItem.NFiles++
+				cacheItem.SizeInBytes += item.Size
+				if opts.Globals.Verbose && cacheItem.NFiles <= opts.MaxRecords {
+					cacheItem.Items = append(cacheItem.Items, evictedItem(chain, testMode, cT, &item))
+				}
+			}
+			logger.Info("Freed", cacheItem.SizeInBytes, "bytes in", cacheItem.NFiles, "files from the", folder, "cache (quota", quotaStr+")")
+			status.Caches = append(status.Caches, cacheItem)
+		}
+
+		modelChan <- status
+	}
+
+	extraOpts := map[string]any{
+		"chains": opts.Chains,
+	}
+
+	return output.StreamMany(rCtx, fetchData, opts.Globals.OutputOptsWithExtra(extraOpts))
+}
+
+// evictedItem describes a removed item in the same way walk.GetCacheItem describes a cached one
+func evictedItem(chain string, testMode bool, cT walk.CacheType, item *cache.UsedItem) map[string]any {
+	display := filepath.Clean(strings.ReplaceAll(item.Path, config.PathToCache(chain)+"/", "./"))
+	lastUsed := item.LastUsed.Format("2006-01-02 15:04:05")
+	size := item.Size
+	if testMode {
+		display = "$cachePath/data-model/file.bin"
+		lastUsed = "--lastUsed--"
+		size = 123456789
+	}
+	return map[string]any{
+		"filename":    display,
+		"itemType":    walk.WalkCacheName(cT) + "Item",
+		"lastUsed":    lastUsed,
+		"sizeInBytes": size,
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc/query"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/tslib"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/validate"
@@ -100,7 +101,24 @@ func (opts *StatusOptions) GetStatus(diagnose bool) (*types.Status, error) {
 		// Ripe:      meta.Latest - meta.Ripe,
 	}
 
+	if pool, err := query.PoolFor(chain); err == nil {
+		for _, stats := range pool.Stats() {
+			s.Endpoints = append(s.Endpoints, types.RpcEndpoint{
+				Url:       stats.Url,
+				Requests:  stats.Requests,
+				Errors:    stats.Errors,
+				Throttled: stats.Throttled,
+				ErrorRate: stats.ErrorRate,
+				RateLimit: stats.RateLimit,
+				Latency:   uint64(stats.Latency.Milliseconds()),
+				LastError: stats.LastError,
+				Healthy:   stats.Healthy,
+			})
+		}
+	}
+
 	if testMode {
+		s.Endpoints = nil
 		s.ClientVersion = "Client version"
 		s.Version = "GHC-TrueBlocks//vers-beta--git-hash---git-ts-"
 		s.RpcProvider = "--providers--"
@@ -133,6 +151,7 @@ func toTemplate(s *types.Status, w io.Writer, testMode, diagnose, logTimerOn boo
 	table = strings.Replace(table, "[VERSION]", getVersionTemplate(), -1)
 	table = strings.Replace(table, "[IDS]", getIdTemplate(), -1)
 	table = strings.Replace(table, "[PROGRESS]", getProgress(s, testMode, diagnose), -1)
+	table = strings.Replace(table, "[ENDPOINTS]", getEndpointsTemplate(), -1)
 	table = strings.Replace(table, "INFO ", timeDatePart+colors.Green, -1)
 	table = strings.Replace(table, "[RED]", colors.Red, -1)
 	table = strings.Replace(table, "[GREEN]", colors.Green, -1)
@@ -168,6 +187,12 @@ func getIdTemplate() string {
 	return networkId + "/" + chainId
 }
 
+func getEndpointsTemplate() string {
+	health := "{{if .Healthy}}[GREEN]healthy[OFF]{{else}}[RED]cooling down[OFF]{{end}}"
+	stats := "{{.Requests}} calls, {{.Errors}} errors, {{.Throttled}} throttled, {{.Latency}}ms, " + health
+	return "{{range .Endpoints}}INFO   Endpoint:        {{.Url}} (" + stats + ")\n{{end}}"
+}
+
 func getProgress(s *types.Status, testMode, diagnose bool) string {
 	if diagnose {
 		if testMode {
@@ -199,7 +224,7 @@ INFO [OFF]  Timestamps [GREEN]{nTs}[OFF] ([GREEN]{nTsDiff}[OFF] behind head)
 const templateStr = `INFO Client:            [CLIENT]
 INFO TrueBlocks:        [VERSION]
 INFO RPC Provider:      {{.RpcProvider}} - {{.Chain}} ([IDS])
-INFO Root Config Path:  {{.RootConfig}}
+[ENDPOINTS]INFO Root Config Path:  {{.RootConfig}}
 INFO Chain Config Path: {{.ChainConfig}}
 INFO Cache Path:        {{.CachePath}}
 INFO Index Path:        {{.IndexPath}}
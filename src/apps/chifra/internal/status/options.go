@@ -34,6 +34,7 @@ type StatusOptions struct {
 	MaxRecords  uint64                `json:"maxRecords,omitempty"`  // The maximum number of records to process
 	Chains      bool                  `json:"chains,omitempty"`      // Include a list of chain configurations in the output
 	Healthcheck bool                  `json:"healthcheck,omitempty"` // An alias for the diagnose endpoint
+	Gc          bool                  `json:"gc,omitempty"`          // Evict the least recently used items from each binary cache until it is under its configured quota
 	Globals     globals.GlobalOptions `json:"globals,omitempty"`     // The global options
 	Conn        *rpc.Connection       `json:"conn,omitempty"`        // The connection to the RPC server
 	BadFlag     error                 `json:"badFlag,omitempty"`     // An error flag if needed
@@ -54,6 +55,7 @@ func (opts *StatusOptions) testLog() {
 	logger.TestLog(opts.MaxRecords != 10000, "MaxRecords: ", opts.MaxRecords)
 	logger.TestLog(opts.Chains, "Chains: ", opts.Chains)
 	logger.TestLog(opts.Healthcheck, "Healthcheck: ", opts.Healthcheck)
+	logger.TestLog(opts.Gc, "Gc: ", opts.Gc)
 	opts.Conn.TestLog(opts.getCaches())
 	opts.Globals.TestLog()
 }
@@ -95,6 +97,8 @@ func StatusFinishParseInternal(w io.Writer, values url.Values) *StatusOptions {
 			opts.Chains = true
 		case "healthcheck":
 			opts.Healthcheck = true
+		case "gc":
+			opts.Gc = true
 		default:
 			if !copy.Globals.Caps.HasKey(key) {
 				err := validate.Usage("Invalid key ({0}) in {1} route.", key, "status")
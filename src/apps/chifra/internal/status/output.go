@@ -56,6 +56,8 @@ func (opts *StatusOptions) StatusInternal(rCtx *output.RenderCtx) error {
 	// EXISTING_CODE
 	if opts.Diagnose {
 		err = opts.HandleDiagnose(rCtx)
+	} else if opts.Gc {
+		err = opts.HandleGc(rCtx)
 	} else {
 		err = opts.HandleShow(rCtx)
 	}
@@ -36,6 +36,15 @@ func (opts *StatusOptions) validateStatus() error {
 		return validate.Usage("{0} may not be used with {1}", "--diagnose", opts.Modes[0])
 	}
 
+	if opts.Gc {
+		if len(opts.Modes) > 0 {
+			return validate.Usage("{0} may not be used with {1}", "--gc", opts.Modes[0])
+		}
+		if opts.Diagnose {
+			return validate.Usage("Please choose only one of {0}.", "--gc or --diagnose")
+		}
+	}
+
 	if len(opts.Modes) == 0 && opts.Chains {
 		return validate.Usage("The {0} option is only available{1}.", "--chains", " with a mode")
 	}
@@ -41,7 +41,7 @@ func (opts *TransactionsOptions) HandleUniq(rCtx *output.RenderCtx) (err error)
 				}
 				bn := base.Blknum(app.BlockNumber)
 				ts := opts.Conn.GetBlockTimestamp(bn)
-				addrMap := make(uniq.AddressBooleanMap)
+				addrMap := make(uniq.AddressReasonMap)
 				if trans, err := opts.Conn.GetTransactionByAppearance(&app, true); err != nil {
 					errorChan <- err
 				} else {
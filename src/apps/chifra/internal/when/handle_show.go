@@ -38,7 +38,7 @@ func (opts *WhenOptions) HandleShow(rCtx *output.RenderCtx) error {
 			})
 
 			for _, bn := range blockNums {
-				block, err := opts.Conn.GetBlockHeaderByNumber(bn)
+				block, err := opts.getBlockHeader(bn)
 				if err != nil {
 					errorChan <- err
 					if errors.Is(err, ethereum.NotFound) {
@@ -69,3 +69,20 @@ func (opts *WhenOptions) HandleShow(rCtx *output.RenderCtx) error {
 
 	return output.StreamMany(rCtx, fetchData, opts.Globals.OutputOpts())
 }
+
+// getBlockHeader returns the block's number, timestamp, and hashes from the timestamp database if
+// it has them, and from the node otherwise.
+func (opts *WhenOptions) getBlockHeader(bn base.Blknum) (types.LightBlock, error) {
+	chain := opts.Globals.Chain
+	if hashes, err := tslib.FromBnToHash(chain, bn); err == nil && hashes.IsKnown() {
+		if ts, err := tslib.FromBn(chain, bn); err == nil {
+			return types.LightBlock{
+				BlockNumber: bn,
+				Timestamp:   base.Timestamp(ts.Ts),
+				Hash:        hashes.Hash,
+				ParentHash:  hashes.ParentHash,
+			}, nil
+		}
+	}
+	return opts.Conn.GetBlockHeaderByNumber(bn)
+}
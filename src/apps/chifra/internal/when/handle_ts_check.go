@@ -26,7 +26,7 @@ func (opts *WhenOptions) HandleTimestampsCheck(rCtx *output.RenderCtx) error {
 	skip := uint64(500)
 	if opts.Deep {
 		m, _ := opts.Conn.GetMetaData(opts.Globals.TestMode)
-		skip = uint64(m.Latest) / 500
+		skip = max(uint64(m.Latest)/500, 1)
 	}
 	count := uint64(cnt)
 	scanBar := progress.NewScanBar(count /* wanted */, (count / skip) /* freq */, count /* max */, (2. / 3.))
@@ -40,18 +40,27 @@ func (opts *WhenOptions) HandleTimestampsCheck(rCtx *output.RenderCtx) error {
 		BlockNumber: base.NOPOSN,
 		Timestamp:   base.NOPOSI,
 	}
+	prevHashes := tslib.HashRecord{}
+
+	if opts.Deep {
+		// Blocks without hashes are not checked against their parents
+		nHashes, _ := tslib.NHashes(chain)
+		if hashes, _ := tslib.FromBnToHash(chain, 0); nHashes < cnt || !hashes.IsKnown() {
+			logger.Warn("The hash file is incomplete. Run chifra when --timestamps --update to complete it.")
+		}
+	}
 
 	if len(blockNums) > 0 {
 		for _, bn := range blockNums {
 			if bn < cnt { // ranges may include blocks after last block
-				if err = opts.checkOneBlock(scanBar, &prev, bn); err != nil {
+				if err = opts.checkOneBlock(scanBar, &prev, &prevHashes, bn); err != nil {
 					return err
 				}
 			}
 		}
 	} else {
 		for bn := base.Blknum(0); bn < cnt; bn++ {
-			if err = opts.checkOneBlock(scanBar, &prev, bn); err != nil {
+			if err = opts.checkOneBlock(scanBar, &prev, &prevHashes, bn); err != nil {
 				return err
 			}
 		}
@@ -60,7 +69,7 @@ func (opts *WhenOptions) HandleTimestampsCheck(rCtx *output.RenderCtx) error {
 	return nil
 }
 
-func (opts *WhenOptions) checkOneBlock(scanBar *progress.ScanBar, prev *types.NamedBlock, bn base.Blknum) error {
+func (opts *WhenOptions) checkOneBlock(scanBar *progress.ScanBar, prev *types.NamedBlock, prevHashes *tslib.HashRecord, bn base.Blknum) error {
 	chain := opts.Globals.Chain
 
 	// The i'th item in the timestamp array on disc
@@ -76,9 +85,11 @@ func (opts *WhenOptions) checkOneBlock(scanBar *progress.ScanBar, prev *types.Na
 	}
 
 	expected := types.LightBlock{BlockNumber: bn, Timestamp: onDisc.Timestamp}
+	hashesOnDisc := &tslib.HashRecord{}
 	if opts.Deep {
 		// If we're going deep, we need to query the node
 		expected, _ = opts.Conn.GetBlockHeaderByNumber(bn)
+		hashesOnDisc, _ = tslib.FromBnToHash(chain, bn)
 	}
 
 	if prev.Timestamp != base.NOPOSI {
@@ -115,12 +126,34 @@ func (opts *WhenOptions) checkOneBlock(scanBar *progress.ScanBar, prev *types.Na
 			status = "Error"
 		}
 
+		if hashesOnDisc.IsKnown() {
+			if hashesOnDisc.Bn != uint32(bn) {
+				msg := fmt.Sprintf("At block %d, hashes are recorded for block %d%s", bn, hashesOnDisc.Bn, clear)
+				logger.Error(msg)
+				status = "Error"
+			}
+
+			if hashesOnDisc.Hash != expected.Hash {
+				msg := fmt.Sprintf("At block %d, hash on disc %s does not agree with on chain %s%s", bn, hashesOnDisc.Hash.Hex(), expected.Hash.Hex(), clear)
+				logger.Error(msg)
+				status = "Error"
+			}
+
+			isParent := prevHashes.Bn+1 == hashesOnDisc.Bn
+			if isParent && prevHashes.IsKnown() && hashesOnDisc.ParentHash != prevHashes.Hash {
+				msg := fmt.Sprintf("At block %d, parent hash %s is not the hash of block %d %s%s", bn, hashesOnDisc.ParentHash.Hex(), prevHashes.Bn, prevHashes.Hash.Hex(), clear)
+				logger.Error(msg)
+				status = "Error"
+			}
+		}
+
 		if status == "Okay" {
 			scanBar.Report(opts.Globals.Writer, status, fmt.Sprintf(" bn: %d ts: %d", expected.BlockNumber, expected.Timestamp))
 		}
 	}
 
 	*prev = onDisc
+	*prevHashes = *hashesOnDisc
 	return nil
 }
 
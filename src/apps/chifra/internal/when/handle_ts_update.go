@@ -7,12 +7,14 @@ package whenPkg
 import (
 	"fmt"
 
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/tslib"
 )
 
-// HandleTimestampsUpdate update the timestamp file to the latest block
+// HandleTimestampsUpdate update the timestamp file to the latest block, first adding the hashes of
+// any blocks that have a timestamp but no hashes
 func (opts *WhenOptions) HandleTimestampsUpdate(rCtx *output.RenderCtx) error {
 	chain := opts.Globals.Chain
 
@@ -31,29 +33,87 @@ func (opts *WhenOptions) HandleTimestampsUpdate(rCtx *output.RenderCtx) error {
 		return err
 	}
 
+	if err := opts.fillHashes(cnt); err != nil {
+		return err
+	}
+
 	if cnt >= meta.Latest {
 		logger.Info("Timestamp file is up to date.")
 		return nil
 	}
 
 	timestamps := make([]tslib.TimestampRecord, 0, meta.Latest-cnt+2)
+	hashes := make([]tslib.HashRecord, 0, meta.Latest-cnt+2)
 
 	logger.Info("Updating timestamps file from", cnt, "to", meta.Latest, fmt.Sprintf("(%d blocks)", (meta.Latest-cnt)))
 	for bn := cnt; bn < meta.Latest; bn++ {
 		block, _ := opts.Conn.GetBlockHeaderByNumber(bn)
 		record := tslib.TimestampRecord{Bn: uint32(block.BlockNumber), Ts: uint32(block.Timestamp)}
 		timestamps = append(timestamps, record)
+		hashes = append(hashes, tslib.HashRecord{Bn: uint32(block.BlockNumber), Hash: block.Hash, ParentHash: block.ParentHash})
 		logger.Progress(bn%23 == 0, "Adding block", bn, "of", meta.Latest, "to timestamp array")
 		if bn%1000 == 0 {
 			logger.Info("Writing...", len(timestamps), "timestamps at block", bn, "                          ")
 			_ = tslib.Append(chain, timestamps)
+			_ = tslib.WriteHashes(chain, hashes)
 			timestamps = []tslib.TimestampRecord{}
+			hashes = []tslib.HashRecord{}
 		}
 	}
 
 	if len(timestamps) > 0 {
 		logger.Info("Writing...", len(timestamps), "timestamps at block", meta.Latest, "                          ")
 		_ = tslib.Append(chain, timestamps)
+		_ = tslib.WriteHashes(chain, hashes)
+	}
+
+	return nil
+}
+
+// fillHashes adds the hashes of the blocks in the timestamp database that have none. Installs that
+// predate the hash file need this once. It costs one call to the node per missing block.
+func (opts *WhenOptions) fillHashes(cnt base.Blknum) error {
+	chain := opts.Globals.Chain
+
+	nHashes, err := tslib.NHashes(chain)
+	if err != nil {
+		return err
+	}
+
+	batchSize := base.Blknum(1000)
+	for first := base.Blknum(0); first < cnt; first += batchSize {
+		last := base.Min(first+batchSize, cnt) - 1
+
+		// Records past the end of the hash file are missing
+		records := make([]tslib.HashRecord, last-first+1)
+		if first < nHashes {
+			onDisc, err := tslib.ReadHashes(chain, first, base.Min(last, nHashes-1))
+			if err != nil {
+				return err
+			}
+			copy(records, onDisc)
+		}
+
+		hashes := make([]tslib.HashRecord, 0, len(records))
+		for i := range records {
+			if records[i].IsKnown() {
+				continue
+			}
+			bn := first + base.Blknum(i)
+			block, err := opts.Conn.GetBlockHeaderByNumber(bn)
+			if err != nil {
+				return err
+			}
+			hashes = append(hashes, tslib.HashRecord{Bn: uint32(bn), Hash: block.Hash, ParentHash: block.ParentHash})
+			logger.Progress(bn%23 == 0, "Adding hashes for block", bn, "of", cnt, "to hash array")
+		}
+
+		if len(hashes) > 0 {
+			logger.Info("Writing...", len(hashes), "hashes at block", last, "                          ")
+			if err := tslib.WriteHashes(chain, hashes); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -6,6 +6,7 @@ var (
 	London         = "london"
 	Merge          = "merge"
 	Shanghai       = "shanghai"
+	Cancun         = "cancun"
 	FirstTrace     = "first_trace"
 )
 
@@ -16,10 +17,12 @@ var knownBlocks = map[string]map[string]Blknum{
 		London:         12965000,
 		Merge:          15537393,
 		Shanghai:       17034870,
+		Cancun:         19426587,
 	},
 	"sepolia": {
 		Merge:    1450409,
 		Shanghai: 2990908,
+		Cancun:   5187023,
 	},
 	"optimism": {
 		FirstTrace: 105235063,
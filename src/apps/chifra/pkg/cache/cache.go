@@ -3,6 +3,7 @@ package cache
 import (
 	"io"
 	"log"
+	"path"
 	"path/filepath"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/cache/locations"
@@ -14,8 +15,20 @@ type StoreLocation uint
 const (
 	FsCache StoreLocation = iota
 	MemoryCache
+	IpfsCache
 )
 
+// LocationFromString returns the StoreLocation named by the `cacheLocation` setting
+func LocationFromString(str string) StoreLocation {
+	switch str {
+	case "ipfs":
+		return IpfsCache
+	case "memory":
+		return MemoryCache
+	}
+	return FsCache
+}
+
 // NoCache indicates that we are not caching or reading from the cache
 var NoCache *Store = nil
 
@@ -31,6 +44,12 @@ type Storer interface {
 	Stat(path string) (*locations.ItemInfo, error)
 }
 
+// usageTracker is implemented by Storers that record when each item was last read so that
+// the least recently used items may be evicted (see Store.Evict)
+type usageTracker interface {
+	MarkUsed(path string) error
+}
+
 // Locator is a struct implementing the Locator interface. It can describe its
 // location in the cache
 type Locator interface {
@@ -57,6 +76,8 @@ type StoreOptions struct {
 	// Optional
 
 	RootDir string
+	// IpfsApi is the url of the IPFS API used by IpfsCache. Defaults to the localPinUrl setting.
+	IpfsApi string
 	// If ReadOnly is true, then we will not write to the cache
 	ReadOnly bool
 }
@@ -69,6 +90,12 @@ func (s *StoreOptions) location() (loc Storer, err error) {
 	switch s.Location {
 	case MemoryCache:
 		loc, err = locations.Memory()
+	case IpfsCache:
+		apiUrl := s.IpfsApi
+		if apiUrl == "" {
+			apiUrl = config.GetPinning().LocalPinUrl
+		}
+		loc, err = locations.Ipfs(apiUrl, path.Join(locations.IPFS_MFS_ROOT, s.Chain), s.rootDir())
 	case FsCache:
 		fallthrough
 	default:
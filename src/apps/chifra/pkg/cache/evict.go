@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+)
+
+// UsedItem is a cache item along with its size and the last time it was read (or written)
+type UsedItem struct {
+	Path     string
+	Size     int64
+	LastUsed time.Time
+}
+
+// Usage returns every item in the given folder (relative to the store's root) along with its
+// size and the last time it was used
+func (s *Store) Usage(folder string) ([]UsedItem, error) {
+	items := make([]UsedItem, 0)
+	root := filepath.Join(s.rootDir, folder)
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		items = append(items, UsedItem{
+			Path:     path,
+			Size:     info.Size(),
+			LastUsed: file.AccessTime(info),
+		})
+		return nil
+	})
+	return items, err
+}
+
+// Evict removes the least recently used items from the given folder (relative to the store's
+// root) until the folder's total size is no larger than quota. It returns the removed items.
+func (s *Store) Evict(folder string, quota int64) ([]UsedItem, error) {
+	if s.readOnly {
+		return []UsedItem{}, ErrReadOnly
+	}
+
+	items, err := s.Usage(folder)
+	if err != nil {
+		return []UsedItem{}, err
+	}
+
+	evicted := make([]UsedItem, 0)
+	for _, item := range SelectLeastRecentlyUsed(items, quota) {
+		if err := s.location.Remove(item.Path); err != nil {
+			return evicted, err
+		}
+		evicted = append(evicted, item)
+	}
+	return evicted, nil
+}
+
+// SelectLeastRecentlyUsed returns the items, least recently used first, whose removal brings
+// the total size of all items to no more than quota
+func SelectLeastRecentlyUsed(items []UsedItem, quota int64) []UsedItem {
+	total := int64(0)
+	for _, item := range items {
+		total += item.Size
+	}
+	if total <= quota {
+		return []UsedItem{}
+	}
+
+	sorted := make([]UsedItem, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].LastUsed.Equal(sorted[j].LastUsed) {
+			return sorted[i].Path < sorted[j].Path
+		}
+		return sorted[i].LastUsed.Before(sorted[j].LastUsed)
+	})
+
+	selected := make([]UsedItem, 0)
+	for _, item := range sorted {
+		if total <= quota {
+			break
+		}
+		selected = append(selected, item)
+		total -= item.Size
+	}
+	return selected
+}
+
+var sizeUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"TB", 1 << 40},
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// ParseSize parses a size such as 500MB, 1.5GB, or 1024 (bytes). Units are powers of 1024.
+func ParseSize(size string) (int64, error) {
+	str := strings.ToUpper(strings.TrimSpace(size))
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(str, unit.suffix) {
+			str = strings.TrimSpace(strings.TrimSuffix(str, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	value, err := strconv.ParseFloat(str, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size: %s", size)
+	}
+	return int64(value * float64(multiplier)), nil
+}
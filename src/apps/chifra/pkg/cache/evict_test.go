@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSelectLeastRecentlyUsed(t *testing.T) {
+	now := time.Now()
+	items := []UsedItem{
+		{Path: "a", Size: 100, LastUsed: now.Add(-1 * time.Hour)},
+		{Path: "b", Size: 100, LastUsed: now.Add(-3 * time.Hour)},
+		{Path: "c", Size: 100, LastUsed: now},
+		{Path: "d", Size: 100, LastUsed: now.Add(-2 * time.Hour)},
+	}
+
+	if selected := SelectLeastRecentlyUsed(items, 400); len(selected) != 0 {
+		t.Error("expected nothing to be selected when under quota", selected)
+	}
+
+	selected := SelectLeastRecentlyUsed(items, 250)
+	if len(selected) != 2 || selected[0].Path != "b" || selected[1].Path != "d" {
+		t.Error("expected b and d to be selected", selected)
+	}
+
+	if selected := SelectLeastRecentlyUsed(items, 0); len(selected) != 4 || selected[3].Path != "c" {
+		t.Error("expected everything to be selected", selected)
+	}
+
+	if items[0].Path != "a" {
+		t.Error("the items should not be reordered")
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+		err      bool
+	}{
+		{"1024", 1024, false},
+		{"10B", 10, false},
+		{"2KB", 2048, false},
+		{" 500mb ", 500 << 20, false},
+		{"1.5GB", 3 << 29, false},
+		{"1TB", 1 << 40, false},
+		{"lots", 0, true},
+		{"-1GB", 0, true},
+	}
+	for _, test := range tests {
+		got, err := ParseSize(test.input)
+		if (err != nil) != test.err {
+			t.Error(test.input, "unexpected error state", err)
+		}
+		if got != test.expected {
+			t.Error(test.input, "expected", test.expected, "got", got)
+		}
+	}
+}
+
+func TestStoreEvict(t *testing.T) {
+	rootDir := t.TempDir()
+	cacheStore, err := NewStore(&StoreOptions{
+		Location: FsCache,
+		RootDir:  rootDir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	longAgo := time.Now().Add(-24 * time.Hour)
+	for _, id := range []string{"1", "2", "3"} {
+		if err := cacheStore.Write(&testStoreData{Id: id, Value: "trueblocks"}, nil); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(rootDir, "test", id+".bin")
+		if err := os.Chtimes(path, longAgo, longAgo); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Reading an item marks it as used
+	if err := cacheStore.Read(&testStoreData{Id: "1"}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := cacheStore.Usage("test")
+	if err != nil || len(items) != 3 {
+		t.Fatal("expected three items", items, err)
+	}
+	itemSize := items[0].Size
+
+	evicted, err := cacheStore.Evict("test", itemSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evicted) != 2 {
+		t.Fatal("expected two items to be evicted", evicted)
+	}
+	for _, item := range evicted {
+		if filepath.Base(item.Path) == "1.bin" {
+			t.Error("the recently read item should not be evicted")
+		}
+	}
+	if err := cacheStore.Read(&testStoreData{Id: "1"}, nil); err != nil {
+		t.Error("expected the recently read item to remain", err)
+	}
+
+	// Missing folders are empty
+	if items, err := cacheStore.Usage("missing"); err != nil || len(items) != 0 {
+		t.Error("expected no items in a missing folder", items, err)
+	}
+}
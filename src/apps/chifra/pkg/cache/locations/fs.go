@@ -95,6 +95,11 @@ func (l *fileSystem) Stat(path string) (*ItemInfo, error) {
 	}, err
 }
 
+// MarkUsed records that the item at given path was just read
+func (l *fileSystem) MarkUsed(path string) error {
+	return file.TouchAccess(path)
+}
+
 func (l *fileSystem) makeParentDirectories(path string) error {
 	dirPath, _ := filepath.Split(path)
 	return os.MkdirAll(dirPath, FS_PERMISSIONS)
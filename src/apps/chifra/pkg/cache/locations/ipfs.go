@@ -0,0 +1,201 @@
+package locations
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	shell "github.com/ipfs/go-ipfs-api"
+)
+
+// IPFS_MFS_ROOT is the folder in the IPFS node's mutable file system (MFS) under which each
+// chain's cache is published
+const IPFS_MFS_ROOT = "/trueblocks/cache"
+
+// Each Storer implementation is global and thread-safe to save resources
+// when reading/writing large number of items. There is one ipfs Storer per
+// cache root (that is, per chain).
+var ipfsInstances = make(map[string]*ipfs)
+var ipfsInstancesMutex sync.Mutex
+
+// ipfsWriteCloser buffers a cache item until Close() is called, at which point the item is
+// written to the file system and published to IPFS
+type ipfsWriteCloser struct {
+	buf  bytes.Buffer
+	path string
+	l    *ipfs
+}
+
+func (w *ipfsWriteCloser) Write(p []byte) (n int, err error) {
+	return w.buf.Write(p)
+}
+
+func (w *ipfsWriteCloser) Close() error {
+	return w.l.store(w.path, w.buf.Bytes())
+}
+
+// ipfs is a content-addressed Storer that writes items to the file system and publishes them in
+// the mutable file system (MFS) of an IPFS (kubo compatible) node. An item's MFS path is its path
+// relative to the cache's root placed under the chain's folder, so the node's MFS tree is the map
+// from locators to CIDs. Every machine using the same node sees every other machine's items as
+// soon as they are written. Items are read from the file system first, then from the node (in
+// which case they are kept on the file system as well).
+type ipfs struct {
+	sh      *shell.Shell
+	mfsDir  string
+	rootDir string
+	fs      *fileSystem
+}
+
+// Ipfs returns an instance of the ipfs Storer for the cache at rootDir using the IPFS HTTP API
+// at apiUrl, ready to be used. The items are published under mfsDir in the node's MFS.
+func Ipfs(apiUrl, mfsDir, rootDir string) (*ipfs, error) {
+	if apiUrl == "" {
+		return nil, errors.New("the ipfs cache requires the url of an IPFS API")
+	}
+
+	ipfsInstancesMutex.Lock()
+	defer ipfsInstancesMutex.Unlock()
+
+	if instance, ok := ipfsInstances[rootDir]; ok {
+		return instance, nil
+	}
+
+	fs, _ := FileSystem()
+	instance := &ipfs{
+		sh:      shell.NewShell(apiUrl),
+		mfsDir:  mfsDir,
+		rootDir: rootDir,
+		fs:      fs,
+	}
+	ipfsInstances[rootDir] = instance
+	return instance, nil
+}
+
+// Writer returns io.WriterCloser for the item at given path
+func (l *ipfs) Writer(path string) (io.WriteCloser, error) {
+	return &ipfsWriteCloser{path: path, l: l}, nil
+}
+
+// Reader returns io.ReaderCloser for the item at given path
+func (l *ipfs) Reader(path string) (io.ReadCloser, error) {
+	reader, err := l.fs.Reader(path)
+	if err == nil || err != ErrNotFound {
+		return reader, err
+	}
+
+	mfsReader, err := l.sh.FilesRead(context.Background(), l.mfsPath(path))
+	if err != nil {
+		return nil, mfsError(err)
+	}
+	defer mfsReader.Close()
+
+	// We read the whole item so that a failure part way through is not mistaken for the item
+	buf := new(bytes.Buffer)
+	if _, err = buf.ReadFrom(mfsReader); err != nil {
+		return nil, err
+	}
+	// Keeping a copy is only an optimization, so we do not care if it fails
+	if writer, err := l.fs.Writer(path); err == nil {
+		_, _ = writer.Write(buf.Bytes())
+		_ = writer.Close()
+	}
+	return io.NopCloser(buf), nil
+}
+
+// Remove removes the item at given path both from the file system and from MFS (which leaves
+// its contents to the node's garbage collector)
+func (l *ipfs) Remove(path string) error {
+	published := true
+	if err := l.sh.FilesRm(context.Background(), l.mfsPath(path), true /* force */); err != nil {
+		if err = mfsError(err); err != ErrNotFound {
+			return err
+		}
+		published = false
+	}
+
+	if err := l.fs.Remove(path); err != nil {
+		if published && errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func (l *ipfs) Stat(path string) (*ItemInfo, error) {
+	info, err := l.fs.Stat(path)
+	if err == nil {
+		return info, nil
+	}
+
+	stat, mfsErr := l.sh.FilesStat(context.Background(), l.mfsPath(path))
+	if mfsErr != nil {
+		return nil, err
+	}
+	return &ItemInfo{
+		fileSize: int(stat.Size),
+		name:     filepath.Base(path),
+	}, nil
+}
+
+// MarkUsed records that the item at given path was just read
+func (l *ipfs) MarkUsed(path string) error {
+	return l.fs.MarkUsed(path)
+}
+
+// store writes the item to the file system and publishes it in MFS
+func (l *ipfs) store(path string, data []byte) error {
+	writer, err := l.fs.Writer(path)
+	if err != nil {
+		return err
+	}
+	if _, err = writer.Write(data); err != nil {
+		writer.Close()
+		return err
+	}
+	if err = writer.Close(); err != nil {
+		return err
+	}
+
+	return l.sh.FilesWrite(context.Background(), l.mfsPath(path), bytes.NewReader(data),
+		shell.FilesWrite.Create(true),
+		shell.FilesWrite.Truncate(true),
+		shell.FilesWrite.Parents(true),
+		shell.FilesWrite.CidVersion(1),
+		shell.FilesWrite.RawLeaves(true),
+	)
+}
+
+// cidFor returns the CID of the item at given path as published in MFS
+func (l *ipfs) cidFor(path string) (string, bool) {
+	stat, err := l.sh.FilesStat(context.Background(), l.mfsPath(path))
+	if err != nil {
+		return "", false
+	}
+	return stat.Hash, true
+}
+
+// mfsPath returns the item's path in MFS. The path is relative to the cache's root (with forward
+// slashes) so that it is the same on every machine.
+func (l *ipfs) mfsPath(itemPath string) string {
+	rel, err := filepath.Rel(l.rootDir, itemPath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = itemPath
+	}
+	return path.Join(l.mfsDir, filepath.ToSlash(rel))
+}
+
+// mfsError returns ErrNotFound if the node reports that there is nothing at the MFS path
+func mfsError(err error) error {
+	if strings.Contains(err.Error(), "does not exist") {
+		return ErrNotFound
+	}
+	return err
+}
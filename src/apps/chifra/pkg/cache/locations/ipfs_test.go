@@ -0,0 +1,199 @@
+package locations
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// fakeIpfsApi is an in-process stand-in for the parts of the kubo HTTP API used by the ipfs Storer.
+// Its mutable file system is a map from MFS paths to contents.
+type fakeIpfsApi struct {
+	mfs    map[string][]byte
+	nReads int
+	mutex  sync.Mutex
+}
+
+func newFakeIpfsApi() (*fakeIpfsApi, *httptest.Server) {
+	api := &fakeIpfsApi{mfs: make(map[string][]byte)}
+	return api, httptest.NewServer(api)
+}
+
+func (f *fakeIpfsApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	arg := r.URL.Query().Get("arg")
+	switch r.URL.Path {
+	case "/api/v0/files/write":
+		mr, err := r.MultipartReader()
+		if err != nil {
+			f.fail(w, err.Error())
+			return
+		}
+		part, err := mr.NextPart()
+		if err != nil {
+			f.fail(w, err.Error())
+			return
+		}
+		f.mfs[arg], _ = io.ReadAll(part)
+	case "/api/v0/files/read":
+		f.nReads++
+		if data, ok := f.mfs[arg]; ok {
+			_, _ = w.Write(data)
+			return
+		}
+		f.fail(w, "file does not exist")
+	case "/api/v0/files/stat":
+		if data, ok := f.mfs[arg]; ok {
+			cid := fmt.Sprintf("bafk%x", sha256.Sum256(data))
+			_ = json.NewEncoder(w).Encode(map[string]any{"Hash": cid, "Size": len(data), "Type": "file"})
+			return
+		}
+		f.fail(w, "file does not exist")
+	case "/api/v0/files/rm":
+		if _, ok := f.mfs[arg]; !ok {
+			f.fail(w, "file does not exist")
+			return
+		}
+		delete(f.mfs, arg)
+	default:
+		f.fail(w, "unknown command "+r.URL.Path)
+	}
+}
+
+func (f *fakeIpfsApi) fail(w http.ResponseWriter, msg string) {
+	w.WriteHeader(http.StatusInternalServerError)
+	_ = json.NewEncoder(w).Encode(map[string]any{"Message": msg, "Code": 0, "Type": "error"})
+}
+
+func (f *fakeIpfsApi) forget() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.mfs = make(map[string][]byte)
+}
+
+func readAll(t *testing.T, l *ipfs, path string) string {
+	t.Helper()
+	reader, err := l.Reader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	data, _ := io.ReadAll(reader)
+	return string(data)
+}
+
+func writeItem(t *testing.T, l *ipfs, path, contents string) {
+	t.Helper()
+	writer, _ := l.Writer(path)
+	_, _ = writer.Write([]byte(contents))
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIpfsStorer(t *testing.T) {
+	api, server := newFakeIpfsApi()
+	defer server.Close()
+
+	rootDir := t.TempDir()
+	l, err := Ipfs(server.URL, "/trueblocks/cache/storer", rootDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(rootDir, "blocks", "00", "00", "01", "000000001.bin")
+	writeItem(t, l, path, "hello cache")
+
+	if _, ok := l.cidFor(path); !ok {
+		t.Fatal("expected a cid for the item")
+	}
+	if string(api.mfs["/trueblocks/cache/storer/blocks/00/00/01/000000001.bin"]) != "hello cache" {
+		t.Error("expected the item to be published at its locator", api.mfs)
+	}
+
+	// Remove the file system copy, the item is still available from ipfs (and copied back to disc)
+	_ = os.Remove(path)
+	if got := readAll(t, l, path); got != "hello cache" {
+		t.Error("expected to read from ipfs, got", got)
+	}
+	if info, err := l.Stat(path); err != nil || info.Size() != len("hello cache") {
+		t.Error("unexpected stat", info, err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "hello cache" {
+		t.Error("expected the item to be kept on disc")
+	}
+
+	// Remove removes the item from both places
+	if err := l.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := l.cidFor(path); ok {
+		t.Error("expected the item to be removed from ipfs")
+	}
+	if _, err := l.Reader(path); err != ErrNotFound {
+		t.Error("expected ErrNotFound, got", err)
+	}
+	if err := l.Remove(path); err == nil {
+		t.Error("expected removing a missing item to fail")
+	}
+}
+
+func TestIpfsStorerReadsFileSystemFirst(t *testing.T) {
+	api, server := newFakeIpfsApi()
+	defer server.Close()
+
+	rootDir := t.TempDir()
+	l, err := Ipfs(server.URL, "/trueblocks/cache/local", rootDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(rootDir, "txs", "item.bin")
+	writeItem(t, l, path, "on disc too")
+
+	// The ipfs node loses the item, we read it from disc without asking the node
+	api.forget()
+	if got := readAll(t, l, path); got != "on disc too" {
+		t.Error("expected to read from the file system, got", got)
+	}
+	if api.nReads != 0 {
+		t.Error("expected the node not to be asked for an item on disc", api.nReads)
+	}
+}
+
+func TestIpfsStorerShared(t *testing.T) {
+	_, server := newFakeIpfsApi()
+	defer server.Close()
+
+	// Two machines share a node...
+	first, second := t.TempDir(), t.TempDir()
+	l1, err := Ipfs(server.URL, "/trueblocks/cache/shared", first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l2, err := Ipfs(server.URL, "/trueblocks/cache/shared", second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ...one populates the cache after both have started...
+	writeItem(t, l1, filepath.Join(first, "traces", "item.bin"), string(bytes.Repeat([]byte("x"), 1000)))
+
+	// ...and the other, with nothing on disc, reads from it
+	if got := readAll(t, l2, filepath.Join(second, "traces", "item.bin")); len(got) != 1000 {
+		t.Error("expected to read the shared item, got", len(got), "bytes")
+	}
+	if _, err := l2.Reader(filepath.Join(second, "traces", "other.bin")); err != ErrNotFound {
+		t.Error("expected ErrNotFound, got", err)
+	}
+}
@@ -122,12 +122,18 @@ func (s *Store) Write(value Locator, options *WriteOptions) (err error) {
 	return
 }
 
-// ReadOptions are options that we might need in the future
-type ReadOptions interface{}
+// ReadOptions passes additional context to Read if needed
+type ReadOptions struct {
+	// MinVersion, if not zero, invalidates items written by an earlier version of the
+	// library. Callers use it when an upgrade added data that older items lack but
+	// that they may not do without (see #3638).
+	MinVersion uint64
+}
 
 // Read retrieves value from a location defined by options.Location. If options is nil,
 // then FileSystem is used. The value has to implement Locator interface, which
-// provides information about in-cache path
+// provides information about in-cache path. Items that cannot be decoded (or that
+// are older than options.MinVersion) are removed from the cache.
 func (s *Store) Read(value Locator, options *ReadOptions) (err error) {
 	itemPath, err := s.resolvePath(value)
 	if err != nil {
@@ -150,9 +156,16 @@ func (s *Store) Read(value Locator, options *ReadOptions) (err error) {
 
 	item := NewItem(buffer)
 	err = item.Decode(value)
+	if err == nil && options != nil && item.header.Version < options.MinVersion {
+		err = ErrIncompatibleVersion
+	}
 	if err != nil {
 		_ = os.Remove(itemPath)
 		printErr("decoding", err)
+	} else if tracker, ok := s.location.(usageTracker); ok {
+		if trackErr := tracker.MarkUsed(itemPath); trackErr != nil {
+			printErr("marking used", trackErr)
+		}
 	}
 	return
 }
@@ -59,3 +59,25 @@ func TestStoreWrite(t *testing.T) {
 		t.Fatal("wrong value:", result.Value)
 	}
 }
+
+func TestStoreReadMinVersion(t *testing.T) {
+	cacheStore, err := NewStore(&StoreOptions{
+		Location: MemoryCache,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, minVersion := range []uint64{currentHeader.Version, currentHeader.Version + 1} {
+		if err := cacheStore.Write(&testStoreData{Id: "1", Value: "trueblocks"}, nil); err != nil {
+			t.Fatal(err)
+		}
+		result := &testStoreData{Id: "1"}
+		err := cacheStore.Read(result, &ReadOptions{MinVersion: minVersion})
+		if minVersion > currentHeader.Version && err != ErrIncompatibleVersion {
+			t.Error("expected an incompatible version, got", err)
+		} else if minVersion == currentHeader.Version && (err != nil || result.Value != "trueblocks") {
+			t.Error("wrong value:", result.Value, err)
+		}
+	}
+}
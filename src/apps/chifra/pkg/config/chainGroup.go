@@ -23,5 +23,46 @@ func GetChains() []configtypes.ChainGroup {
 
 // IsChainConfigured returns true if the chain is configured in the config file.
 func IsChainConfigured(needle string) bool {
-	return GetRootConfig().Chains != nil && GetRootConfig().Chains[needle] != configtypes.ChainGroup{}
+	_, ok := GetRootConfig().Chains[needle]
+	return ok
+}
+
+// The chain families. Rollups differ from Ethereum in how they produce blocks, how value arrives
+// from the parent chain (deposits), and in charging a fee for posting each transaction's data to
+// the parent chain.
+const (
+	FamilyEthereum = "ethereum"
+	FamilyOptimism = "optimism"
+	FamilyArbitrum = "arbitrum"
+)
+
+// wellKnownFamilies are the families of rollups for which the family setting may be omitted
+var wellKnownFamilies = map[string]string{
+	"10":      FamilyOptimism, // optimism
+	"8453":    FamilyOptimism, // base
+	"7777777": FamilyOptimism, // zora
+	"42161":   FamilyArbitrum, // arbitrum one
+	"42170":   FamilyArbitrum, // arbitrum nova
+}
+
+// GetChainFamily returns the family of the given chain. If the chain's config has no family
+// setting, well-known rollups are recognized by their chainId. All other chains are ethereum.
+func GetChainFamily(chain string) string {
+	ch := GetChain(chain)
+	if len(ch.Family) > 0 {
+		return ch.Family
+	}
+	if family, ok := wellKnownFamilies[ch.ChainId]; ok {
+		return family
+	}
+	return FamilyEthereum
+}
+
+// IsRollup returns true if the chain is not of the ethereum family
+func IsRollup(chain string) bool {
+	return GetChainFamily(chain) != FamilyEthereum
+}
+
+func isValidFamily(family string) bool {
+	return family == FamilyEthereum || family == FamilyOptimism || family == FamilyArbitrum
 }
@@ -125,7 +125,22 @@ func GetRootConfig() *configtypes.Config {
 		if err := validateRpcEndpoint(ch.Chain, ch.RpcProvider); err != nil {
 			logger.Fatal(err)
 		}
+		providers := []string{}
+		for _, provider := range ch.Rpc.Providers {
+			if provider = strings.TrimSpace(provider); len(provider) > 0 {
+				providers = append(providers, strings.Trim(clean(provider), "/"))
+			}
+		}
+		ch.Rpc.Providers = providers
 		ch.IpfsGateway = clean(ch.IpfsGateway)
+		gateways := []configtypes.IpfsGateway{}
+		for _, gateway := range ch.Ipfs.Gateways {
+			if gateway.Url = strings.TrimSpace(gateway.Url); len(gateway.Url) > 0 {
+				gateway.Url = clean(gateway.Url)
+				gateways = append(gateways, gateway)
+			}
+		}
+		ch.Ipfs.Gateways = gateways
 		if ch.Scrape.AppsPerChunk == 0 {
 			settings := configtypes.ScrapeSettings{
 				AppsPerChunk: 2000000,
@@ -141,6 +156,10 @@ func GetRootConfig() *configtypes.Config {
 			}
 			ch.Scrape = settings
 		}
+		ch.Family = strings.ToLower(strings.TrimSpace(ch.Family))
+		if len(ch.Family) > 0 && !isValidFamily(ch.Family) {
+			logger.Fatal(fmt.Sprintf("invalid family %s for chain %s (must be one of ethereum, optimism, or arbitrum)", ch.Family, chain))
+		}
 		trueBlocksConfig.Chains[chain] = ch
 	}
 	configLoaded = true
@@ -81,6 +81,16 @@ func deepSetByPath(structure *reflect.Value, path []string, value string) error
 			fieldValue.SetBool(b)
 		case reflect.String:
 			fieldValue.SetString(value)
+		case reflect.Slice:
+			// Lists of strings (for example, RPC providers) are comma separated
+			if field.Type.Elem().Kind() != reflect.String {
+				return fmt.Errorf("unsupported type for %v", path)
+			}
+			items := reflect.MakeSlice(field.Type, 0, 0)
+			for _, item := range strings.Split(value, ",") {
+				items = reflect.Append(items, reflect.ValueOf(item).Convert(field.Type.Elem()))
+			}
+			fieldValue.Set(items)
 		case reflect.Map:
 			// When dealing with maps, we first have to obtain values for map key and map value
 			mapKey := reflect.ValueOf(strings.ToLower(path[1]))
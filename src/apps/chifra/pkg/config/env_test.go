@@ -74,4 +74,15 @@ func Test_setByPath(t *testing.T) {
 	if v := result.Chains["mainnet"].Scrape.AppsPerChunk; v != 2000000 {
 		t.Fatal("wrong value", v)
 	}
+
+	// Lists are comma separated
+	if err := setByPath(&result, []string{"CONFIG", "CHAINS", "MAINNET", "RPC", "PROVIDERS"}, "http://a:8545,http://b:8545"); err != nil {
+		t.Fatal(err)
+	}
+	if v := result.Chains["mainnet"].Rpc.Providers; len(v) != 2 || v[1] != "http://b:8545" {
+		t.Fatal("wrong value", v)
+	}
+	if v := result.Chains["mainnet"].Scrape.AppsPerChunk; v != 2000000 {
+		t.Fatal("wrong value", v)
+	}
 }
@@ -0,0 +1,52 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package config
+
+import (
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/configtypes"
+)
+
+// GetIpfs returns the IPFS download settings per chain
+func GetIpfs(chain string) configtypes.IpfsSettings {
+	return GetRootConfig().Chains[chain].Ipfs
+}
+
+// GetIpfsGateways returns, in order of preference, the gateways from which the chain's index may
+// be downloaded. The chain's ipfsGateway comes first, followed by any additional gateways, then the
+// pinning gateway and the default gateway, if they differ. The later gateways are used when an
+// earlier one is busy, fails, or serves bad data. Gateways without a limit of their own get the
+// chain's concurrency (which may be zero, in which case the caller decides).
+func GetIpfsGateways(chain string) []configtypes.IpfsGateway {
+	ch := GetChain(chain)
+
+	candidates := []configtypes.IpfsGateway{{Url: ch.IpfsGateway}}
+	candidates = append(candidates, ch.Ipfs.Gateways...)
+	candidates = append(candidates, configtypes.IpfsGateway{Url: GetPinning().GatewayUrl}, configtypes.IpfsGateway{Url: defaultIpfsGateway})
+
+	ret := []configtypes.IpfsGateway{}
+	seen := map[string]bool{}
+	for _, candidate := range candidates {
+		if candidate.Concurrency == 0 {
+			candidate.Concurrency = ch.Ipfs.Concurrency
+		}
+		key := strings.TrimRight(candidate.Url, "/")
+		if len(key) > 0 && !seen[key] {
+			seen[key] = true
+			ret = append(ret, candidate)
+		}
+	}
+	return ret
+}
+
+// GetIpfsGatewayUrls returns the urls of the chain's gateways in order of preference
+func GetIpfsGatewayUrls(chain string) []string {
+	ret := []string{}
+	for _, gateway := range GetIpfsGateways(chain) {
+		ret = append(ret, gateway.Url)
+	}
+	return ret
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/configtypes"
+	"github.com/pelletier/go-toml/v2"
+)
+
+func Test_ipfsAndRpcLists(t *testing.T) {
+	contents := `
+[chains.mainnet.rpc]
+  providers = ["http://localhost:8545", "https://rpc.example.com"]
+
+[chains.mainnet.ipfs]
+  concurrency = 8
+
+  [[chains.mainnet.ipfs.gateways]]
+    url = "https://ipfs.io/ipfs/"
+
+  [[chains.mainnet.ipfs.gateways]]
+    url = "http://192.168.1.5:8080/ipfs/"
+    concurrency = 12
+`
+	var cfg configtypes.Config
+	if err := toml.Unmarshal([]byte(contents), &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	ch := cfg.Chains["mainnet"]
+	if len(ch.Rpc.Providers) != 2 || ch.Rpc.Providers[1] != "https://rpc.example.com" {
+		t.Error("wrong providers", ch.Rpc.Providers)
+	}
+	expected := []configtypes.IpfsGateway{
+		{Url: "https://ipfs.io/ipfs/"},
+		{Url: "http://192.168.1.5:8080/ipfs/", Concurrency: 12},
+	}
+	if len(ch.Ipfs.Gateways) != len(expected) {
+		t.Fatal("wrong gateways", ch.Ipfs.Gateways)
+	}
+	for i, gateway := range ch.Ipfs.Gateways {
+		if gateway != expected[i] {
+			t.Error("wrong gateway", i, gateway)
+		}
+	}
+}
@@ -42,6 +42,11 @@ func PathToTimestamps(chain string) string {
 	return filepath.Join(PathToIndex(chain), "ts.bin")
 }
 
+// PathToHashes returns the path to the block hash database per chain (the timestamp database's companion)
+func PathToHashes(chain string) string {
+	return filepath.Join(PathToIndex(chain), "hashes.bin")
+}
+
 // PathToIndex returns the one and only indexPath
 func PathToIndex(chain string) string {
 	// We need the index path from either XDG which dominates or the config file
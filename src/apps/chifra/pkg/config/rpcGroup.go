@@ -0,0 +1,29 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package config
+
+import (
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/configtypes"
+)
+
+// GetRpc returns the RPC pool settings per chain
+func GetRpc(chain string) configtypes.RpcSettings {
+	return GetRootConfig().Chains[chain].Rpc
+}
+
+// GetRpcProviders returns the chain's RPC endpoints, the rpcProvider first, followed by any
+// additional endpoints to fail over to
+func GetRpcProviders(chain string) []string {
+	ch := GetChain(chain)
+	providers := []string{ch.RpcProvider}
+	seen := map[string]bool{ch.RpcProvider: true}
+	for _, provider := range ch.Rpc.Providers {
+		if !seen[provider] {
+			providers = append(providers, provider)
+			seen[provider] = true
+		}
+	}
+	return providers
+}
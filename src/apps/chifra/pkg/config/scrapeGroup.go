@@ -38,6 +38,10 @@ func SetScrapeArgs(chain string, args map[string]string) {
 				settings.ChannelCount, _ = strconv.ParseUint(value, 0, 64)
 			case "allowMissing":
 				settings.AllowMissing = true
+			case "bloomFormat":
+				settings.BloomFormat = value
+			case "reasons":
+				settings.Reasons = true
 			}
 		}
 		ch.Scrape = settings
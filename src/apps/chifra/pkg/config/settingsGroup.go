@@ -9,3 +9,13 @@ import "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/configtypes"
 func GetSettings() configtypes.SettingsGroup {
 	return GetRootConfig().Settings
 }
+
+// GetCacheQuota returns the configured size limit (for example, 10GB) for the binary cache in
+// the given folder, the default limit if there is none, or an empty string if neither is set
+func GetCacheQuota(folder string) string {
+	quotas := GetSettings().CacheQuotas
+	if quota, ok := quotas[folder]; ok {
+		return quota
+	}
+	return quotas["default"]
+}
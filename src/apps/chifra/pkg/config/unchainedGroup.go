@@ -1,6 +1,7 @@
 package config
 
 import (
+	"bytes"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -32,16 +33,31 @@ func GetPublisher(value string) string {
 var VersionTags = map[string]string{
 	"0x81ae14ba68e372bc9bd4a295b844abd8e72b1de10fcd706e624647701d911da1": "trueblocks-core@v0.40.0",
 	"0x6fc0c6dd027719f456c1e50a329f6157767325aa937411fa6e7be9359d9e0046": "trueblocks-core@v2.0.0-release",
+	"0x11193311ece811b8790f28651fb0eaeb35fec5719c815e7d1ed6fa7733f35831": "trueblocks-core@v2.1.0-reasons",
+	"0x3005c9aaf25ce9ada13fa0139f3c3728656af3ed4db4920414b2806ec96ef01d": "trueblocks-core@v2.1.0-fuse",
 }
 
+// ReasonsVersion is the version found in the header of index files that carry a reason for each
+// appearance following the appearance table. The bloom filters of such chunks are unchanged.
+const ReasonsVersion = "trueblocks-core@v2.1.0-reasons"
+
+// FuseVersion is the version found in the header of bloom files that carry a binary fuse filter in
+// place of the adaptive bloom filter. The index files of such chunks are unchanged.
+const FuseVersion = "trueblocks-core@v2.1.0-fuse"
+
 // SpecTags allows us to go from a version string to an IPFS hash pointing to the spec
 var SpecTags = map[string]string{
 	"trueblocks-core@v0.40.0":        "QmUou7zX2g2tY58LP1A2GyP5RF9nbJsoxKTp299ah3svgb",
 	"trueblocks-core@v2.0.0-release": "QmUyyU8wKW57c3CuwphhMdZb2QA5bsjt9vVfTE6LcBKmE9",
 }
 
+// KnownVersionTag returns true if the tag is a known release version. The versions that mark a file's
+// format (ReasonsVersion and FuseVersion) may not be used to tag chunks.
 func KnownVersionTag(tag string) bool {
 	for _, v := range VersionTags {
+		if v == ReasonsVersion || v == FuseVersion {
+			continue
+		}
 		vShort := strings.Replace(v, "trueblocks-core@", "", -1)
 		if v == tag || vShort == tag {
 			return true
@@ -65,6 +81,12 @@ func ExpectedVersion() string {
 	return headerVersion
 }
 
+// IsExpectedHeader returns true if the hash found in an index file's header is either the expected
+// version or the expected version extended with reasons
+func IsExpectedHeader(hash []byte) bool {
+	return bytes.Equal(hash, HeaderHash(ExpectedVersion())) || bytes.Equal(hash, HeaderHash(ReasonsVersion))
+}
+
 func SetExpectedVersion(version string) {
 	m.Lock()
 	historyFile := filepath.Join(PathToRootConfig(), "unchained.txt")
@@ -3,15 +3,19 @@ package configtypes
 import "encoding/json"
 
 type ChainGroup struct {
-	Chain          string         `json:"chain" toml:"chain,omitempty"`
-	ChainId        string         `json:"chainId" toml:"chainId"`
-	IpfsGateway    string         `json:"ipfsGateway" toml:"ipfsGateway,omitempty"`
-	KeyEndpoint    string         `json:"keyEndpoint" toml:"keyEndpoint,omitempty"`
-	LocalExplorer  string         `json:"localExplorer" toml:"localExplorer,omitempty"`
-	RemoteExplorer string         `json:"removeExplorer" toml:"remoteExplorer,omitempty"`
-	RpcProvider    string         `json:"rpcProvider" toml:"rpcProvider"`
-	Symbol         string         `json:"symbol" toml:"symbol"`
-	Scrape         ScrapeSettings `json:"scrape" toml:"scrape"`
+	Chain          string          `json:"chain" toml:"chain,omitempty"`
+	ChainId        string          `json:"chainId" toml:"chainId"`
+	Family         string          `json:"family" toml:"family,omitempty"`
+	IpfsGateway    string          `json:"ipfsGateway" toml:"ipfsGateway,omitempty"`
+	KeyEndpoint    string          `json:"keyEndpoint" toml:"keyEndpoint,omitempty"`
+	LocalExplorer  string          `json:"localExplorer" toml:"localExplorer,omitempty"`
+	RemoteExplorer string          `json:"removeExplorer" toml:"remoteExplorer,omitempty"`
+	RpcProvider    string          `json:"rpcProvider" toml:"rpcProvider"`
+	Symbol         string          `json:"symbol" toml:"symbol"`
+	Scrape         ScrapeSettings  `json:"scrape" toml:"scrape"`
+	Pricing        PricingSettings `json:"pricing" toml:"pricing,omitempty"`
+	Rpc            RpcSettings     `json:"rpc" toml:"rpc,omitempty"`
+	Ipfs           IpfsSettings    `json:"ipfs" toml:"ipfs,omitempty"`
 }
 
 func (s *ChainGroup) String() string {
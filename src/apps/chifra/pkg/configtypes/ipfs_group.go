@@ -0,0 +1,18 @@
+package configtypes
+
+import "encoding/json"
+
+type IpfsSettings struct {
+	Gateways    []IpfsGateway `json:"gateways,omitempty" toml:"gateways,omitempty" comment:"Additional IPFS gateways to download from (ipfsGateway is always preferred at first)"`
+	Concurrency uint64        `json:"concurrency,omitempty" toml:"concurrency,omitempty" comment:"If not zero, the most downloads at a time from a gateway without its own limit (if zero, twice the number of CPUs)"`
+}
+
+type IpfsGateway struct {
+	Url         string `json:"url" toml:"url" comment:"The gateway's url (for example, https://ipfs.io/ipfs/)"`
+	Concurrency uint64 `json:"concurrency,omitempty" toml:"concurrency,omitempty" comment:"If not zero, the most downloads at a time from this gateway"`
+}
+
+func (s *IpfsSettings) String() string {
+	bytes, _ := json.Marshal(s)
+	return string(bytes)
+}
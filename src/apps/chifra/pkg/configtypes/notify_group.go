@@ -5,6 +5,7 @@ import "encoding/json"
 type NotifyGroup struct {
 	Url    string `json:"url,omitempty" toml:"url"`
 	Author string `json:"author,omitempty" toml:"author"`
+	Secret string `json:"secret,omitempty" toml:"secret"`
 }
 
 func (s *NotifyGroup) String() string {
@@ -0,0 +1,14 @@
+package configtypes
+
+import "encoding/json"
+
+type PricingSettings struct {
+	Sources    string `json:"sources,omitempty" toml:"sources,omitempty" comment:"A comma separated, ordered list of price sources (stable, csv, chainlink, uniswap-v3, uniswap, curve, maker)"`
+	PriceFile  string `json:"priceFile,omitempty" toml:"priceFile,omitempty" comment:"For the csv source, the path to a file of asset,timestamp,price records"`
+	TwapWindow uint64 `json:"twapWindow,omitempty" toml:"twapWindow,omitempty" comment:"For the uniswap-v3 source, the length in seconds of the time weighted average"`
+}
+
+func (s *PricingSettings) String() string {
+	bytes, _ := json.Marshal(s)
+	return string(bytes)
+}
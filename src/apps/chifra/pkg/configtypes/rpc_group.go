@@ -0,0 +1,18 @@
+package configtypes
+
+import "encoding/json"
+
+type RpcSettings struct {
+	Providers         []string `json:"providers,omitempty" toml:"providers,omitempty" comment:"Additional RPC endpoints to fail over to (rpcProvider is always preferred at first)"`
+	HedgeDelay        uint64   `json:"hedgeDelay,omitempty" toml:"hedgeDelay,omitempty" comment:"If not zero, the milliseconds after which a slow read-only call is also sent to the next endpoint"`
+	RequestsPerSecond float64  `json:"requestsPerSecond,omitempty" toml:"requestsPerSecond,omitempty" comment:"If not zero, the most calls per second sent to each endpoint (lowered for a while if the endpoint says it is called too often)"`
+	MaxBatchSize      uint64   `json:"maxBatchSize,omitempty" toml:"maxBatchSize,omitempty" comment:"If not zero, the most calls sent to an endpoint in a single batch"`
+	MaxRetries        uint64   `json:"maxRetries,omitempty" toml:"maxRetries,omitempty" comment:"The number of times a call that failed on every endpoint for a reason that may pass is retried (if zero, 3)"`
+	Fixtures          string   `json:"fixtures,omitempty" toml:"fixtures,omitempty" comment:"The file in which calls are recorded or from which they are replayed (see fixtureMode)"`
+	FixtureMode       string   `json:"fixtureMode,omitempty" toml:"fixtureMode,omitempty" comment:"If record, every call and its answer is saved to the fixtures file; if replay, calls are answered from the fixtures file only (no node is called)"`
+}
+
+func (s *RpcSettings) String() string {
+	bytes, _ := json.Marshal(s)
+	return string(bytes)
+}
@@ -13,6 +13,8 @@ type ScrapeSettings struct {
 	UnripeDist   uint64 `json:"unripeDist" toml:"unripeDist"`
 	AllowMissing bool   `json:"allowMissing,omitempty" toml:"allowMissing"`
 	ChannelCount uint64 `json:"channelCount,omitempty" toml:"channelCount"`
+	BloomFormat  string `json:"bloomFormat,omitempty" toml:"bloomFormat,omitempty"`
+	Reasons      bool   `json:"reasons,omitempty" toml:"reasons,omitempty"`
 }
 
 func (s *ScrapeSettings) String() string {
@@ -27,4 +29,6 @@ func (s *ScrapeSettings) TestLog(chain string, test bool) {
 	logger.TestLog(false, "UnripeDist: ", s.UnripeDist)
 	logger.TestLog(false, "ChannelCount: ", s.ChannelCount)
 	logger.TestLog(false, "AllowMissing: ", s.AllowMissing)
+	logger.TestLog(false, "BloomFormat: ", s.BloomFormat)
+	logger.TestLog(false, "Reasons: ", s.Reasons)
 }
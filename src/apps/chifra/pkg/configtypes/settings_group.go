@@ -3,11 +3,13 @@ package configtypes
 import "encoding/json"
 
 type SettingsGroup struct {
-	CachePath      string      `json:"cachePath" toml:"cachePath" comment:"The location of the per chain caches"`
-	IndexPath      string      `json:"indexPath" toml:"indexPath" comment:"The location of the per chain unchained indexes"`
-	DefaultChain   string      `json:"defaultChain" toml:"defaultChain" comment:"The default chain to use if none is provided"`
-	DefaultGateway string      `json:"defaultGateway" toml:"defaultGateway,omitempty"`
-	Notify         NotifyGroup `json:"notify" toml:"notify"`
+	CachePath      string            `json:"cachePath" toml:"cachePath" comment:"The location of the per chain caches"`
+	IndexPath      string            `json:"indexPath" toml:"indexPath" comment:"The location of the per chain unchained indexes"`
+	DefaultChain   string            `json:"defaultChain" toml:"defaultChain" comment:"The default chain to use if none is provided"`
+	DefaultGateway string            `json:"defaultGateway" toml:"defaultGateway,omitempty"`
+	CacheLocation  string            `json:"cacheLocation,omitempty" toml:"cacheLocation,omitempty" comment:"Where to store the binary cache (fs or ipfs)"`
+	CacheQuotas    map[string]string `json:"cacheQuotas,omitempty" toml:"cacheQuotas,omitempty" comment:"The maximum size (for example, 10GB) of each binary cache by folder name (blocks, transactions, ...) or default"`
+	Notify         NotifyGroup       `json:"notify" toml:"notify"`
 }
 
 func (s *SettingsGroup) String() string {
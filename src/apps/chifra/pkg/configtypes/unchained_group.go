@@ -5,6 +5,7 @@ import "encoding/json"
 type UnchainedGroup struct {
 	PreferredPublisher string `json:"preferredPublisher" toml:"preferredPublisher,omitempty" comment:"The default publisher of the index if none other is provided"`
 	SmartContract      string `json:"smartContract" toml:"smartContract,omitempty" comment:"The address of the current version of the Unchained Index"`
+	Keystore           string `json:"keystore" toml:"keystore,omitempty" comment:"The keystore file holding the key with which chifra chunks --publish signs"`
 }
 
 func (s *UnchainedGroup) String() string {
@@ -22,6 +22,7 @@ func Test_write(t *testing.T) {
   [settings.notify]
     url = ''
     author = ''
+    secret = ''
 
 [pinning]
   # The pinning gateway to query when downloading the unchained index
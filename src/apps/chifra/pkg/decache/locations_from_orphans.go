@@ -0,0 +1,72 @@
+package decache
+
+import (
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/cache"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// LocationsFromOrphans returns the locations of the cached items of blocks the chain has orphaned. The
+// node no longer knows these blocks, so the cached block (if any) tells us how many transactions it
+// had. Transactions and traces cached without their block are found by looking for them. There are
+// none if the cache is not open.
+func LocationsFromOrphans(conn *rpc.Connection, blockNums []base.Blknum) ([]cache.Locator, error) {
+	locations := make([]cache.Locator, 0)
+	if !conn.StoreReadable() {
+		return locations, nil
+	}
+	for _, bn := range blockNums {
+		nTxs := 0
+		block := &types.LightBlock{
+			BlockNumber: bn,
+		}
+		if err := conn.Store.Read(block, nil); err == nil {
+			nTxs = len(block.Transactions)
+		}
+
+		// walk.Cache_Blocks
+		locations = append(locations, block)
+
+		// walk.Cache_Receipts
+		locations = append(locations, &types.ReceiptGroup{
+			BlockNumber:      bn,
+			TransactionIndex: base.NOPOSN,
+		})
+
+		// walk.Cache_Logs
+		locations = append(locations, &types.LogGroup{
+			BlockNumber:      bn,
+			TransactionIndex: base.NOPOSN,
+		})
+
+		// walk.Cache_Withdrawals
+		locations = append(locations, &types.WithdrawalGroup{
+			BlockNumber:      bn,
+			TransactionIndex: base.NOPOSN,
+		})
+
+		for index := 0; ; index++ {
+			// walk.Cache_Transactions
+			tx := &types.Transaction{
+				BlockNumber:      bn,
+				TransactionIndex: base.Txnum(index),
+			}
+			// walk.Cache_Traces
+			traces := &types.TraceGroup{
+				BlockNumber:      bn,
+				TransactionIndex: base.Txnum(index),
+			}
+			if index >= nTxs && !isCached(conn, tx) && !isCached(conn, traces) {
+				break
+			}
+			locations = append(locations, tx, traces)
+		}
+	}
+	return locations, nil
+}
+
+func isCached(conn *rpc.Connection, locator cache.Locator) bool {
+	_, err := conn.Store.Stat(locator)
+	return err == nil
+}
@@ -0,0 +1,235 @@
+package devnet
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+)
+
+// The four byte selectors of the token functions the chain answers
+const (
+	selectorName              = "0x06fdde03"
+	selectorSymbol            = "0x95d89b41"
+	selectorDecimals          = "0x313ce567"
+	selectorTotalSupply       = "0x18160ddd"
+	selectorBalanceOf         = "0x70a08231"
+	selectorSupportsInterface = "0x01ffc9a7"
+)
+
+// ethCall answers a token's functions from the token's state, and other calls from the
+// scenario's calls. A call the scenario does not answer returns nothing, as a call to an
+// account without code does.
+func (c *chain) ethCall(params []json.RawMessage) (any, error) {
+	if len(params) == 0 {
+		return nil, invalidParams("missing value for required argument 0")
+	}
+	var msg struct {
+		To    string `json:"to"`
+		Data  string `json:"data"`
+		Input string `json:"input"`
+	}
+	if err := json.Unmarshal(params[0], &msg); err != nil {
+		return nil, invalidParams("invalid call %s", string(params[0]))
+	}
+	data := strings.ToLower(msg.Data)
+	if len(data) == 0 {
+		data = strings.ToLower(msg.Input)
+	}
+
+	b, err := c.blockParam(params, 1)
+	if err != nil {
+		return nil, err
+	} else if b == nil {
+		return nil, errNotFound
+	}
+
+	to := base.HexToAddress(msg.To)
+	if token := c.tokens[to]; token != nil {
+		return c.tokenCall(token, to, data, b.number)
+	}
+
+	var answer *Call
+	for i := range c.scenario.Calls {
+		call := &c.scenario.Calls[i]
+		if base.HexToAddress(call.To) != to || !strings.EqualFold(call.Data, data) || call.Block > b.number {
+			continue
+		}
+		if answer == nil || call.Block > answer.Block {
+			answer = call
+		}
+	}
+	switch {
+	case answer == nil:
+		return "0x", nil
+	case answer.Revert:
+		return nil, errReverted
+	case len(answer.Result) == 0:
+		return "0x", nil
+	}
+	return answer.Result, nil
+}
+
+func (c *chain) tokenCall(token *Token, address base.Address, data string, bn uint64) (any, error) {
+	if len(data) < 10 {
+		return nil, errReverted
+	}
+	switch data[:10] {
+	case selectorName:
+		return abiString(token.Name), nil
+	case selectorSymbol:
+		return abiString(token.Symbol), nil
+	case selectorDecimals:
+		return abiUint(new(big.Int).SetUint64(token.Decimals)), nil
+	case selectorTotalSupply:
+		return abiUint(c.supplies[address].at(bn)), nil
+	case selectorBalanceOf:
+		if len(data) < 10+64 {
+			return nil, errReverted
+		}
+		holder := base.HexToAddress("0x" + data[10+24:10+64])
+		return abiUint(c.holdings[tokenHolder{address, holder}].at(bn)), nil
+	case selectorSupportsInterface:
+		return abiUint(new(big.Int)), nil
+	}
+	return nil, errReverted
+}
+
+func abiUint(n *big.Int) string {
+	return fmt.Sprintf("0x%064x", n)
+}
+
+// abiString returns the ABI encoding of a string returned by a function
+func abiString(s string) string {
+	padded := hex.EncodeToString([]byte(s))
+	if rem := len(padded) % 64; rem != 0 {
+		padded += strings.Repeat("0", 64-rem)
+	}
+	return fmt.Sprintf("0x%064x%064x%s", 32, len(s), padded)
+}
+
+// getLogs returns the logs that match the filter's block range (or block hash), addresses,
+// and topics
+func (c *chain) getLogs(params []json.RawMessage) (any, error) {
+	if len(params) == 0 {
+		return nil, invalidParams("missing value for required argument 0")
+	}
+	var filter struct {
+		FromBlock json.RawMessage   `json:"fromBlock"`
+		ToBlock   json.RawMessage   `json:"toBlock"`
+		BlockHash string            `json:"blockHash"`
+		Address   json.RawMessage   `json:"address"`
+		Topics    []json.RawMessage `json:"topics"`
+	}
+	if err := json.Unmarshal(params[0], &filter); err != nil {
+		return nil, invalidParams("invalid filter %s", string(params[0]))
+	}
+
+	var blocks []*block
+	if len(filter.BlockHash) > 0 {
+		b := c.byHash[strings.ToLower(filter.BlockHash)]
+		if b == nil {
+			return nil, &rpcError{Code: -32000, Message: "unknown block"}
+		}
+		blocks = []*block{b}
+	} else {
+		from, err := c.rangeParam(filter.FromBlock)
+		if err != nil {
+			return nil, err
+		}
+		to, err := c.rangeParam(filter.ToBlock)
+		if err != nil {
+			return nil, err
+		}
+		if from <= to {
+			blocks = c.blocks[from : to+1]
+		}
+	}
+
+	addresses, err := anyOf(filter.Address)
+	if err != nil {
+		return nil, invalidParams("invalid address filter %s", string(filter.Address))
+	}
+	topics := make([][]string, 0, len(filter.Topics))
+	for _, t := range filter.Topics {
+		values, err := anyOf(t)
+		if err != nil {
+			return nil, invalidParams("invalid topic filter %s", string(t))
+		}
+		topics = append(topics, values)
+	}
+
+	logs := []map[string]any{}
+	for _, b := range blocks {
+		for _, tx := range b.transactions {
+			for _, l := range tx.logs {
+				if matches(l, addresses, topics) {
+					logs = append(logs, c.renderLog(tx, l))
+				}
+			}
+		}
+	}
+	return logs, nil
+}
+
+// rangeParam returns the block number named by one end of a filter's range. Blocks beyond
+// the chain's head are the head.
+func (c *chain) rangeParam(param json.RawMessage) (uint64, error) {
+	if len(param) == 0 || string(param) == "null" {
+		return c.latest(), nil
+	}
+	b, err := c.blockParam([]json.RawMessage{param}, 0)
+	if err != nil {
+		return 0, err
+	} else if b == nil {
+		return c.latest(), nil
+	}
+	return b.number, nil
+}
+
+// anyOf returns the values of a filter's position, which may be null (anything matches), a
+// single value, or a list of values (any of which matches)
+func anyOf(param json.RawMessage) ([]string, error) {
+	if len(param) == 0 || string(param) == "null" {
+		return nil, nil
+	}
+	var single string
+	if err := json.Unmarshal(param, &single); err == nil {
+		return []string{strings.ToLower(single)}, nil
+	}
+	var list []string
+	if err := json.Unmarshal(param, &list); err != nil {
+		return nil, err
+	}
+	for i := range list {
+		list[i] = strings.ToLower(list[i])
+	}
+	return list, nil
+}
+
+func matches(l *log, addresses []string, topics [][]string) bool {
+	if len(addresses) > 0 && !contains(addresses, hexAddress(l.address)) {
+		return false
+	}
+	if len(topics) > len(l.topics) {
+		return false
+	}
+	for i, values := range topics {
+		if len(values) > 0 && !contains(values, strings.ToLower(l.topics[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
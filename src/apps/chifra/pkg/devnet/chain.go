@@ -0,0 +1,339 @@
+package devnet
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// transferTopic is the topic of ERC-20 Transfer logs
+	transferTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	// contractCode is the code of every contract on the chain. Only its presence matters.
+	contractCode = "0x6080604052"
+)
+
+// chain is a scenario's blocks (including the empty blocks between those the scenario gives)
+// and the state that follows from them
+type chain struct {
+	scenario *Scenario
+	branch   string
+	blocks   []*block
+	byHash   map[string]*block
+	txByHash map[string]*transaction
+	tokens   map[base.Address]*Token
+	deployed map[base.Address]uint64
+	balances map[base.Address]*history
+	nonces   map[base.Address]*history
+	holdings map[tokenHolder]*history
+	supplies map[base.Address]*history
+}
+
+type block struct {
+	number       uint64
+	branch       string
+	timestamp    int64
+	hash         string
+	parentHash   string
+	miner        base.Address
+	gasUsed      uint64
+	transactions []*transaction
+	withdrawals  []Withdrawal
+}
+
+type transaction struct {
+	block   *block
+	index   uint64
+	hash    string
+	nonce   uint64
+	from    base.Address
+	to      *base.Address
+	creates *base.Address
+	source  *Transaction
+	logs    []*log
+	gasUsed uint64
+	// cumulativeGasUsed is the gas used by the block's transactions up to and including this one
+	cumulativeGasUsed uint64
+}
+
+type log struct {
+	address base.Address
+	topics  []string
+	data    string
+	index   uint64
+}
+
+type tokenHolder struct {
+	token  base.Address
+	holder base.Address
+}
+
+func (t tokenHolder) String() string {
+	return t.holder.Hex() + " (holding " + t.token.Hex() + ")"
+}
+
+// history is the value of something (a balance, a nonce) from each block at which it changed
+type history struct {
+	blocks []uint64
+	values []*big.Int
+}
+
+// at returns the value at the end of the block
+func (h *history) at(bn uint64) *big.Int {
+	if h == nil {
+		return new(big.Int)
+	}
+	i := sort.Search(len(h.blocks), func(i int) bool { return h.blocks[i] > bn })
+	if i == 0 {
+		return new(big.Int)
+	}
+	return h.values[i-1]
+}
+
+func (h *history) latest() *big.Int {
+	if h == nil || len(h.values) == 0 {
+		return new(big.Int)
+	}
+	return h.values[len(h.values)-1]
+}
+
+// add changes the value at the block (which may not be before the last change)
+func (h *history) add(bn uint64, delta *big.Int) *big.Int {
+	value := new(big.Int).Add(h.latest(), delta)
+	if n := len(h.blocks); n > 0 && h.blocks[n-1] == bn {
+		h.values[n-1] = value
+	} else {
+		h.blocks = append(h.blocks, bn)
+		h.values = append(h.values, value)
+	}
+	return value
+}
+
+// newChain builds the chain from the (prepared) scenario. It fails if the scenario would leave
+// an account (or a token holder) with a negative balance.
+func newChain(s *Scenario) (*chain, error) {
+	c := &chain{
+		scenario: s,
+		byHash:   map[string]*block{},
+		txByHash: map[string]*transaction{},
+		tokens:   map[base.Address]*Token{},
+		deployed: map[base.Address]uint64{},
+		balances: map[base.Address]*history{},
+		nonces:   map[base.Address]*history{},
+		holdings: map[tokenHolder]*history{},
+		supplies: map[base.Address]*history{},
+	}
+
+	genesis := c.addBlock(0, s.Timestamp, base.ZeroAddr)
+	for addr, value := range s.Alloc {
+		if err := adjust(c.balances, base.HexToAddress(addr), genesis.number, wei(value).BigInt()); err != nil {
+			return nil, err
+		}
+	}
+	for i := range s.Tokens {
+		token := &s.Tokens[i]
+		address := base.HexToAddress(token.Address)
+		c.tokens[address] = token
+		c.deployed[address] = 0
+		for holder, value := range token.Alloc {
+			if err := c.mint(address, base.HexToAddress(holder), 0, wei(value).BigInt()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for i := range s.Blocks {
+		source := &s.Blocks[i]
+
+		// The blocks the scenario skips are empty, with timestamps spread evenly between its neighbors
+		prev := c.blocks[len(c.blocks)-1]
+		gap := source.Number - prev.number
+		for bn := prev.number + 1; bn < source.Number; bn++ {
+			ts := prev.timestamp + (source.Timestamp-prev.timestamp)*int64(bn-prev.number)/int64(gap)
+			c.addBlock(bn, ts, base.ZeroAddr)
+		}
+
+		if len(source.Fork) > 0 {
+			c.branch = source.Fork
+		}
+		b := c.addBlock(source.Number, source.Timestamp, base.HexToAddress(source.Miner))
+		b.withdrawals = source.Withdrawals
+		for j := range source.Transactions {
+			if err := c.addTransaction(b, &source.Transactions[j]); err != nil {
+				return nil, err
+			}
+		}
+		for _, w := range source.Withdrawals {
+			amount := new(big.Int).Mul(new(big.Int).SetUint64(w.Amount), big.NewInt(1000000000))
+			if err := adjust(c.balances, base.HexToAddress(w.Address), b.number, amount); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return c, nil
+}
+
+func (c *chain) addBlock(bn uint64, ts int64, miner base.Address) *block {
+	b := &block{
+		number:     bn,
+		branch:     c.branch,
+		timestamp:  ts,
+		hash:       c.hash(c.branch, "block", bn),
+		parentHash: "0x" + strings.Repeat("0", 64),
+		miner:      miner,
+	}
+	if bn > 0 {
+		b.parentHash = c.blocks[bn-1].hash
+	}
+	c.blocks = append(c.blocks, b)
+	c.byHash[b.hash] = b
+	return b
+}
+
+// addTransaction adds the transaction to the block and applies its changes to the state
+func (c *chain) addTransaction(b *block, source *Transaction) error {
+	tx := &transaction{
+		block:   b,
+		index:   uint64(len(b.transactions)),
+		from:    base.HexToAddress(source.From),
+		source:  source,
+		gasUsed: source.GasUsed,
+	}
+	tx.hash = c.hash(b.branch, "tx", b.number, tx.index)
+	tx.nonce = c.nonces[tx.from].latest().Uint64()
+	if len(source.To) > 0 {
+		to := base.HexToAddress(source.To)
+		tx.to = &to
+	} else {
+		creates := base.HexToAddress(source.Creates)
+		tx.creates = &creates
+	}
+	b.gasUsed += tx.gasUsed
+	tx.cumulativeGasUsed = b.gasUsed
+	b.transactions = append(b.transactions, tx)
+	c.txByHash[tx.hash] = tx
+
+	bn := b.number
+	if err := adjust(c.nonces, tx.from, bn, big.NewInt(1)); err != nil {
+		return err
+	}
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(tx.gasUsed), wei(source.GasPrice).BigInt())
+	if err := adjust(c.balances, tx.from, bn, fee.Neg(fee)); err != nil {
+		return err
+	}
+	if source.IsError {
+		return nil
+	}
+
+	receiver := tx.to
+	if tx.creates != nil {
+		receiver = tx.creates
+		c.deployed[*tx.creates] = bn
+	}
+	if err := c.move(tx.from, *receiver, bn, wei(source.Value).BigInt()); err != nil {
+		return err
+	}
+	for _, trace := range source.Traces {
+		if err := c.move(base.HexToAddress(trace.From), base.HexToAddress(trace.To), bn, wei(trace.Value).BigInt()); err != nil {
+			return err
+		}
+	}
+
+	nLogs := uint64(0)
+	for _, prior := range b.transactions[:tx.index] {
+		nLogs += uint64(len(prior.logs))
+	}
+	for _, l := range source.Logs {
+		tx.logs = append(tx.logs, &log{
+			address: base.HexToAddress(l.Address),
+			topics:  l.Topics,
+			data:    l.Data,
+			index:   nLogs + uint64(len(tx.logs)),
+		})
+	}
+	for _, transfer := range source.Transfers {
+		token, from, to := base.HexToAddress(transfer.Token), base.HexToAddress(transfer.From), base.HexToAddress(transfer.To)
+		value := wei(transfer.Value).BigInt()
+		if err := c.transfer(token, from, to, bn, value); err != nil {
+			return err
+		}
+		tx.logs = append(tx.logs, &log{
+			address: token,
+			topics:  []string{transferTopic, topic(from), topic(to)},
+			data:    fmt.Sprintf("0x%064x", value),
+			index:   nLogs + uint64(len(tx.logs)),
+		})
+	}
+	return nil
+}
+
+// move moves value (in wei) from one account to another
+func (c *chain) move(from, to base.Address, bn uint64, value *big.Int) error {
+	if err := adjust(c.balances, from, bn, new(big.Int).Neg(value)); err != nil {
+		return err
+	}
+	return adjust(c.balances, to, bn, value)
+}
+
+// transfer moves tokens from one holder to another, minting them if they come from the zero
+// address and burning them if they go to it
+func (c *chain) transfer(token, from, to base.Address, bn uint64, value *big.Int) error {
+	if from.IsZero() {
+		return c.mint(token, to, bn, value)
+	}
+	if err := adjust(c.holdings, tokenHolder{token, from}, bn, new(big.Int).Neg(value)); err != nil {
+		return err
+	}
+	if to.IsZero() {
+		return adjust(c.supplies, token, bn, new(big.Int).Neg(value))
+	}
+	return adjust(c.holdings, tokenHolder{token, to}, bn, value)
+}
+
+func (c *chain) mint(token, to base.Address, bn uint64, value *big.Int) error {
+	if err := adjust(c.supplies, token, bn, value); err != nil {
+		return err
+	}
+	return adjust(c.holdings, tokenHolder{token, to}, bn, value)
+}
+
+// adjust changes the value of the key's history at the block, failing if the value would
+// become negative
+func adjust[K comparable](histories map[K]*history, key K, bn uint64, delta *big.Int) error {
+	h := histories[key]
+	if h == nil {
+		h = &history{}
+		histories[key] = h
+	}
+	if h.add(bn, delta).Sign() < 0 {
+		return fmt.Errorf("block %d: %v would have a negative balance", bn, key)
+	}
+	return nil
+}
+
+// hash returns a made up (but stable) hash for the blocks and transactions on a branch of the chain
+func (c *chain) hash(branch, kind string, ids ...uint64) string {
+	seed := fmt.Sprintf("devnet:%d:%s", c.scenario.ChainId, kind)
+	if len(branch) > 0 {
+		seed = fmt.Sprintf("devnet:%d/%s:%s", c.scenario.ChainId, branch, kind)
+	}
+	for _, id := range ids {
+		seed += fmt.Sprintf(":%d", id)
+	}
+	return crypto.Keccak256Hash([]byte(seed)).Hex()
+}
+
+func (c *chain) latest() uint64 {
+	return c.blocks[len(c.blocks)-1].number
+}
+
+func (c *chain) code(addr base.Address, bn uint64) string {
+	if deployed, ok := c.deployed[addr]; ok && deployed <= bn {
+		return contractCode
+	}
+	return "0x"
+}
@@ -0,0 +1,298 @@
+package devnet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	accountOne = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	accountTwo = common.HexToAddress("0x2222222222222222222222222222222222222222")
+	token      = common.HexToAddress("0xaaaa000000000000000000000000000000000000")
+	contract   = common.HexToAddress("0xcccc000000000000000000000000000000000000")
+)
+
+// newTestServer serves the scenario in testdata and returns a client of it
+func newTestServer(t *testing.T) (*ethclient.Client, *rpc.Client) {
+	t.Helper()
+	s, err := LoadScenario("testdata/simple.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+
+	client, err := rpc.Dial(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	return ethclient.NewClient(client), client
+}
+
+func TestDevnetBlocks(t *testing.T) {
+	client, _ := newTestServer(t)
+	ctx := context.Background()
+
+	if chainId, err := client.ChainID(ctx); err != nil || chainId.Uint64() != 1337 {
+		t.Fatalf("chainId %v, %v", chainId, err)
+	}
+	if latest, err := client.BlockNumber(ctx); err != nil || latest != 4 {
+		t.Fatalf("latest block %d, %v", latest, err)
+	}
+
+	expected := []struct {
+		timestamp uint64
+		nTxs      int
+	}{{1700000000, 0}, {1700000012, 1}, {1700000024, 0}, {1700000036, 1}, {1700000048, 2}}
+	var parent common.Hash
+	for bn, want := range expected {
+		block, err := client.BlockByNumber(ctx, big.NewInt(int64(bn)))
+		if err != nil {
+			t.Fatalf("block %d: %v", bn, err)
+		}
+		if block.Time() != want.timestamp || len(block.Transactions()) != want.nTxs {
+			t.Errorf("block %d: timestamp %d with %d transactions, expected %d with %d", bn, block.Time(), len(block.Transactions()), want.timestamp, want.nTxs)
+		}
+		if bn > 0 && block.ParentHash() != parent {
+			t.Errorf("block %d: parent hash %s, expected %s", bn, block.ParentHash().Hex(), parent.Hex())
+		}
+		parent = common.HexToHash(blockHashes(t, client, bn)[0])
+	}
+
+	if block, err := client.BlockByNumber(ctx, big.NewInt(5)); err != ethereum.NotFound {
+		t.Errorf("block beyond the head: %v, %v", block, err)
+	}
+	if block, err := client.BlockByNumber(ctx, big.NewInt(4)); err != nil || len(block.Withdrawals()) != 1 {
+		t.Errorf("block 4 withdrawals: %v", err)
+	}
+}
+
+func TestDevnetFork(t *testing.T) {
+	// The same four blocks, but the second scenario's blocks are on another branch from block 2
+	scenario := "[[blocks]]\n[[blocks]]\n%s[[blocks]]\n[[blocks]]\n"
+	hashes := make([][]string, 0, 2)
+	for _, fork := range []string{"", "fork = \"b\"\n"} {
+		s, err := ParseScenario([]byte(fmt.Sprintf(scenario, fork)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := newChain(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chainHashes := []string{}
+		for _, b := range c.blocks {
+			if b.number > 0 && b.parentHash != chainHashes[len(chainHashes)-1] {
+				t.Errorf("block %d: parent hash %s", b.number, b.parentHash)
+			}
+			chainHashes = append(chainHashes, b.hash)
+		}
+		hashes = append(hashes, chainHashes)
+	}
+
+	for bn := range hashes[0] {
+		if same := hashes[0][bn] == hashes[1][bn]; same != (bn < 2) {
+			t.Errorf("block %d: hashes %s and %s", bn, hashes[0][bn], hashes[1][bn])
+		}
+	}
+}
+
+// blockHashes returns the hashes the server gave the block and its transactions (which are not
+// the hashes a client computes from them)
+func blockHashes(t *testing.T, client *ethclient.Client, bn int) []string {
+	var head struct {
+		Hash         string   `json:"hash"`
+		Transactions []string `json:"transactions"`
+	}
+	if err := client.Client().Call(&head, "eth_getBlockByNumber", hexUint(uint64(bn)), false); err != nil {
+		t.Fatal(err)
+	}
+	return append([]string{head.Hash}, head.Transactions...)
+}
+
+func TestDevnetBalances(t *testing.T) {
+	client, _ := newTestServer(t)
+	ctx := context.Background()
+
+	expected := []struct {
+		address common.Address
+		bn      int64
+		balance string
+	}{
+		{accountOne, 0, "10000000000000000000"},
+		{accountOne, 1, "8999979000000000000"},
+		{accountOne, 2, "8999979000000000000"},
+		{accountOne, 3, "8999928999999999995"},
+		{accountOne, 4, "8999929001999999995"},
+		{accountTwo, 0, "1000000000000000000"},
+		{accountTwo, 1, "2000000000000000000"},
+		{accountTwo, 3, "2000000000000000005"},
+		{accountTwo, 4, "1999958000000000005"},
+		{contract, 4, "0"},
+	}
+	for _, want := range expected {
+		balance, err := client.BalanceAt(ctx, want.address, big.NewInt(want.bn))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if balance.String() != want.balance {
+			t.Errorf("%s at %d: balance %s, expected %s", want.address.Hex(), want.bn, balance, want.balance)
+		}
+	}
+
+	if nonce, err := client.NonceAt(ctx, accountTwo, big.NewInt(4)); err != nil || nonce != 2 {
+		t.Errorf("nonce %d, %v", nonce, err)
+	}
+	if _, err := client.BalanceAt(ctx, accountOne, big.NewInt(5)); err == nil {
+		t.Error("expected an error for a balance beyond the head")
+	}
+	for bn, want := range map[int64]int{3: 0, 4: len(contractCode)/2 - 1} {
+		if code, err := client.CodeAt(ctx, contract, big.NewInt(bn)); err != nil || len(code) != want {
+			t.Errorf("code at %d: %x, %v", bn, code, err)
+		}
+	}
+}
+
+func TestDevnetReceiptsAndTraces(t *testing.T) {
+	client, raw := newTestServer(t)
+	ctx := context.Background()
+
+	hashes := blockHashes(t, client, 4)
+	failed, err := client.TransactionReceipt(ctx, common.HexToHash(hashes[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failed.Status != 0 || len(failed.Logs) != 0 {
+		t.Errorf("failed transaction: status %d with %d logs", failed.Status, len(failed.Logs))
+	}
+	created, err := client.TransactionReceipt(ctx, common.HexToHash(hashes[2]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ContractAddress != contract || created.CumulativeGasUsed != 42000 {
+		t.Errorf("creation: contract %s, cumulative gas %d", created.ContractAddress.Hex(), created.CumulativeGasUsed)
+	}
+
+	var traces []struct {
+		Type   string `json:"type"`
+		Error  string `json:"error"`
+		Action struct {
+			From  string `json:"from"`
+			To    string `json:"to"`
+			Value string `json:"value"`
+		} `json:"action"`
+		TraceAddress []int `json:"traceAddress"`
+	}
+	if err := raw.Call(&traces, "trace_transaction", blockHashes(t, client, 3)[1]); err != nil {
+		t.Fatal(err)
+	}
+	if len(traces) != 2 || traces[1].Action.Value != "0x5" || len(traces[1].TraceAddress) != 1 {
+		t.Errorf("traces: %+v", traces)
+	}
+	if err := raw.Call(&traces, "trace_block", "0x4"); err != nil {
+		t.Fatal(err)
+	}
+	if len(traces) != 2 || traces[0].Error != "Reverted" || traces[1].Type != "create" {
+		t.Errorf("block traces: %+v", traces)
+	}
+}
+
+func TestDevnetLogsAndCalls(t *testing.T) {
+	client, _ := newTestServer(t)
+	ctx := context.Background()
+
+	logs, err := client.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: big.NewInt(0),
+		Addresses: []common.Address{token},
+		Topics:    [][]common.Hash{{common.HexToHash(transferTopic)}, nil, {common.BytesToHash(accountTwo.Bytes())}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 1 || logs[0].BlockNumber != 3 || new(big.Int).SetBytes(logs[0].Data).Int64() != 100 {
+		t.Fatalf("logs: %+v", logs)
+	}
+	if logs, err := client.FilterLogs(ctx, ethereum.FilterQuery{FromBlock: big.NewInt(4), Addresses: []common.Address{token}}); err != nil || len(logs) != 0 {
+		t.Errorf("logs in block 4: %d, %v", len(logs), err)
+	}
+
+	balanceOf := func(holder common.Address, bn int64) *big.Int {
+		data := common.FromHex(selectorBalanceOf + strings.Repeat("0", 24) + holder.Hex()[2:])
+		result, err := client.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data}, big.NewInt(bn))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return new(big.Int).SetBytes(result)
+	}
+	if one, two := balanceOf(accountOne, 2), balanceOf(accountTwo, 2); one.Int64() != 500 || two.Int64() != 0 {
+		t.Errorf("token balances at 2: %s, %s", one, two)
+	}
+	if one, two := balanceOf(accountOne, 3), balanceOf(accountTwo, 3); one.Int64() != 400 || two.Int64() != 100 {
+		t.Errorf("token balances at 3: %s, %s", one, two)
+	}
+
+	symbol, err := client.CallContract(ctx, ethereum.CallMsg{To: &token, Data: common.FromHex(selectorSymbol)}, nil)
+	if err != nil || len(symbol) != 96 || string(symbol[64:66]) != "TT" {
+		t.Errorf("symbol: %x, %v", symbol, err)
+	}
+	if _, err := client.CallContract(ctx, ethereum.CallMsg{To: &token, Data: common.FromHex("0xdeadbeef")}, nil); err == nil || !strings.Contains(err.Error(), "execution reverted") {
+		t.Errorf("unknown token function: %v", err)
+	}
+	if result, err := client.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: common.FromHex("0x12345678")}, nil); err != nil || new(big.Int).SetBytes(result).Int64() != 1 {
+		t.Errorf("scenario call: %x, %v", result, err)
+	}
+}
+
+func TestDevnetScenarioErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		scenario string
+		err      string
+	}{
+		{
+			name:     "unknown traces",
+			scenario: "traces = \"vmTrace\"\n",
+			err:      "unknown traces vmTrace",
+		},
+		{
+			name:     "out of order",
+			scenario: "[[blocks]]\nnumber = 3\n[[blocks]]\nnumber = 2\n",
+			err:      "blocks must be in order",
+		},
+		{
+			name:     "no receiver",
+			scenario: "[[blocks]]\n[[blocks.transactions]]\nfrom = \"0x1111111111111111111111111111111111111111\"\n",
+			err:      "requires either to or creates",
+		},
+		{
+			name:     "overdrawn",
+			scenario: "[[blocks]]\n[[blocks.transactions]]\nfrom = \"0x1111111111111111111111111111111111111111\"\nto = \"0x2222222222222222222222222222222222222222\"\n",
+			err:      "negative balance",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseScenario([]byte(tt.scenario))
+			if err == nil {
+				_, err = NewServer(s)
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+// Package devnet serves a synthetic chain, described by a scenario file, over JSON-RPC as an
+// archive, tracing node would, so that chifra's commands (scrape, export --accounting, chunks)
+// may be run end to end against a chain whose every balance is known in advance.
+//
+// The chain's state follows from the scenario: genesis allocations, transaction values and
+// fees, internal calls (traces), token transfers (which are emitted as Transfer logs), and
+// withdrawals. A scenario that would leave a balance negative is rejected when it is loaded.
+// See dev_tools/devnet for a server that may be pointed at by a chain's rpcProvider.
+package devnet
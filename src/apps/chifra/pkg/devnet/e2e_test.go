@@ -0,0 +1,183 @@
+package devnet
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// chifraAgainst builds chifra and returns a function that runs it against the scenario's chain
+// (as chain devnet) with a configuration of its own
+func chifraAgainst(t *testing.T, scenarioPath string) func(args ...string) []byte {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("builds and runs chifra")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("needs the go tool to build chifra")
+	}
+
+	s, err := LoadScenario(scenarioPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+
+	dir := t.TempDir()
+	chifra := filepath.Join(dir, "chifra")
+	if out, err := exec.Command(goBin, "build", "-o", chifra, "../..").CombinedOutput(); err != nil {
+		t.Fatalf("building chifra: %v\n%s", err, out)
+	}
+
+	configDir := filepath.Join(dir, "config")
+	if err := os.MkdirAll(filepath.Join(configDir, "config", "devnet"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteAllocs(filepath.Join(configDir, "config", "devnet", "allocs.csv")); err != nil {
+		t.Fatal(err)
+	}
+	config := fmt.Sprintf(`[version]
+  current = "v2.0.0-release"
+
+[settings]
+  defaultChain = "devnet"
+
+[keys]
+  [keys.trueblocks]
+    license = "+accounting"
+
+[chains]
+  [chains.devnet]
+    chain = "devnet"
+    chainId = "%d"
+    rpcProvider = "%s"
+    symbol = "ETH"
+`, s.ChainId, ts.URL)
+	if err := os.WriteFile(filepath.Join(configDir, "trueBlocks.toml"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	env := []string{"XDG_CONFIG_HOME=" + configDir}
+	for _, v := range os.Environ() {
+		if !strings.HasPrefix(v, "TB_") && !strings.HasPrefix(v, "XDG_CONFIG_HOME=") {
+			env = append(env, v)
+		}
+	}
+
+	return func(args ...string) []byte {
+		t.Helper()
+		cmd := exec.Command(chifra, append(args, "--chain", "devnet")...)
+		cmd.Dir = dir
+		cmd.Env = env
+		stderr := &strings.Builder{}
+		cmd.Stderr = stderr
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("chifra %s: %v\n%s", strings.Join(args, " "), err, stderr.String())
+		}
+		return out
+	}
+}
+
+// decodeData decodes the data of chifra's json output into v
+func decodeData(t *testing.T, out []byte, v any) {
+	t.Helper()
+	var result struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+	if err := json.Unmarshal(result.Data, v); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+}
+
+// TestDevnetEndToEnd scrapes the simple scenario's chain, checks the chunks it wrote, and then
+// reconciles the second account's history against the balances the scenario implies
+func TestDevnetEndToEnd(t *testing.T) {
+	chifra := chifraAgainst(t, "testdata/simple.toml")
+	accountTwo := "0x2222222222222222222222222222222222222222"
+
+	chifra("scrape", "--block_cnt", "10", "--run_count", "1", "--unripe_dist", "0",
+		"--apps_per_chunk", "5", "--first_snap", "0", "--snap_to_grid", "100")
+
+	var chunks []struct {
+		Range        string `json:"range"`
+		NAddresses   uint64 `json:"nAddresses"`
+		NAppearances uint64 `json:"nAppearances"`
+	}
+	decodeData(t, chifra("chunks", "index", "--fmt", "json"), &chunks)
+	expectedChunks := []string{"000000000-000000000 2 2", "000000001-000000003 4 6", "000000004-000000004 3 5"}
+	if len(chunks) != len(expectedChunks) {
+		t.Fatal("unexpected chunks", chunks)
+	}
+	for i, want := range expectedChunks {
+		if got := fmt.Sprintf("%s %d %d", chunks[i].Range, chunks[i].NAddresses, chunks[i].NAppearances); got != want {
+			t.Errorf("chunk %d: got %s, expected %s", i, got, want)
+		}
+	}
+
+	var apps []struct {
+		BlockNumber      uint64 `json:"blockNumber"`
+		TransactionIndex uint64 `json:"transactionIndex"`
+	}
+	decodeData(t, chifra("export", "--appearances", "--fmt", "json", accountTwo), &apps)
+	if got := fmt.Sprint(apps); got != "[{0 1} {1 0} {3 0} {4 0} {4 1}]" {
+		t.Error("unexpected appearances", got)
+	}
+
+	// Block-level balances cannot reconcile two transactions of one account in the same block
+	// (the account's two in block 4), so the reconciliations stop at block 3
+	var statements []struct {
+		BlockNumber uint64 `json:"blockNumber"`
+		AssetAddr   string `json:"assetAddr"`
+		PrefundIn   string `json:"prefundIn"`
+		AmountIn    string `json:"amountIn"`
+		InternalIn  string `json:"internalIn"`
+		BegBal      string `json:"begBal"`
+		EndBal      string `json:"endBal"`
+		Reconciled  bool   `json:"reconciled"`
+	}
+	decodeData(t, chifra("export", "--accounting", "--statements", "--last_block", "3", "--fmt", "json", accountTwo), &statements)
+
+	eth, token := "0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee", "0xaaaa000000000000000000000000000000000000"
+	expected := []struct {
+		block    uint64
+		asset    string
+		prefund  string
+		amount   string
+		internal string
+		begBal   string
+		endBal   string
+	}{
+		{0, eth, "1000000000000000000", "0", "0", "0", "1000000000000000000"},
+		{1, eth, "0", "1000000000000000000", "0", "1000000000000000000", "2000000000000000000"},
+		{3, eth, "0", "0", "5", "2000000000000000000", "2000000000000000005"},
+		{3, token, "0", "100", "0", "0", "100"},
+	}
+	if len(statements) != len(expected) {
+		t.Fatalf("expected %d statements, got %d: %v", len(expected), len(statements), statements)
+	}
+	for i, want := range expected {
+		s := statements[i]
+		if !s.Reconciled {
+			t.Errorf("statement %d (block %d, %s) does not reconcile", i, s.BlockNumber, s.AssetAddr)
+		}
+		got := fmt.Sprint(s.BlockNumber, s.AssetAddr, s.PrefundIn, s.AmountIn, s.InternalIn, s.BegBal, s.EndBal)
+		if exp := fmt.Sprint(want.block, want.asset, want.prefund, want.amount, want.internal, want.begBal, want.endBal); got != exp {
+			t.Errorf("statement %d: got %s, expected %s", i, got, exp)
+		}
+	}
+}
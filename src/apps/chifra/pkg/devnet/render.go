@@ -0,0 +1,446 @@
+package devnet
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+)
+
+const (
+	// emptyUnclesHash and emptyListHash are the hashes of an empty list of uncles (or of
+	// transactions, receipts, or withdrawals), which clients check against the block's lists
+	emptyUnclesHash = "0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347"
+	emptyListHash   = "0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421"
+	gasLimit        = 30000000
+)
+
+var (
+	zeroHash  = "0x" + strings.Repeat("0", 64)
+	zeroBloom = "0x" + strings.Repeat("0", 512)
+)
+
+func hexUint(n uint64) string {
+	return "0x" + strconv.FormatUint(n, 16)
+}
+
+func hexBig(n *big.Int) string {
+	return fmt.Sprintf("0x%x", n)
+}
+
+// hexAddress returns the address in full (base.Address's Hex shortens the zero address to 0x0,
+// which clients reject)
+func hexAddress(addr base.Address) string {
+	return "0x" + hex.EncodeToString(addr.Bytes())
+}
+
+// topic returns the address as a log topic
+func topic(addr base.Address) string {
+	return "0x" + strings.Repeat("0", 24) + hex.EncodeToString(addr.Bytes())
+}
+
+// blockParam returns the block named by the param (a tag, a number, or an EIP-1898 object). A
+// block beyond the chain's head is nil.
+func (c *chain) blockParam(params []json.RawMessage, pos int) (*block, error) {
+	if pos >= len(params) {
+		return c.blocks[c.latest()], nil
+	}
+
+	var name string
+	if err := json.Unmarshal(params[pos], &name); err != nil {
+		var object struct {
+			BlockNumber string `json:"blockNumber"`
+			BlockHash   string `json:"blockHash"`
+		}
+		if err := json.Unmarshal(params[pos], &object); err != nil {
+			return nil, invalidParams("invalid block %s", string(params[pos]))
+		}
+		if len(object.BlockHash) > 0 {
+			return c.byHash[strings.ToLower(object.BlockHash)], nil
+		}
+		name = object.BlockNumber
+	}
+
+	switch name {
+	case "", "latest", "pending", "safe", "finalized":
+		return c.blocks[c.latest()], nil
+	case "earliest":
+		return c.blocks[0], nil
+	}
+	bn, err := strconv.ParseUint(strings.TrimPrefix(name, "0x"), 16, 64)
+	if err != nil || !strings.HasPrefix(name, "0x") {
+		return nil, invalidParams("invalid block %s", name)
+	}
+	if bn > c.latest() {
+		return nil, nil
+	}
+	return c.blocks[bn], nil
+}
+
+func (c *chain) hashParam(params []json.RawMessage, pos int) (*block, error) {
+	hash, err := stringParam(params, pos)
+	if err != nil {
+		return nil, err
+	}
+	return c.byHash[strings.ToLower(hash)], nil
+}
+
+func (c *chain) txParam(params []json.RawMessage, pos int) (*transaction, error) {
+	hash, err := stringParam(params, pos)
+	if err != nil {
+		return nil, err
+	}
+	return c.txByHash[strings.ToLower(hash)], nil
+}
+
+func (c *chain) indexParam(b *block, params []json.RawMessage, pos int) *transaction {
+	value, err := stringParam(params, pos)
+	if err != nil {
+		return nil
+	}
+	index, err := strconv.ParseUint(strings.TrimPrefix(value, "0x"), 16, 64)
+	if err != nil || index >= uint64(len(b.transactions)) {
+		return nil
+	}
+	return b.transactions[index]
+}
+
+func stringParam(params []json.RawMessage, pos int) (string, error) {
+	if pos >= len(params) {
+		return "", invalidParams("missing value for required argument %d", pos)
+	}
+	var value string
+	if err := json.Unmarshal(params[pos], &value); err != nil {
+		return "", invalidParams("invalid argument %d: %s", pos, string(params[pos]))
+	}
+	return value, nil
+}
+
+func boolParam(params []json.RawMessage, pos int) bool {
+	var value bool
+	if pos < len(params) {
+		_ = json.Unmarshal(params[pos], &value)
+	}
+	return value
+}
+
+func addressParam(params []json.RawMessage, pos int) (base.Address, error) {
+	value, err := stringParam(params, pos)
+	if err != nil {
+		return base.ZeroAddr, err
+	}
+	if !base.IsValidAddress(value) {
+		return base.ZeroAddr, invalidParams("invalid address %s", value)
+	}
+	return base.HexToAddress(value), nil
+}
+
+func (c *chain) renderBlock(b *block, full bool) map[string]any {
+	txs := make([]any, 0, len(b.transactions))
+	for _, tx := range b.transactions {
+		if full {
+			txs = append(txs, c.renderTransaction(tx))
+		} else {
+			txs = append(txs, tx.hash)
+		}
+	}
+	withdrawals := make([]map[string]any, 0, len(b.withdrawals))
+	for _, w := range b.withdrawals {
+		withdrawals = append(withdrawals, map[string]any{
+			"index":          hexUint(w.Index),
+			"validatorIndex": hexUint(w.ValidatorIndex),
+			"address":        hexAddress(base.HexToAddress(w.Address)),
+			"amount":         hexUint(w.Amount),
+		})
+	}
+
+	txsRoot, receiptsRoot, withdrawalsRoot := emptyListHash, emptyListHash, emptyListHash
+	if len(b.transactions) > 0 {
+		txsRoot, receiptsRoot = c.hash(b.branch, "txs", b.number), c.hash(b.branch, "receipts", b.number)
+	}
+	if len(b.withdrawals) > 0 {
+		withdrawalsRoot = c.hash(b.branch, "withdrawals", b.number)
+	}
+
+	return map[string]any{
+		"number":           hexUint(b.number),
+		"hash":             b.hash,
+		"parentHash":       b.parentHash,
+		"timestamp":        hexUint(uint64(b.timestamp)),
+		"miner":            hexAddress(b.miner),
+		"gasUsed":          hexUint(b.gasUsed),
+		"gasLimit":         hexUint(gasLimit),
+		"baseFeePerGas":    "0x0",
+		"difficulty":       "0x0",
+		"totalDifficulty":  "0x0",
+		"nonce":            "0x0000000000000000",
+		"mixHash":          zeroHash,
+		"sha3Uncles":       emptyUnclesHash,
+		"stateRoot":        zeroHash,
+		"transactionsRoot": txsRoot,
+		"receiptsRoot":     receiptsRoot,
+		"withdrawalsRoot":  withdrawalsRoot,
+		"logsBloom":        zeroBloom,
+		"extraData":        "0x",
+		"size":             hexUint(uint64(540 + 120*len(b.transactions))),
+		"uncles":           []string{},
+		"transactions":     txs,
+		"withdrawals":      withdrawals,
+	}
+}
+
+func (c *chain) renderTransaction(tx *transaction) map[string]any {
+	ret := map[string]any{
+		"hash":             tx.hash,
+		"blockHash":        tx.block.hash,
+		"blockNumber":      hexUint(tx.block.number),
+		"transactionIndex": hexUint(tx.index),
+		"from":             hexAddress(tx.from),
+		"to":               nil,
+		"value":            hexBig(wei(tx.source.Value).BigInt()),
+		"gas":              hexUint(tx.gasUsed),
+		"gasPrice":         hexBig(wei(tx.source.GasPrice).BigInt()),
+		"input":            tx.source.Input,
+		"nonce":            hexUint(tx.nonce),
+		"type":             "0x0",
+		"chainId":          hexUint(c.scenario.ChainId),
+		"v":                "0x0",
+		"r":                "0x0",
+		"s":                "0x0",
+	}
+	if tx.to != nil {
+		ret["to"] = hexAddress(*tx.to)
+	}
+	return ret
+}
+
+func (c *chain) renderReceipt(tx *transaction) map[string]any {
+	status := "0x1"
+	if tx.source.IsError {
+		status = "0x0"
+	}
+	ret := map[string]any{
+		"transactionHash":   tx.hash,
+		"transactionIndex":  hexUint(tx.index),
+		"blockHash":         tx.block.hash,
+		"blockNumber":       hexUint(tx.block.number),
+		"from":              hexAddress(tx.from),
+		"to":                nil,
+		"contractAddress":   nil,
+		"status":            status,
+		"gasUsed":           hexUint(tx.gasUsed),
+		"cumulativeGasUsed": hexUint(tx.cumulativeGasUsed),
+		"effectiveGasPrice": hexBig(wei(tx.source.GasPrice).BigInt()),
+		"type":              "0x0",
+		"logsBloom":         zeroBloom,
+		"logs":              c.renderLogs(tx),
+	}
+	if tx.to != nil {
+		ret["to"] = hexAddress(*tx.to)
+	}
+	if tx.creates != nil {
+		ret["contractAddress"] = hexAddress(*tx.creates)
+	}
+	return ret
+}
+
+func (c *chain) renderLogs(tx *transaction) []map[string]any {
+	logs := make([]map[string]any, 0, len(tx.logs))
+	for _, l := range tx.logs {
+		logs = append(logs, c.renderLog(tx, l))
+	}
+	return logs
+}
+
+func (c *chain) renderLog(tx *transaction, l *log) map[string]any {
+	topics := l.topics
+	if topics == nil {
+		topics = []string{}
+	}
+	return map[string]any{
+		"address":          hexAddress(l.address),
+		"topics":           topics,
+		"data":             l.data,
+		"blockNumber":      hexUint(tx.block.number),
+		"blockHash":        tx.block.hash,
+		"transactionHash":  tx.hash,
+		"transactionIndex": hexUint(tx.index),
+		"logIndex":         hexUint(l.index),
+		"removed":          false,
+	}
+}
+
+// renderTraces returns the transaction's traces in the format of trace_transaction: the
+// transaction itself, then each of its internal calls. A failed transaction has no internal
+// calls.
+func (c *chain) renderTraces(tx *transaction) []map[string]any {
+	source := tx.source
+	common := func(action, result map[string]any, traceType string, address []int, subtraces int) map[string]any {
+		return map[string]any{
+			"action":              action,
+			"result":              result,
+			"type":                traceType,
+			"traceAddress":        address,
+			"subtraces":           subtraces,
+			"blockHash":           tx.block.hash,
+			"blockNumber":         tx.block.number,
+			"transactionHash":     tx.hash,
+			"transactionPosition": tx.index,
+		}
+	}
+
+	internal := source.Traces
+	if source.IsError {
+		internal = nil
+	}
+
+	var top map[string]any
+	if tx.creates != nil {
+		top = common(map[string]any{
+			"from":  hexAddress(tx.from),
+			"gas":   hexUint(tx.gasUsed),
+			"init":  source.Input,
+			"value": hexBig(wei(source.Value).BigInt()),
+		}, map[string]any{
+			"address": hexAddress(*tx.creates),
+			"code":    contractCode,
+			"gasUsed": hexUint(tx.gasUsed),
+		}, "create", []int{}, len(internal))
+	} else {
+		top = common(map[string]any{
+			"callType": "call",
+			"from":     hexAddress(tx.from),
+			"to":       hexAddress(*tx.to),
+			"gas":      hexUint(tx.gasUsed),
+			"input":    source.Input,
+			"value":    hexBig(wei(source.Value).BigInt()),
+		}, map[string]any{
+			"gasUsed": hexUint(tx.gasUsed),
+			"output":  "0x",
+		}, "call", []int{}, len(internal))
+	}
+	if source.IsError {
+		top["result"] = nil
+		top["error"] = "Reverted"
+	}
+
+	traces := []map[string]any{top}
+	for i, trace := range internal {
+		traces = append(traces, common(map[string]any{
+			"callType": trace.CallType,
+			"from":     hexAddress(base.HexToAddress(trace.From)),
+			"to":       hexAddress(base.HexToAddress(trace.To)),
+			"gas":      "0x0",
+			"input":    trace.Input,
+			"value":    hexBig(wei(trace.Value).BigInt()),
+		}, map[string]any{
+			"gasUsed": "0x0",
+			"output":  trace.Output,
+		}, "call", []int{i}, 0))
+	}
+	return traces
+}
+
+func (c *chain) parityTraces(method string, params []json.RawMessage) (any, error) {
+	if method == "trace_transaction" {
+		if tx, err := c.txParam(params, 0); err != nil || tx == nil {
+			return nil, err
+		} else {
+			return c.renderTraces(tx), nil
+		}
+	}
+
+	b, err := c.blockParam(params, 0)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	traces := []map[string]any{}
+	for _, tx := range b.transactions {
+		traces = append(traces, c.renderTraces(tx)...)
+	}
+	return traces, nil
+}
+
+// callTraces answers as geth does with the callTracer (the only tracer the chain has)
+func (c *chain) callTraces(method string, params []json.RawMessage) (any, error) {
+	var config struct {
+		Tracer string `json:"tracer"`
+	}
+	if len(params) > 1 {
+		_ = json.Unmarshal(params[1], &config)
+	}
+	if config.Tracer != "callTracer" {
+		return nil, invalidParams("only the callTracer is supported")
+	}
+
+	if method == "debug_traceTransaction" {
+		tx, err := c.txParam(params, 0)
+		if err != nil {
+			return nil, err
+		} else if tx == nil {
+			return nil, &rpcError{Code: -32000, Message: "transaction not found"}
+		}
+		return c.renderCallFrame(tx), nil
+	}
+
+	b, err := c.blockParam(params, 0)
+	if err != nil {
+		return nil, err
+	} else if b == nil {
+		return nil, &rpcError{Code: -32000, Message: "block not found"}
+	}
+	frames := make([]map[string]any, 0, len(b.transactions))
+	for _, tx := range b.transactions {
+		frames = append(frames, map[string]any{"txHash": tx.hash, "result": c.renderCallFrame(tx)})
+	}
+	return frames, nil
+}
+
+// renderCallFrame returns the transaction's top level call, and the calls it made, as geth's
+// callTracer does
+func (c *chain) renderCallFrame(tx *transaction) map[string]any {
+	source := tx.source
+	frame := map[string]any{
+		"type":    "CALL",
+		"from":    hexAddress(tx.from),
+		"gas":     hexUint(tx.gasUsed),
+		"gasUsed": hexUint(tx.gasUsed),
+		"input":   source.Input,
+		"output":  "0x",
+		"value":   hexBig(wei(source.Value).BigInt()),
+	}
+	if tx.creates != nil {
+		frame["type"] = "CREATE"
+		frame["to"] = hexAddress(*tx.creates)
+		frame["output"] = contractCode
+	} else {
+		frame["to"] = hexAddress(*tx.to)
+	}
+
+	if source.IsError {
+		frame["error"] = "execution reverted"
+		return frame
+	}
+
+	calls := make([]map[string]any, 0, len(source.Traces))
+	for _, trace := range source.Traces {
+		calls = append(calls, map[string]any{
+			"type":    strings.ToUpper(trace.CallType),
+			"from":    hexAddress(base.HexToAddress(trace.From)),
+			"to":      hexAddress(base.HexToAddress(trace.To)),
+			"gas":     "0x0",
+			"gasUsed": "0x0",
+			"input":   trace.Input,
+			"output":  trace.Output,
+			"value":   hexBig(wei(trace.Value).BigInt()),
+		})
+	}
+	if len(calls) > 0 {
+		frame["calls"] = calls
+	}
+	return frame
+}
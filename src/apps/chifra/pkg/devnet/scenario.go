@@ -0,0 +1,345 @@
+package devnet
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/pelletier/go-toml/v2"
+)
+
+const (
+	defaultChainId   = 1337
+	defaultGenesisTs = 1700000000
+	defaultBlockTime = 12
+	defaultGasUsed   = 21000
+	defaultGasPrice  = 1000000000
+)
+
+// The tracing APIs a scenario's chain may expose
+const (
+	// TracesParity exposes the trace_ namespace only, as erigon and nethermind do
+	TracesParity = "parity"
+	// TracesCallTracer exposes debug_traceBlockByNumber and debug_traceTransaction only, as geth does
+	TracesCallTracer = "callTracer"
+	// TracesBoth exposes both (the default), as reth does
+	TracesBoth = "both"
+)
+
+// Scenario is a synthetic chain: its genesis balances, its tokens, and its blocks. Blocks are
+// numbered from one (block zero is the genesis block) unless their numbers are given, and are
+// twelve seconds apart unless their timestamps are given. Values are strings (in wei) so that
+// they may be as large as they need to be. See testdata/simple.toml for an example.
+type Scenario struct {
+	ChainId   uint64            `toml:"chainId"`
+	Traces    string            `toml:"traces"`
+	Timestamp int64             `toml:"timestamp"`
+	Alloc     map[string]string `toml:"alloc"`
+	Tokens    []Token           `toml:"tokens"`
+	Calls     []Call            `toml:"calls"`
+	Blocks    []Block           `toml:"blocks"`
+}
+
+// Token is an ERC-20 token whose state (balances and total supply) follows from its Transfer
+// logs. The token answers name, symbol, decimals, totalSupply, and balanceOf calls.
+type Token struct {
+	Address  string            `toml:"address"`
+	Name     string            `toml:"name"`
+	Symbol   string            `toml:"symbol"`
+	Decimals uint64            `toml:"decimals"`
+	Alloc    map[string]string `toml:"alloc"`
+}
+
+// Call is the answer to an eth_call (other than a token's) from the given block onwards
+type Call struct {
+	To     string `toml:"to"`
+	Data   string `toml:"data"`
+	Block  uint64 `toml:"block"`
+	Result string `toml:"result"`
+	Revert bool   `toml:"revert"`
+}
+
+// Block is a block and its transactions and withdrawals. Fork, if given, names the branch of the
+// chain the block (and those after it) is on. Blocks on different branches have different hashes,
+// so two scenarios that name different branches from some block on describe a reorg at that block.
+type Block struct {
+	Number       uint64        `toml:"number"`
+	Fork         string        `toml:"fork"`
+	Timestamp    int64         `toml:"timestamp"`
+	Miner        string        `toml:"miner"`
+	Transactions []Transaction `toml:"transactions"`
+	Withdrawals  []Withdrawal  `toml:"withdrawals"`
+}
+
+// Transaction is a transaction and everything that happens in it. A transaction without a to
+// address creates the contract at creates. A failed transaction (isError) moves no value,
+// emits no logs, and makes no transfers, but its sender still pays for gas.
+type Transaction struct {
+	From      string     `toml:"from"`
+	To        string     `toml:"to"`
+	Creates   string     `toml:"creates"`
+	Value     string     `toml:"value"`
+	GasUsed   uint64     `toml:"gasUsed"`
+	GasPrice  string     `toml:"gasPrice"`
+	Input     string     `toml:"input"`
+	IsError   bool       `toml:"isError"`
+	Transfers []Transfer `toml:"transfers"`
+	Logs      []Log      `toml:"logs"`
+	Traces    []Trace    `toml:"traces"`
+}
+
+// Transfer is a token transfer, emitted as the token's Transfer log. Transfers from the zero
+// address mint tokens and transfers to it burn them.
+type Transfer struct {
+	Token string `toml:"token"`
+	From  string `toml:"from"`
+	To    string `toml:"to"`
+	Value string `toml:"value"`
+}
+
+// Log is any other log emitted by a transaction
+type Log struct {
+	Address string   `toml:"address"`
+	Topics  []string `toml:"topics"`
+	Data    string   `toml:"data"`
+}
+
+// Trace is an internal call made by a transaction. Its value moves from its from address to
+// its to address (unless the transaction failed).
+type Trace struct {
+	From     string `toml:"from"`
+	To       string `toml:"to"`
+	Value    string `toml:"value"`
+	Input    string `toml:"input"`
+	Output   string `toml:"output"`
+	CallType string `toml:"callType"`
+}
+
+// Withdrawal is a consensus layer withdrawal. Its amount is in gwei.
+type Withdrawal struct {
+	Index          uint64 `toml:"index"`
+	ValidatorIndex uint64 `toml:"validatorIndex"`
+	Address        string `toml:"address"`
+	Amount         uint64 `toml:"amount"`
+}
+
+// LoadScenario reads a scenario from a TOML file
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseScenario(data)
+}
+
+// ParseScenario reads a scenario from TOML, filling in its defaults and checking it
+func ParseScenario(data []byte) (*Scenario, error) {
+	var s Scenario
+	if err := toml.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	if err := s.prepare(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// prepare fills in the scenario's defaults and checks that it makes sense
+func (s *Scenario) prepare() error {
+	if s.ChainId == 0 {
+		s.ChainId = defaultChainId
+	}
+	if s.Timestamp == 0 {
+		s.Timestamp = defaultGenesisTs
+	}
+	switch s.Traces {
+	case "":
+		s.Traces = TracesBoth
+	case TracesParity, TracesCallTracer, TracesBoth:
+	default:
+		return fmt.Errorf("unknown traces %s (expected %s, %s, or %s)", s.Traces, TracesParity, TracesCallTracer, TracesBoth)
+	}
+
+	if err := checkAllocs("alloc", s.Alloc); err != nil {
+		return err
+	}
+	for _, token := range s.Tokens {
+		if !base.IsValidAddress(token.Address) {
+			return fmt.Errorf("token %s: invalid address", token.Address)
+		}
+		if err := checkAllocs("token "+token.Address+" alloc", token.Alloc); err != nil {
+			return err
+		}
+	}
+	for _, call := range s.Calls {
+		if !base.IsValidAddress(call.To) {
+			return fmt.Errorf("call to %s: invalid address", call.To)
+		}
+	}
+
+	var prev *Block
+	for i := range s.Blocks {
+		block := &s.Blocks[i]
+		switch {
+		case block.Number == 0 && prev == nil:
+			block.Number = 1
+		case block.Number == 0:
+			block.Number = prev.Number + 1
+		case prev != nil && block.Number <= prev.Number:
+			return fmt.Errorf("block %d: blocks must be in order", block.Number)
+		}
+
+		prevTs, prevBn := s.Timestamp, uint64(0)
+		if prev != nil {
+			prevTs, prevBn = prev.Timestamp, prev.Number
+		}
+		if block.Timestamp == 0 {
+			block.Timestamp = prevTs + defaultBlockTime*int64(block.Number-prevBn)
+		} else if block.Timestamp-prevTs < int64(block.Number-prevBn) {
+			return fmt.Errorf("block %d: timestamps must increase (by at least a second per block)", block.Number)
+		}
+
+		if len(block.Miner) == 0 {
+			block.Miner = "0x0000000000000000000000000000000000000000"
+		}
+		if err := checkAddresses(block.Number, block.Miner); err != nil {
+			return err
+		}
+		for _, w := range block.Withdrawals {
+			if err := checkAddresses(block.Number, w.Address); err != nil {
+				return err
+			}
+		}
+		for j := range block.Transactions {
+			if err := block.Transactions[j].prepare(block.Number); err != nil {
+				return err
+			}
+		}
+		prev = block
+	}
+	return nil
+}
+
+func (tx *Transaction) prepare(bn uint64) error {
+	if tx.GasUsed == 0 {
+		tx.GasUsed = defaultGasUsed
+	}
+	if len(tx.GasPrice) == 0 {
+		tx.GasPrice = fmt.Sprintf("%d", defaultGasPrice)
+	}
+	if len(tx.Value) == 0 {
+		tx.Value = "0"
+	}
+	if len(tx.Input) == 0 {
+		tx.Input = "0x"
+	}
+	if len(tx.From) == 0 {
+		return fmt.Errorf("block %d: a transaction requires a from address", bn)
+	}
+	if len(tx.To) == 0 && len(tx.Creates) == 0 {
+		return fmt.Errorf("block %d: a transaction requires either to or creates", bn)
+	}
+	if err := checkAddresses(bn, tx.From, tx.To, tx.Creates); err != nil {
+		return err
+	}
+	if err := checkValues(bn, tx.Value, tx.GasPrice); err != nil {
+		return err
+	}
+
+	for i := range tx.Transfers {
+		transfer := &tx.Transfers[i]
+		if len(transfer.From) == 0 {
+			transfer.From = tx.From
+		}
+		if len(transfer.Token) == 0 || len(transfer.To) == 0 {
+			return fmt.Errorf("block %d: a transfer requires a token and a to address", bn)
+		}
+		if err := checkAddresses(bn, transfer.Token, transfer.From, transfer.To); err != nil {
+			return err
+		}
+		if err := checkValues(bn, transfer.Value); err != nil {
+			return err
+		}
+	}
+	for i := range tx.Traces {
+		trace := &tx.Traces[i]
+		if len(trace.Value) == 0 {
+			trace.Value = "0"
+		}
+		if len(trace.Input) == 0 {
+			trace.Input = "0x"
+		}
+		if len(trace.Output) == 0 {
+			trace.Output = "0x"
+		}
+		if len(trace.CallType) == 0 {
+			trace.CallType = "call"
+		}
+		if err := checkAddresses(bn, trace.From, trace.To); err != nil {
+			return err
+		}
+		if err := checkValues(bn, trace.Value); err != nil {
+			return err
+		}
+	}
+	for i := range tx.Logs {
+		if len(tx.Logs[i].Data) == 0 {
+			tx.Logs[i].Data = "0x"
+		}
+		if err := checkAddresses(bn, tx.Logs[i].Address); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteAllocs writes the scenario's genesis allocation as a chain's allocs.csv, which chifra
+// reads to check that the node is an archive node and to reconcile genesis balances
+func (s *Scenario) WriteAllocs(path string) error {
+	lines := make([]string, 0, len(s.Alloc))
+	for addr, value := range s.Alloc {
+		lines = append(lines, fmt.Sprintf("%s,0x%x", strings.ToLower(addr), wei(value).BigInt()))
+	}
+	sort.Strings(lines)
+	lines = append([]string{"address,balance"}, lines...)
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+func checkAllocs(what string, allocs map[string]string) error {
+	for addr, value := range allocs {
+		if !base.IsValidAddress(addr) {
+			return fmt.Errorf("%s: invalid address %s", what, addr)
+		}
+		if _, ok := base.NewWei(0).SetString(value, 0); !ok {
+			return fmt.Errorf("%s: invalid value %s", what, value)
+		}
+	}
+	return nil
+}
+
+// checkAddresses checks that the addresses (if present) are valid
+func checkAddresses(bn uint64, addrs ...string) error {
+	for _, addr := range addrs {
+		if len(addr) > 0 && !base.IsValidAddress(addr) {
+			return fmt.Errorf("block %d: invalid address %s", bn, addr)
+		}
+	}
+	return nil
+}
+
+func checkValues(bn uint64, values ...string) error {
+	for _, value := range values {
+		if _, ok := base.NewWei(0).SetString(value, 0); !ok {
+			return fmt.Errorf("block %d: invalid value %s", bn, value)
+		}
+	}
+	return nil
+}
+
+// wei returns the (already checked) value
+func wei(value string) *base.Wei {
+	ret, _ := base.NewWei(0).SetString(value, 0)
+	return ret
+}
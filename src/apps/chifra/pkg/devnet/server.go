@@ -0,0 +1,230 @@
+package devnet
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Server answers JSON-RPC calls (single or batched) about a scenario's chain as an archive,
+// tracing node would. It serves the calls chifra makes while scraping, exporting (including
+// reconciling), and checking the index; other calls fail as a node would fail them.
+type Server struct {
+	chain *chain
+}
+
+// NewServer returns a server of the scenario's chain
+func NewServer(s *Scenario) (*Server, error) {
+	c, err := newChain(s)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{chain: c}, nil
+}
+
+// rpcError is an error reported to the caller as an EIP-1474 error
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("%d: %s", e.Code, e.Message)
+}
+
+var (
+	errParse    = &rpcError{Code: -32700, Message: "parse error"}
+	errNotFound = &rpcError{Code: -32000, Message: "header not found"}
+	errReverted = &rpcError{Code: 3, Message: "execution reverted"}
+)
+
+func invalidParams(format string, args ...any) *rpcError {
+	return &rpcError{Code: -32602, Message: fmt.Sprintf(format, args...)}
+}
+
+type request struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type response struct {
+	Jsonrpc string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// ServeHTTP answers a single call or a batch of calls
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var answer any
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var requests []request
+		if err := json.Unmarshal(trimmed, &requests); err != nil || len(requests) == 0 {
+			answer = response{Jsonrpc: "2.0", ID: json.RawMessage("null"), Error: errParse}
+		} else {
+			responses := make([]response, 0, len(requests))
+			for _, req := range requests {
+				responses = append(responses, s.answer(&req))
+			}
+			answer = responses
+		}
+	} else {
+		var req request
+		if err := json.Unmarshal(trimmed, &req); err != nil {
+			answer = response{Jsonrpc: "2.0", ID: json.RawMessage("null"), Error: errParse}
+		} else {
+			answer = s.answer(&req)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(answer)
+}
+
+func (s *Server) answer(req *request) response {
+	resp := response{Jsonrpc: "2.0", ID: req.ID}
+	if len(resp.ID) == 0 {
+		resp.ID = json.RawMessage("null")
+	}
+
+	result, err := s.call(req.Method, req.Params)
+	if err != nil {
+		if e, ok := err.(*rpcError); ok {
+			resp.Error = e
+		} else {
+			resp.Error = invalidParams("%s", err.Error())
+		}
+		return resp
+	}
+	if result == nil {
+		// A missing block or transaction is a null result, not an error
+		result = json.RawMessage("null")
+	}
+	resp.Result = result
+	return resp
+}
+
+// call returns the result of the call. A nil result (for example, a block beyond the chain's
+// head) is reported as null.
+func (s *Server) call(method string, params []json.RawMessage) (any, error) {
+	c := s.chain
+	switch method {
+	case "web3_clientVersion":
+		return "TrueBlocks/devnet", nil
+	case "eth_chainId":
+		return hexUint(c.scenario.ChainId), nil
+	case "net_version":
+		return fmt.Sprintf("%d", c.scenario.ChainId), nil
+	case "eth_blockNumber":
+		return hexUint(c.latest()), nil
+	case "eth_syncing":
+		return false, nil
+	case "eth_getBlockByNumber":
+		b, err := c.blockParam(params, 0)
+		if err != nil || b == nil {
+			return nil, err
+		}
+		return c.renderBlock(b, boolParam(params, 1)), nil
+	case "eth_getBlockByHash":
+		b, err := c.hashParam(params, 0)
+		if err != nil || b == nil {
+			return nil, err
+		}
+		return c.renderBlock(b, boolParam(params, 1)), nil
+	case "eth_getTransactionByHash":
+		if tx, err := c.txParam(params, 0); err != nil || tx == nil {
+			return nil, err
+		} else {
+			return c.renderTransaction(tx), nil
+		}
+	case "eth_getTransactionByBlockNumberAndIndex", "eth_getTransactionByBlockHashAndIndex":
+		var b *block
+		var err error
+		if method == "eth_getTransactionByBlockNumberAndIndex" {
+			b, err = c.blockParam(params, 0)
+		} else {
+			b, err = c.hashParam(params, 0)
+		}
+		if err != nil || b == nil {
+			return nil, err
+		}
+		if tx := c.indexParam(b, params, 1); tx != nil {
+			return c.renderTransaction(tx), nil
+		}
+		return nil, nil
+	case "eth_getTransactionReceipt":
+		if tx, err := c.txParam(params, 0); err != nil || tx == nil {
+			return nil, err
+		} else {
+			return c.renderReceipt(tx), nil
+		}
+	case "eth_getBlockReceipts":
+		b, err := c.blockParam(params, 0)
+		if err != nil || b == nil {
+			return nil, err
+		}
+		receipts := make([]map[string]any, 0, len(b.transactions))
+		for _, tx := range b.transactions {
+			receipts = append(receipts, c.renderReceipt(tx))
+		}
+		return receipts, nil
+	case "trace_transaction", "trace_block":
+		if c.scenario.Traces == TracesCallTracer {
+			return nil, notAvailable(method)
+		}
+		return c.parityTraces(method, params)
+	case "debug_traceTransaction", "debug_traceBlockByNumber":
+		if c.scenario.Traces == TracesParity {
+			return nil, notAvailable(method)
+		}
+		return c.callTraces(method, params)
+	case "eth_getUncleCountByBlockNumber":
+		return "0x0", nil
+	case "eth_getUncleByBlockNumberAndIndex":
+		return nil, nil
+	case "eth_getBalance", "eth_getTransactionCount", "eth_getCode", "eth_getStorageAt":
+		addr, err := addressParam(params, 0)
+		if err != nil {
+			return nil, err
+		}
+		pos := 1
+		if method == "eth_getStorageAt" {
+			pos = 2
+		}
+		b, err := c.blockParam(params, pos)
+		if err != nil {
+			return nil, err
+		} else if b == nil {
+			return nil, errNotFound
+		}
+		switch method {
+		case "eth_getBalance":
+			return hexBig(c.balances[addr].at(b.number)), nil
+		case "eth_getTransactionCount":
+			return hexBig(c.nonces[addr].at(b.number)), nil
+		case "eth_getCode":
+			return c.code(addr, b.number), nil
+		default:
+			return fmt.Sprintf("0x%064x", 0), nil
+		}
+	case "eth_call":
+		return c.ethCall(params)
+	case "eth_getLogs":
+		return c.getLogs(params)
+	}
+	return nil, notAvailable(method)
+}
+
+func notAvailable(method string) *rpcError {
+	return &rpcError{Code: -32601, Message: fmt.Sprintf("the method %s does not exist/is not available", method)}
+}
@@ -0,0 +1,22 @@
+//go:build darwin
+// +build darwin
+
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package file
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// AccessTime returns the last time the file was accessed (falling back to its modification time)
+func AccessTime(info os.FileInfo) time.Time {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return time.Unix(int64(stat.Atimespec.Sec), int64(stat.Atimespec.Nsec))
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,18 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package file
+
+import (
+	"os"
+	"time"
+)
+
+// AccessTime returns the file's modification time on platforms where we do not read the access time
+func AccessTime(info os.FileInfo) time.Time {
+	return info.ModTime()
+}
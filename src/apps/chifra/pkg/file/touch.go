@@ -29,3 +29,13 @@ func Touch(filename string) bool {
 	}
 	return true
 }
+
+// TouchAccess sets the file's access time to now leaving its modification time unchanged. We
+// do this explicitly because many file systems are mounted with noatime or relatime.
+func TouchAccess(filename string) error {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+	return os.Chtimes(filename, time.Now(), info.ModTime())
+}
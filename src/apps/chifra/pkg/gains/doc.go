@@ -0,0 +1,3 @@
+// Package gains builds tax lots from reconciled statements and computes realized gains,
+// unrealized positions, and yearly summaries using a chosen lot selection method
+package gains
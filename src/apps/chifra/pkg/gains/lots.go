@@ -0,0 +1,64 @@
+package gains
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+)
+
+// Lot is a quantity of an asset acquired in a single transaction at a single unit price
+type Lot struct {
+	BlockNumber base.Blknum
+	Timestamp   base.Timestamp
+	Hash        base.Hash
+	Quantity    base.Wei // the quantity remaining in the lot (in the asset's smallest unit)
+	UnitCost    float64  // the US dollar cost of one whole unit of the asset
+}
+
+// SpecificLots maps the hash of a disposal to the (ordered) hashes of the acquisitions it consumes
+type SpecificLots map[base.Hash][]base.Hash
+
+// ReadSpecificLots reads a csv file of disposalHash,acquisitionHash pairs. Blank lines, lines
+// starting with '#', and a header line (if present) are ignored. A disposal may appear more
+// than once in which case its acquisitions are consumed in the order they appear in the file.
+func ReadSpecificLots(path string) (SpecificLots, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ret := make(SpecificLots)
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.Split(line, ",")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected disposalHash,acquisitionHash", path, lineNo)
+		}
+		disposal, acquisition := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if !isHash(disposal) || !isHash(acquisition) {
+			if lineNo == 1 {
+				// header line
+				continue
+			}
+			return nil, fmt.Errorf("%s:%d: invalid hash", path, lineNo)
+		}
+		dHash := base.HexToHash(disposal)
+		ret[dHash] = append(ret[dHash], base.HexToHash(acquisition))
+	}
+	return ret, scanner.Err()
+}
+
+func isHash(str string) bool {
+	ok, _ := base.IsValidHex("hash", str, 32)
+	return ok
+}
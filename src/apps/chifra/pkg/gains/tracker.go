@@ -0,0 +1,290 @@
+package gains
+
+import (
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// longTermSeconds is the holding period after which a gain is considered long term (one year)
+const longTermSeconds = 365 * 24 * 60 * 60
+
+// position holds the open lots of a single asset
+type position struct {
+	assetAddr base.Address
+	symbol    string
+	decimals  base.Value
+	lots      []*Lot
+	lastPrice float64
+}
+
+// Tracker consumes reconciled statements (in chronological order) for a single address,
+// opening a lot for each net inflow and disposing of lots for each net outflow.
+type Tracker struct {
+	accountedFor base.Address
+	method       string
+	specific     SpecificLots
+	positions    map[base.Address]*position
+	assetOrder   []base.Address
+	summaries    map[base.Address]map[uint64]*types.Gain
+}
+
+// NewTracker returns a Tracker for the given address using `method` (one of fifo, lifo,
+// hifo, or specific) to choose which lots to dispose of. `specific` is only used by the
+// specific method.
+func NewTracker(accountedFor base.Address, method string, specific SpecificLots) *Tracker {
+	return &Tracker{
+		accountedFor: accountedFor,
+		method:       method,
+		specific:     specific,
+		positions:    make(map[base.Address]*position),
+		summaries:    make(map[base.Address]map[uint64]*types.Gain),
+	}
+}
+
+// Add processes a single statement and returns the realized gains (if any) it generates.
+// The statement's spot price is taken as the US dollar price of one whole unit of the asset.
+// A statement with no price opens lots with a zero cost basis and realizes zero proceeds.
+func (t *Tracker) Add(stmt *types.Statement) []types.Gain {
+	pos := t.positionOf(stmt)
+	price := float64(stmt.SpotPrice)
+	if price != 0 {
+		pos.lastPrice = price
+	}
+
+	net := new(base.Wei).Sub(stmt.TotalIn(), stmt.TotalOut())
+	switch net.Cmp(base.NewWei(0)) {
+	case 1:
+		pos.lots = append(pos.lots, &Lot{
+			BlockNumber: stmt.BlockNumber,
+			Timestamp:   stmt.Timestamp,
+			Hash:        stmt.TransactionHash,
+			Quantity:    *net,
+			UnitCost:    price,
+		})
+		return nil
+	case -1:
+		return t.dispose(pos, stmt, new(base.Wei).Sub(base.NewWei(0), net), price)
+	}
+	return nil
+}
+
+// Unrealized returns one record per open lot valued at the last known price of its asset
+func (t *Tracker) Unrealized() []types.Gain {
+	ret := make([]types.Gain, 0)
+	for _, addr := range t.assetOrder {
+		pos := t.positions[addr]
+		for _, lot := range pos.lots {
+			if lot.Quantity.IsZero() {
+				continue
+			}
+			units := toUnits(&lot.Quantity, pos.decimals)
+			costBasis := units * lot.UnitCost
+			proceeds := units * pos.lastPrice
+			ret = append(ret, types.Gain{
+				GainType:          "unrealized",
+				Method:            t.method,
+				AccountedFor:      t.accountedFor,
+				AssetAddr:         pos.assetAddr,
+				AssetSymbol:       pos.symbol,
+				Decimals:          pos.decimals,
+				AcquiredBlock:     lot.BlockNumber,
+				AcquiredTimestamp: lot.Timestamp,
+				AcquiredHash:      lot.Hash,
+				Quantity:          lot.Quantity,
+				CostBasis:         costBasis,
+				Proceeds:          proceeds,
+				Gain:              proceeds - costBasis,
+			})
+		}
+	}
+	return ret
+}
+
+// Summary returns one record per asset per (UTC) calendar year in which a disposal happened
+func (t *Tracker) Summary() []types.Gain {
+	ret := make([]types.Gain, 0)
+	for _, addr := range t.assetOrder {
+		years := make([]uint64, 0, len(t.summaries[addr]))
+		for year := range t.summaries[addr] {
+			years = append(years, year)
+		}
+		sort.Slice(years, func(i, j int) bool {
+			return years[i] < years[j]
+		})
+		for _, year := range years {
+			ret = append(ret, *t.summaries[addr][year])
+		}
+	}
+	return ret
+}
+
+func (t *Tracker) positionOf(stmt *types.Statement) *position {
+	pos, ok := t.positions[stmt.AssetAddr]
+	if !ok {
+		pos = &position{
+			assetAddr: stmt.AssetAddr,
+			symbol:    stmt.AssetSymbol,
+			decimals:  stmt.Decimals,
+		}
+		t.positions[stmt.AssetAddr] = pos
+		t.assetOrder = append(t.assetOrder, stmt.AssetAddr)
+	}
+	return pos
+}
+
+// dispose consumes `quantity` from the position's lots in the order dictated by the method.
+// If the lots do not cover the disposal (for example, because the history is incomplete),
+// the remainder is treated as a lot acquired at the time of disposal with a zero cost basis.
+func (t *Tracker) dispose(pos *position, stmt *types.Statement, quantity *base.Wei, price float64) []types.Gain {
+	ret := make([]types.Gain, 0)
+	remaining := copyWei(quantity)
+	for _, lot := range t.selectLots(pos, stmt.TransactionHash) {
+		if remaining.IsZero() {
+			break
+		}
+		if lot.Quantity.IsZero() {
+			continue
+		}
+		used := &lot.Quantity
+		if lot.Quantity.Cmp(remaining) > 0 {
+			used = remaining
+		}
+		used = copyWei(used)
+		lot.Quantity = *new(base.Wei).Sub(&lot.Quantity, used)
+		remaining = remaining.Sub(remaining, used)
+		ret = append(ret, t.realize(pos, stmt, lot, used, price))
+	}
+
+	if !remaining.IsZero() {
+		orphan := &Lot{
+			BlockNumber: stmt.BlockNumber,
+			Timestamp:   stmt.Timestamp,
+			Hash:        stmt.TransactionHash,
+		}
+		ret = append(ret, t.realize(pos, stmt, orphan, remaining, price))
+	}
+
+	// drop the exhausted lots
+	open := pos.lots[:0]
+	for _, lot := range pos.lots {
+		if !lot.Quantity.IsZero() {
+			open = append(open, lot)
+		}
+	}
+	pos.lots = open
+
+	return ret
+}
+
+func (t *Tracker) realize(pos *position, stmt *types.Statement, lot *Lot, quantity *base.Wei, price float64) types.Gain {
+	units := toUnits(quantity, pos.decimals)
+	costBasis := units * lot.UnitCost
+	proceeds := units * price
+	gain := types.Gain{
+		GainType:          "realized",
+		Method:            t.method,
+		AccountedFor:      t.accountedFor,
+		AssetAddr:         pos.assetAddr,
+		AssetSymbol:       pos.symbol,
+		Decimals:          pos.decimals,
+		AcquiredBlock:     lot.BlockNumber,
+		AcquiredTimestamp: lot.Timestamp,
+		AcquiredHash:      lot.Hash,
+		DisposedBlock:     stmt.BlockNumber,
+		DisposedTimestamp: stmt.Timestamp,
+		DisposedHash:      stmt.TransactionHash,
+		Quantity:          *quantity,
+		CostBasis:         costBasis,
+		Proceeds:          proceeds,
+		Gain:              proceeds - costBasis,
+		LongTerm:          stmt.Timestamp-lot.Timestamp > longTermSeconds,
+	}
+	t.accumulate(&gain)
+	return gain
+}
+
+func (t *Tracker) accumulate(gain *types.Gain) {
+	year := uint64(time.Unix(int64(gain.DisposedTimestamp), 0).UTC().Year())
+	if t.summaries[gain.AssetAddr] == nil {
+		t.summaries[gain.AssetAddr] = make(map[uint64]*types.Gain)
+	}
+	summary, ok := t.summaries[gain.AssetAddr][year]
+	if !ok {
+		summary = &types.Gain{
+			GainType:     "summary",
+			Method:       t.method,
+			AccountedFor: t.accountedFor,
+			AssetAddr:    gain.AssetAddr,
+			AssetSymbol:  gain.AssetSymbol,
+			Decimals:     gain.Decimals,
+			Year:         year,
+		}
+		t.summaries[gain.AssetAddr][year] = summary
+	}
+	summary.Quantity = *new(base.Wei).Add(&summary.Quantity, &gain.Quantity)
+	summary.CostBasis += gain.CostBasis
+	summary.Proceeds += gain.Proceeds
+	summary.Gain += gain.Gain
+	if gain.LongTerm {
+		summary.LongTermGain += gain.Gain
+	} else {
+		summary.ShortTermGain += gain.Gain
+	}
+}
+
+// selectLots returns the position's lots in the order they should be disposed of
+func (t *Tracker) selectLots(pos *position, disposal base.Hash) []*Lot {
+	fifo := make([]*Lot, len(pos.lots))
+	copy(fifo, pos.lots)
+
+	switch t.method {
+	case "lifo":
+		for i, j := 0, len(fifo)-1; i < j; i, j = i+1, j-1 {
+			fifo[i], fifo[j] = fifo[j], fifo[i]
+		}
+		return fifo
+
+	case "hifo":
+		sort.SliceStable(fifo, func(i, j int) bool {
+			return fifo[i].UnitCost > fifo[j].UnitCost
+		})
+		return fifo
+
+	case "specific":
+		// the identified lots first (in the order given), then fifo for anything left over
+		ret := make([]*Lot, 0, len(fifo))
+		used := make(map[*Lot]bool)
+		for _, hash := range t.specific[disposal] {
+			for _, lot := range fifo {
+				if lot.Hash == hash && !used[lot] {
+					ret = append(ret, lot)
+					used[lot] = true
+				}
+			}
+		}
+		for _, lot := range fifo {
+			if !used[lot] {
+				ret = append(ret, lot)
+			}
+		}
+		return ret
+	}
+
+	return fifo
+}
+
+// toUnits converts a quantity in the asset's smallest unit to whole units
+func toUnits(quantity *base.Wei, decimals base.Value) float64 {
+	f := new(big.Float).SetInt(quantity.BigInt())
+	divisor := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	ret, _ := f.Quo(f, divisor).Float64()
+	return ret
+}
+
+func copyWei(w *base.Wei) *base.Wei {
+	return new(base.Wei).Add(w, base.NewWei(0))
+}
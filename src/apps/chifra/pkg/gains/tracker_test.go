@@ -0,0 +1,151 @@
+package gains
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+const day = 24 * 60 * 60
+
+var (
+	t0     = base.Timestamp(1577836800) // 2020-01-01
+	holder = base.HexToAddress("0xf503017d7baf7fbc0fff7492b751025c6a78179b")
+	eth    = base.HexToAddress("0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee")
+)
+
+func ether(n int64) *base.Wei {
+	return new(base.Wei).Mul(base.NewWei(n), base.NewWei(1_000_000_000_000_000_000))
+}
+
+func inflow(hash string, days int64, amount int64, price float64) *types.Statement {
+	return &types.Statement{
+		AssetAddr:       eth,
+		AssetSymbol:     "WEI",
+		Decimals:        18,
+		BlockNumber:     base.Blknum(days),
+		Timestamp:       t0 + base.Timestamp(days*day),
+		TransactionHash: base.HexToHash(hash),
+		AmountIn:        *ether(amount),
+		SpotPrice:       base.Float(price),
+	}
+}
+
+func outflow(hash string, days int64, amount int64, price float64) *types.Statement {
+	stmt := inflow(hash, days, 0, price)
+	stmt.AmountIn = *ether(0)
+	stmt.AmountOut = *ether(amount)
+	return stmt
+}
+
+func closeTo(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestTrackerMethods(t *testing.T) {
+	tests := []struct {
+		method   string
+		specific SpecificLots
+		expected float64 // the gain on disposing of 1 ether at $300
+	}{
+		{"fifo", nil, 200},
+		{"lifo", nil, 0},
+		{"hifo", nil, -100},
+		{"specific", SpecificLots{base.HexToHash("0x4"): {base.HexToHash("0x1")}}, 200},
+	}
+
+	for _, test := range tests {
+		tracker := NewTracker(holder, test.method, test.specific)
+		tracker.Add(inflow("0x1", 0, 1, 100))
+		tracker.Add(inflow("0x2", 10, 1, 400))
+		tracker.Add(inflow("0x3", 20, 1, 300))
+		realized := tracker.Add(outflow("0x4", 30, 1, 300))
+		if len(realized) != 1 {
+			t.Fatal(test.method, "expected one realized record, got", len(realized))
+		}
+		if !closeTo(realized[0].Gain, test.expected) {
+			t.Error(test.method, "wrong gain", realized[0].Gain, "expected", test.expected)
+		}
+		if len(tracker.Unrealized()) != 2 {
+			t.Error(test.method, "expected two open lots")
+		}
+	}
+}
+
+func TestTrackerSplitsLots(t *testing.T) {
+	tracker := NewTracker(holder, "fifo", nil)
+	tracker.Add(inflow("0x1", 0, 2, 100))
+	tracker.Add(inflow("0x2", 400, 2, 200))
+	realized := tracker.Add(outflow("0x3", 500, 3, 500))
+	if len(realized) != 2 {
+		t.Fatal("expected the disposal to span two lots, got", len(realized))
+	}
+	if !realized[0].LongTerm || realized[1].LongTerm {
+		t.Error("wrong holding periods", realized[0].LongTerm, realized[1].LongTerm)
+	}
+	if realized[1].Quantity.String() != ether(1).String() {
+		t.Error("wrong quantity from second lot", realized[1].Quantity.String())
+	}
+
+	unrealized := tracker.Unrealized()
+	if len(unrealized) != 1 || unrealized[0].Quantity.String() != ether(1).String() {
+		t.Fatal("expected one ether remaining in the second lot", unrealized)
+	}
+	if !closeTo(unrealized[0].Gain, 300) {
+		t.Error("wrong unrealized gain", unrealized[0].Gain)
+	}
+
+	summary := tracker.Summary()
+	if len(summary) != 1 || summary[0].Year != 2021 {
+		t.Fatal("expected a single summary for 2021", summary)
+	}
+	if !closeTo(summary[0].LongTermGain, 800) || !closeTo(summary[0].ShortTermGain, 300) || !closeTo(summary[0].Gain, 1100) {
+		t.Error("wrong summary", summary[0])
+	}
+}
+
+func TestTrackerUncoveredDisposal(t *testing.T) {
+	tracker := NewTracker(holder, "fifo", nil)
+	tracker.Add(inflow("0x1", 0, 1, 100))
+	realized := tracker.Add(outflow("0x2", 1, 2, 100))
+	if len(realized) != 2 {
+		t.Fatal("expected two realized records, got", len(realized))
+	}
+	if realized[1].CostBasis != 0 || !closeTo(realized[1].Proceeds, 100) {
+		t.Error("uncovered quantity should have a zero cost basis", realized[1])
+	}
+	if len(tracker.Unrealized()) != 0 {
+		t.Error("expected no open lots")
+	}
+}
+
+func TestReadSpecificLots(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lots.csv")
+	contents := `disposalHash,acquisitionHash
+# a comment
+0x0000000000000000000000000000000000000000000000000000000000000004,0x0000000000000000000000000000000000000000000000000000000000000001
+0x0000000000000000000000000000000000000000000000000000000000000004,0x0000000000000000000000000000000000000000000000000000000000000002
+`
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	lots, err := ReadSpecificLots(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acquisitions := lots[base.HexToHash("0x4")]
+	if len(acquisitions) != 2 || acquisitions[0] != base.HexToHash("0x1") || acquisitions[1] != base.HexToHash("0x2") {
+		t.Error("wrong lots", lots)
+	}
+
+	if err := os.WriteFile(path, []byte("0x4,0x1,0x2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadSpecificLots(path); err == nil {
+		t.Error("expected an error for a malformed line")
+	}
+}
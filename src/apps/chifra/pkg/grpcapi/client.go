@@ -0,0 +1,111 @@
+// Copyright 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Client calls the Chifra service served by chifra daemon --grpc. Its typed methods (one per
+// command) are generated.
+type Client struct {
+	conn grpc.ClientConnInterface
+}
+
+// NewClient returns a Client that calls the service over conn
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{conn: conn}
+}
+
+// Record is a single record streamed by the service. It carries either the record's data (keyed
+// as chifra renders it in json) or a non-fatal error. Type is the name of the record's message
+// (for example Log) and is empty for errors and for records of a type without a message.
+type Record struct {
+	Type  string
+	Data  map[string]any
+	Error string
+}
+
+// Decode decodes the record's data into v (for example a *types.Log)
+func (r *Record) Decode(v any) error {
+	bytes, err := json.Marshal(r.Data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bytes, v)
+}
+
+// RecordStream receives the records of a single call as they are produced
+type RecordStream struct {
+	stream grpc.ClientStream
+}
+
+// Recv returns the next record. It returns io.EOF once the command has finished.
+func (s *RecordStream) Recv() (*Record, error) {
+	msg, err := newMessage("Record")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.stream.RecvMsg(msg); err != nil {
+		return nil, err
+	}
+
+	fields := msg.Descriptor().Fields()
+	rec := &Record{
+		Error: msg.Get(fields.ByName("error")).String(),
+	}
+	if fd := msg.WhichOneof(msg.Descriptor().Oneofs().ByName("record")); fd != nil {
+		m := messageFor(string(fd.Message().Name()))
+		if m == nil {
+			return nil, fmt.Errorf("unknown message %s", fd.Message().Name())
+		}
+		rec.Type = m.name
+		if rec.Data, err = toMap(msg.Get(fd).Message(), m.fields); err != nil {
+			return nil, err
+		}
+	} else if data := fields.ByName("data"); msg.Has(data) {
+		bytes, err := protojson.Marshal(msg.Get(data).Message().Interface())
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(bytes, &rec.Data); err != nil {
+			return nil, err
+		}
+	}
+	return rec, nil
+}
+
+// stream calls the method with the given (query) options
+func (c *Client) stream(ctx context.Context, method string, values url.Values) (*RecordStream, error) {
+	svc, err := serviceFor(method)
+	if err != nil {
+		return nil, err
+	}
+
+	var req *dynamicpb.Message
+	if req, err = svc.fromValues(values); err != nil {
+		return nil, err
+	}
+
+	desc := &grpc.StreamDesc{StreamName: method, ServerStreams: true}
+	stream, err := c.conn.NewStream(ctx, desc, "/"+ServiceName+"/"+method)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(req); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return &RecordStream{stream: stream}, nil
+}
@@ -0,0 +1,348 @@
+// Copyright 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+// Package grpcapi implements chifra's typed, streaming API. Each command served by the API is a
+// server-streaming method of the trueblocks.chifra.v1.Chifra service whose request message
+// carries the command's options. Records are sent to the client as they are produced, each in
+// the message of its type (Transaction, Log, Trace, Block, Appearance, etc.).
+//
+// The service, its request and record messages, and the typed Go client are generated by
+// goMaker from the same command and data definitions as the rest of chifra. The generated chifra.proto may be used to
+// build clients in other languages.
+package grpcapi
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// ServiceName is the fully qualified name of the gRPC service
+	ServiceName  = "trueblocks.chifra.v1.Chifra"
+	protoPackage = "trueblocks.chifra.v1"
+	protoFile    = "chifra.proto"
+)
+
+type fieldKind int
+
+const (
+	kindString fieldKind = iota
+	kindBool
+	kindUint64
+	kindDouble
+	kindInt64
+	kindMessage
+	kindValue
+)
+
+// field describes one field of a request message and the query key of the option it carries
+// or one field of a record's message and the json key of the value it carries
+type field struct {
+	name     string
+	key      string
+	number   int32
+	kind     fieldKind
+	message  string
+	repeated bool
+}
+
+// message describes the message of one type of record and its field in the Record message
+type message struct {
+	name   string
+	field  string
+	key    string
+	number int32
+	fields []field
+}
+
+func messageFor(name string) *message {
+	for _, msg := range messages {
+		if msg.name == name {
+			return msg
+		}
+	}
+	return nil
+}
+
+// service describes one server-streaming method and the chifra command (route) behind it
+type service struct {
+	route  string
+	method string
+	fields []field
+}
+
+func serviceFor(method string) (*service, error) {
+	for _, svc := range services {
+		if svc.method == method {
+			return svc, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown method %s", method)
+}
+
+var (
+	fileDesc     protoreflect.FileDescriptor
+	fileDescErr  error
+	fileDescOnce sync.Once
+)
+
+// descriptor returns the descriptor of chifra.proto, built from the same tables as the file
+func descriptor() (protoreflect.FileDescriptor, error) {
+	fileDescOnce.Do(func() {
+		fileDesc, fileDescErr = protodesc.NewFile(fileDescriptorProto(), protoregistry.GlobalFiles)
+	})
+	return fileDesc, fileDescErr
+}
+
+func fileDescriptorProto() *descriptorpb.FileDescriptorProto {
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	repeated := descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	typeOf := map[fieldKind]*descriptorpb.FieldDescriptorProto_Type{
+		kindString: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+		kindBool:   descriptorpb.FieldDescriptorProto_TYPE_BOOL.Enum(),
+		kindUint64: descriptorpb.FieldDescriptorProto_TYPE_UINT64.Enum(),
+		kindDouble: descriptorpb.FieldDescriptorProto_TYPE_DOUBLE.Enum(),
+		kindInt64:  descriptorpb.FieldDescriptorProto_TYPE_INT64.Enum(),
+	}
+	typeName := func(f field) *string {
+		switch f.kind {
+		case kindMessage:
+			return proto.String("." + protoPackage + "." + f.message)
+		case kindValue:
+			return proto.String("." + string((&structpb.Value{}).ProtoReflect().Descriptor().FullName()))
+		}
+		return nil
+	}
+	messageType := descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+
+	record := &descriptorpb.DescriptorProto{
+		Name: proto.String("Record"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     proto.String("data"),
+				JsonName: proto.String("data"),
+				Number:   proto.Int32(1),
+				Label:    optional,
+				Type:     messageType,
+				TypeName: proto.String("." + string((&structpb.Struct{}).ProtoReflect().Descriptor().FullName())),
+			},
+			{
+				Name:     proto.String("error"),
+				JsonName: proto.String("error"),
+				Number:   proto.Int32(2),
+				Label:    optional,
+				Type:     typeOf[kindString],
+			},
+		},
+		OneofDecl: []*descriptorpb.OneofDescriptorProto{
+			{Name: proto.String("record")},
+		},
+	}
+
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:        proto.String(protoFile),
+		Package:     proto.String(protoPackage),
+		Syntax:      proto.String("proto3"),
+		Dependency:  []string{"google/protobuf/struct.proto"},
+		MessageType: []*descriptorpb.DescriptorProto{record},
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{Name: proto.String("Chifra")},
+		},
+	}
+
+	for _, m := range messages {
+		record.Field = append(record.Field, &descriptorpb.FieldDescriptorProto{
+			Name:       proto.String(m.field),
+			JsonName:   proto.String(m.key),
+			Number:     proto.Int32(m.number),
+			Label:      optional,
+			Type:       messageType,
+			TypeName:   proto.String("." + protoPackage + "." + m.name),
+			OneofIndex: proto.Int32(0),
+		})
+
+		msg := &descriptorpb.DescriptorProto{Name: proto.String(m.name)}
+		for _, f := range m.fields {
+			label, typ := optional, typeOf[f.kind]
+			if f.repeated {
+				label = repeated
+			}
+			if f.kind == kindMessage || f.kind == kindValue {
+				typ = messageType
+			}
+			msg.Field = append(msg.Field, &descriptorpb.FieldDescriptorProto{
+				Name:     proto.String(f.name),
+				JsonName: proto.String(f.key),
+				Number:   proto.Int32(f.number),
+				Label:    label,
+				Type:     typ,
+				TypeName: typeName(f),
+			})
+		}
+		fdp.MessageType = append(fdp.MessageType, msg)
+	}
+
+	for _, svc := range services {
+		msg := &descriptorpb.DescriptorProto{Name: proto.String(svc.method + "Request")}
+		for _, f := range svc.fields {
+			label := optional
+			if f.repeated {
+				label = repeated
+			}
+			msg.Field = append(msg.Field, &descriptorpb.FieldDescriptorProto{
+				Name:     proto.String(f.name),
+				JsonName: proto.String(f.key),
+				Number:   proto.Int32(f.number),
+				Label:    label,
+				Type:     typeOf[f.kind],
+			})
+		}
+		fdp.MessageType = append(fdp.MessageType, msg)
+		fdp.Service[0].Method = append(fdp.Service[0].Method, &descriptorpb.MethodDescriptorProto{
+			Name:            proto.String(svc.method),
+			InputType:       proto.String("." + protoPackage + "." + svc.method + "Request"),
+			OutputType:      proto.String("." + protoPackage + ".Record"),
+			ServerStreaming: proto.Bool(true),
+		})
+	}
+
+	return fdp
+}
+
+// newMessage returns an empty (dynamic) message of the given name from chifra.proto
+func newMessage(name string) (*dynamicpb.Message, error) {
+	fd, err := descriptor()
+	if err != nil {
+		return nil, err
+	}
+	md := fd.Messages().ByName(protoreflect.Name(name))
+	if md == nil {
+		return nil, fmt.Errorf("unknown message %s", name)
+	}
+	return dynamicpb.NewMessage(md), nil
+}
+
+// toValues converts a request message into the query values chifra's commands parse
+func (svc *service) toValues(msg protoreflect.Message) url.Values {
+	values := url.Values{}
+	fields := msg.Descriptor().Fields()
+	for _, f := range svc.fields {
+		fd := fields.ByNumber(protoreflect.FieldNumber(f.number))
+		if fd == nil || !msg.Has(fd) {
+			continue
+		}
+		value := msg.Get(fd)
+		if f.repeated {
+			list := value.List()
+			for i := 0; i < list.Len(); i++ {
+				values.Add(f.key, list.Get(i).String())
+			}
+			continue
+		}
+		switch f.kind {
+		case kindBool:
+			values.Set(f.key, strconv.FormatBool(value.Bool()))
+		case kindUint64:
+			values.Set(f.key, strconv.FormatUint(value.Uint(), 10))
+		case kindDouble:
+			values.Set(f.key, strconv.FormatFloat(value.Float(), 'f', -1, 64))
+		default:
+			values.Set(f.key, value.String())
+		}
+	}
+	return values
+}
+
+// fromValues converts query values into a request message
+func (svc *service) fromValues(values url.Values) (*dynamicpb.Message, error) {
+	msg, err := newMessage(svc.method + "Request")
+	if err != nil {
+		return nil, err
+	}
+
+	fields := msg.Descriptor().Fields()
+	for _, f := range svc.fields {
+		fd := fields.ByNumber(protoreflect.FieldNumber(f.number))
+		if fd == nil || len(values[f.key]) == 0 {
+			continue
+		}
+		if f.repeated {
+			list := msg.Mutable(fd).List()
+			for _, v := range values[f.key] {
+				list.Append(protoreflect.ValueOfString(v))
+			}
+			continue
+		}
+		str := values.Get(f.key)
+		switch f.kind {
+		case kindBool:
+			msg.Set(fd, protoreflect.ValueOfBool(str == "true"))
+		case kindUint64:
+			v, err := strconv.ParseUint(str, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for %s: %s", f.name, str)
+			}
+			msg.Set(fd, protoreflect.ValueOfUint64(v))
+		case kindDouble:
+			v, err := strconv.ParseFloat(str, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for %s: %s", f.name, str)
+			}
+			msg.Set(fd, protoreflect.ValueOfFloat64(v))
+		default:
+			msg.Set(fd, protoreflect.ValueOfString(str))
+		}
+	}
+	return msg, nil
+}
+
+func addString(values url.Values, key, value string) {
+	if len(value) > 0 {
+		values.Set(key, value)
+	}
+}
+
+func addStrings(values url.Values, key string, value []string) {
+	for _, v := range value {
+		values.Add(key, v)
+	}
+}
+
+func addBool(values url.Values, key string, value bool) {
+	if value {
+		values.Set(key, "true")
+	}
+}
+
+func addUint64(values url.Values, key string, value uint64) {
+	if value != 0 {
+		values.Set(key, strconv.FormatUint(value, 10))
+	}
+}
+
+func addDouble(values url.Values, key string, value float64) {
+	if value != 0 {
+		values.Set(key, strconv.FormatFloat(value, 'f', -1, 64))
+	}
+}
+
+// methodFromPath returns the method named in a path such as /trueblocks.chifra.v1.Chifra/Export
+func methodFromPath(path string) (string, bool) {
+	prefix := "/" + ServiceName + "/"
+	if !strings.HasPrefix(path, prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(path, prefix), true
+}
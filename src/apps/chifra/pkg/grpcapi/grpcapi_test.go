@@ -0,0 +1,233 @@
+// Copyright 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package grpcapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// fakeRun stands in for chifra's commands. It streams one appearance per address and
+// reports a non-fatal error for addresses that are not valid.
+func fakeRun(got *url.Values) RunFunc {
+	return func(rCtx *output.RenderCtx, route string, values url.Values) error {
+		*got = values
+		if route != "export" {
+			return errors.New("unexpected route " + route)
+		}
+		for i, addr := range values["addrs"] {
+			if !base.IsValidAddress(addr) {
+				rCtx.ErrorChan <- errors.New("invalid address " + addr)
+				continue
+			}
+			rCtx.ModelChan <- &types.Appearance{
+				Address:          base.HexToAddress(addr),
+				BlockNumber:      uint32(1000 + i),
+				TransactionIndex: uint32(i),
+			}
+		}
+		return nil
+	}
+}
+
+func TestGrpcExport(t *testing.T) {
+	var got url.Values
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	if err := NewServer(fakeRun(&got)).Register(server); err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	req := &ExportRequest{
+		Addrs:       []string{"0xf503017d7baf7fbc0fff7492b751025c6a78179b", "0xbad"},
+		Appearances: true,
+		FirstBlock:  100,
+		Chain:       "mainnet",
+	}
+	stream, err := NewClient(conn).Export(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := []*Record{}
+	for {
+		rec, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, rec)
+	}
+
+	expected := url.Values{
+		"addrs":       {"0xf503017d7baf7fbc0fff7492b751025c6a78179b", "0xbad"},
+		"appearances": {"true"},
+		"firstBlock":  {"100"},
+		"chain":       {"mainnet"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Error("unexpected options", got)
+	}
+
+	if len(records) != 2 {
+		t.Fatal("expected two records, got", len(records))
+	}
+	if records[0].Type != "Appearance" {
+		t.Error("expected an Appearance record, got", records[0].Type)
+	}
+	var app types.Appearance
+	if err := records[0].Decode(&app); err != nil {
+		t.Fatal(err)
+	}
+	if app.Address.Hex() != "0xf503017d7baf7fbc0fff7492b751025c6a78179b" || app.BlockNumber != 1000 {
+		t.Error("unexpected appearance", records[0].Data)
+	}
+	if records[1].Error != "invalid address 0xbad" {
+		t.Error("expected an error record, got", records[1])
+	}
+}
+
+func TestConnectExport(t *testing.T) {
+	var got url.Values
+	server := httptest.NewServer(http.HandlerFunc(NewServer(fakeRun(&got)).ServeConnect))
+	defer server.Close()
+
+	body := []byte(`{"addrs": ["0xf503017d7baf7fbc0fff7492b751025c6a78179b"], "maxRecords": "10"}`)
+	envelope := make([]byte, 5)
+	binary.BigEndian.PutUint32(envelope[1:], uint32(len(body)))
+	resp, err := http.Post(server.URL+"/"+ServiceName+"/Export", "application/connect+json", bytes.NewReader(append(envelope, body...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	frames := []string{}
+	flags := []byte{}
+	for {
+		header := make([]byte, 5)
+		if _, err := io.ReadFull(resp.Body, header); err != nil {
+			break
+		}
+		data := make([]byte, binary.BigEndian.Uint32(header[1:]))
+		_, _ = io.ReadFull(resp.Body, data)
+		flags = append(flags, header[0])
+		frames = append(frames, string(data))
+	}
+
+	if got.Get("maxRecords") != "10" {
+		t.Error("unexpected options", got)
+	}
+
+	if len(frames) != 2 || flags[0] != 0 || flags[1] != 2 {
+		t.Fatal("expected one record and the end of the stream, got", frames)
+	}
+	var rec struct {
+		Appearance map[string]any `json:"appearance"`
+	}
+	if err := json.Unmarshal([]byte(frames[0]), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Appearance["address"] != "0xf503017d7baf7fbc0fff7492b751025c6a78179b" {
+		t.Error("unexpected record", frames[0])
+	}
+	if strings.TrimSpace(frames[1]) != "{}" {
+		t.Error("expected a successful end of stream, got", frames[1])
+	}
+}
+
+func TestProtoDescriptor(t *testing.T) {
+	fd, err := descriptor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := fd.Services().ByName("Chifra")
+	for _, method := range []string{"Export", "Logs", "Traces", "Blocks"} {
+		m := service.Methods().ByName(protoreflect.Name(method))
+		if m == nil || !m.IsStreamingServer() {
+			t.Error("expected a server-streaming method", method)
+		}
+	}
+
+	record := fd.Messages().ByName("Record")
+	for _, name := range []string{"Transaction", "Log", "Trace", "Block", "Appearance"} {
+		m := messageFor(name)
+		if m == nil {
+			t.Error("expected a message for", name)
+			continue
+		}
+		if f := record.Fields().ByName(protoreflect.Name(m.field)); f == nil || f.Message().Name() != protoreflect.Name(name) {
+			t.Error("expected the Record to carry a typed message", name)
+		}
+	}
+}
+
+func TestTypedRecord(t *testing.T) {
+	tx := &types.Transaction{
+		BlockNumber: 1001,
+		Hash:        base.HexToHash("0x12"),
+		Value:       *base.NewWei(1234),
+		Receipt: &types.Receipt{
+			GasUsed: 21000,
+			Logs: []types.Log{
+				{Address: base.HexToAddress("0x1234"), LogIndex: 3, Topics: []base.Hash{base.HexToHash("0x34")}},
+			},
+		},
+	}
+	rec, err := newRecord(typeName(tx), tx.Model("mainnet", "json", false, nil).Data, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := messageFor("Transaction")
+	fd := rec.Descriptor().Fields().ByNumber(protoreflect.FieldNumber(m.number))
+	if !rec.Has(fd) {
+		t.Fatal("expected the record to carry a Transaction")
+	}
+	data, err := toMap(rec.Get(fd).Message(), m.fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got types.Transaction
+	if err := (&Record{Data: data}).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.BlockNumber != tx.BlockNumber || got.Hash != tx.Hash || got.Value.String() != "1234" {
+		t.Error("unexpected transaction", data)
+	}
+	if got.Receipt == nil || got.Receipt.GasUsed != 21000 || len(got.Receipt.Logs) != 1 ||
+		got.Receipt.Logs[0].LogIndex != 3 || got.Receipt.Logs[0].Topics[0] != tx.Receipt.Logs[0].Topics[0] {
+		t.Error("unexpected receipt", data["receipt"])
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package grpcapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// fill sets the fields of msg from data (a record as chifra renders it in json). Keys without
+// a field in the message and values that do not fit their field are left out.
+func fill(msg protoreflect.Message, fields []field, data map[string]any) {
+	fds := msg.Descriptor().Fields()
+	for _, f := range fields {
+		fd := fds.ByNumber(protoreflect.FieldNumber(f.number))
+		v := data[f.key]
+		if fd == nil || v == nil {
+			continue
+		}
+
+		if f.repeated {
+			items, ok := v.([]any)
+			if !ok {
+				continue
+			}
+			list := msg.Mutable(fd).List()
+			for _, item := range items {
+				if value, ok := toValue(list.NewElement, f, item); ok {
+					list.Append(value)
+				}
+			}
+			continue
+		}
+
+		newValue := func() protoreflect.Value { return msg.NewField(fd) }
+		if value, ok := toValue(newValue, f, v); ok {
+			msg.Set(fd, value)
+		}
+	}
+}
+
+// toValue converts a json value into the value of the field. newValue returns an empty value
+// of the field's type (used for nested messages).
+func toValue(newValue func() protoreflect.Value, f field, v any) (protoreflect.Value, bool) {
+	str := fmt.Sprint(v)
+	switch f.kind {
+	case kindBool:
+		b, err := strconv.ParseBool(str)
+		return protoreflect.ValueOfBool(b), err == nil
+	case kindUint64:
+		if strings.HasPrefix(str, "0x") {
+			n, err := strconv.ParseUint(str[2:], 16, 64)
+			return protoreflect.ValueOfUint64(n), err == nil
+		}
+		n, err := strconv.ParseUint(str, 10, 64)
+		return protoreflect.ValueOfUint64(n), err == nil
+	case kindInt64:
+		n, err := strconv.ParseInt(str, 10, 64)
+		return protoreflect.ValueOfInt64(n), err == nil
+	case kindDouble:
+		n, err := strconv.ParseFloat(str, 64)
+		return protoreflect.ValueOfFloat64(n), err == nil
+	case kindMessage:
+		data, ok := v.(map[string]any)
+		nested := messageFor(f.message)
+		if !ok || nested == nil {
+			return protoreflect.Value{}, false
+		}
+		value := newValue()
+		fill(value.Message(), nested.fields, data)
+		return value, true
+	case kindValue:
+		value, err := structpb.NewValue(plain(v))
+		if err != nil {
+			return protoreflect.Value{}, false
+		}
+		return protoreflect.ValueOfMessage(value.ProtoReflect()), true
+	default:
+		switch v.(type) {
+		case map[string]any, []any:
+			bytes, err := json.Marshal(v)
+			return protoreflect.ValueOfString(string(bytes)), err == nil
+		}
+		return protoreflect.ValueOfString(str), true
+	}
+}
+
+// plain replaces the json.Numbers in v (which structpb does not accept) with float64s
+func plain(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	case map[string]any:
+		ret := make(map[string]any, len(v))
+		for key, item := range v {
+			ret[key] = plain(item)
+		}
+		return ret
+	case []any:
+		ret := make([]any, len(v))
+		for i, item := range v {
+			ret[i] = plain(item)
+		}
+		return ret
+	}
+	return v
+}
+
+// toMap returns the fields of msg keyed as chifra renders them in json. Fields holding their
+// zero value are left out.
+func toMap(msg protoreflect.Message, fields []field) (map[string]any, error) {
+	ret := map[string]any{}
+	fds := msg.Descriptor().Fields()
+	for _, f := range fields {
+		fd := fds.ByNumber(protoreflect.FieldNumber(f.number))
+		if fd == nil || !msg.Has(fd) {
+			continue
+		}
+
+		if f.repeated {
+			list := msg.Get(fd).List()
+			items := make([]any, 0, list.Len())
+			for i := 0; i < list.Len(); i++ {
+				item, err := fromValue(f, list.Get(i))
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+			}
+			ret[f.key] = items
+			continue
+		}
+
+		item, err := fromValue(f, msg.Get(fd))
+		if err != nil {
+			return nil, err
+		}
+		ret[f.key] = item
+	}
+	return ret, nil
+}
+
+// fromValue converts the value of the field into a json value
+func fromValue(f field, v protoreflect.Value) (any, error) {
+	switch f.kind {
+	case kindBool:
+		return v.Bool(), nil
+	case kindUint64:
+		return v.Uint(), nil
+	case kindInt64:
+		return v.Int(), nil
+	case kindDouble:
+		return v.Float(), nil
+	case kindMessage:
+		nested := messageFor(f.message)
+		if nested == nil {
+			return nil, fmt.Errorf("unknown message %s", f.message)
+		}
+		return toMap(v.Message(), nested.fields)
+	case kindValue:
+		bytes, err := protojson.Marshal(v.Message().Interface())
+		if err != nil {
+			return nil, err
+		}
+		var ret any
+		err = json.Unmarshal(bytes, &ret)
+		return ret, err
+	default:
+		return v.String(), nil
+	}
+}
@@ -0,0 +1,280 @@
+// Copyright 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package grpcapi
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/output"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// RunFunc runs the chifra command for route with the given (query) options. The command sends
+// its records to rCtx.ModelChan and its non-fatal errors to rCtx.ErrorChan.
+type RunFunc func(rCtx *output.RenderCtx, route string, values url.Values) error
+
+// Server serves chifra's commands as server-streaming methods over gRPC and over the Connect
+// protocol (which works over HTTP/1.1 and from browsers)
+type Server struct {
+	run RunFunc
+}
+
+// NewServer returns a Server that uses run to run the commands
+func NewServer(run RunFunc) *Server {
+	return &Server{run: run}
+}
+
+// Register registers the Chifra service with the gRPC server
+func (s *Server) Register(registrar grpc.ServiceRegistrar) error {
+	if _, err := descriptor(); err != nil {
+		return err
+	}
+
+	desc := grpc.ServiceDesc{
+		ServiceName: ServiceName,
+		HandlerType: (*any)(nil),
+		Metadata:    protoFile,
+	}
+	for _, svc := range services {
+		svc := svc
+		desc.Streams = append(desc.Streams, grpc.StreamDesc{
+			StreamName:    svc.method,
+			ServerStreams: true,
+			Handler: func(_ any, stream grpc.ServerStream) error {
+				req, err := newMessage(svc.method + "Request")
+				if err != nil {
+					return err
+				}
+				if err := stream.RecvMsg(req); err != nil {
+					return err
+				}
+				return s.stream(stream.Context(), svc, svc.toValues(req), func(rec *dynamicpb.Message) error {
+					return stream.SendMsg(rec)
+				})
+			},
+		})
+	}
+	registrar.RegisterService(&desc, s)
+	return nil
+}
+
+// ServeConnect serves a method of the Chifra service using the Connect protocol's server
+// streaming, with either the proto (application/connect+proto) or the json
+// (application/connect+json) codec
+func (s *Server) ServeConnect(w http.ResponseWriter, r *http.Request) {
+	method, ok := methodFromPath(r.URL.Path)
+	svc, err := serviceFor(method)
+	if !ok || err != nil {
+		http.Error(w, "unknown method "+r.URL.Path, http.StatusNotFound)
+		return
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	isJson := contentType == "application/connect+json"
+	if !isJson && contentType != "application/connect+proto" {
+		http.Error(w, "unsupported content type "+contentType, http.StatusUnsupportedMediaType)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+
+	marshal := proto.Marshal
+	if isJson {
+		marshal = protojson.Marshal
+	}
+	send := func(flags byte, data []byte) error {
+		header := make([]byte, 5)
+		header[0] = flags
+		binary.BigEndian.PutUint32(header[1:], uint32(len(data)))
+		if _, err := w.Write(append(header, data...)); err != nil {
+			return err
+		}
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+		return nil
+	}
+
+	err = func() error {
+		data, err := readEnvelope(r.Body)
+		if err != nil {
+			return err
+		}
+		req, err := newMessage(svc.method + "Request")
+		if err != nil {
+			return err
+		}
+		if isJson {
+			err = protojson.Unmarshal(data, req)
+		} else {
+			err = proto.Unmarshal(data, req)
+		}
+		if err != nil {
+			return err
+		}
+		return s.stream(r.Context(), svc, svc.toValues(req), func(rec *dynamicpb.Message) error {
+			data, err := marshal(rec)
+			if err != nil {
+				return err
+			}
+			return send(0, data)
+		})
+	}()
+
+	// The end of the stream is always json, whatever the codec
+	end := map[string]any{}
+	if err != nil {
+		code := "unknown"
+		if errors.Is(err, context.Canceled) {
+			code = "canceled"
+		}
+		end["error"] = map[string]string{"code": code, "message": err.Error()}
+	}
+	data, _ := json.Marshal(end)
+	_ = send(2, data)
+}
+
+// readEnvelope reads the single enveloped message of a Connect streaming request
+func readEnvelope(r io.Reader) ([]byte, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("could not read request: %w", err)
+	}
+	if header[0]&1 != 0 {
+		return nil, errors.New("compressed requests are not supported")
+	}
+	data := make([]byte, binary.BigEndian.Uint32(header[1:]))
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("could not read request: %w", err)
+	}
+	return data, nil
+}
+
+// stream runs the command and sends its records (and non-fatal errors) to the client as they
+// are produced. It returns the command's error, if any.
+func (s *Server) stream(ctx context.Context, svc *service, values url.Values, send func(*dynamicpb.Message) error) error {
+	chain := values.Get("chain")
+	if chain == "" {
+		chain = config.GetSettings().DefaultChain
+	}
+	extra := map[string]any{
+		"ether": values.Get("ether") == "true",
+	}
+
+	rCtx := output.NewStreamingContext()
+	done := make(chan error, 1)
+	go func() {
+		done <- s.run(rCtx, svc.route, values)
+	}()
+
+	stop := func(err error) error {
+		rCtx.Cancel()
+		// The command may be blocked sending its next record, so we drain until it quits
+		go func() {
+			for {
+				select {
+				case <-rCtx.ModelChan:
+				case <-rCtx.ErrorChan:
+				case <-done:
+					return
+				}
+			}
+		}()
+		return err
+	}
+
+	for {
+		select {
+		case model := <-rCtx.ModelChan:
+			rec, err := newRecord(typeName(model), model.Model(chain, "json", false, extra).Data, "")
+			if err == nil {
+				err = send(rec)
+			}
+			if err != nil {
+				return stop(err)
+			}
+		case cmdErr := <-rCtx.ErrorChan:
+			rec, err := newRecord("", nil, cmdErr.Error())
+			if err == nil {
+				err = send(rec)
+			}
+			if err != nil {
+				return stop(err)
+			}
+		case err := <-done:
+			return err
+		case <-ctx.Done():
+			return stop(ctx.Err())
+		}
+	}
+}
+
+// typeName returns the name of the model's type (for example Log for a *types.Log)
+func typeName(model any) string {
+	t := reflect.TypeOf(model)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil {
+		return ""
+	}
+	return t.Name()
+}
+
+// newRecord returns a Record carrying either data (the record as chifra would render it in json)
+// in the message of its type or a non-fatal error. Records of a type without a message of its
+// own are carried as they would be rendered.
+func newRecord(name string, data any, errMsg string) (*dynamicpb.Message, error) {
+	rec, err := newMessage("Record")
+	if err != nil {
+		return nil, err
+	}
+
+	fields := rec.Descriptor().Fields()
+	if len(errMsg) > 0 {
+		rec.Set(fields.ByName("error"), protoreflect.ValueOfString(strings.TrimSpace(errMsg)))
+		return rec, nil
+	}
+
+	bytes, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if m := messageFor(name); m != nil {
+		var values map[string]any
+		decoder := json.NewDecoder(strings.NewReader(string(bytes)))
+		decoder.UseNumber()
+		if err := decoder.Decode(&values); err != nil {
+			return nil, err
+		}
+		fd := fields.ByNumber(protoreflect.FieldNumber(m.number))
+		fill(rec.Mutable(fd).Message(), m.fields, values)
+		return rec, nil
+	}
+
+	st := &structpb.Struct{}
+	if err := protojson.Unmarshal(bytes, st); err != nil {
+		return nil, err
+	}
+	rec.Set(fields.ByName("data"), protoreflect.ValueOfMessage(st.ProtoReflect()))
+	return rec, nil
+}
@@ -0,0 +1,735 @@
+// Copyright 2016, 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were auto generated. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package grpcapi
+
+import (
+	"context"
+	"net/url"
+	// EXISTING_CODE
+	// EXISTING_CODE
+)
+
+var services = []*service{
+	{
+		route:  "export",
+		method: "Export",
+		fields: []field{
+			{name: "addrs", key: "addrs", number: 20, kind: kindString, repeated: true},
+			{name: "topics", key: "topics", number: 30, kind: kindString, repeated: true},
+			{name: "fourbytes", key: "fourbytes", number: 40, kind: kindString, repeated: true},
+			{name: "appearances", key: "appearances", number: 50, kind: kindBool},
+			{name: "receipts", key: "receipts", number: 60, kind: kindBool},
+			{name: "logs", key: "logs", number: 70, kind: kindBool},
+			{name: "traces", key: "traces", number: 80, kind: kindBool},
+			{name: "neighbors", key: "neighbors", number: 90, kind: kindBool},
+			{name: "accounting", key: "accounting", number: 100, kind: kindBool},
+			{name: "statements", key: "statements", number: 110, kind: kindBool},
+			{name: "gains", key: "gains", number: 112, kind: kindBool},
+			{name: "lot_method", key: "lotMethod", number: 114, kind: kindString},
+			{name: "lots", key: "lots", number: 116, kind: kindString},
+			{name: "balances", key: "balances", number: 120, kind: kindBool},
+			{name: "withdrawals", key: "withdrawals", number: 130, kind: kindBool},
+			{name: "articulate", key: "articulate", number: 140, kind: kindBool},
+			{name: "cache_traces", key: "cacheTraces", number: 150, kind: kindBool},
+			{name: "count", key: "count", number: 160, kind: kindBool},
+			{name: "first_record", key: "firstRecord", number: 170, kind: kindUint64},
+			{name: "max_records", key: "maxRecords", number: 180, kind: kindUint64},
+			{name: "relevant", key: "relevant", number: 190, kind: kindBool},
+			{name: "emitter", key: "emitter", number: 200, kind: kindString, repeated: true},
+			{name: "topic", key: "topic", number: 210, kind: kindString, repeated: true},
+			{name: "reverted", key: "reverted", number: 220, kind: kindBool},
+			{name: "asset", key: "asset", number: 230, kind: kindString, repeated: true},
+			{name: "flow", key: "flow", number: 240, kind: kindString},
+			{name: "factory", key: "factory", number: 250, kind: kindBool},
+			{name: "unripe", key: "unripe", number: 260, kind: kindBool},
+			{name: "cursor", key: "cursor", number: 270, kind: kindString},
+			{name: "reversed", key: "reversed", number: 280, kind: kindBool},
+			{name: "no_zero", key: "noZero", number: 290, kind: kindBool},
+			{name: "first_block", key: "firstBlock", number: 300, kind: kindUint64},
+			{name: "last_block", key: "lastBlock", number: 310, kind: kindUint64},
+			{name: "chain", key: "chain", number: 1001, kind: kindString},
+			{name: "cache", key: "cache", number: 1002, kind: kindBool},
+			{name: "ether", key: "ether", number: 1003, kind: kindBool},
+		},
+	},
+	{
+		route:  "blocks",
+		method: "Blocks",
+		fields: []field{
+			{name: "blocks", key: "blocks", number: 20, kind: kindString, repeated: true},
+			{name: "hashes", key: "hashes", number: 30, kind: kindBool},
+			{name: "uncles", key: "uncles", number: 40, kind: kindBool},
+			{name: "traces", key: "traces", number: 50, kind: kindBool},
+			{name: "uniq", key: "uniq", number: 60, kind: kindBool},
+			{name: "flow", key: "flow", number: 70, kind: kindString},
+			{name: "logs", key: "logs", number: 80, kind: kindBool},
+			{name: "emitter", key: "emitter", number: 90, kind: kindString, repeated: true},
+			{name: "topic", key: "topic", number: 100, kind: kindString, repeated: true},
+			{name: "withdrawals", key: "withdrawals", number: 110, kind: kindBool},
+			{name: "blobs", key: "blobs", number: 115, kind: kindBool},
+			{name: "articulate", key: "articulate", number: 120, kind: kindBool},
+			{name: "count", key: "count", number: 140, kind: kindBool},
+			{name: "cache_txs", key: "cacheTxs", number: 150, kind: kindBool},
+			{name: "cache_traces", key: "cacheTraces", number: 160, kind: kindBool},
+			{name: "chain", key: "chain", number: 1001, kind: kindString},
+			{name: "cache", key: "cache", number: 1002, kind: kindBool},
+			{name: "ether", key: "ether", number: 1003, kind: kindBool},
+		},
+	},
+	{
+		route:  "logs",
+		method: "Logs",
+		fields: []field{
+			{name: "transactions", key: "transactions", number: 20, kind: kindString, repeated: true},
+			{name: "emitter", key: "emitter", number: 30, kind: kindString, repeated: true},
+			{name: "topic", key: "topic", number: 40, kind: kindString, repeated: true},
+			{name: "articulate", key: "articulate", number: 50, kind: kindBool},
+			{name: "chain", key: "chain", number: 1001, kind: kindString},
+			{name: "cache", key: "cache", number: 1002, kind: kindBool},
+		},
+	},
+	{
+		route:  "traces",
+		method: "Traces",
+		fields: []field{
+			{name: "transactions", key: "transactions", number: 20, kind: kindString, repeated: true},
+			{name: "articulate", key: "articulate", number: 30, kind: kindBool},
+			{name: "filter", key: "filter", number: 40, kind: kindString},
+			{name: "count", key: "count", number: 50, kind: kindBool},
+			{name: "chain", key: "chain", number: 1001, kind: kindString},
+			{name: "cache", key: "cache", number: 1002, kind: kindBool},
+			{name: "ether", key: "ether", number: 1003, kind: kindBool},
+		},
+	},
+}
+
+var messages = []*message{
+	{
+		name:   "Appearance",
+		field:  "appearance",
+		key:    "appearance",
+		number: 103,
+		fields: []field{
+			{name: "address", key: "address", number: 1, kind: kindString},
+			{name: "block_number", key: "blockNumber", number: 2, kind: kindUint64},
+			{name: "transaction_index", key: "transactionIndex", number: 3, kind: kindUint64},
+			{name: "trace_index", key: "traceIndex", number: 4, kind: kindUint64},
+			{name: "reason", key: "reason", number: 5, kind: kindString},
+			{name: "timestamp", key: "timestamp", number: 6, kind: kindInt64},
+			{name: "date", key: "date", number: 7, kind: kindString},
+		},
+	},
+	{
+		name:   "Monitor",
+		field:  "monitor",
+		key:    "monitor",
+		number: 106,
+		fields: []field{
+			{name: "address", key: "address", number: 1, kind: kindString},
+			{name: "deleted", key: "deleted", number: 2, kind: kindBool},
+			{name: "is_empty", key: "isEmpty", number: 3, kind: kindBool},
+			{name: "is_staged", key: "isStaged", number: 4, kind: kindBool},
+			{name: "file_size", key: "fileSize", number: 5, kind: kindInt64},
+			{name: "last_scanned", key: "lastScanned", number: 6, kind: kindUint64},
+			{name: "n_records", key: "nRecords", number: 7, kind: kindInt64},
+			{name: "name", key: "name", number: 8, kind: kindString},
+		},
+	},
+	{
+		name:   "Statement",
+		field:  "statement",
+		key:    "statement",
+		number: 118,
+		fields: []field{
+			{name: "block_number", key: "blockNumber", number: 1, kind: kindUint64},
+			{name: "transaction_index", key: "transactionIndex", number: 2, kind: kindUint64},
+			{name: "log_index", key: "logIndex", number: 3, kind: kindUint64},
+			{name: "transaction_hash", key: "transactionHash", number: 4, kind: kindString},
+			{name: "timestamp", key: "timestamp", number: 5, kind: kindInt64},
+			{name: "date", key: "date", number: 6, kind: kindString},
+			{name: "asset_addr", key: "assetAddr", number: 7, kind: kindString},
+			{name: "asset_symbol", key: "assetSymbol", number: 8, kind: kindString},
+			{name: "decimals", key: "decimals", number: 9, kind: kindUint64},
+			{name: "spot_price", key: "spotPrice", number: 10, kind: kindDouble},
+			{name: "price_source", key: "priceSource", number: 11, kind: kindString},
+			{name: "accounted_for", key: "accountedFor", number: 12, kind: kindString},
+			{name: "sender", key: "sender", number: 13, kind: kindString},
+			{name: "recipient", key: "recipient", number: 14, kind: kindString},
+			{name: "beg_bal", key: "begBal", number: 15, kind: kindString},
+			{name: "amount_net", key: "amountNet", number: 16, kind: kindString},
+			{name: "end_bal", key: "endBal", number: 17, kind: kindString},
+			{name: "reconciliation_type", key: "reconciliationType", number: 18, kind: kindString},
+			{name: "reconciled", key: "reconciled", number: 19, kind: kindBool},
+			{name: "total_in", key: "totalIn", number: 20, kind: kindString},
+			{name: "amount_in", key: "amountIn", number: 21, kind: kindString},
+			{name: "internal_in", key: "internalIn", number: 22, kind: kindString},
+			{name: "self_destruct_in", key: "selfDestructIn", number: 23, kind: kindString},
+			{name: "miner_base_reward_in", key: "minerBaseRewardIn", number: 24, kind: kindString},
+			{name: "miner_nephew_reward_in", key: "minerNephewRewardIn", number: 25, kind: kindString},
+			{name: "miner_tx_fee_in", key: "minerTxFeeIn", number: 26, kind: kindString},
+			{name: "miner_uncle_reward_in", key: "minerUncleRewardIn", number: 27, kind: kindString},
+			{name: "correcting_in", key: "correctingIn", number: 28, kind: kindString},
+			{name: "prefund_in", key: "prefundIn", number: 29, kind: kindString},
+			{name: "total_out", key: "totalOut", number: 30, kind: kindString},
+			{name: "amount_out", key: "amountOut", number: 31, kind: kindString},
+			{name: "internal_out", key: "internalOut", number: 32, kind: kindString},
+			{name: "correcting_out", key: "correctingOut", number: 33, kind: kindString},
+			{name: "self_destruct_out", key: "selfDestructOut", number: 34, kind: kindString},
+			{name: "gas_out", key: "gasOut", number: 35, kind: kindString},
+			{name: "l1_fee_out", key: "l1FeeOut", number: 36, kind: kindString},
+			{name: "total_out_less_gas", key: "totalOutLessGas", number: 37, kind: kindString},
+			{name: "prev_bal", key: "prevBal", number: 38, kind: kindString},
+			{name: "beg_bal_diff", key: "begBalDiff", number: 39, kind: kindString},
+			{name: "end_bal_diff", key: "endBalDiff", number: 40, kind: kindString},
+			{name: "end_bal_calc", key: "endBalCalc", number: 41, kind: kindString},
+			{name: "correcting_reason", key: "correctingReason", number: 42, kind: kindString},
+			{name: "token_id", key: "tokenId", number: 43, kind: kindString},
+			{name: "price_pool", key: "pricePool", number: 44, kind: kindString},
+		},
+	},
+	{
+		name:   "Gain",
+		field:  "gain",
+		key:    "gain",
+		number: 124,
+		fields: []field{
+			{name: "gain_type", key: "gainType", number: 1, kind: kindString},
+			{name: "method", key: "method", number: 2, kind: kindString},
+			{name: "accounted_for", key: "accountedFor", number: 3, kind: kindString},
+			{name: "asset_addr", key: "assetAddr", number: 4, kind: kindString},
+			{name: "asset_symbol", key: "assetSymbol", number: 5, kind: kindString},
+			{name: "decimals", key: "decimals", number: 6, kind: kindUint64},
+			{name: "year", key: "year", number: 7, kind: kindUint64},
+			{name: "acquired_block", key: "acquiredBlock", number: 8, kind: kindUint64},
+			{name: "acquired_timestamp", key: "acquiredTimestamp", number: 9, kind: kindInt64},
+			{name: "acquired_date", key: "acquiredDate", number: 10, kind: kindString},
+			{name: "acquired_hash", key: "acquiredHash", number: 11, kind: kindString},
+			{name: "disposed_block", key: "disposedBlock", number: 12, kind: kindUint64},
+			{name: "disposed_timestamp", key: "disposedTimestamp", number: 13, kind: kindInt64},
+			{name: "disposed_date", key: "disposedDate", number: 14, kind: kindString},
+			{name: "disposed_hash", key: "disposedHash", number: 15, kind: kindString},
+			{name: "quantity", key: "quantity", number: 16, kind: kindString},
+			{name: "cost_basis", key: "costBasis", number: 17, kind: kindDouble},
+			{name: "proceeds", key: "proceeds", number: 18, kind: kindDouble},
+			{name: "gain", key: "gain", number: 19, kind: kindDouble},
+			{name: "long_term", key: "longTerm", number: 20, kind: kindBool},
+			{name: "short_term_gain", key: "shortTermGain", number: 21, kind: kindDouble},
+			{name: "long_term_gain", key: "longTermGain", number: 22, kind: kindDouble},
+		},
+	},
+	{
+		name:   "Block",
+		field:  "block",
+		key:    "block",
+		number: 203,
+		fields: []field{
+			{name: "gas_limit", key: "gasLimit", number: 2, kind: kindUint64},
+			{name: "gas_used", key: "gasUsed", number: 3, kind: kindUint64},
+			{name: "hash", key: "hash", number: 4, kind: kindString},
+			{name: "block_number", key: "blockNumber", number: 5, kind: kindUint64},
+			{name: "parent_hash", key: "parentHash", number: 6, kind: kindString},
+			{name: "miner", key: "miner", number: 12, kind: kindString},
+			{name: "difficulty", key: "difficulty", number: 13, kind: kindUint64},
+			{name: "timestamp", key: "timestamp", number: 18, kind: kindInt64},
+			{name: "date", key: "date", number: 19, kind: kindString},
+			{name: "base_fee_per_gas", key: "baseFeePerGas", number: 20, kind: kindUint64},
+			{name: "blob_gas_used", key: "blobGasUsed", number: 21, kind: kindUint64},
+			{name: "excess_blob_gas", key: "excessBlobGas", number: 22, kind: kindUint64},
+			{name: "transactions", key: "transactions", number: 23, kind: kindMessage, message: "Transaction", repeated: true},
+			{name: "uncles", key: "uncles", number: 25, kind: kindString, repeated: true},
+			{name: "withdrawals", key: "withdrawals", number: 26, kind: kindMessage, message: "Withdrawal", repeated: true},
+		},
+	},
+	{
+		name:   "Transaction",
+		field:  "transaction",
+		key:    "transaction",
+		number: 206,
+		fields: []field{
+			{name: "block_number", key: "blockNumber", number: 3, kind: kindUint64},
+			{name: "transaction_index", key: "transactionIndex", number: 4, kind: kindUint64},
+			{name: "timestamp", key: "timestamp", number: 5, kind: kindInt64},
+			{name: "date", key: "date", number: 6, kind: kindString},
+			{name: "hash", key: "hash", number: 7, kind: kindString},
+			{name: "block_hash", key: "blockHash", number: 8, kind: kindString},
+			{name: "from", key: "from", number: 9, kind: kindString},
+			{name: "to", key: "to", number: 10, kind: kindString},
+			{name: "nonce", key: "nonce", number: 11, kind: kindUint64},
+			{name: "value", key: "value", number: 12, kind: kindString},
+			{name: "ether", key: "ether", number: 13, kind: kindString},
+			{name: "gas", key: "gas", number: 14, kind: kindUint64},
+			{name: "gas_price", key: "gasPrice", number: 15, kind: kindUint64},
+			{name: "max_fee_per_gas", key: "maxFeePerGas", number: 16, kind: kindUint64},
+			{name: "max_priority_fee_per_gas", key: "maxPriorityFeePerGas", number: 17, kind: kindUint64},
+			{name: "max_fee_per_blob_gas", key: "maxFeePerBlobGas", number: 18, kind: kindUint64},
+			{name: "blob_versioned_hashes", key: "blobVersionedHashes", number: 19, kind: kindString, repeated: true},
+			{name: "mint", key: "mint", number: 20, kind: kindString},
+			{name: "input", key: "input", number: 21, kind: kindString},
+			{name: "is_error", key: "isError", number: 22, kind: kindBool},
+			{name: "has_token", key: "hasToken", number: 23, kind: kindBool},
+			{name: "receipt", key: "receipt", number: 24, kind: kindMessage, message: "Receipt"},
+			{name: "traces", key: "traces", number: 25, kind: kindMessage, message: "Trace", repeated: true},
+			{name: "articulated_tx", key: "articulatedTx", number: 26, kind: kindMessage, message: "Function"},
+			{name: "compressed_tx", key: "compressedTx", number: 27, kind: kindString},
+			{name: "statements", key: "statements", number: 28, kind: kindMessage, message: "Statement", repeated: true},
+			{name: "gas_used", key: "gasUsed", number: 29, kind: kindUint64},
+			{name: "type", key: "type", number: 30, kind: kindString},
+		},
+	},
+	{
+		name:   "Withdrawal",
+		field:  "withdrawal",
+		key:    "withdrawal",
+		number: 209,
+		fields: []field{
+			{name: "address", key: "address", number: 1, kind: kindString},
+			{name: "amount", key: "amount", number: 2, kind: kindString},
+			{name: "ether", key: "ether", number: 3, kind: kindString},
+			{name: "block_number", key: "blockNumber", number: 4, kind: kindUint64},
+			{name: "index", key: "index", number: 5, kind: kindUint64},
+			{name: "timestamp", key: "timestamp", number: 6, kind: kindInt64},
+			{name: "date", key: "date", number: 7, kind: kindString},
+			{name: "validator_index", key: "validatorIndex", number: 8, kind: kindUint64},
+		},
+	},
+	{
+		name:   "Receipt",
+		field:  "receipt",
+		key:    "receipt",
+		number: 212,
+		fields: []field{
+			{name: "block_hash", key: "blockHash", number: 1, kind: kindString},
+			{name: "block_number", key: "blockNumber", number: 2, kind: kindUint64},
+			{name: "blob_gas_price", key: "blobGasPrice", number: 3, kind: kindUint64},
+			{name: "blob_gas_used", key: "blobGasUsed", number: 4, kind: kindUint64},
+			{name: "contract_address", key: "contractAddress", number: 5, kind: kindString},
+			{name: "cumulative_gas_used", key: "cumulativeGasUsed", number: 6, kind: kindUint64},
+			{name: "deposit_nonce", key: "depositNonce", number: 7, kind: kindUint64},
+			{name: "from", key: "from", number: 8, kind: kindString},
+			{name: "gas_used", key: "gasUsed", number: 9, kind: kindUint64},
+			{name: "gas_used_for_l1", key: "gasUsedForL1", number: 10, kind: kindUint64},
+			{name: "effective_gas_price", key: "effectiveGasPrice", number: 11, kind: kindUint64},
+			{name: "is_error", key: "isError", number: 12, kind: kindBool},
+			{name: "l1_block_number", key: "l1BlockNumber", number: 13, kind: kindUint64},
+			{name: "l1_fee", key: "l1Fee", number: 14, kind: kindString},
+			{name: "l1_gas_price", key: "l1GasPrice", number: 15, kind: kindUint64},
+			{name: "l1_gas_used", key: "l1GasUsed", number: 16, kind: kindUint64},
+			{name: "logs", key: "logs", number: 17, kind: kindMessage, message: "Log", repeated: true},
+			{name: "status", key: "status", number: 19, kind: kindUint64},
+			{name: "to", key: "to", number: 20, kind: kindString},
+			{name: "transaction_hash", key: "transactionHash", number: 21, kind: kindString},
+			{name: "transaction_index", key: "transactionIndex", number: 22, kind: kindUint64},
+			{name: "type", key: "type", number: 23, kind: kindString},
+		},
+	},
+	{
+		name:   "Log",
+		field:  "log",
+		key:    "log",
+		number: 215,
+		fields: []field{
+			{name: "block_number", key: "blockNumber", number: 1, kind: kindUint64},
+			{name: "transaction_index", key: "transactionIndex", number: 2, kind: kindUint64},
+			{name: "log_index", key: "logIndex", number: 3, kind: kindUint64},
+			{name: "timestamp", key: "timestamp", number: 4, kind: kindInt64},
+			{name: "date", key: "date", number: 5, kind: kindString},
+			{name: "address", key: "address", number: 6, kind: kindString},
+			{name: "topics", key: "topics", number: 7, kind: kindString, repeated: true},
+			{name: "data", key: "data", number: 8, kind: kindString},
+			{name: "transaction_hash", key: "transactionHash", number: 9, kind: kindString},
+			{name: "block_hash", key: "blockHash", number: 10, kind: kindString},
+			{name: "articulated_log", key: "articulatedLog", number: 11, kind: kindMessage, message: "Function"},
+			{name: "compressed_log", key: "compressedLog", number: 12, kind: kindString},
+		},
+	},
+	{
+		name:   "Trace",
+		field:  "trace",
+		key:    "trace",
+		number: 221,
+		fields: []field{
+			{name: "block_hash", key: "blockHash", number: 1, kind: kindString},
+			{name: "block_number", key: "blockNumber", number: 2, kind: kindUint64},
+			{name: "subtraces", key: "subtraces", number: 3, kind: kindUint64},
+			{name: "trace_address", key: "traceAddress", number: 4, kind: kindUint64, repeated: true},
+			{name: "transaction_hash", key: "transactionHash", number: 5, kind: kindString},
+			{name: "transaction_index", key: "transactionIndex", number: 6, kind: kindUint64},
+			{name: "type", key: "type", number: 7, kind: kindString},
+			{name: "error", key: "error", number: 8, kind: kindString},
+			{name: "action", key: "action", number: 9, kind: kindMessage, message: "TraceAction"},
+			{name: "result", key: "result", number: 10, kind: kindMessage, message: "TraceResult"},
+			{name: "articulated_trace", key: "articulatedTrace", number: 11, kind: kindMessage, message: "Function"},
+			{name: "compressed_trace", key: "compressedTrace", number: 12, kind: kindString},
+			{name: "timestamp", key: "timestamp", number: 13, kind: kindInt64},
+			{name: "date", key: "date", number: 14, kind: kindString},
+		},
+	},
+	{
+		name:   "TraceAction",
+		field:  "trace_action",
+		key:    "traceAction",
+		number: 224,
+		fields: []field{
+			{name: "self_destructed", key: "selfDestructed", number: 1, kind: kindString},
+			{name: "balance", key: "balance", number: 2, kind: kindString},
+			{name: "balance_eth", key: "balanceEth", number: 3, kind: kindString},
+			{name: "call_type", key: "callType", number: 4, kind: kindString},
+			{name: "from", key: "from", number: 5, kind: kindString},
+			{name: "gas", key: "gas", number: 6, kind: kindUint64},
+			{name: "init", key: "init", number: 7, kind: kindString},
+			{name: "input", key: "input", number: 8, kind: kindString},
+			{name: "refund_address", key: "refundAddress", number: 9, kind: kindString},
+			{name: "reward_type", key: "rewardType", number: 10, kind: kindString},
+			{name: "to", key: "to", number: 11, kind: kindString},
+			{name: "value", key: "value", number: 12, kind: kindString},
+			{name: "ether", key: "ether", number: 13, kind: kindString},
+			{name: "address", key: "address", number: 14, kind: kindString},
+			{name: "author", key: "author", number: 15, kind: kindString},
+		},
+	},
+	{
+		name:   "TraceResult",
+		field:  "trace_result",
+		key:    "traceResult",
+		number: 227,
+		fields: []field{
+			{name: "address", key: "address", number: 1, kind: kindString},
+			{name: "code", key: "code", number: 2, kind: kindString},
+			{name: "gas_used", key: "gasUsed", number: 3, kind: kindUint64},
+			{name: "output", key: "output", number: 4, kind: kindString},
+		},
+	},
+	{
+		name:   "TraceCount",
+		field:  "trace_count",
+		key:    "traceCount",
+		number: 230,
+		fields: []field{
+			{name: "block_number", key: "blockNumber", number: 1, kind: kindUint64},
+			{name: "transaction_index", key: "transactionIndex", number: 2, kind: kindUint64},
+			{name: "transaction_hash", key: "transactionHash", number: 3, kind: kindString},
+			{name: "timestamp", key: "timestamp", number: 4, kind: kindInt64},
+			{name: "date", key: "date", number: 5, kind: kindString},
+			{name: "traces_cnt", key: "tracesCnt", number: 6, kind: kindUint64},
+		},
+	},
+	{
+		name:   "TraceFilter",
+		field:  "trace_filter",
+		key:    "traceFilter",
+		number: 233,
+		fields: []field{
+			{name: "from_block", key: "fromBlock", number: 1, kind: kindUint64},
+			{name: "to_block", key: "toBlock", number: 2, kind: kindUint64},
+			{name: "from_address", key: "fromAddress", number: 3, kind: kindString},
+			{name: "to_address", key: "toAddress", number: 4, kind: kindString},
+			{name: "after", key: "after", number: 5, kind: kindUint64},
+			{name: "count", key: "count", number: 6, kind: kindUint64},
+		},
+	},
+	{
+		name:   "BlockCount",
+		field:  "block_count",
+		key:    "blockCount",
+		number: 236,
+		fields: []field{
+			{name: "block_number", key: "blockNumber", number: 1, kind: kindUint64},
+			{name: "timestamp", key: "timestamp", number: 2, kind: kindInt64},
+			{name: "date", key: "date", number: 3, kind: kindString},
+			{name: "transactions_cnt", key: "transactionsCnt", number: 4, kind: kindUint64},
+			{name: "uncles_cnt", key: "unclesCnt", number: 5, kind: kindUint64},
+			{name: "logs_cnt", key: "logsCnt", number: 6, kind: kindUint64},
+			{name: "traces_cnt", key: "tracesCnt", number: 7, kind: kindUint64},
+			{name: "withdrawals_cnt", key: "withdrawalsCnt", number: 8, kind: kindUint64},
+			{name: "address_cnt", key: "addressCnt", number: 9, kind: kindUint64},
+		},
+	},
+	{
+		name:   "LightBlock",
+		field:  "light_block",
+		key:    "lightBlock",
+		number: 248,
+		fields: []field{
+			{name: "gas_limit", key: "gasLimit", number: 2, kind: kindUint64},
+			{name: "gas_used", key: "gasUsed", number: 3, kind: kindUint64},
+			{name: "hash", key: "hash", number: 4, kind: kindString},
+			{name: "block_number", key: "blockNumber", number: 5, kind: kindUint64},
+			{name: "parent_hash", key: "parentHash", number: 6, kind: kindString},
+			{name: "miner", key: "miner", number: 12, kind: kindString},
+			{name: "difficulty", key: "difficulty", number: 13, kind: kindUint64},
+			{name: "timestamp", key: "timestamp", number: 18, kind: kindInt64},
+			{name: "date", key: "date", number: 19, kind: kindString},
+			{name: "base_fee_per_gas", key: "baseFeePerGas", number: 20, kind: kindUint64},
+			{name: "blob_gas_used", key: "blobGasUsed", number: 21, kind: kindUint64},
+			{name: "excess_blob_gas", key: "excessBlobGas", number: 22, kind: kindUint64},
+			{name: "transactions", key: "transactions", number: 23, kind: kindString, repeated: true},
+			{name: "uncles", key: "uncles", number: 25, kind: kindString, repeated: true},
+			{name: "withdrawals", key: "withdrawals", number: 26, kind: kindMessage, message: "Withdrawal", repeated: true},
+		},
+	},
+	{
+		name:   "Token",
+		field:  "token",
+		key:    "token",
+		number: 306,
+		fields: []field{
+			{name: "block_number", key: "blockNumber", number: 1, kind: kindUint64},
+			{name: "transaction_index", key: "transactionIndex", number: 2, kind: kindUint64},
+			{name: "timestamp", key: "timestamp", number: 3, kind: kindInt64},
+			{name: "date", key: "date", number: 4, kind: kindString},
+			{name: "total_supply", key: "totalSupply", number: 5, kind: kindString},
+			{name: "address", key: "address", number: 6, kind: kindString},
+			{name: "holder", key: "holder", number: 7, kind: kindString},
+			{name: "prior_balance", key: "priorBalance", number: 8, kind: kindString},
+			{name: "balance", key: "balance", number: 9, kind: kindString},
+			{name: "balance_dec", key: "balanceDec", number: 10, kind: kindDouble},
+			{name: "diff", key: "diff", number: 11, kind: kindString},
+			{name: "name", key: "name", number: 12, kind: kindString},
+			{name: "symbol", key: "symbol", number: 13, kind: kindString},
+			{name: "decimals", key: "decimals", number: 14, kind: kindUint64},
+			{name: "type", key: "type", number: 15, kind: kindValue},
+		},
+	},
+	{
+		name:   "Function",
+		field:  "function",
+		key:    "function",
+		number: 506,
+		fields: []field{
+			{name: "name", key: "name", number: 1, kind: kindString},
+			{name: "type", key: "type", number: 2, kind: kindString},
+			{name: "anonymous", key: "anonymous", number: 3, kind: kindBool},
+			{name: "constant", key: "constant", number: 4, kind: kindBool},
+			{name: "state_mutability", key: "stateMutability", number: 5, kind: kindString},
+			{name: "signature", key: "signature", number: 6, kind: kindString},
+			{name: "encoding", key: "encoding", number: 7, kind: kindString},
+			{name: "message", key: "message", number: 8, kind: kindString},
+			{name: "inputs", key: "inputs", number: 9, kind: kindMessage, message: "Parameter", repeated: true},
+			{name: "outputs", key: "outputs", number: 10, kind: kindMessage, message: "Parameter", repeated: true},
+		},
+	},
+	{
+		name:   "Parameter",
+		field:  "parameter",
+		key:    "parameter",
+		number: 509,
+		fields: []field{
+			{name: "type", key: "type", number: 1, kind: kindString},
+			{name: "name", key: "name", number: 2, kind: kindString},
+			{name: "str_default", key: "strDefault", number: 3, kind: kindString},
+			{name: "value", key: "value", number: 4, kind: kindString},
+			{name: "indexed", key: "indexed", number: 5, kind: kindBool},
+			{name: "internal_type", key: "internalType", number: 6, kind: kindString},
+			{name: "components", key: "components", number: 7, kind: kindMessage, message: "Parameter", repeated: true},
+		},
+	},
+	{
+		name:   "Message",
+		field:  "message",
+		key:    "message",
+		number: 518,
+		fields: []field{
+			{name: "msg", key: "msg", number: 1, kind: kindString},
+			{name: "num", key: "num", number: 2, kind: kindInt64},
+		},
+	},
+}
+
+// ExportRequest holds the options of chifra export
+type ExportRequest struct {
+	Addrs       []string `json:"addrs,omitempty"`
+	Topics      []string `json:"topics,omitempty"`
+	Fourbytes   []string `json:"fourbytes,omitempty"`
+	Appearances bool     `json:"appearances,omitempty"`
+	Receipts    bool     `json:"receipts,omitempty"`
+	Logs        bool     `json:"logs,omitempty"`
+	Traces      bool     `json:"traces,omitempty"`
+	Neighbors   bool     `json:"neighbors,omitempty"`
+	Accounting  bool     `json:"accounting,omitempty"`
+	Statements  bool     `json:"statements,omitempty"`
+	Gains       bool     `json:"gains,omitempty"`
+	LotMethod   string   `json:"lotMethod,omitempty"`
+	Lots        string   `json:"lots,omitempty"`
+	Balances    bool     `json:"balances,omitempty"`
+	Withdrawals bool     `json:"withdrawals,omitempty"`
+	Articulate  bool     `json:"articulate,omitempty"`
+	CacheTraces bool     `json:"cacheTraces,omitempty"`
+	Count       bool     `json:"count,omitempty"`
+	FirstRecord uint64   `json:"firstRecord,omitempty"`
+	MaxRecords  uint64   `json:"maxRecords,omitempty"`
+	Relevant    bool     `json:"relevant,omitempty"`
+	Emitter     []string `json:"emitter,omitempty"`
+	Topic       []string `json:"topic,omitempty"`
+	Reverted    bool     `json:"reverted,omitempty"`
+	Asset       []string `json:"asset,omitempty"`
+	Flow        string   `json:"flow,omitempty"`
+	Factory     bool     `json:"factory,omitempty"`
+	Unripe      bool     `json:"unripe,omitempty"`
+	Cursor      string   `json:"cursor,omitempty"`
+	Reversed    bool     `json:"reversed,omitempty"`
+	NoZero      bool     `json:"noZero,omitempty"`
+	FirstBlock  uint64   `json:"firstBlock,omitempty"`
+	LastBlock   uint64   `json:"lastBlock,omitempty"`
+	Chain       string   `json:"chain,omitempty"`
+	Cache       bool     `json:"cache,omitempty"`
+	Ether       bool     `json:"ether,omitempty"`
+}
+
+func (req *ExportRequest) values() url.Values {
+	values := url.Values{}
+	addStrings(values, "addrs", req.Addrs)
+	addStrings(values, "topics", req.Topics)
+	addStrings(values, "fourbytes", req.Fourbytes)
+	addBool(values, "appearances", req.Appearances)
+	addBool(values, "receipts", req.Receipts)
+	addBool(values, "logs", req.Logs)
+	addBool(values, "traces", req.Traces)
+	addBool(values, "neighbors", req.Neighbors)
+	addBool(values, "accounting", req.Accounting)
+	addBool(values, "statements", req.Statements)
+	addBool(values, "gains", req.Gains)
+	addString(values, "lotMethod", req.LotMethod)
+	addString(values, "lots", req.Lots)
+	addBool(values, "balances", req.Balances)
+	addBool(values, "withdrawals", req.Withdrawals)
+	addBool(values, "articulate", req.Articulate)
+	addBool(values, "cacheTraces", req.CacheTraces)
+	addBool(values, "count", req.Count)
+	addUint64(values, "firstRecord", req.FirstRecord)
+	addUint64(values, "maxRecords", req.MaxRecords)
+	addBool(values, "relevant", req.Relevant)
+	addStrings(values, "emitter", req.Emitter)
+	addStrings(values, "topic", req.Topic)
+	addBool(values, "reverted", req.Reverted)
+	addStrings(values, "asset", req.Asset)
+	addString(values, "flow", req.Flow)
+	addBool(values, "factory", req.Factory)
+	addBool(values, "unripe", req.Unripe)
+	addString(values, "cursor", req.Cursor)
+	addBool(values, "reversed", req.Reversed)
+	addBool(values, "noZero", req.NoZero)
+	addUint64(values, "firstBlock", req.FirstBlock)
+	addUint64(values, "lastBlock", req.LastBlock)
+	addString(values, "chain", req.Chain)
+	addBool(values, "cache", req.Cache)
+	addBool(values, "ether", req.Ether)
+	return values
+}
+
+// Export streams the records produced by chifra export as they are produced
+func (c *Client) Export(ctx context.Context, req *ExportRequest) (*RecordStream, error) {
+	return c.stream(ctx, "Export", req.values())
+}
+
+// BlocksRequest holds the options of chifra blocks
+type BlocksRequest struct {
+	Blocks      []string `json:"blocks,omitempty"`
+	Hashes      bool     `json:"hashes,omitempty"`
+	Uncles      bool     `json:"uncles,omitempty"`
+	Traces      bool     `json:"traces,omitempty"`
+	Uniq        bool     `json:"uniq,omitempty"`
+	Flow        string   `json:"flow,omitempty"`
+	Logs        bool     `json:"logs,omitempty"`
+	Emitter     []string `json:"emitter,omitempty"`
+	Topic       []string `json:"topic,omitempty"`
+	Withdrawals bool     `json:"withdrawals,omitempty"`
+	Blobs       bool     `json:"blobs,omitempty"`
+	Articulate  bool     `json:"articulate,omitempty"`
+	Count       bool     `json:"count,omitempty"`
+	CacheTxs    bool     `json:"cacheTxs,omitempty"`
+	CacheTraces bool     `json:"cacheTraces,omitempty"`
+	Chain       string   `json:"chain,omitempty"`
+	Cache       bool     `json:"cache,omitempty"`
+	Ether       bool     `json:"ether,omitempty"`
+}
+
+func (req *BlocksRequest) values() url.Values {
+	values := url.Values{}
+	addStrings(values, "blocks", req.Blocks)
+	addBool(values, "hashes", req.Hashes)
+	addBool(values, "uncles", req.Uncles)
+	addBool(values, "traces", req.Traces)
+	addBool(values, "uniq", req.Uniq)
+	addString(values, "flow", req.Flow)
+	addBool(values, "logs", req.Logs)
+	addStrings(values, "emitter", req.Emitter)
+	addStrings(values, "topic", req.Topic)
+	addBool(values, "withdrawals", req.Withdrawals)
+	addBool(values, "blobs", req.Blobs)
+	addBool(values, "articulate", req.Articulate)
+	addBool(values, "count", req.Count)
+	addBool(values, "cacheTxs", req.CacheTxs)
+	addBool(values, "cacheTraces", req.CacheTraces)
+	addString(values, "chain", req.Chain)
+	addBool(values, "cache", req.Cache)
+	addBool(values, "ether", req.Ether)
+	return values
+}
+
+// Blocks streams the records produced by chifra blocks as they are produced
+func (c *Client) Blocks(ctx context.Context, req *BlocksRequest) (*RecordStream, error) {
+	return c.stream(ctx, "Blocks", req.values())
+}
+
+// LogsRequest holds the options of chifra logs
+type LogsRequest struct {
+	Transactions []string `json:"transactions,omitempty"`
+	Emitter      []string `json:"emitter,omitempty"`
+	Topic        []string `json:"topic,omitempty"`
+	Articulate   bool     `json:"articulate,omitempty"`
+	Chain        string   `json:"chain,omitempty"`
+	Cache        bool     `json:"cache,omitempty"`
+}
+
+func (req *LogsRequest) values() url.Values {
+	values := url.Values{}
+	addStrings(values, "transactions", req.Transactions)
+	addStrings(values, "emitter", req.Emitter)
+	addStrings(values, "topic", req.Topic)
+	addBool(values, "articulate", req.Articulate)
+	addString(values, "chain", req.Chain)
+	addBool(values, "cache", req.Cache)
+	return values
+}
+
+// Logs streams the records produced by chifra logs as they are produced
+func (c *Client) Logs(ctx context.Context, req *LogsRequest) (*RecordStream, error) {
+	return c.stream(ctx, "Logs", req.values())
+}
+
+// TracesRequest holds the options of chifra traces
+type TracesRequest struct {
+	Transactions []string `json:"transactions,omitempty"`
+	Articulate   bool     `json:"articulate,omitempty"`
+	Filter       string   `json:"filter,omitempty"`
+	Count        bool     `json:"count,omitempty"`
+	Chain        string   `json:"chain,omitempty"`
+	Cache        bool     `json:"cache,omitempty"`
+	Ether        bool     `json:"ether,omitempty"`
+}
+
+func (req *TracesRequest) values() url.Values {
+	values := url.Values{}
+	addStrings(values, "transactions", req.Transactions)
+	addBool(values, "articulate", req.Articulate)
+	addString(values, "filter", req.Filter)
+	addBool(values, "count", req.Count)
+	addString(values, "chain", req.Chain)
+	addBool(values, "cache", req.Cache)
+	addBool(values, "ether", req.Ether)
+	return values
+}
+
+// Traces streams the records produced by chifra traces as they are produced
+func (c *Client) Traces(ctx context.Context, req *TracesRequest) (*RecordStream, error) {
+	return c.stream(ctx, "Traces", req.values())
+}
+
+// EXISTING_CODE
+// EXISTING_CODE
@@ -40,6 +40,8 @@ type bloomHeader struct {
 // Bloom structures contain an array of bloomBytes each BLOOM_WIDTH_IN_BYTES wide. A new bloomBytes is added to
 // the Bloom when around MAX_ADDRS_IN_BLOOM addresses has been added. These Adaptive Bloom Filters allow us to
 // maintain a near-constant false-positive rate at the expense of slightly larger bloom filters than might be expected.
+// If the header carries the FuseVersion, the bloom file instead carries a binary fuse filter (see binaryFuse), Count
+// is zero, and Fuse is not nil.
 type Bloom struct {
 	File       *os.File
 	SizeOnDisc int64
@@ -48,6 +50,7 @@ type Bloom struct {
 	Header     bloomHeader
 	Count      uint32 // Do not change the size of this field, it's stored on disc
 	Blooms     []bloomBytes
+	Fuse       *binaryFuse
 }
 
 // OpenBloom returns a newly initialized bloom filter. The bloom filter's file pointer is open (if there
@@ -76,6 +79,14 @@ func OpenBloom(path string, check bool) (Bloom, error) {
 		return bl, err
 	}
 
+	if bl.IsFuse() {
+		if err = bl.readFuseHeader(); err != nil {
+			return bl, err
+		}
+		_, _ = bl.File.Seek(int64(bl.HeaderSize), io.SeekStart)
+		return bl, nil
+	}
+
 	if err = binary.Read(bl.File, binary.LittleEndian, &bl.Count); err != nil {
 		return bl, err
 	}
@@ -0,0 +1,281 @@
+package index
+
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+)
+
+const (
+	// BloomFormatBloom is the (default) format of a chunk's filter, an adaptive bloom filter
+	BloomFormatBloom = "bloom"
+	// BloomFormatFuse is the format of a chunk's filter when it is a binary fuse filter
+	BloomFormatFuse = "fuse"
+
+	// The number of positions each address hashes to in a binaryFuse (do not change, the filter depends on it)
+	fuseArity = 3
+	// The number of seeds to try before giving up on building a binaryFuse (failure is astronomically unlikely)
+	maxFuseAttempts = 100
+)
+
+// fuseHeader is the part of a binaryFuse that is stored on disc following the bloom file's header. It is
+// followed by (SegmentCount + fuseArity - 1) * SegmentLength two-byte fingerprints.
+type fuseHeader struct {
+	NInserted     uint32 // Do not change the size of these fields, they're stored on disc
+	Seed          uint64
+	SegmentLength uint32
+	SegmentCount  uint32
+}
+
+// binaryFuse is a binary fuse filter (Graf and Lemire, 2022) with 16-bit fingerprints. Each address is hashed to
+// three positions in three consecutive segments of the Fingerprints array. The address is a member if the xor of the
+// fingerprints at those positions equals the address's own fingerprint. Using about 18 bits per address, it has a
+// false-positive rate of 1 in 65,536, which is both smaller and more accurate than the adaptive bloom filters.
+type binaryFuse struct {
+	fuseHeader
+	Fingerprints       []uint16
+	segmentLengthMask  uint32
+	segmentCountLength uint32
+}
+
+// fuseHeaderWidth - size of the fuseHeader on disc
+var fuseHeaderWidth = int64(binary.Size(fuseHeader{}))
+
+// IsFuse returns true if the bloom file carries a binary fuse filter in place of the adaptive bloom filter
+func (bl *Bloom) IsFuse() bool {
+	return bl.Header.Hash == base.BytesToHash(config.HeaderHash(config.FuseVersion))
+}
+
+// newBinaryFuse returns a binary fuse filter containing the given addresses
+func newBinaryFuse(addrs []base.Address) (*binaryFuse, error) {
+	keys := make([]uint64, 0, len(addrs))
+	for _, addr := range addrs {
+		keys = append(keys, addressToKey(addr))
+	}
+
+	// The construction requires distinct keys, so we sort and remove duplicates
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
+	unique := keys[:0]
+	for i, key := range keys {
+		if i == 0 || key != keys[i-1] {
+			unique = append(unique, key)
+		}
+	}
+	keys = unique
+
+	size := uint32(len(keys))
+	fuse := &binaryFuse{}
+	fuse.initialize(size)
+	fuse.NInserted = size
+
+	capacity := uint32(len(fuse.Fingerprints))
+	alone := make([]uint32, capacity)
+	t2count := make([]uint8, capacity) // the lowest 2 bits hold the xor of the indices (0, 1, or 2), the rest a count
+	t2hash := make([]uint64, capacity)
+	reverseH := make([]uint8, size)
+	reverseOrder := make([]uint64, size)
+
+	counter := uint64(1)
+	for attempt := 0; ; attempt++ {
+		if attempt == maxFuseAttempts {
+			return nil, errors.New("could not build binary fuse filter")
+		}
+
+		fuse.Seed = splitmix64(&counter)
+		for i := range t2count {
+			t2count[i] = 0
+			t2hash[i] = 0
+		}
+
+		overflow := false
+		for _, key := range keys {
+			hash := mixsplit(key, fuse.Seed)
+			h := fuse.positions(hash)
+			for j := uint8(0); j < fuseArity; j++ {
+				t2count[h[j]] += 4
+				t2count[h[j]] ^= j
+				t2hash[h[j]] ^= hash
+				overflow = overflow || t2count[h[j]] < 4
+			}
+		}
+		if overflow {
+			continue
+		}
+
+		// Peel off the positions with a single key, remembering the order in which we found them
+		qSize := 0
+		for i := uint32(0); i < capacity; i++ {
+			alone[qSize] = i
+			if (t2count[i] >> 2) == 1 {
+				qSize++
+			}
+		}
+
+		stackSize := uint32(0)
+		for qSize > 0 {
+			qSize--
+			index := alone[qSize]
+			if (t2count[index] >> 2) != 1 {
+				continue
+			}
+			hash := t2hash[index]
+			found := t2count[index] & 3
+			reverseH[stackSize] = found
+			reverseOrder[stackSize] = hash
+			stackSize++
+
+			h := fuse.positions(hash)
+			for k := uint8(1); k < fuseArity; k++ {
+				j := (found + k) % fuseArity
+				other := h[j]
+				alone[qSize] = other
+				if (t2count[other] >> 2) == 2 {
+					qSize++
+				}
+				t2count[other] -= 4
+				t2count[other] ^= j
+				t2hash[other] ^= hash
+			}
+		}
+
+		if stackSize == size {
+			break
+		}
+	}
+
+	// Assign the fingerprints in the reverse of the order in which the keys were peeled
+	for i := int(size) - 1; i >= 0; i-- {
+		hash := reverseOrder[i]
+		h := fuse.positions(hash)
+		found := reverseH[i]
+		fuse.Fingerprints[h[found]] = fingerprint(hash) ^
+			fuse.Fingerprints[h[(found+1)%fuseArity]] ^
+			fuse.Fingerprints[h[(found+2)%fuseArity]]
+	}
+
+	return fuse, nil
+}
+
+// initialize sizes the filter for the given number of keys and allocates the fingerprints. The
+// parameters are those recommended for three-wise binary fuse filters.
+func (fuse *binaryFuse) initialize(size uint32) {
+	fuse.SegmentLength = 4
+	if size > 0 {
+		fuse.SegmentLength = uint32(1) << int(math.Floor(math.Log(float64(size))/math.Log(3.33)+2.25))
+	}
+	if fuse.SegmentLength > 262144 {
+		fuse.SegmentLength = 262144
+	}
+
+	capacity := uint32(0)
+	if size > 1 {
+		sizeFactor := math.Max(1.125, 0.875+0.25*math.Log(1000000)/math.Log(float64(size)))
+		capacity = uint32(math.Round(float64(size) * sizeFactor))
+	}
+
+	nSegments := (capacity + fuse.SegmentLength - 1) / fuse.SegmentLength
+	if nSegments <= fuseArity-1 {
+		fuse.SegmentCount = 1
+	} else {
+		fuse.SegmentCount = nSegments - (fuseArity - 1)
+	}
+
+	fuse.setDerived()
+	fuse.Fingerprints = make([]uint16, fuse.nFingerprints())
+}
+
+// setDerived sets the values that are computed from the header
+func (fuse *binaryFuse) setDerived() {
+	fuse.segmentLengthMask = fuse.SegmentLength - 1
+	fuse.segmentCountLength = fuse.SegmentCount * fuse.SegmentLength
+}
+
+// nFingerprints returns the number of fingerprints in the filter
+func (fuse *binaryFuse) nFingerprints() int64 {
+	return int64(fuse.SegmentCount+fuseArity-1) * int64(fuse.SegmentLength)
+}
+
+// validate returns an error if the header read from disc does not describe a filter of the given size
+func (fuse *binaryFuse) validate(bodySize int64) error {
+	if fuse.SegmentLength == 0 || fuse.SegmentLength&(fuse.SegmentLength-1) != 0 || fuse.SegmentCount == 0 {
+		return fmt.Errorf("invalid binary fuse filter segments %d %d", fuse.SegmentLength, fuse.SegmentCount)
+	}
+	if want := fuseHeaderWidth + 2*fuse.nFingerprints(); bodySize != want {
+		return fmt.Errorf("invalid binary fuse filter size %d %d", bodySize, want)
+	}
+	return nil
+}
+
+// positions returns the three positions (one in each of three consecutive segments) to which the hash maps
+func (fuse *binaryFuse) positions(hash uint64) [fuseArity]uint32 {
+	hi, _ := bits.Mul64(hash, uint64(fuse.segmentCountLength))
+	h0 := uint32(hi)
+	h1 := h0 + fuse.SegmentLength
+	h2 := h1 + fuse.SegmentLength
+	h1 ^= uint32(hash>>18) & fuse.segmentLengthMask
+	h2 ^= uint32(hash) & fuse.segmentLengthMask
+	return [fuseArity]uint32{h0, h1, h2}
+}
+
+// contains returns true if the address may be in the filter (the fingerprints must be in memory)
+func (fuse *binaryFuse) contains(addr base.Address) bool {
+	hash := mixsplit(addressToKey(addr), fuse.Seed)
+	h := fuse.positions(hash)
+	return fingerprint(hash)^fuse.Fingerprints[h[0]]^fuse.Fingerprints[h[1]]^fuse.Fingerprints[h[2]] == 0
+}
+
+// addressToKey reduces an address to a 64-bit key (FNV-1a over the address's bytes)
+func addressToKey(addr base.Address) uint64 {
+	key := uint64(14695981039346656037)
+	for _, b := range addr.Bytes() {
+		key ^= uint64(b)
+		key *= 1099511628211
+	}
+	return key
+}
+
+func fingerprint(hash uint64) uint16 {
+	return uint16(hash ^ (hash >> 32))
+}
+
+func mixsplit(key, seed uint64) uint64 {
+	return murmur64(key + seed)
+}
+
+func murmur64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+func splitmix64(seed *uint64) uint64 {
+	*seed = *seed + 0x9E3779B97F4A7C15
+	z := *seed
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return z ^ (z >> 31)
+}
+
+// FuseStats returns the number of addresses inserted in a binary fuse filter and the width in bytes of the
+// filter (its header and fingerprints). Both are zero if the Bloom is not a binary fuse filter.
+func (bl *Bloom) FuseStats() (uint32, int64) {
+	if bl.Fuse == nil {
+		return 0, 0
+	}
+	return bl.Fuse.NInserted, fuseHeaderWidth + 2*bl.Fuse.nFingerprints()
+}
@@ -0,0 +1,175 @@
+//go:build integration
+// +build integration
+
+package index
+
+import (
+	"encoding/binary"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/utils"
+)
+
+// benchmarkChunks is the number of chunks (the latest ones, which are the largest) to compare
+var benchmarkChunks = 20
+
+// benchmarkProbes is the number of addresses (half of them known members) to look up in each chunk
+var benchmarkProbes = 2000
+
+type benchmarkFilters struct {
+	blooms   []string
+	fuses    []string
+	members  []base.Address
+	bloomSz  int64
+	fuseSz   int64
+	nAddress int
+}
+
+// prepareBenchmarkFilters builds a binary fuse filter for each of the latest chunks in the local index (which
+// must have been initialized with chifra init --all) and returns the paths to both filters for each chunk.
+func prepareBenchmarkFilters(b *testing.B) *benchmarkFilters {
+	chain := utils.GetTestChain()
+	if !config.IsChainConfigured(chain) {
+		b.Skip("chain not configured", chain)
+	}
+
+	bloomFolder := filepath.Join(config.PathToIndex(chain), "blooms")
+	entries, err := os.ReadDir(bloomFolder)
+	if err != nil {
+		b.Skip("no index found for", chain, err)
+	}
+
+	paths := []string{}
+	for _, entry := range entries {
+		path := filepath.Join(bloomFolder, entry.Name())
+		if strings.HasSuffix(path, ".bloom") && file.FileExists(ToIndexPath(path)) {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		b.Skip("no index chunks found for", chain)
+	}
+	sort.Strings(paths)
+	if len(paths) > benchmarkChunks {
+		paths = paths[len(paths)-benchmarkChunks:]
+	}
+
+	ret := &benchmarkFilters{}
+	tmpDir := b.TempDir()
+	r := rand.New(rand.NewSource(1))
+	for _, path := range paths {
+		addrs, err := readAddresses(ToIndexPath(path))
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		bl := Bloom{}
+		if bl.Fuse, err = newBinaryFuse(addrs); err != nil {
+			b.Fatal(err)
+		}
+		fusePath := filepath.Join(tmpDir, filepath.Base(path))
+		if _, err = bl.writeBloom(fusePath); err != nil {
+			b.Fatal(err)
+		}
+
+		ret.blooms = append(ret.blooms, path)
+		ret.fuses = append(ret.fuses, fusePath)
+		ret.bloomSz += file.FileSize(path)
+		ret.fuseSz += file.FileSize(fusePath)
+		ret.nAddress += len(addrs)
+		for i := 0; i < benchmarkProbes/2/len(paths)+1 && len(addrs) > 0; i++ {
+			ret.members = append(ret.members, addrs[r.Intn(len(addrs))])
+		}
+	}
+
+	return ret
+}
+
+// readAddresses returns the addresses in the address table of an index file
+func readAddresses(indexPath string) ([]base.Address, error) {
+	indexChunk, err := OpenIndex(indexPath, false /* check */)
+	if err != nil {
+		return nil, err
+	}
+	defer indexChunk.Close()
+
+	addressTable := make([]types.AddrRecord, indexChunk.Header.AddressCount)
+	if _, err = indexChunk.File.Seek(int64(HeaderWidth), io.SeekStart); err != nil {
+		return nil, err
+	}
+	if err = binary.Read(indexChunk.File, binary.LittleEndian, &addressTable); err != nil {
+		return nil, err
+	}
+
+	addrs := make([]base.Address, 0, len(addressTable))
+	for _, addrRecord := range addressTable {
+		addrs = append(addrs, addrRecord.Address)
+	}
+	return addrs, nil
+}
+
+// BenchmarkFilters compares the size, lookup speed, and false-positive rate of the adaptive bloom filters and binary
+// fuse filters built from the same chunks. Each operation looks up a single address in every chunk as chifra list
+// does. Half the addresses appear in one of the chunks, the other half are random (so any hit is a false positive).
+//
+//	go test -tags integration -run none -bench BenchmarkFilters ./pkg/index/
+func BenchmarkFilters(b *testing.B) {
+	filters := prepareBenchmarkFilters(b)
+	probes := append([]base.Address{}, filters.members...)
+	nonMembers := randomAddresses(rand.New(rand.NewSource(2)), len(filters.members))
+	probes = append(probes, nonMembers...)
+
+	b.Logf("chunks: %d addresses: %d", len(filters.blooms), filters.nAddress)
+	b.Logf("bloom: %d bytes (%.1f bits per address)", filters.bloomSz, 8*float64(filters.bloomSz)/float64(filters.nAddress))
+	b.Logf("fuse:  %d bytes (%.1f bits per address)", filters.fuseSz, 8*float64(filters.fuseSz)/float64(filters.nAddress))
+
+	run := func(b *testing.B, paths []string, size int64) {
+		blooms := make([]Bloom, 0, len(paths))
+		for _, path := range paths {
+			bl, err := OpenBloom(path, false /* check */)
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer bl.Close()
+			blooms = append(blooms, bl)
+		}
+
+		nFalse := 0
+		for _, addr := range nonMembers {
+			for i := range blooms {
+				if blooms[i].IsMember(addr) {
+					nFalse++
+				}
+			}
+		}
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			addr := probes[i%len(probes)]
+			for j := range blooms {
+				_ = blooms[j].IsMember(addr)
+			}
+		}
+		b.StopTimer()
+
+		b.ReportMetric(float64(size), "bytes")
+		b.ReportMetric(float64(nFalse)/float64(len(nonMembers)*len(blooms)), "false-positive-rate")
+	}
+
+	b.Run("bloom", func(b *testing.B) {
+		run(b, filters.blooms, filters.bloomSz)
+	})
+	b.Run("fuse", func(b *testing.B) {
+		run(b, filters.fuses, filters.fuseSz)
+	})
+}
@@ -0,0 +1,149 @@
+package index
+
+import (
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+)
+
+func randomAddresses(r *rand.Rand, n int) []base.Address {
+	addrs := make([]base.Address, n)
+	for i := range addrs {
+		var b [20]byte
+		_, _ = r.Read(b[:])
+		addrs[i] = base.BytesToAddress(b[:])
+	}
+	return addrs
+}
+
+func Test_BinaryFuse(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, n := range []int{0, 1, 2, 3, 10, 100, 1000, 50000} {
+		addrs := randomAddresses(r, n)
+		fuse, err := newBinaryFuse(addrs)
+		if err != nil {
+			t.Fatal(n, err)
+		}
+		if fuse.NInserted != uint32(n) {
+			t.Error("wrong number inserted", fuse.NInserted, n)
+		}
+		for _, addr := range addrs {
+			if !fuse.contains(addr) {
+				t.Fatal("address should be member, but isn't", n, addr.Hex())
+			}
+		}
+	}
+
+	// Small addresses (like precompiles) differ only in their last byte
+	addrs := []base.Address{}
+	for i := 1; i < 10; i++ {
+		addrs = append(addrs, base.HexToAddress("0x000000000000000000000000000000000000000"+string(rune('0'+i))))
+	}
+	fuse, err := newBinaryFuse(append(addrs, addrs...)) // duplicates are ignored
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fuse.NInserted != uint32(len(addrs)) {
+		t.Error("duplicates not removed", fuse.NInserted)
+	}
+	for _, addr := range addrs {
+		if !fuse.contains(addr) {
+			t.Error("address should be member, but isn't", addr.Hex())
+		}
+	}
+}
+
+func Test_BinaryFuseFalsePositives(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	nAddrs := 200000
+	fuse, err := newBinaryFuse(randomAddresses(r, nAddrs))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The expected rate is 1 in 65,536, so we expect about 15 false positives
+	nTests := 1000000
+	nFalse := 0
+	for _, addr := range randomAddresses(r, nTests) {
+		if fuse.contains(addr) {
+			nFalse++
+		}
+	}
+	if nFalse > 60 {
+		t.Error("too many false positives", nFalse, "of", nTests)
+	}
+
+	// About 18 bits per address
+	if bitsPer := float64(16*len(fuse.Fingerprints)) / float64(nAddrs); bitsPer > 19 {
+		t.Error("filter too large", bitsPer, "bits per address")
+	}
+}
+
+func Test_BinaryFuseFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "000000010-000000012.bloom")
+	r := rand.New(rand.NewSource(3))
+	addrs := randomAddresses(r, 5000)
+
+	bl := Bloom{}
+	var err error
+	if bl.Fuse, err = newBinaryFuse(addrs); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = bl.writeBloom(path); err != nil {
+		t.Fatal(err)
+	}
+
+	opened, err := OpenBloom(path, true /* check */)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer opened.Close()
+
+	if !opened.IsFuse() || opened.Fuse == nil || opened.Count != 0 {
+		t.Fatal("expected a binary fuse filter")
+	}
+	nInserted, width := opened.FuseStats()
+	if nInserted != 5000 || opened.HeaderSize+width != opened.SizeOnDisc {
+		t.Error("wrong stats", nInserted, width, opened.SizeOnDisc)
+	}
+
+	for _, addr := range addrs {
+		if !opened.IsMember(addr) {
+			t.Fatal("address should be member, but isn't", addr.Hex())
+		}
+	}
+
+	var read Bloom
+	if err = read.Read(path); err != nil {
+		t.Fatal(err)
+	}
+	for _, addr := range randomAddresses(r, 5000) {
+		if read.IsMember(addr) != opened.IsMember(addr) {
+			t.Error("in-memory and on-disc filters disagree", addr.Hex())
+		}
+	}
+}
+
+func Test_BinaryFuseTruncated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "000000010-000000012.bloom")
+	bl := Bloom{}
+	var err error
+	if bl.Fuse, err = newBinaryFuse(randomAddresses(rand.New(rand.NewSource(4)), 100)); err != nil {
+		t.Fatal(err)
+	}
+	bl.Fuse.Fingerprints = bl.Fuse.Fingerprints[:len(bl.Fuse.Fingerprints)-1]
+	if _, err = bl.writeBloom(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = OpenBloom(path, true /* check */); err == nil {
+		t.Error("expected an error for a truncated filter")
+	}
+}
+
+func Test_fuseHeaderWidth(t *testing.T) {
+	if fuseHeaderWidth != 20 {
+		t.Error("the on-disc size of the header changed", fuseHeaderWidth)
+	}
+}
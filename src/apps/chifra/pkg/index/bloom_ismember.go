@@ -9,6 +9,10 @@ import (
 )
 
 func (bl *Bloom) IsMember(addr base.Address) bool {
+	if bl.Fuse != nil {
+		return bl.isMemberFuse(addr)
+	}
+
 	whichBits := bl.addressToBits(addr)
 	offset := uint32(bl.HeaderSize) + 4 // the end of Count
 	for j := 0; j < int(bl.Count); j++ {
@@ -22,6 +26,27 @@ func (bl *Bloom) IsMember(addr base.Address) bool {
 	return false
 }
 
+// isMemberFuse returns true if the address may be in the binary fuse filter. Like the bloom filter, it reads
+// only the fingerprints it needs from disc (unless they've already been read into memory).
+func (bl *Bloom) isMemberFuse(addr base.Address) bool {
+	if bl.Fuse.Fingerprints != nil {
+		return bl.Fuse.contains(addr)
+	}
+
+	hash := mixsplit(addressToKey(addr), bl.Fuse.Seed)
+	f := fingerprint(hash)
+	offset := bl.HeaderSize + fuseHeaderWidth
+	var buf [2]byte
+	for _, pos := range bl.Fuse.positions(hash) {
+		if _, err := bl.File.ReadAt(buf[:], offset+2*int64(pos)); err != nil {
+			fmt.Println("Read error:", err)
+			return false
+		}
+		f ^= binary.LittleEndian.Uint16(buf[:])
+	}
+	return f == 0
+}
+
 func (bl *Bloom) isMember(tester *bitChecker) bool {
 	for _, bit := range tester.whichBits {
 		tester.bit = bit
@@ -33,6 +33,14 @@ func (bl *Bloom) Read(fileName string) (err error) {
 		return err
 	}
 
+	if bl.IsFuse() {
+		if err = bl.readFuseHeader(); err != nil {
+			return err
+		}
+		bl.Fuse.Fingerprints = make([]uint16, bl.Fuse.nFingerprints())
+		return binary.Read(bl.File, binary.LittleEndian, &bl.Fuse.Fingerprints)
+	}
+
 	if err = binary.Read(bl.File, binary.LittleEndian, &bl.Count); err != nil {
 		return err
 	}
@@ -76,8 +84,8 @@ func (bl *Bloom) readHeader(check bool) error {
 	// Set HeaderSize.
 	bl.HeaderSize = int64(unsafe.Sizeof(bl.Header))
 
-	// Validate hash against provided tag.
-	if check {
+	// Validate hash against provided tag (binary fuse filters carry their own version).
+	if check && !bl.IsFuse() {
 		if bl.Header.Hash != base.BytesToHash(config.HeaderHash(config.ExpectedVersion())) {
 			return fmt.Errorf("Bloom.readHeader: %w %x %x", ErrIncorrectHash, bl.Header.Hash, base.BytesToHash(config.HeaderHash(config.ExpectedVersion())))
 		}
@@ -85,3 +93,23 @@ func (bl *Bloom) readHeader(check bool) error {
 
 	return nil
 }
+
+// readFuseHeader reads the header of a binary fuse filter (but not its fingerprints) into Bloom. The file
+// pointer must point to the end of the bloom file's header.
+func (bl *Bloom) readFuseHeader() error {
+	bl.Count = 0
+	bl.Blooms = nil
+	bl.Fuse = &binaryFuse{}
+	if err := binary.Read(bl.File, binary.LittleEndian, &bl.Fuse.fuseHeader); err != nil {
+		return err
+	}
+	info, err := bl.File.Stat()
+	if err != nil {
+		return err
+	}
+	if err = bl.Fuse.validate(info.Size() - bl.HeaderSize); err != nil {
+		return fmt.Errorf("Bloom.readFuseHeader: %w", err)
+	}
+	bl.Fuse.setDerived()
+	return nil
+}
@@ -12,10 +12,11 @@ import (
 
 // writeBloom writes a single Bloom filter to file. We do not make a backup copy of the file
 // because the caller is responsible for that. This is because the caller may be writing the
-// entire chunk (both Bloom and Index) and we want either both to succeed or both to fail.
+// entire chunk (both Bloom and Index) and we want either both to succeed or both to fail. If
+// the Bloom carries a binary fuse filter, that is written in place of the bloom filter.
 func (bl *Bloom) writeBloom(fileName string) ( /* changed */ bool, error) {
 	var err error
-	if bl.File, err = os.OpenFile(fileName, os.O_RDWR|os.O_CREATE, 0644); err == nil {
+	if bl.File, err = os.OpenFile(fileName, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644); err == nil {
 		defer func() {
 			bl.File.Close()
 			bl.File = nil
@@ -24,11 +25,24 @@ func (bl *Bloom) writeBloom(fileName string) ( /* changed */ bool, error) {
 		_, _ = bl.File.Seek(0, io.SeekStart) // already true, but can't hurt
 		bl.Header.Magic = file.SmallMagicNumber
 		bl.Header.Hash = base.BytesToHash(config.HeaderHash(config.ExpectedVersion()))
+		if bl.Fuse != nil {
+			bl.Header.Hash = base.BytesToHash(config.HeaderHash(config.FuseVersion))
+		}
 
 		if err = binary.Write(bl.File, binary.LittleEndian, bl.Header); err != nil {
 			return false, err
 		}
 
+		if bl.Fuse != nil {
+			if err = binary.Write(bl.File, binary.LittleEndian, bl.Fuse.fuseHeader); err != nil {
+				return false, err
+			}
+			if err = binary.Write(bl.File, binary.LittleEndian, bl.Fuse.Fingerprints); err != nil {
+				return false, err
+			}
+			return true, nil
+		}
+
 		if err = binary.Write(bl.File, binary.LittleEndian, bl.Count); err != nil {
 			return false, err
 		}
@@ -48,8 +62,13 @@ func (bl *Bloom) writeBloom(fileName string) ( /* changed */ bool, error) {
 	return false, nil
 }
 
-// updateTag writes a the header back to the bloom file
+// updateTag writes a the header back to the bloom file. Binary fuse filters are left as they
+// are because their header identifies their format.
 func (bl *Bloom) updateTag(tag, fileName string) error {
+	if bl.IsFuse() {
+		return nil
+	}
+
 	var err error
 	if bl.File, err = os.OpenFile(fileName, os.O_RDWR, 0644); err != nil {
 		return err
@@ -19,7 +19,6 @@ import (
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
-	shell "github.com/ipfs/go-ipfs-api"
 )
 
 // The Chunk data structure consists of three parts. A FileRange, a Index structure, and a Bloom that
@@ -75,7 +74,7 @@ func (chunk *Chunk) Close() {
 	}
 }
 
-// ChunkCid returns IPFS CID for the chunk without uploading it
+// ChunkCid returns IPFS CID for the chunk without uploading it (or needing an IPFS daemon)
 func ChunkCid(path string) (chunkCid string, err error) {
 	file, err := os.OpenFile(path, os.O_RDONLY, 0)
 	if err != nil {
@@ -86,6 +85,9 @@ func ChunkCid(path string) (chunkCid string, err error) {
 }
 
 func calculateCid(r io.Reader) (chunkCid string, err error) {
-	sh := shell.NewShell(config.GetPinning().LocalPinUrl)
-	return sh.AddNoPin(r)
+	w := newCidWriter()
+	if _, err = io.Copy(w, r); err != nil {
+		return
+	}
+	return w.Sum(), nil
 }
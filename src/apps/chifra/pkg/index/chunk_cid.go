@@ -0,0 +1,144 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package index
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+
+	"github.com/ipfs/go-cid"
+)
+
+// The CIDs found in the manifest are those IPFS assigns to a file added with its default settings.
+// The file is cut into 256KiB pieces, each piece is wrapped in a UnixFS leaf node, and the leaves
+// are joined into a balanced tree of nodes with at most 174 links each. The CID (version 0) is the
+// sha256 multihash of the root node. cidWriter calculates the same CID without an IPFS daemon.
+
+const (
+	cidChunkSize = 256 * 1024
+	cidMaxLinks  = 174
+)
+
+// cidNode carries what a parent needs to know about one of its children
+type cidNode struct {
+	hash     []byte // the multihash of the encoded node
+	fileSize uint64 // the number of bytes of the file below the node
+	dagSize  uint64 // the encoded size of the node and all of its descendants
+}
+
+// cidWriter is an io.Writer that calculates the IPFS CID of the bytes written to it
+type cidWriter struct {
+	buf    []byte
+	leaves []cidNode
+}
+
+func newCidWriter() *cidWriter {
+	return &cidWriter{buf: make([]byte, 0, cidChunkSize)}
+}
+
+func (w *cidWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		take := cidChunkSize - len(w.buf)
+		if take > len(p) {
+			take = len(p)
+		}
+		w.buf = append(w.buf, p[:take]...)
+		p = p[take:]
+		if len(w.buf) == cidChunkSize {
+			w.leaves = append(w.leaves, newCidLeaf(w.buf))
+			w.buf = w.buf[:0]
+		}
+	}
+	return n, nil
+}
+
+// Sum returns the CID of the bytes written. It should be called only once, after the last write.
+func (w *cidWriter) Sum() string {
+	if len(w.buf) > 0 {
+		w.leaves = append(w.leaves, newCidLeaf(w.buf))
+	} else if len(w.leaves) == 0 {
+		w.leaves = append(w.leaves, newCidLeaf(nil))
+	}
+
+	level := w.leaves
+	for len(level) > 1 {
+		next := make([]cidNode, 0, (len(level)+cidMaxLinks-1)/cidMaxLinks)
+		for i := 0; i < len(level); i += cidMaxLinks {
+			end := i + cidMaxLinks
+			if end > len(level) {
+				end = len(level)
+			}
+			next = append(next, newCidParent(level[i:end]))
+		}
+		level = next
+	}
+
+	return cid.NewCidV0(level[0].hash).String()
+}
+
+// newCidLeaf returns the node holding a piece of the file
+func newCidLeaf(data []byte) cidNode {
+	unixfs := appendVarintField(nil, 1, 2 /* file */)
+	if data != nil {
+		unixfs = appendBytesField(unixfs, 2, data)
+	}
+	unixfs = appendVarintField(unixfs, 3, uint64(len(data)))
+
+	encoded := appendBytesField(nil, 1, unixfs)
+	return cidNode{
+		hash:     cidHash(encoded),
+		fileSize: uint64(len(data)),
+		dagSize:  uint64(len(encoded)),
+	}
+}
+
+// newCidParent returns the node linking to the given children
+func newCidParent(children []cidNode) cidNode {
+	fileSize, dagSize := uint64(0), uint64(0)
+	for _, child := range children {
+		fileSize += child.fileSize
+		dagSize += child.dagSize
+	}
+
+	unixfs := appendVarintField(nil, 1, 2 /* file */)
+	unixfs = appendVarintField(unixfs, 3, fileSize)
+	for _, child := range children {
+		unixfs = appendVarintField(unixfs, 4, child.fileSize)
+	}
+
+	// The links precede the data in the encoding and each carries an empty name
+	encoded := []byte{}
+	for _, child := range children {
+		link := appendBytesField(nil, 1, child.hash)
+		link = appendBytesField(link, 2, []byte{})
+		link = appendVarintField(link, 3, child.dagSize)
+		encoded = appendBytesField(encoded, 2, link)
+	}
+	encoded = appendBytesField(encoded, 1, unixfs)
+
+	return cidNode{
+		hash:     cidHash(encoded),
+		fileSize: fileSize,
+		dagSize:  dagSize + uint64(len(encoded)),
+	}
+}
+
+// cidHash returns the sha256 multihash of the encoded node
+func cidHash(encoded []byte) []byte {
+	sum := sha256.Sum256(encoded)
+	return append([]byte{0x12, 0x20}, sum[:]...)
+}
+
+func appendVarintField(buf []byte, field int, value uint64) []byte {
+	buf = binary.AppendUvarint(buf, uint64(field<<3))
+	return binary.AppendUvarint(buf, value)
+}
+
+func appendBytesField(buf []byte, field int, value []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(field<<3|2))
+	buf = binary.AppendUvarint(buf, uint64(len(value)))
+	return append(buf, value...)
+}
@@ -1,18 +1,50 @@
 package index
 
-// import (
-// 	"strings"
-// 	"testing"
-// )
-
-// func Test_calculateCid(t *testing.T) {
-// 	r := strings.NewReader("hello world")
-// 	cid, err := calculateCid(r)
-// 	if err != nil {
-// 		t.Fatal(err)
-// 	}
-
-// 	if cid != "Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD" {
-// 		t.Fatal("wrong CID:", cid)
-// 	}
-// }
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func Test_calculateCid(t *testing.T) {
+	r := strings.NewReader("hello world")
+	cid, err := calculateCid(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cid != "Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD" {
+		t.Fatal("wrong CID:", cid)
+	}
+}
+
+func Test_calculateCidEmpty(t *testing.T) {
+	cid, err := calculateCid(strings.NewReader(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cid != "QmbFMke1KXqnYyBBWxB74N4c5SBnJMVAiMNRcGu6x1AwQH" {
+		t.Fatal("wrong CID:", cid)
+	}
+}
+
+// Test_calculateCidMultiBlock uses the same data as IPFS's own test of its importer (TestStableCid),
+// ten megabytes that are split into forty leaves below a single root.
+func Test_calculateCidMultiBlock(t *testing.T) {
+	buf := make([]byte, 10*1024*1024)
+	rnd := rand.New(rand.NewSource(0xdeadbeef))
+	for i := range buf {
+		buf[i] = byte(rnd.Intn(255))
+	}
+
+	cid, err := calculateCid(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cid != "QmZN1qquw84zhV4j6vT56tCcmFxaDaySL1ezTXFvMdNmrK" {
+		t.Fatal("wrong CID:", cid)
+	}
+}
@@ -32,13 +32,21 @@ func (c *writeReport) Report() string {
 	return colors.ColoredWith(fmt.Sprintf(report, c.nAddresses, c.nAppearances, c.Range, c.FileSize, c.Range.Span()), colors.BrightBlue)
 }
 
-func (chunk *Chunk) Write(chain string, publisher base.Address, fileName string, addrAppearanceMap map[string][]types.AppRecord, nApps int) (*writeReport, error) {
+// Write writes the chunk's index and bloom files. If the chain's scrape settings call for reasons and
+// addrReasonMap is not nil, the index carries the reasons for each appearance (in the same order as the
+// appearances) following the appearance table. If the chain's scrape settings call for it, the bloom
+// file carries a binary fuse filter.
+func (chunk *Chunk) Write(chain string, publisher base.Address, fileName string, addrAppearanceMap map[string][]types.AppRecord, addrReasonMap map[string][]types.Reason, nApps int) (*writeReport, error) {
 	// We're going to build two tables. An addressTable and an appearanceTable. We do this as we spin
 	// through the map
 
-	// Create space for the two tables...
+	// Create space for the two tables (and the reasons if we have them)...
 	addressTable := make([]types.AddrRecord, 0, len(addrAppearanceMap))
 	appearanceTable := make([]types.AppRecord, 0, nApps)
+	var reasonTable []types.Reason
+	if addrReasonMap != nil && config.GetScrape(chain).Reasons {
+		reasonTable = make([]types.Reason, 0, nApps)
+	}
 
 	// We want to sort the items in the map by address (maps in GoLang are not sorted)
 	sorted := []string{}
@@ -52,16 +60,22 @@ func (chunk *Chunk) Write(chain string, publisher base.Address, fileName string,
 	// We need somewhere to store our progress...
 	offset := uint32(0)
 	bl := Bloom{}
+	useFuse := config.GetScrape(chain).BloomFormat == BloomFormatFuse
 
 	// For each address in the sorted list...
 	for _, addrStr := range sorted {
 		// ...get its appearances and append them to the appearanceTable....
 		apps := addrAppearanceMap[addrStr]
 		appearanceTable = append(appearanceTable, apps...)
+		if reasonTable != nil {
+			reasonTable = append(reasonTable, alignReasons(addrReasonMap[addrStr], len(apps))...)
+		}
 
 		// ...add the address to the bloom filter...
 		address := base.HexToAddress(addrStr)
-		bl.InsertAddress(address)
+		if !useFuse {
+			bl.InsertAddress(address)
+		}
 
 		// ...and append the record to the addressTable.
 		addressTable = append(addressTable, types.AddrRecord{
@@ -74,6 +88,18 @@ func (chunk *Chunk) Write(chain string, publisher base.Address, fileName string,
 		offset += uint32(len(apps))
 	}
 
+	// ...or, if we're writing a binary fuse filter, build it from the full list of addresses.
+	if useFuse {
+		addrs := make([]base.Address, 0, len(addressTable))
+		for _, addrRecord := range addressTable {
+			addrs = append(addrs, addrRecord.Address)
+		}
+		var err error
+		if bl.Fuse, err = newBinaryFuse(addrs); err != nil {
+			return nil, err
+		}
+	}
+
 	// At this point, the two tables and the bloom filter are fully populated. We're ready to write to disc...
 
 	// First, we backup the existing chunk if there is one...
@@ -84,29 +110,11 @@ func (chunk *Chunk) Write(chain string, publisher base.Address, fileName string,
 			backup.Restore()
 		}()
 
-		if fp, err := os.OpenFile(indexFn, os.O_WRONLY|os.O_CREATE, 0644); err == nil {
+		if fp, err := os.OpenFile(indexFn, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644); err == nil {
 			// defer fp.Close() // Note -- we don't defer because we want to close the file and possibly pin it below...
 
-			_, _ = fp.Seek(0, io.SeekStart) // already true, but can't hurt
-			header := indexHeader{
-				Magic:           file.MagicNumber,
-				Hash:            base.BytesToHash(config.HeaderHash(config.ExpectedVersion())),
-				AddressCount:    uint32(len(addressTable)),
-				AppearanceCount: uint32(len(appearanceTable)),
-			}
-			if err = binary.Write(fp, binary.LittleEndian, header); err != nil {
-				return nil, err
-			}
-
-			if err = binary.Write(fp, binary.LittleEndian, addressTable); err != nil {
-				return nil, err
-			}
-
-			if err = binary.Write(fp, binary.LittleEndian, appearanceTable); err != nil {
-				return nil, err
-			}
-
-			if err := fp.Sync(); err != nil {
+			if err = writeIndex(fp, addressTable, appearanceTable, reasonTable); err != nil {
+				fp.Close()
 				return nil, err
 			}
 
@@ -138,13 +146,60 @@ func (chunk *Chunk) Write(chain string, publisher base.Address, fileName string,
 	}
 }
 
-// Tag updates the manifest version in the chunk's header
+// writeIndex writes the header and the tables of an index file. If reasonTable is not nil, it is
+// written following the appearance table and the header carries the reasons version.
+func writeIndex(fp *os.File, addressTable []types.AddrRecord, appearanceTable []types.AppRecord, reasonTable []types.Reason) error {
+	version := config.ExpectedVersion()
+	if reasonTable != nil {
+		version = config.ReasonsVersion
+	}
+
+	_, _ = fp.Seek(0, io.SeekStart) // already true, but can't hurt
+	header := indexHeader{
+		Magic:           file.MagicNumber,
+		Hash:            base.BytesToHash(config.HeaderHash(version)),
+		AddressCount:    uint32(len(addressTable)),
+		AppearanceCount: uint32(len(appearanceTable)),
+	}
+	if err := binary.Write(fp, binary.LittleEndian, header); err != nil {
+		return err
+	}
+
+	if err := binary.Write(fp, binary.LittleEndian, addressTable); err != nil {
+		return err
+	}
+
+	if err := binary.Write(fp, binary.LittleEndian, appearanceTable); err != nil {
+		return err
+	}
+
+	if reasonTable != nil {
+		if err := binary.Write(fp, binary.LittleEndian, reasonTable); err != nil {
+			return err
+		}
+	}
+
+	return fp.Sync()
+}
+
+// alignReasons returns exactly one reason for each of an address's nApps appearances. Missing reasons are unknown.
+func alignReasons(reasons []types.Reason, nApps int) []types.Reason {
+	if len(reasons) == nApps {
+		return reasons
+	}
+	ret := make([]types.Reason, nApps)
+	copy(ret, reasons)
+	return ret
+}
+
+// Tag updates the manifest version in the chunk's header. Index files that carry reasons keep the
+// reasons version, which identifies their format.
 func (chunk *Chunk) Tag(tag, fileName string) (err error) {
 	blVers, idxVers, err := versions(fileName)
 	if err != nil {
 		return err
 	}
-	if blVers == tag && idxVers == tag {
+	if blVers == tag && (idxVers == tag || idxVers == config.ReasonsVersion) {
 		return nil
 	}
 
@@ -20,6 +20,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/colors"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/debug"
@@ -36,10 +37,13 @@ import (
 // jobResult type is used to carry both downloaded data and some
 // metadata to decompressing/file writing function through a channel
 type jobResult struct {
-	rng      string
-	fileSize int64
-	contents io.Reader
-	theChunk *types.ChunkRecord
+	rng        string
+	fileSize   int64
+	contents   io.Reader
+	theChunk   *types.ChunkRecord
+	hash       base.IpfsHash
+	gateway    string
+	alternates []string
 }
 
 type progressChan chan<- *progress.ProgressMsg
@@ -50,6 +54,7 @@ var ErrFailedLocalFileRemoval = errors.New("failed to remove local file")
 var ErrUserHitControlC = errors.New("user hit control + c")
 var ErrDownloadError = errors.New("download error")
 var ErrWriteToDiscError = errors.New("write to disc error")
+var ErrCidMismatch = errors.New("downloaded file does not match its CID")
 
 // WorkerArguments are types meant to hold worker function arguments. We cannot
 // pass the arguments directly, because a worker function is expected to take one
@@ -57,7 +62,7 @@ var ErrWriteToDiscError = errors.New("write to disc error")
 type downloadWorkerArguments struct {
 	ctx             context.Context
 	progressChannel progressChan
-	gatewayUrl      string
+	gateways        *gatewayPool
 	downloadWg      *sync.WaitGroup
 	writeChannel    chan *jobResult
 	nRetries        int
@@ -106,7 +111,10 @@ func getDownloadWorker(chain string, workerArgs downloadWorkerArguments, chunkTy
 					Message: msg,
 				}
 
-				download, err := fetchFromIpfsGateway(workerArgs.ctx, workerArgs.gatewayUrl, hash.String())
+				download, gw, err := workerArgs.gateways.fetch(workerArgs.ctx, hash.String())
+				if err == nil && workerArgs.ctx.Err() != nil {
+					download.Body.Close()
+				}
 				if errors.Is(workerArgs.ctx.Err(), context.Canceled) {
 					// The request to fetch the chunk was cancelled, because user has
 					// pressed Ctrl-C
@@ -127,10 +135,13 @@ func getDownloadWorker(chain string, workerArgs downloadWorkerArguments, chunkTy
 				}
 				if err == nil {
 					workerArgs.writeChannel <- &jobResult{
-						rng:      chunk.Range,
-						fileSize: download.ContentLen,
-						contents: download.Body,
-						theChunk: &chunk,
+						rng:        chunk.Range,
+						fileSize:   download.ContentLen,
+						contents:   download.Body,
+						theChunk:   &chunk,
+						hash:       hash,
+						gateway:    gw.url,
+						alternates: alternatesTo(workerArgs.gateways.urls(), gw.url),
 					}
 				} else {
 					progressChannel <- &progress.ProgressMsg{
@@ -144,6 +155,17 @@ func getDownloadWorker(chain string, workerArgs downloadWorkerArguments, chunkTy
 	}
 }
 
+// alternatesTo returns the gateways, other than the one used, to try if the download is bad
+func alternatesTo(urls []string, used string) []string {
+	ret := make([]string, 0, len(urls))
+	for _, url := range urls {
+		if url != used {
+			ret = append(ret, url)
+		}
+	}
+	return ret
+}
+
 // fetchResult type make it easier to return both download content and
 // download size information (for validation purposes)
 type fetchResult struct {
@@ -198,6 +220,9 @@ func getWriteWorker(chain string, workerArgs writeWorkerArguments, chunkType wal
 
 		select {
 		case <-workerArgs.ctx.Done():
+			if closer, ok := res.contents.(io.Closer); ok {
+				closer.Close()
+			}
 			return
 		default:
 			cleanOnQuit := func() {
@@ -205,6 +230,22 @@ func getWriteWorker(chain string, workerArgs writeWorkerArguments, chunkType wal
 			}
 			trapChannel := sigintTrap.Enable(workerArgs.ctx, workerArgs.cancel, cleanOnQuit)
 			err := writeBytesToDisc(chain, chunkType, res)
+			for errors.Is(err, ErrCidMismatch) && len(res.alternates) > 0 && workerArgs.ctx.Err() == nil {
+				// The gateway served the wrong bytes. They are quarantined, so we try the next gateway
+				progressChannel <- &progress.ProgressMsg{
+					Payload: res.theChunk,
+					Event:   progress.Quarantined,
+					Message: err.Error(),
+				}
+				res.gateway, res.alternates = res.alternates[0], res.alternates[1:]
+				download, fetchErr := fetchFromIpfsGateway(workerArgs.ctx, res.gateway, res.hash.String())
+				if fetchErr != nil {
+					err = fmt.Errorf("%w [%s]", ErrDownloadError, fetchErr.Error())
+					break
+				}
+				res.contents, res.fileSize = download.Body, download.ContentLen
+				err = writeBytesToDisc(chain, chunkType, res)
+			}
 			sigintTrap.Disable(trapChannel)
 			if errors.Is(workerArgs.ctx.Err(), context.Canceled) {
 				// Ctrl-C was pressed, cancel
@@ -212,10 +253,19 @@ func getWriteWorker(chain string, workerArgs writeWorkerArguments, chunkType wal
 			}
 
 			if err != nil {
+				if errors.Is(err, ErrCidMismatch) {
+					progressChannel <- &progress.ProgressMsg{
+						Payload: res.theChunk,
+						Event:   progress.Quarantined,
+						Message: err.Error(),
+					}
+				} else if !errors.Is(err, ErrDownloadError) {
+					err = fmt.Errorf("%w [%s]", ErrWriteToDiscError, err.Error())
+				}
 				progressChannel <- &progress.ProgressMsg{
 					Payload: res.theChunk,
 					Event:   progress.Error,
-					Error:   fmt.Errorf("%w [%s]", ErrWriteToDiscError, err.Error()),
+					Error:   err,
 				}
 				return
 			}
@@ -238,17 +288,25 @@ func DownloadChunks(chain string, chunksToDownload []types.ChunkRecord, chunkTyp
 		cancel()
 	}()
 
+	// Each gateway serves at most its own number of downloads at a time, so there is one download
+	// worker for each of the gateways' slots
+	gateways := newGatewayPool(config.GetIpfsGateways(chain), poolSize)
+	go func() {
+		<-ctx.Done()
+		gateways.wake()
+	}()
+
 	var downloadWg sync.WaitGroup
 	writeChannel := make(chan *jobResult, poolSize)
 	downloadWorkerArgs := downloadWorkerArguments{
 		ctx:             ctx,
 		progressChannel: progressChannel,
 		downloadWg:      &downloadWg,
-		gatewayUrl:      config.GetChain(chain).IpfsGateway,
+		gateways:        gateways,
 		writeChannel:    writeChannel,
 		nRetries:        8,
 	}
-	downloadPool, err := ants.NewPoolWithFunc(poolSize, getDownloadWorker(chain, downloadWorkerArgs, chunkType))
+	downloadPool, err := ants.NewPoolWithFunc(gateways.size(), getDownloadWorker(chain, downloadWorkerArgs, chunkType))
 	defer downloadPool.Release()
 	if err != nil {
 		panic(err)
@@ -274,6 +332,9 @@ func DownloadChunks(chain string, chunksToDownload []types.ChunkRecord, chunkTyp
 			if ctx.Err() != nil {
 				// The user hit Ctrl-C. It may have been disabled by sigintTrap, so we
 				// must drain the channel. Otherwise, it will deadlock
+				if closer, ok := result.contents.(io.Closer); ok {
+					closer.Close()
+				}
 				continue
 			}
 
@@ -313,13 +374,26 @@ func writeBytesToDisc(chain string, chunkType walk.CacheType, res *jobResult) er
 	if chunkType == walk.Index_Bloom {
 		fullPath = ToBloomPath(fullPath)
 	}
-	outputFile, err := os.OpenFile(fullPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+	return writeVerified(fullPath, filepath.Join(config.PathToIndex(chain), "quarantine"), res)
+}
+
+// writeVerified writes the downloaded bytes to a temporary file and moves the file into place only
+// if its CID matches the one in the manifest. Otherwise, the file is moved to the quarantine folder
+// (so it may be inspected later) and ErrCidMismatch is returned.
+func writeVerified(fullPath, quarantineFolder string, res *jobResult) error {
+	if closer, ok := res.contents.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	tmpPath := fullPath + ".download"
+	outputFile, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
 	if err != nil {
 		return fmt.Errorf("error creating output file file %s in writeBytesToDisc: [%s]", res.rng, err)
 	}
 
-	// Save downloaded bytes to a file
-	_, err = io.Copy(outputFile, res.contents)
+	// Save downloaded bytes to a file while calculating their CID
+	cw := newCidWriter()
+	_, err = io.Copy(io.MultiWriter(outputFile, cw), res.contents)
 	if err != nil {
 		if file.FileExists(outputFile.Name()) {
 			outputFile.Close()
@@ -334,9 +408,21 @@ func writeBytesToDisc(chain string, chunkType walk.CacheType, res *jobResult) er
 		// https://community.k6.io/t/warn-0040-request-failed-error-stream-error-stream-id-3-internal-error/777/2
 		return fmt.Errorf("error copying %s file in writeBytesToDisc: [%s]", res.rng, err)
 	}
-
 	outputFile.Close()
-	return nil
+
+	if cid := cw.Sum(); cid != res.hash.String() {
+		quarantinePath := filepath.Join(quarantineFolder, filepath.Base(fullPath)+"."+cid)
+		if err := os.MkdirAll(quarantineFolder, 0755); err != nil {
+			os.Remove(tmpPath)
+			quarantinePath = "removed"
+		} else if err := os.Rename(tmpPath, quarantinePath); err != nil {
+			os.Remove(tmpPath)
+			quarantinePath = "removed"
+		}
+		return fmt.Errorf("%w: %s from %s has CID %s, expected %s [%s]", ErrCidMismatch, filepath.Base(fullPath), res.gateway, cid, res.hash, quarantinePath)
+	}
+
+	return os.Rename(tmpPath, fullPath)
 }
 
 func removeLocalFile(fullPath, reason string, progressChannel progressChan) bool {
@@ -4,6 +4,50 @@
 
 package index
 
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+)
+
+func TestWriteVerified(t *testing.T) {
+	dir := t.TempDir()
+	fullPath := filepath.Join(dir, "000000001-000000002.bin")
+	quarantine := filepath.Join(dir, "quarantine")
+
+	res := &jobResult{
+		rng:      "000000001-000000002",
+		contents: strings.NewReader("hello world!"),
+		hash:     "Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD", // the CID of "hello world"
+		gateway:  "https://bad.gateway/ipfs/",
+	}
+	err := writeVerified(fullPath, quarantine, res)
+	if !errors.Is(err, ErrCidMismatch) {
+		t.Fatal("expected a CID mismatch, got", err)
+	}
+	if file.FileExists(fullPath) || file.FileExists(fullPath+".download") {
+		t.Fatal("a file that failed verification was left in the index")
+	}
+	if entries, _ := os.ReadDir(quarantine); len(entries) != 1 {
+		t.Fatal("expected one quarantined file, got", len(entries))
+	}
+
+	res.contents = strings.NewReader("hello world")
+	if err := writeVerified(fullPath, quarantine, res); err != nil {
+		t.Fatal(err)
+	}
+	if contents := file.AsciiFileToString(fullPath); contents != "hello world" {
+		t.Fatal("wrong contents:", contents)
+	}
+	if file.FileExists(fullPath + ".download") {
+		t.Fatal("the temporary file was not removed")
+	}
+}
+
 // TODO: BOGUS TEST
 // func Test_exclude(t *testing.T) {
 // 	onDisc := map[string]bool{
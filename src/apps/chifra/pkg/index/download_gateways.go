@@ -0,0 +1,171 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package index
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/configtypes"
+)
+
+// gatewayDownAfter is the number of failed downloads in a row after which a gateway is used only
+// if every other gateway has also failed
+const gatewayDownAfter = 3
+
+// gateway is an IPFS gateway along with the number of downloads it is currently serving
+type gateway struct {
+	url      string
+	limit    int
+	inUse    int
+	failures int
+}
+
+// gatewayPool hands out gateways to the download workers. Each gateway serves at most its limit of
+// downloads at a time. Workers get the most preferred gateway with a free slot, so a slow gateway
+// holds on to its slots longer and the faster ones pick up more of the work.
+type gatewayPool struct {
+	mutex    sync.Mutex
+	cond     *sync.Cond
+	gateways []*gateway
+}
+
+// newGatewayPool returns a pool of the given gateways. Gateways without a limit of their own
+// serve at most defaultLimit downloads at a time.
+func newGatewayPool(gateways []configtypes.IpfsGateway, defaultLimit int) *gatewayPool {
+	pool := &gatewayPool{}
+	pool.cond = sync.NewCond(&pool.mutex)
+	for _, gw := range gateways {
+		limit := int(gw.Concurrency)
+		if limit <= 0 {
+			limit = defaultLimit
+		}
+		pool.gateways = append(pool.gateways, &gateway{url: gw.Url, limit: limit})
+	}
+	return pool
+}
+
+// size returns the number of downloads all of the gateways may serve at once
+func (p *gatewayPool) size() int {
+	ret := 0
+	for _, gw := range p.gateways {
+		ret += gw.limit
+	}
+	return ret
+}
+
+// urls returns the urls of the gateways in order of preference
+func (p *gatewayPool) urls() []string {
+	ret := make([]string, 0, len(p.gateways))
+	for _, gw := range p.gateways {
+		ret = append(ret, gw.url)
+	}
+	return ret
+}
+
+// acquire returns the most preferred gateway, not yet tried, that has a free slot. Gateways that
+// have failed repeatedly are used only if none of the others remain. If every remaining gateway is
+// busy, acquire waits for one to be released. It returns nil if every gateway has been tried or if
+// the context is canceled.
+func (p *gatewayPool) acquire(ctx context.Context, tried map[*gateway]bool) *gateway {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		var healthy, failing []*gateway
+		for _, gw := range p.gateways {
+			if tried[gw] {
+				continue
+			} else if gw.failures < gatewayDownAfter {
+				healthy = append(healthy, gw)
+			} else {
+				failing = append(failing, gw)
+			}
+		}
+
+		candidates := healthy
+		if len(candidates) == 0 {
+			candidates = failing
+		}
+		if len(candidates) == 0 {
+			return nil
+		}
+
+		for _, gw := range candidates {
+			if gw.inUse < gw.limit {
+				gw.inUse++
+				return gw
+			}
+		}
+
+		p.cond.Wait()
+	}
+}
+
+// release frees the gateway's slot and records whether the download succeeded
+func (p *gatewayPool) release(gw *gateway, failed bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	gw.inUse--
+	if failed {
+		gw.failures++
+	} else {
+		gw.failures = 0
+	}
+	p.cond.Broadcast()
+}
+
+// wake rouses any workers waiting in acquire (so they may notice a canceled context)
+func (p *gatewayPool) wake() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.cond.Broadcast()
+}
+
+// fetch downloads the hash from the most preferred available gateway, falling back to the others
+// in order if a gateway fails. The gateway's slot is held until the returned body is closed.
+func (p *gatewayPool) fetch(ctx context.Context, hash string) (*fetchResult, *gateway, error) {
+	tried := map[*gateway]bool{}
+	var lastErr error
+	for {
+		gw := p.acquire(ctx, tried)
+		if gw == nil {
+			if lastErr == nil {
+				lastErr = ctx.Err()
+			}
+			return nil, nil, lastErr
+		}
+		tried[gw] = true
+
+		download, err := fetchFromIpfsGateway(ctx, gw.url, hash)
+		if err != nil {
+			p.release(gw, ctx.Err() == nil)
+			lastErr = err
+			continue
+		}
+
+		download.Body = &releasingBody{ReadCloser: download.Body, release: func() { p.release(gw, false) }}
+		return download, gw, nil
+	}
+}
+
+// releasingBody releases its gateway's slot when the body is closed
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package index
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/configtypes"
+)
+
+func TestGatewayPoolFallback(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusBadGateway)
+	}))
+	defer down.Close()
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer up.Close()
+
+	pool := newGatewayPool([]configtypes.IpfsGateway{
+		{Url: down.URL + "/ipfs/", Concurrency: 1},
+		{Url: up.URL + "/ipfs/"},
+	}, 2)
+	if pool.size() != 3 {
+		t.Fatal("wrong pool size", pool.size())
+	}
+
+	ctx := context.Background()
+	for i := 0; i < gatewayDownAfter+1; i++ {
+		download, gw, err := pool.fetch(ctx, "QmHash")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gw.url != up.URL+"/ipfs/" {
+			t.Fatal("expected the working gateway, got", gw.url)
+		}
+		body, _ := io.ReadAll(download.Body)
+		download.Body.Close()
+		if string(body) != "/ipfs/QmHash" {
+			t.Fatal("wrong body", string(body))
+		}
+	}
+
+	// The failing gateway is now passed over and every slot has been released
+	if pool.gateways[0].failures != gatewayDownAfter || pool.gateways[0].inUse != 0 || pool.gateways[1].inUse != 0 {
+		t.Fatal("wrong gateway state", pool.gateways[0], pool.gateways[1])
+	}
+}
+
+func TestGatewayPoolBusy(t *testing.T) {
+	pool := newGatewayPool([]configtypes.IpfsGateway{{Url: "a", Concurrency: 1}, {Url: "b", Concurrency: 1}}, 4)
+	ctx := context.Background()
+
+	// The preferred gateway is used until it is busy
+	first := pool.acquire(ctx, map[*gateway]bool{})
+	second := pool.acquire(ctx, map[*gateway]bool{})
+	if first.url != "a" || second.url != "b" {
+		t.Fatal("wrong gateways", first.url, second.url)
+	}
+
+	// With every gateway busy, acquire waits for a release
+	got := make(chan *gateway)
+	go func() {
+		got <- pool.acquire(ctx, map[*gateway]bool{})
+	}()
+	pool.release(second, false)
+	if gw := <-got; gw.url != "b" {
+		t.Fatal("expected the released gateway, got", gw.url)
+	}
+
+	// Once every gateway is tried there is nothing left to acquire
+	if gw := pool.acquire(ctx, map[*gateway]bool{first: true, second: true}); gw != nil {
+		t.Fatal("expected no gateway, got", gw.url)
+	}
+
+	// A canceled context stops the wait
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if gw := pool.acquire(canceled, map[*gateway]bool{}); gw != nil {
+		t.Fatal("expected no gateway after cancel, got", gw.url)
+	}
+}
@@ -44,7 +44,7 @@ func DownloadOneChunk(chain string, man *manifest.Manifest, fileRange base.FileR
 		case progress.Cancelled:
 			return nil
 		case progress.Error:
-			return fmt.Errorf("error while downloading: %s", event.Message)
+			return fmt.Errorf("error while downloading: %w", event.Error)
 		}
 	}
 	return nil
@@ -34,7 +34,7 @@ func (chunk *Index) readHeader(check bool) (indexHeader, error) {
 	}
 
 	if check { // check if told to do so
-		if header.Hash != base.BytesToHash(config.HeaderHash(config.ExpectedVersion())) {
+		if !config.IsExpectedHeader(header.Hash.Bytes()) {
 			return header, fmt.Errorf("Index.readHeader: %w %x %x", ErrIncorrectHash, header.Hash, base.BytesToHash(config.HeaderHash(config.ExpectedVersion())))
 		}
 	}
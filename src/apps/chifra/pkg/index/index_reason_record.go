@@ -0,0 +1,174 @@
+package index
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+const (
+	// ReasonWidth - size of a Reason Record
+	ReasonWidth = 2
+)
+
+// reasonTableStart returns the offset of the ReasonTable, which (if present) follows the AppearanceTable
+func (chunk *Index) reasonTableStart() int64 {
+	return int64(HeaderWidth) + int64(AddrRecordWidth)*int64(chunk.Header.AddressCount) + int64(AppRecordWidth)*int64(chunk.Header.AppearanceCount)
+}
+
+// HasReasons returns true if the Index carries a ReasonTable (with one reason for each appearance), which
+// its header identifies by the reasons version
+func (chunk *Index) HasReasons() bool {
+	return chunk.Header.Hash == base.BytesToHash(config.HeaderHash(config.ReasonsVersion))
+}
+
+// ReadReasons searches an already-opened Index for the given address and returns its appearances along with the
+// reason for each. If the Index carries no reasons, the reasons are all zero (unknown).
+func (chunk *Index) ReadReasons(address base.Address) ([]types.AppRecord, []types.Reason, error) {
+	foundAt := chunk.searchForAddressRecord(address)
+	if foundAt == -1 {
+		return nil, nil, nil
+	}
+
+	_, err := chunk.File.Seek(int64(HeaderWidth+(foundAt*AddrRecordWidth)), io.SeekStart)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	addressRecord := types.AddrRecord{}
+	if err := binary.Read(chunk.File, binary.LittleEndian, &addressRecord); err != nil {
+		return nil, nil, err
+	}
+
+	apps, err := chunk.readAppearanceRecords(&addressRecord)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reasons := make([]types.Reason, len(apps))
+	if !chunk.HasReasons() {
+		return apps, reasons, nil
+	}
+
+	readLocation := chunk.reasonTableStart() + int64(ReasonWidth)*int64(addressRecord.Offset)
+	if _, err = chunk.File.Seek(readLocation, io.SeekStart); err != nil {
+		return nil, nil, err
+	}
+	err = binary.Read(chunk.File, binary.LittleEndian, &reasons)
+	return apps, reasons, err
+}
+
+// SizeWithoutReasons returns the size of the index file as it was before reasons were added to it. For index
+// files without reasons, this is the size of the file.
+func SizeWithoutReasons(path string) int64 {
+	size := file.FileSize(path)
+	indexChunk, err := OpenIndex(path, false /* check */)
+	if err != nil {
+		return size
+	}
+	defer indexChunk.Close()
+
+	if indexChunk.HasReasons() {
+		return indexChunk.reasonTableStart()
+	}
+	return size
+}
+
+// SizeMatches returns true if the index file is of the expected size (as recorded in a manifest). An index
+// file whose header identifies it as carrying reasons also matches the size it had before they were added to
+// it, so chunks rewritten with reasons since they were downloaded still match the manifest they came from.
+func SizeMatches(path string, expected int64) bool {
+	return file.FileSize(path) == expected || SizeWithoutReasons(path) == expected
+}
+
+// ReasonFunc returns the reason the address appears at the given appearance
+type ReasonFunc func(address base.Address, app types.AppRecord) (types.Reason, error)
+
+// hasAllReasons returns true if the Index carries a ReasonTable in which every reason is known
+func (chunk *Index) hasAllReasons() bool {
+	if !chunk.HasReasons() {
+		return false
+	}
+
+	reasons := make([]types.Reason, chunk.Header.AppearanceCount)
+	if _, err := chunk.File.Seek(chunk.reasonTableStart(), io.SeekStart); err != nil {
+		return false
+	}
+	if err := binary.Read(chunk.File, binary.LittleEndian, &reasons); err != nil {
+		return false
+	}
+	for _, reason := range reasons {
+		if reason == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// RewriteWithReasons rewrites the index file of a chunk adding the reason (as returned by reasonFunc) for each of
+// its appearances. The addresses and appearances, and therefore the bloom filter, are unchanged. Index files that
+// already carry a known reason for every appearance are left as is, in which case it returns false.
+func RewriteWithReasons(chain, fileName string, reasonFunc ReasonFunc) (bool, error) {
+	indexFn := ToIndexPath(fileName)
+	indexChunk, err := OpenIndex(indexFn, true /* check */)
+	if err != nil {
+		return false, err
+	}
+
+	if indexChunk.hasAllReasons() {
+		indexChunk.Close()
+		return false, nil
+	}
+
+	addressTable := make([]types.AddrRecord, indexChunk.Header.AddressCount)
+	appearanceTable := make([]types.AppRecord, indexChunk.Header.AppearanceCount)
+	_, _ = indexChunk.File.Seek(HeaderWidth, io.SeekStart)
+	if err = binary.Read(indexChunk.File, binary.LittleEndian, &addressTable); err == nil {
+		err = binary.Read(indexChunk.File, binary.LittleEndian, &appearanceTable)
+	}
+	indexChunk.Close()
+	if err != nil {
+		return false, err
+	}
+
+	reasonTable := make([]types.Reason, len(appearanceTable))
+	for _, addrRecord := range addressTable {
+		for i := addrRecord.Offset; i < addrRecord.Offset+addrRecord.Count && int(i) < len(appearanceTable); i++ {
+			if reasonTable[i], err = reasonFunc(addrRecord.Address, appearanceTable[i]); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	tmpPath := filepath.Join(config.PathToCache(chain), "tmp")
+	backup, err := file.MakeBackup(tmpPath, indexFn)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		backup.Restore()
+	}()
+
+	fp, err := os.OpenFile(indexFn, os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return false, err
+	}
+
+	if err = writeIndex(fp, addressTable, appearanceTable, reasonTable); err != nil {
+		fp.Close()
+		return false, err
+	}
+
+	if err = fp.Close(); err != nil {
+		return false, err
+	}
+
+	backup.Clear()
+	return true, nil
+}
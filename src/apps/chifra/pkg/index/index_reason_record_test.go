@@ -0,0 +1,185 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+func writeTestIndex(t *testing.T, path string, reasonTable []types.Reason) ([]types.AddrRecord, []types.AppRecord) {
+	addressTable := []types.AddrRecord{
+		{Address: base.HexToAddress("0x1111111111111111111111111111111111111111"), Offset: 0, Count: 2},
+		{Address: base.HexToAddress("0x2222222222222222222222222222222222222222"), Offset: 2, Count: 1},
+	}
+	appearanceTable := []types.AppRecord{
+		{BlockNumber: 10, TransactionIndex: 1},
+		{BlockNumber: 12, TransactionIndex: 0},
+		{BlockNumber: 10, TransactionIndex: 1},
+	}
+
+	fp, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = writeIndex(fp, addressTable, appearanceTable, reasonTable); err != nil {
+		t.Fatal(err)
+	}
+	fp.Close()
+	return addressTable, appearanceTable
+}
+
+func TestIndexReasons(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "000000010-000000012.bin")
+	reasonTable := []types.Reason{types.ReasonFrom, types.ReasonTopic | types.ReasonTrace, types.ReasonTo}
+	addressTable, _ := writeTestIndex(t, path, reasonTable)
+
+	indexChunk, err := OpenIndex(path, false /* check */)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer indexChunk.Close()
+
+	if !indexChunk.HasReasons() {
+		t.Fatal("expected reasons")
+	}
+
+	apps, reasons, err := indexChunk.ReadReasons(addressTable[0].Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(apps) != 2 || len(reasons) != 2 {
+		t.Fatal("wrong number of appearances", len(apps), len(reasons))
+	}
+	if reasons[0] != types.ReasonFrom || reasons[1] != types.ReasonTopic|types.ReasonTrace {
+		t.Error("wrong reasons", reasons)
+	}
+
+	_, reasons, err = indexChunk.ReadReasons(addressTable[1].Address)
+	if err != nil || len(reasons) != 1 || reasons[0] != types.ReasonTo {
+		t.Error("wrong reasons for second address", reasons, err)
+	}
+
+	apps, _, err = indexChunk.ReadReasons(base.HexToAddress("0x3333333333333333333333333333333333333333"))
+	if err != nil || apps != nil {
+		t.Error("expected no appearances", apps, err)
+	}
+
+	want := int64(HeaderWidth + 2*AddrRecordWidth + 3*AppRecordWidth)
+	if got := SizeWithoutReasons(path); got != want {
+		t.Errorf("SizeWithoutReasons() = %d, want %d", got, want)
+	}
+}
+
+func TestIndexWithoutReasons(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "000000010-000000012.bin")
+	reasonTable := []types.Reason{0, 0, 0} // unknown reasons are written, but a nil table is not
+	writeTestIndex(t, path, reasonTable)
+
+	indexChunk, err := OpenIndex(path, false /* check */)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !indexChunk.HasReasons() || indexChunk.hasAllReasons() {
+		t.Error("expected a reason table with unknown reasons")
+	}
+	indexChunk.Close()
+
+	// Without a reason table, the index is in the older format
+	size := SizeWithoutReasons(path)
+	writeTestIndex(t, path, nil)
+	if got := file.FileSize(path); got != size {
+		t.Errorf("FileSize() = %d, want %d", got, size)
+	}
+	if got := SizeWithoutReasons(path); got != size {
+		t.Errorf("SizeWithoutReasons() = %d, want %d", got, size)
+	}
+
+	indexChunk, err = OpenIndex(path, false /* check */)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer indexChunk.Close()
+	if indexChunk.HasReasons() {
+		t.Fatal("expected no reasons")
+	}
+	apps, reasons, err := indexChunk.ReadReasons(base.HexToAddress("0x1111111111111111111111111111111111111111"))
+	if err != nil || len(apps) != 2 || len(reasons) != 2 || reasons[0] != 0 {
+		t.Error("expected unknown reasons", apps, reasons, err)
+	}
+}
+
+func TestWriteReasonsOptIn(t *testing.T) {
+	// The chain's scrape settings do not ask for reasons, so none are written even if they are known
+	path := filepath.Join(t.TempDir(), "finalized", "000000010-000000012.bin")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(filepath.Dir(filepath.Dir(path)), "blooms"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	appMap := map[string][]types.AppRecord{
+		"0x1111111111111111111111111111111111111111": {{BlockNumber: 10, TransactionIndex: 1}},
+	}
+	reasonMap := map[string][]types.Reason{
+		"0x1111111111111111111111111111111111111111": {types.ReasonFrom},
+	}
+
+	var chunk Chunk
+	if _, err := chunk.Write("no-reasons", base.ZeroAddr, path, appMap, reasonMap, 1); err != nil {
+		t.Fatal(err)
+	}
+	indexChunk, err := OpenIndex(path, true /* check */)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer indexChunk.Close()
+	if indexChunk.HasReasons() || file.FileSize(path) != SizeWithoutReasons(path) {
+		t.Error("expected the older format unless reasons are configured")
+	}
+}
+
+func TestIndexReasonsTagged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "000000010-000000012.bin")
+	writeTestIndex(t, path, []types.Reason{types.ReasonFrom, types.ReasonTo, types.ReasonTo})
+
+	// Tagging the chunk leaves the version that identifies the format in place
+	idx := Index{}
+	if err := idx.updateTag(config.ExpectedVersion(), path); err != nil {
+		t.Fatal(err)
+	}
+	indexChunk, err := OpenIndex(path, true /* check */)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !indexChunk.HasReasons() || !indexChunk.hasAllReasons() {
+		t.Error("expected the tagged index to keep its reasons")
+	}
+	indexChunk.Close()
+
+	// Index files in the older format are tagged
+	writeTestIndex(t, path, nil)
+	if err := idx.updateTag(config.ExpectedVersion(), path); err != nil {
+		t.Fatal(err)
+	}
+	if indexChunk, err = OpenIndex(path, true /* check */); err != nil {
+		t.Fatal(err)
+	}
+	defer indexChunk.Close()
+	if indexChunk.HasReasons() || indexChunk.Header.Hash != base.BytesToHash(config.HeaderHash(config.ExpectedVersion())) {
+		t.Error("expected the older format to be tagged", indexChunk.Header.Hash.Hex())
+	}
+}
+
+func Test_alignReasons(t *testing.T) {
+	if got := alignReasons([]types.Reason{types.ReasonFrom}, 3); len(got) != 3 || got[0] != types.ReasonFrom || got[2] != 0 {
+		t.Error("wrong alignment", got)
+	}
+	if got := alignReasons(nil, 2); len(got) != 2 {
+		t.Error("wrong alignment", got)
+	}
+}
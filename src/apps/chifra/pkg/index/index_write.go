@@ -12,7 +12,8 @@ import (
 
 // updateTag updates both the index and the bloom filter headers for a chunk.
 // This is a non-recoverable operation. The caller must take care of making a backup of
-// the file before we start if desired.
+// the file before we start if desired. The header of an index file that carries reasons
+// is left as it is, because its version identifies the file's format.
 func (idx *Index) updateTag(tag, fileName string) error {
 	var err error
 	if idx.File, err = os.OpenFile(fileName, os.O_RDWR, 0644); err != nil {
@@ -25,6 +26,12 @@ func (idx *Index) updateTag(tag, fileName string) error {
 			idx.File = nil
 		}()
 
+		if idx.Header, err = idx.readHeader(false /* check */); err != nil {
+			return err
+		} else if idx.HasReasons() {
+			return nil
+		}
+
 		// don't love this, but it saves us from having to read in and preserve the header
 		_, _ = idx.File.Seek(int64(unsafe.Sizeof(idx.Header.Magic)), io.SeekStart)
 		if err = binary.Write(idx.File, binary.LittleEndian, base.BytesToHash(config.HeaderHash(tag))); err != nil {
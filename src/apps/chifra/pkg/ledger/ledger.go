@@ -2,6 +2,7 @@ package ledger
 
 import (
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/names"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
@@ -25,8 +26,10 @@ type Ledger struct {
 	Reversed    bool
 	UseTraces   bool
 	Conn        *rpc.Connection
+	family      string
 	assetFilter []base.Address
 	theTx       *types.Transaction
+	tokenTypes  map[base.Address]types.TokenType
 }
 
 // NewLedger returns a new empty Ledger struct
@@ -42,6 +45,7 @@ func NewLedger(conn *rpc.Connection, acctFor base.Address, fb, lb base.Blknum, a
 		NoZero:     noZero,
 		Reversed:   reversed,
 		UseTraces:  useTraces,
+		family:     config.GetChainFamily(conn.Chain),
 	}
 
 	if assetFilters != nil {
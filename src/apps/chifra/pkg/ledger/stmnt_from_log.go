@@ -16,63 +16,74 @@ var transferTopic = base.HexToHash(
 	"0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef",
 )
 
+// TransferSingle(address indexed _operator, address indexed _from, address indexed _to, uint256 _id, uint256 _value)
+var transferSingleTopic = base.HexToHash(
+	"0xc3d58168c5ae7397731d063d5bbf3d657854427343f4c083240f7aacaa2d0f62",
+)
+
+// TransferBatch(address indexed _operator, address indexed _from, address indexed _to, uint256[] _ids, uint256[] _values)
+var transferBatchTopic = base.HexToHash(
+	"0x4a39dc06d4c0dbc64b70af90fd698a233a518aa5d07e595d983b8c0526c8f7fb",
+)
+
 var ErrNonIndexedTransfer = fmt.Errorf("non-indexed transfer")
 
-// getStatementsFromLog returns a statement from a given log
-func (l *Ledger) getStatementsFromLog(conn *rpc.Connection, logIn *types.Log) (types.Statement, error) {
-	if logIn.Topics[0] != transferTopic {
-		// Not a transfer
-		return types.Statement{}, nil
-	}
+// assetTransfer is a single movement of an asset found in a log. For NFTs, `tokenId` is the id of
+// the token and `amount` is the number of that token transferred (always one for ERC-721).
+type assetTransfer struct {
+	sender    base.Address
+	recipient base.Address
+	amount    base.Wei
+	tokenId   *base.Wei
+	tokenType types.TokenType
+}
 
-	if log, err := l.normalizeTransfer(logIn); err != nil {
-		return types.Statement{}, err
+// getStatementsFromLog returns the statements (one per asset transferred) from a given log
+func (l *Ledger) getStatementsFromLog(conn *rpc.Connection, logIn *types.Log) ([]types.Statement, error) {
+	transfers, err := l.getTransfersFromLog(logIn)
+	if err != nil {
+		return []types.Statement{}, err
+	}
 
-	} else {
-		sym := log.Address.Prefix(6)
-		decimals := base.Value(18)
-		name := l.Names[log.Address]
-		if name.Address == log.Address {
-			if name.Symbol != "" {
-				sym = name.Symbol
-			}
-			if name.Decimals != 0 {
-				decimals = base.Value(name.Decimals)
-			}
+	sym := logIn.Address.Prefix(6)
+	decimals := base.Value(18)
+	name := l.Names[logIn.Address]
+	if name.Address == logIn.Address {
+		if name.Symbol != "" {
+			sym = name.Symbol
 		}
-
-		sender := base.HexToAddress(log.Topics[1].Hex())
-		recipient := base.HexToAddress(log.Topics[2].Hex())
-		var amountIn, amountOut base.Wei
-		var amt *base.Wei
-		if amt, _ = new(base.Wei).SetString(strings.Replace(log.Data, "0x", "", -1), 16); amt == nil {
-			amt = base.NewWei(0)
+		if name.Decimals != 0 {
+			decimals = base.Value(name.Decimals)
 		}
+	}
 
+	statements := make([]types.Statement, 0, len(transfers))
+	for _, transfer := range transfers {
+		var amountIn, amountOut base.Wei
 		ofInterest := false
 
 		// Do not collapse, may be both
-		if l.AccountFor == sender {
-			amountOut = *amt
+		if l.AccountFor == transfer.sender {
+			amountOut = transfer.amount
 			ofInterest = true
 		}
 
 		// Do not collapse, may be both
-		if l.AccountFor == recipient {
-			amountIn = *amt
+		if l.AccountFor == transfer.recipient {
+			amountIn = transfer.amount
 			ofInterest = true
 		}
 
 		s := types.Statement{
 			AccountedFor:     l.AccountFor,
-			Sender:           sender,
-			Recipient:        recipient,
-			BlockNumber:      log.BlockNumber,
-			TransactionIndex: log.TransactionIndex,
-			LogIndex:         log.LogIndex,
-			TransactionHash:  log.TransactionHash,
-			Timestamp:        log.Timestamp,
-			AssetAddr:        log.Address,
+			Sender:           transfer.sender,
+			Recipient:        transfer.recipient,
+			BlockNumber:      logIn.BlockNumber,
+			TransactionIndex: logIn.TransactionIndex,
+			LogIndex:         logIn.LogIndex,
+			TransactionHash:  logIn.TransactionHash,
+			Timestamp:        logIn.Timestamp,
+			AssetAddr:        logIn.Address,
 			AssetSymbol:      sym,
 			Decimals:         decimals,
 			SpotPrice:        0.0,
@@ -81,33 +92,24 @@ func (l *Ledger) getStatementsFromLog(conn *rpc.Connection, logIn *types.Log) (t
 			AmountOut:        amountOut,
 		}
 
-		// TODO: BOGUS PERF - WE HIT GETBALANCE THREE TIMES FOR EACH APPEARANCE. SPIN THROUGH ONCE
-		// TODO: AND CACHE RESULTS IN MEMORY, BUT BE CAREFUL OF MULTIPLE LOGS PER BLOCK (OR TRANSACTION)
-		key := l.ctxKey(log.BlockNumber, log.TransactionIndex)
-		ctx := l.Contexts[key]
-
-		if ofInterest {
-			var err error
-			pBal := new(base.Wei)
-			if pBal, err = conn.GetBalanceAtToken(log.Address, l.AccountFor, fmt.Sprintf("0x%x", ctx.PrevBlock)); pBal == nil {
-				return s, err
-			}
-			s.PrevBal = *pBal
-
-			bBal := new(base.Wei)
-			if bBal, err = conn.GetBalanceAtToken(log.Address, l.AccountFor, fmt.Sprintf("0x%x", ctx.CurBlock-1)); bBal == nil {
-				return s, err
+		reason := "token"
+		if transfer.tokenId != nil {
+			// NFT amounts are counts of a single token id
+			s.TokenId = *transfer.tokenId
+			s.Decimals = 0
+			reason = "erc721"
+			if transfer.tokenType.IsErc1155() {
+				reason = "erc1155"
 			}
-			s.BegBal = *bBal
+		}
 
-			eBal := new(base.Wei)
-			if eBal, err = conn.GetBalanceAtToken(log.Address, l.AccountFor, fmt.Sprintf("0x%x", ctx.CurBlock)); eBal == nil {
-				return s, err
+		if ofInterest {
+			if err := l.setTokenBalances(conn, &s, &transfer); err != nil {
+				return statements, err
 			}
-			s.EndBal = *eBal
 
 			id := fmt.Sprintf(" %d.%d.%d", s.BlockNumber, s.TransactionIndex, s.LogIndex)
-			if !l.trialBalance("token", &s) {
+			if !l.trialBalance(reason, &s) {
 				if !utils.IsFuzzing() {
 					logger.Warn(colors.Yellow+"Log statement at ", id, " does not reconcile."+colors.Off)
 				}
@@ -118,23 +120,231 @@ func (l *Ledger) getStatementsFromLog(conn *rpc.Connection, logIn *types.Log) (t
 			}
 		}
 
-		return s, nil
+		statements = append(statements, s)
 	}
+
+	return statements, nil
 }
 
+// setTokenBalances queries the chain for the previous, beginning, and ending balances of the asset.
+// For NFTs, the balances are those of the single token id being transferred.
+func (l *Ledger) setTokenBalances(conn *rpc.Connection, s *types.Statement, transfer *assetTransfer) error {
+	// TODO: BOGUS PERF - WE HIT GETBALANCE THREE TIMES FOR EACH APPEARANCE. SPIN THROUGH ONCE
+	// TODO: AND CACHE RESULTS IN MEMORY, BUT BE CAREFUL OF MULTIPLE LOGS PER BLOCK (OR TRANSACTION)
+	key := l.ctxKey(s.BlockNumber, s.TransactionIndex)
+	ctx := l.Contexts[key]
+
+	getBalance := func(bn base.Blknum) (*base.Wei, error) {
+		if transfer.tokenId != nil {
+			return conn.GetBalanceAtTokenId(transfer.tokenType, s.AssetAddr, l.AccountFor, transfer.tokenId, fmt.Sprintf("0x%x", bn))
+		}
+		return conn.GetBalanceAtToken(s.AssetAddr, l.AccountFor, fmt.Sprintf("0x%x", bn))
+	}
+
+	var err error
+	pBal := new(base.Wei)
+	if pBal, err = getBalance(ctx.PrevBlock); pBal == nil {
+		return err
+	}
+	s.PrevBal = *pBal
+
+	bBal := new(base.Wei)
+	if bBal, err = getBalance(ctx.CurBlock - 1); bBal == nil {
+		return err
+	}
+	s.BegBal = *bBal
+
+	eBal := new(base.Wei)
+	if eBal, err = getBalance(ctx.CurBlock); eBal == nil {
+		return err
+	}
+	s.EndBal = *eBal
+
+	return nil
+}
+
+// getTransfersFromLog returns the asset transfers found in a log, if any. ERC-20 and ERC-721 share
+// the same Transfer topic and are told apart by the number of indexed topics. ERC-1155 transfers
+// may carry more than one token id (TransferBatch), in which case there is one transfer per id.
+func (l *Ledger) getTransfersFromLog(logIn *types.Log) ([]assetTransfer, error) {
+	if len(logIn.Topics) == 0 {
+		return []assetTransfer{}, nil
+	}
+
+	switch logIn.Topics[0] {
+	case transferTopic:
+		log, err := l.normalizeTransfer(logIn)
+		if err != nil {
+			return []assetTransfer{}, err
+		}
+
+		transfer := assetTransfer{
+			sender:    base.HexToAddress(log.Topics[1].Hex()),
+			recipient: base.HexToAddress(log.Topics[2].Hex()),
+		}
+		if len(log.Topics) > 3 {
+			// Transfer(address indexed _from, address indexed _to, uint256 indexed _tokenId)
+			transfer.tokenType = types.TokenErc721
+			transfer.tokenId = base.HexToWei(log.Topics[3].Hex())
+			transfer.amount = *base.NewWei(1)
+		} else {
+			transfer.tokenType = types.TokenErc20
+			var amt *base.Wei
+			if amt, _ = new(base.Wei).SetString(strings.Replace(log.Data, "0x", "", -1), 16); amt == nil {
+				amt = base.NewWei(0)
+			}
+			transfer.amount = *amt
+
+			if len(logIn.Topics) < 3 {
+				// The value was not indexed, so it may be an ERC-721 token id rather than an amount
+				tokenType, err := l.nonIndexedTokenType(logIn, amt)
+				if err != nil {
+					return []assetTransfer{}, err
+				}
+				if tokenType == types.TokenErc721 {
+					transfer.tokenType = types.TokenErc721
+					transfer.tokenId = amt
+					transfer.amount = *base.NewWei(1)
+				}
+			}
+		}
+		return []assetTransfer{transfer}, nil
+
+	case transferSingleTopic, transferBatchTopic:
+		if len(logIn.Topics) < 4 {
+			return []assetTransfer{}, ErrNonIndexedTransfer
+		}
+
+		sender := base.HexToAddress(logIn.Topics[2].Hex())
+		recipient := base.HexToAddress(logIn.Topics[3].Hex())
+		words := dataWords(logIn.Data)
+
+		var ids, values []*base.Wei
+		if logIn.Topics[0] == transferSingleTopic {
+			if len(words) != 2 {
+				return []assetTransfer{}, fmt.Errorf("malformed TransferSingle at %d.%d.%d", logIn.BlockNumber, logIn.TransactionIndex, logIn.LogIndex)
+			}
+			ids, values = words[:1], words[1:]
+		} else {
+			var ok bool
+			if ids, ok = dynamicArray(words, 0); ok {
+				values, ok = dynamicArray(words, 1)
+			}
+			if !ok || len(ids) != len(values) {
+				return []assetTransfer{}, fmt.Errorf("malformed TransferBatch at %d.%d.%d", logIn.BlockNumber, logIn.TransactionIndex, logIn.LogIndex)
+			}
+		}
+
+		transfers := make([]assetTransfer, 0, len(ids))
+		for i := range ids {
+			transfers = append(transfers, assetTransfer{
+				sender:    sender,
+				recipient: recipient,
+				amount:    *values[i],
+				tokenId:   ids[i],
+				tokenType: types.TokenErc1155,
+			})
+		}
+		return transfers, nil
+	}
+
+	// Not a transfer
+	return []assetTransfer{}, nil
+}
+
+// normalizeTransfer returns a log with `from` and `to` (and, for ERC-721, `tokenId`) in the
+// topics and the amount (if any) in the data regardless of which of the parameters were
+// indexed by the emitting contract.
 func (l *Ledger) normalizeTransfer(log *types.Log) (*types.Log, error) {
-	if len(log.Topics) < 3 {
-		// Transfer(address _from, address _to, uint256 _tokenId) - no indexed topics
+	if len(log.Topics) >= 3 {
 		// Transfer(address indexed _from, address indexed _to, uint256 _value) - two indexed topics
 		// Transfer(address indexed _from, address indexed _to, uint256 indexed _tokenId) - three indexed topics
-		// TODO: This may be a transfer. Returning here is wrong. What this means is that
-		// TODO:the some of the transfer's data is not indexed. Too short topics happens
-		// TODO: (sometimes) because the ABI says that the data is not index, but it is
-		// TODO: or visa versa. In either case, we get the same topic0. We need to
-		// TODO: attempt both with and without indexed parameters. See issues/1366.
-		// TODO: We could fix this and call back in recursively...
+		return log, nil
+	}
+
+	// Transfer(address _from, address _to, uint256 _tokenId) - no indexed topics (for example, CryptoKitties)
+	// Transfer(address indexed _from, address _to, uint256 _value) - one indexed topic
+	// The value may be an amount or a token id, which the caller tells apart. See issues/1366.
+	words := dataWords(log.Data)
+	nMissing := 3 - len(log.Topics)
+	if len(words) != nMissing+1 {
 		return nil, ErrNonIndexedTransfer
 	}
 
-	return log, nil
+	normalized := *log
+	normalized.Topics = append([]base.Hash{}, log.Topics...)
+	for _, word := range words[:nMissing] {
+		normalized.Topics = append(normalized.Topics, base.BytesToHash(word.Bytes()))
+	}
+	normalized.Data = fmt.Sprintf("0x%064x", words[nMissing].BigInt())
+	return &normalized, nil
+}
+
+// nonIndexedTokenType returns the type of the token (ERC-20 or ERC-721) that emitted a Transfer whose
+// value is not indexed. We ask the chain (once per token) because the log itself does not say if
+// the value is an amount or a token id.
+func (l *Ledger) nonIndexedTokenType(log *types.Log, value *base.Wei) (types.TokenType, error) {
+	if tokenType, ok := l.tokenTypes[log.Address]; ok {
+		return tokenType, nil
+	}
+
+	if l.Conn == nil {
+		return types.TokenErc20, fmt.Errorf("cannot classify the non-indexed transfer at %d.%d.%d without a connection", log.BlockNumber, log.TransactionIndex, log.LogIndex)
+	}
+
+	isErc721, err := l.Conn.IsErc721(log.Address, value, fmt.Sprintf("0x%x", log.BlockNumber))
+	if err != nil {
+		return types.TokenErc20, fmt.Errorf("cannot classify the non-indexed transfer at %d.%d.%d: %w", log.BlockNumber, log.TransactionIndex, log.LogIndex, err)
+	}
+
+	tokenType := types.TokenErc20
+	if isErc721 {
+		tokenType = types.TokenErc721
+	}
+	if l.tokenTypes == nil {
+		l.tokenTypes = make(map[base.Address]types.TokenType)
+	}
+	l.tokenTypes[log.Address] = tokenType
+	return tokenType, nil
+}
+
+// dataWords splits a log's data into 32-byte words
+func dataWords(data string) []*base.Wei {
+	data = strings.TrimPrefix(data, "0x")
+	if len(data)%64 != 0 {
+		return []*base.Wei{}
+	}
+
+	words := make([]*base.Wei, 0, len(data)/64)
+	for i := 0; i < len(data); i += 64 {
+		word, ok := new(base.Wei).SetString(data[i:i+64], 16)
+		if !ok {
+			return []*base.Wei{}
+		}
+		words = append(words, word)
+	}
+	return words
+}
+
+// dynamicArray decodes the ABI encoded uint256[] whose offset is in the word at `index`
+func dynamicArray(words []*base.Wei, index int) ([]*base.Wei, bool) {
+	if index >= len(words) || !words[index].BigInt().IsUint64() {
+		return nil, false
+	}
+
+	offset := words[index].Uint64()
+	if offset%32 != 0 || offset/32 >= uint64(len(words)) {
+		return nil, false
+	}
+
+	start := offset / 32
+	if !words[start].BigInt().IsUint64() {
+		return nil, false
+	}
+	count := words[start].Uint64()
+	if start+1+count > uint64(len(words)) {
+		return nil, false
+	}
+
+	return words[start+1 : start+1+count], true
 }
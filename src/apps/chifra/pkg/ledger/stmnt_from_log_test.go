@@ -0,0 +1,171 @@
+package ledger
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+var (
+	alice    = base.HexToAddress("0xf503017d7baf7fbc0fff7492b751025c6a78179b")
+	bob      = base.HexToAddress("0x1212121212121212121212121212121212121212")
+	operator = base.HexToAddress("0x3434343434343434343434343434343434343434")
+	fungible = base.HexToAddress("0x5656565656565656565656565656565656565656")
+	kitties  = base.HexToAddress("0x06012c8cf97bead5deae237070f9587f8e7a266d")
+)
+
+func word(n uint64) string {
+	return fmt.Sprintf("%064x", n)
+}
+
+func addrTopic(addr base.Address) base.Hash {
+	return base.HexToHash(addr.Hex())
+}
+
+func TestTransfersFromLog(t *testing.T) {
+	// The types of tokens whose transfers are not indexed would otherwise be read from the chain
+	l := &Ledger{AccountFor: alice, tokenTypes: map[base.Address]types.TokenType{
+		fungible: types.TokenErc20,
+		kitties:  types.TokenErc721,
+	}}
+
+	tests := []struct {
+		name     string
+		log      types.Log
+		expected []assetTransfer
+	}{
+		{
+			name: "erc20",
+			log: types.Log{
+				Topics: []base.Hash{transferTopic, addrTopic(alice), addrTopic(bob)},
+				Data:   "0x" + word(10),
+			},
+			expected: []assetTransfer{
+				{sender: alice, recipient: bob, amount: *base.NewWei(10), tokenType: types.TokenErc20},
+			},
+		},
+		{
+			name: "erc20 not indexed",
+			log: types.Log{
+				Address: fungible,
+				Topics:  []base.Hash{transferTopic},
+				Data:    "0x" + alice.Pad32() + bob.Pad32() + word(10),
+			},
+			expected: []assetTransfer{
+				{sender: alice, recipient: bob, amount: *base.NewWei(10), tokenType: types.TokenErc20},
+			},
+		},
+		{
+			name: "erc721 not indexed",
+			log: types.Log{
+				Address: kitties,
+				Topics:  []base.Hash{transferTopic},
+				Data:    "0x" + alice.Pad32() + bob.Pad32() + word(1234567),
+			},
+			expected: []assetTransfer{
+				{sender: alice, recipient: bob, amount: *base.NewWei(1), tokenId: base.NewWei(1234567), tokenType: types.TokenErc721},
+			},
+		},
+		{
+			name: "erc721",
+			log: types.Log{
+				Topics: []base.Hash{transferTopic, addrTopic(alice), addrTopic(bob), base.HexToHash("0x2a")},
+				Data:   "0x",
+			},
+			expected: []assetTransfer{
+				{sender: alice, recipient: bob, amount: *base.NewWei(1), tokenId: base.NewWei(42), tokenType: types.TokenErc721},
+			},
+		},
+		{
+			name: "erc1155 single",
+			log: types.Log{
+				Topics: []base.Hash{transferSingleTopic, addrTopic(operator), addrTopic(bob), addrTopic(alice)},
+				Data:   "0x" + word(7) + word(3),
+			},
+			expected: []assetTransfer{
+				{sender: bob, recipient: alice, amount: *base.NewWei(3), tokenId: base.NewWei(7), tokenType: types.TokenErc1155},
+			},
+		},
+		{
+			name: "erc1155 batch",
+			log: types.Log{
+				Topics: []base.Hash{transferBatchTopic, addrTopic(operator), addrTopic(alice), addrTopic(bob)},
+				Data: "0x" + word(0x40) + word(0xa0) +
+					word(2) + word(7) + word(8) +
+					word(2) + word(3) + word(4),
+			},
+			expected: []assetTransfer{
+				{sender: alice, recipient: bob, amount: *base.NewWei(3), tokenId: base.NewWei(7), tokenType: types.TokenErc1155},
+				{sender: alice, recipient: bob, amount: *base.NewWei(4), tokenId: base.NewWei(8), tokenType: types.TokenErc1155},
+			},
+		},
+		{
+			name: "not a transfer",
+			log: types.Log{
+				Topics: []base.Hash{base.HexToHash("0x1234")},
+			},
+			expected: []assetTransfer{},
+		},
+	}
+
+	for _, test := range tests {
+		got, err := l.getTransfersFromLog(&test.log)
+		if err != nil {
+			t.Error(test.name, err)
+			continue
+		}
+		if len(got) != len(test.expected) {
+			t.Error(test.name, "expected", len(test.expected), "transfers, got", len(got))
+			continue
+		}
+		for i, exp := range test.expected {
+			g := got[i]
+			if g.sender != exp.sender || g.recipient != exp.recipient || g.tokenType != exp.tokenType {
+				t.Error(test.name, "mismatched parties or type", g)
+			}
+			if g.amount.Cmp(&exp.amount) != 0 {
+				t.Error(test.name, "expected amount", exp.amount.String(), "got", g.amount.String())
+			}
+			if (g.tokenId == nil) != (exp.tokenId == nil) || (g.tokenId != nil && g.tokenId.Cmp(exp.tokenId) != 0) {
+				t.Error(test.name, "mismatched token id", g.tokenId, exp.tokenId)
+			}
+		}
+	}
+}
+
+func TestMalformedTransfers(t *testing.T) {
+	l := &Ledger{AccountFor: alice}
+
+	logs := []types.Log{
+		// too few topics and not enough data to make up for them
+		{Topics: []base.Hash{transferTopic, addrTopic(alice)}, Data: "0x" + word(10)},
+		// batch with mismatched arrays
+		{
+			Topics: []base.Hash{transferBatchTopic, addrTopic(operator), addrTopic(alice), addrTopic(bob)},
+			Data:   "0x" + word(0x40) + word(0xa0) + word(2) + word(7) + word(8) + word(1) + word(3),
+		},
+		// batch with a bad offset
+		{
+			Topics: []base.Hash{transferBatchTopic, addrTopic(operator), addrTopic(alice), addrTopic(bob)},
+			Data:   "0x" + word(0x400) + word(0xa0),
+		},
+	}
+
+	for i, log := range logs {
+		if _, err := l.getTransfersFromLog(&log); err == nil {
+			t.Error(i, "expected an error")
+		}
+	}
+
+	// A non-indexed transfer of a token we cannot classify is reported rather than booked as an amount
+	unknown := types.Log{
+		Address: fungible,
+		Topics:  []base.Hash{transferTopic},
+		Data:    "0x" + alice.Pad32() + bob.Pad32() + word(10),
+	}
+	if _, err := l.getTransfersFromLog(&unknown); err == nil {
+		t.Error("expected an error for an unclassified token")
+	}
+}
@@ -11,7 +11,7 @@ func (l *Ledger) getStatementsFromTraces(conn *rpc.Connection, trans *types.Tran
 	statements := make([]types.Statement, 0, 20) // a high estimate of the number of statements we'll need
 
 	ret := *s
-	// clear all the internal accounting values. Keeps AmountIn, AmountOut and GasOut because
+	// clear all the internal accounting values. Keeps AmountIn, AmountOut, GasOut and L1FeeOut because
 	// those are at the top level (both the transaction itself and trace '0' have them). We
 	// skip trace '0' because it's the same as the transaction.
 	// ret.AmountIn.SetUint64(0)
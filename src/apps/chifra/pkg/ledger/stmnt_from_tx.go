@@ -40,10 +40,12 @@ func (l *Ledger) GetStatements(conn *rpc.Connection, filter *filter.AppearanceFi
 		// TODO: We ignore errors in the next few lines, but we should not
 		// TODO: BOGUS PERF - This greatly increases the number of times we call into eth_getBalance which is quite slow
 		prevBal, _ := conn.GetBalanceAt(l.AccountFor, ctx.PrevBlock)
+		begBal := new(base.Wei)
 		if trans.BlockNumber == 0 {
-			prevBal = new(base.Wei)
+			prevBal = new(base.Wei) // nothing precedes the genesis block
+		} else {
+			begBal, _ = conn.GetBalanceAt(l.AccountFor, ctx.CurBlock-1)
 		}
-		begBal, _ := conn.GetBalanceAt(l.AccountFor, ctx.CurBlock-1)
 		endBal, _ := conn.GetBalanceAt(l.AccountFor, ctx.CurBlock)
 
 		ret := types.Statement{
@@ -72,15 +74,10 @@ func (l *Ledger) GetStatements(conn *rpc.Connection, filter *filter.AppearanceFi
 
 		// Do not collapse. A single transaction may have many movements of money
 		if l.AccountFor == ret.Sender {
-			gasUsed := new(base.Wei)
-			if trans.Receipt != nil {
-				gasUsed.SetUint64(uint64(trans.Receipt.GasUsed))
-			}
-			gasPrice := new(base.Wei).SetUint64(uint64(trans.GasPrice))
-			gasOut := new(base.Wei).Mul(gasUsed, gasPrice)
-
+			gasOut, l1FeeOut := trans.GasCosts(l.family)
 			ret.AmountOut = trans.Value
 			ret.GasOut = *gasOut
+			ret.L1FeeOut = *l1FeeOut
 		}
 
 		// Do not collapse. A single transaction may have many movements of money
@@ -100,6 +97,11 @@ func (l *Ledger) GetStatements(conn *rpc.Connection, filter *filter.AppearanceFi
 			}
 		}
 
+		// On rollups, a deposit's sender is first credited with the value brought in from the parent chain
+		if l.AccountFor == ret.Sender && trans.IsDeposit(l.family) {
+			ret.AmountIn = *new(base.Wei).Add(&ret.AmountIn, trans.Minted(l.family))
+		}
+
 		if l.AsEther {
 			ret.AssetSymbol = "ETH"
 		}
@@ -0,0 +1,70 @@
+package ledger
+
+import (
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/filter"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc/query"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// TestGetStatementsFixtures reconciles the second account of the devnet's simple scenario, which
+// is paid one ether in block 1 and both one hundred tokens and five wei (through an internal call)
+// in block 3. The calls are answered from fixtures (see query.ReplayFixtures to record them again).
+func TestGetStatementsFixtures(t *testing.T) {
+	if err := query.ReplayFixtures("fixtures", "testdata/fixtures.json"); err != nil {
+		t.Fatal(err)
+	}
+	conn := &rpc.Connection{Chain: "fixtures"}
+
+	accountTwo := base.HexToAddress("0x2222222222222222222222222222222222222222")
+	token := base.HexToAddress("0xaaaa000000000000000000000000000000000000")
+	apps := []types.Appearance{
+		{BlockNumber: 1, TransactionIndex: 0},
+		{BlockNumber: 3, TransactionIndex: 0},
+	}
+
+	l := NewLedger(conn, accountTwo, 0, base.NOPOSN, false, false, false, false, false, nil)
+	if err := l.SetContexts(conn.Chain, apps); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		asset    base.Address
+		amountIn string
+		internal string
+		endBal   string
+	}{
+		{base.FAKE_ETH_ADDRESS, "1000000000000000000", "0", "2000000000000000000"},
+		{base.FAKE_ETH_ADDRESS, "0", "5", "2000000000000000005"},
+		{token, "100", "0", "100"},
+	}
+
+	statements := []types.Statement{}
+	for _, app := range apps {
+		tx, err := conn.GetTransactionByAppearance(&app, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := l.GetStatements(conn, filter.NewEmptyFilter(), tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		statements = append(statements, s...)
+	}
+
+	if len(statements) != len(expected) {
+		t.Fatalf("expected %d statements, got %d", len(expected), len(statements))
+	}
+	for i, want := range expected {
+		s := statements[i]
+		if !s.Reconciled() {
+			t.Errorf("statement %d (%d.%d) does not reconcile", i, s.BlockNumber, s.TransactionIndex)
+		}
+		if s.AssetAddr != want.asset || s.AmountIn.String() != want.amountIn || s.InternalIn.String() != want.internal || s.EndBal.String() != want.endBal {
+			t.Errorf("statement %d: %s in %s internal %s end %s", i, s.AssetAddr.Hex(), s.AmountIn.String(), s.InternalIn.String(), s.EndBal.String())
+		}
+	}
+}
@@ -25,8 +25,9 @@ func (l *Ledger) trialBalance(reason string, s *types.Statement) bool {
 	}
 
 	// TODO: BOGUS PERF
-	if s.IsMaterial() {
-		s.SpotPrice, s.PriceSource, _ = pricing.PriceUsd(l.Conn, s)
+	if s.IsMaterial() && !s.IsNft() {
+		quote, _ := pricing.PriceUsd(l.Conn, s)
+		s.SpotPrice, s.PriceSource, s.PricePool = quote.Price, quote.Source, quote.Pool
 	}
 
 	if l.TestMode {
@@ -19,11 +19,15 @@ import (
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/call"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/debug"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
 	ethAbi "github.com/ethereum/go-ethereum/accounts/abi"
 )
 
+// unchainedChain is the chain on which the Unchained Index smart contract is deployed
+const unchainedChain = "mainnet"
+
 // ReadUnchainedIndex calls UnchainedIndex smart contract to get the current manifest IPFS CID as
 // published by the given publisher
 func ReadUnchainedIndex(chain string, publisher base.Address, database string) (string, error) {
@@ -37,7 +41,6 @@ func ReadUnchainedIndex(chain string, publisher base.Address, database string) (
 		return "", err
 	}
 
-	unchainedChain := "mainnet" // the unchained index is on mainnet
 	conn := rpc.TempConnection(unchainedChain)
 	// if conn.LatestBlockTimestamp < 1_705_173_443 { // block 19_000_000
 	// 	provider := config.GetChain(unchainedChain).RpcProvider
@@ -92,11 +95,37 @@ func downloadManifest(chain, gatewayUrl, cid string) (*Manifest, error) {
 	}
 }
 
+// downloadManifestFromAny tries each of the gateways in order and returns the first manifest downloaded
+// together with the gateway it was downloaded from
+func downloadManifestFromAny(chain string, gateways []string, cid string) (*Manifest, string, error) {
+	err := fmt.Errorf("no IPFS gateway is configured for chain %s", chain)
+	for _, gatewayUrl := range gateways {
+		var man *Manifest
+		if man, err = downloadManifest(chain, gatewayUrl, cid); err == nil {
+			return man, gatewayUrl, nil
+		}
+		logger.Warn("Could not download the manifest from", gatewayUrl, "-", err)
+	}
+	return nil, "", err
+}
+
 func getUnchainedAbi() (base.Address, *abi.SelectorSyncMap, error) {
 	abiMap := &abi.SelectorSyncMap{}
 	callAddress := base.HexToAddress(config.GetUnchained().SmartContract)
 
-	var unchainedAbiJson = `[
+	if abi, err := ethAbi.JSON(strings.NewReader(unchainedAbiJson)); err != nil {
+		return base.Address{}, abiMap, err
+	} else {
+		for _, method := range abi.Methods {
+			function := types.FunctionFromAbiMethod(&method)
+			abiMap.SetValue(function.Encoding, function)
+		}
+	}
+
+	return callAddress, abiMap, nil
+}
+
+var unchainedAbiJson = `[
   {
     "name": "manifestHashMap",
     "type": "function",
@@ -121,20 +150,28 @@ func getUnchainedAbi() (base.Address, *abi.SelectorSyncMap, error) {
         "internalType": "string"
       }
     ]
+  },
+  {
+    "name": "publishHash",
+    "type": "function",
+    "signature": "publishHash(string,string)",
+    "encoding": "0x1fee5cd2",
+    "inputs": [
+      {
+        "type": "string",
+        "name": "database",
+        "internalType": "string"
+      },
+      {
+        "type": "string",
+        "name": "hash",
+        "internalType": "string"
+      }
+    ],
+    "outputs": []
   }
 ]`
 
-	if abi, err := ethAbi.JSON(strings.NewReader(unchainedAbiJson)); err != nil {
-		return base.Address{}, abiMap, err
-	} else {
-		method := abi.Methods["manifestHashMap"]
-		function := types.FunctionFromAbiMethod(&method)
-		abiMap.SetValue(function.Encoding, function)
-	}
-
-	return callAddress, abiMap, nil
-}
-
 // var unchainedWarning string = `
 // The Unchained Index requires your mainnet RPC to be synced (at least to block 0x1304073 or 19000000).
 // Check the progress with the following curl command and try again later.
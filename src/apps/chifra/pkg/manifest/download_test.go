@@ -48,3 +48,30 @@ func TestDownloadJSON(t *testing.T) {
 		t.Errorf("Wrong NewPins length: %d", l)
 	}
 }
+
+func TestDownloadFromAny(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer down.Close()
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintln(w, manifestJSONSource)
+	}))
+	defer up.Close()
+
+	manifest, gateway, err := downloadManifestFromAny("mainnet", []string{down.URL, up.URL}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gateway != up.URL {
+		t.Errorf("expected the manifest to come from %s, got %s", up.URL, gateway)
+	}
+	if l := len(manifest.Chunks); l != 2 {
+		t.Errorf("Wrong NewPins length: %d", l)
+	}
+
+	if _, _, err := downloadManifestFromAny("mainnet", []string{}, ""); err == nil {
+		t.Error("expected an error without gateways")
+	}
+}
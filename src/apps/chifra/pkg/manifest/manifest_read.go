@@ -46,17 +46,17 @@ func LoadManifest(chain string, publisher base.Address, source Source) (man *Man
 		} else if len(cid) == 0 {
 			return nil, fmt.Errorf("no record found in the Unchained Index for database %s from publisher %s", database, publisher.Hex())
 		}
-		gatewayUrl := config.GetChain(chain).IpfsGateway
+
+		newManifest, gateway, err := downloadManifestFromAny(chain, config.GetIpfsGatewayUrls(chain), cid)
+		if err != nil {
+			return nil, err
+		}
 		logger.InfoTable("Chain:", chain)
 		logger.InfoTable("Database:", database)
 		logger.InfoTable("Publisher:", publisher)
-		logger.InfoTable("Gateway:", gatewayUrl)
+		logger.InfoTable("Gateway:", gateway)
 		logger.InfoTable("CID:", cid)
 
-		newManifest, err := downloadManifest(chain, gatewayUrl, cid)
-		if err != nil {
-			return nil, err
-		}
 		if newManifest.Chain != chain {
 			msg := fmt.Sprintf("The remote manifest's chain (%s) does not match the cached manifest's chain (%s).", newManifest.Chain, chain)
 			return newManifest, errors.New(msg)
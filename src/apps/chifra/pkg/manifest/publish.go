@@ -0,0 +1,203 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package manifest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	ethAbi "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Signer holds the key with which a publisher signs the transaction that publishes a manifest.
+// The sender of that transaction is recorded by the smart contract as the publisher.
+type Signer struct {
+	key *ecdsa.PrivateKey
+}
+
+// NewSignerFromKeystore decrypts an Ethereum keystore file (as written by geth or clef) with the given password
+func NewSignerFromKeystore(path, password string) (*Signer, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := keystore.DecryptKey(contents, password)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt keystore %s: %w", path, err)
+	}
+	return &Signer{key: key.PrivateKey}, nil
+}
+
+// NewSignerFromHex creates a signer from a hex encoded private key (with or without the 0x prefix)
+func NewSignerFromHex(hexKey string) (*Signer, error) {
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimSpace(hexKey), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	return &Signer{key: key}, nil
+}
+
+// Address returns the address of the signer (i.e., the publisher)
+func (s *Signer) Address() base.Address {
+	return base.BytesToAddress(crypto.PubkeyToAddress(s.key.PublicKey).Bytes())
+}
+
+// Publication is a signed, but not yet sent, transaction that publishes a manifest's CID for a chain
+type Publication struct {
+	Chain     string
+	Cid       string
+	Publisher base.Address
+	Tx        *ethTypes.Transaction
+}
+
+// Raw returns the signed transaction encoded as it would be sent to the node
+func (p *Publication) Raw() ([]byte, error) {
+	return p.Tx.MarshalBinary()
+}
+
+// NewPublication builds and signs the transaction that publishes the cid as the manifest of the
+// given chain. The nonce, fees, and gas are queried from the chain on which the Unchained Index
+// is deployed.
+func NewPublication(chain, cid string, signer *Signer) (*Publication, error) {
+	contract := base.HexToAddress(config.GetUnchained().SmartContract)
+	input, err := packPublishHash(chain, cid)
+	if err != nil {
+		return nil, err
+	}
+
+	publisher := signer.Address()
+	conn := rpc.TempConnection(unchainedChain)
+
+	chainId, _, err := conn.GetClientIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := conn.GetPendingNonce(publisher)
+	if err != nil {
+		return nil, err
+	}
+
+	tip, err := conn.GetGasTip()
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := conn.GetBlockHeaderByNumber(conn.GetLatestBlockNumber())
+	if err != nil {
+		return nil, err
+	}
+
+	gas, err := conn.EstimateGas(publisher, contract, input)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := newPublishTx(chainId, nonce, contract, input, gas, tip.BigInt(), new(big.Int).SetUint64(uint64(header.BaseFeePerGas)))
+	if tx, err = ethTypes.SignTx(tx, ethTypes.LatestSignerForChainID(tx.ChainId()), signer.key); err != nil {
+		return nil, err
+	}
+
+	return &Publication{
+		Chain:     chain,
+		Cid:       cid,
+		Publisher: publisher,
+		Tx:        tx,
+	}, nil
+}
+
+// Send submits the publication to the node, waits for it to be mined (until the context is
+// done), and then re-reads the smart contract to verify that it reports the published CID.
+func (p *Publication) Send(ctx context.Context) (base.Hash, error) {
+	raw, err := p.Raw()
+	if err != nil {
+		return base.Hash{}, err
+	}
+
+	conn := rpc.TempConnection(unchainedChain)
+	hash, err := conn.SendRawTransaction(raw)
+	if err != nil {
+		return base.Hash{}, err
+	}
+
+	if receipt, err := conn.WaitForReceipt(ctx, hash); err != nil {
+		return hash, err
+	} else if receipt.IsError {
+		return hash, fmt.Errorf("transaction %s failed", hash.Hex())
+	}
+
+	if cid, err := ReadUnchainedIndex(p.Chain, p.Publisher, p.Chain); err != nil {
+		return hash, err
+	} else if cid != p.Cid {
+		return hash, fmt.Errorf("the smart contract reports %s for publisher %s, expected %s", cid, p.Publisher.Hex(), p.Cid)
+	}
+
+	return hash, nil
+}
+
+// newPublishTx returns an unsigned EIP-1559 transaction to the contract with the given input. The
+// gas limit includes some headroom over the estimate and the fee cap allows for the base fee to
+// double before the transaction is mined.
+func newPublishTx(chainId uint64, nonce uint64, contract base.Address, input []byte, gas base.Gas, tip, baseFee *big.Int) *ethTypes.Transaction {
+	to := contract.Common()
+	feeCap := new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), tip)
+	return ethTypes.NewTx(&ethTypes.DynamicFeeTx{
+		ChainID:   new(big.Int).SetUint64(chainId),
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: feeCap,
+		Gas:       uint64(gas) * 6 / 5,
+		To:        &to,
+		Value:     big.NewInt(0),
+		Data:      input,
+	})
+}
+
+// packPublishHash returns the input data for a call to publishHash(database, hash) where the
+// database is the name of the chain and the hash is the CID of its manifest
+func packPublishHash(database, hash string) ([]byte, error) {
+	abi, err := ethAbi.JSON(strings.NewReader(unchainedAbiJson))
+	if err != nil {
+		return nil, err
+	}
+	return abi.Pack("publishHash", database, hash)
+}
+
+// PublisherFromConfig returns a signer from the environment or the configuration. A private key in
+// TB_PUBLISH_PRIVATE_KEY takes precedence over the keystore named in the [unchained] section
+// of the configuration file. The getPassword function is called only if a keystore is used.
+func PublisherFromConfig(getPassword func(path string) (string, error)) (*Signer, error) {
+	if hexKey := os.Getenv("TB_PUBLISH_PRIVATE_KEY"); hexKey != "" {
+		return NewSignerFromHex(hexKey)
+	}
+
+	path := config.GetUnchained().Keystore
+	if path == "" {
+		return nil, ErrNoSigner
+	}
+
+	password, err := getPassword(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewSignerFromKeystore(path, password)
+}
+
+// HasSigner returns true if either a private key or a keystore is available for publishing
+func HasSigner() bool {
+	return os.Getenv("TB_PUBLISH_PRIVATE_KEY") != "" || config.GetUnchained().Keystore != ""
+}
+
+var ErrNoSigner = fmt.Errorf("publishing requires TB_PUBLISH_PRIVATE_KEY or a keystore in the [unchained] section of the config")
@@ -0,0 +1,97 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package manifest
+
+import (
+	"bytes"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// A well known test key (the first account of hardhat and anvil)
+const testKey = "0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+const testAddress = "0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266"
+
+func TestPublishTx(t *testing.T) {
+	signer, err := NewSignerFromHex(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer.Address().String() != testAddress {
+		t.Fatal("wrong signer address", signer.Address().String())
+	}
+
+	input, err := packPublishHash("mychain", "QmUou7zX2g2tY58LP1A2GyP5RF9nbJsoxKTp299ah3svgb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if base.Bytes2Hex(input[:4]) != "1fee5cd2" {
+		t.Fatal("wrong selector", base.Bytes2Hex(input[:4]))
+	}
+
+	contract := base.HexToAddress("0x0c316b7042b419d07d343f2f4f5bd54ff731183d")
+	tx := newPublishTx(1, 7, contract, input, 50000, big.NewInt(2), big.NewInt(10))
+	if tx.Gas() != 60000 || tx.GasFeeCap().Int64() != 22 || tx.GasTipCap().Int64() != 2 {
+		t.Fatal("wrong gas or fees", tx.Gas(), tx.GasFeeCap(), tx.GasTipCap())
+	}
+
+	chainSigner := ethTypes.LatestSignerForChainID(tx.ChainId())
+	signed, err := ethTypes.SignTx(tx, chainSigner, signer.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pub := Publication{Tx: signed}
+	raw, err := pub.Raw()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The raw transaction must decode to the same transaction sent by the publisher
+	decoded := new(ethTypes.Transaction)
+	if err := decoded.UnmarshalBinary(raw); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Hash() != signed.Hash() || decoded.Nonce() != 7 || !bytes.Equal(decoded.Data(), input) {
+		t.Fatal("decoded transaction differs")
+	}
+	if *decoded.To() != contract.Common() {
+		t.Fatal("wrong recipient", decoded.To())
+	}
+	if sender, err := ethTypes.Sender(chainSigner, decoded); err != nil {
+		t.Fatal(err)
+	} else if base.BytesToAddress(sender.Bytes()) != signer.Address() {
+		t.Fatal("wrong sender", sender.Hex())
+	}
+}
+
+func TestSignerFromKeystore(t *testing.T) {
+	dir := t.TempDir()
+	ks := keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP)
+	account, err := ks.NewAccount("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, filepath.Base(account.URL.Path))
+	if _, err := os.Stat(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if signer, err := NewSignerFromKeystore(path, "secret"); err != nil {
+		t.Fatal(err)
+	} else if signer.Address().String() != base.BytesToAddress(account.Address.Bytes()).String() {
+		t.Fatal("wrong signer address", signer.Address().String())
+	}
+
+	if _, err := NewSignerFromKeystore(path, "wrong"); err == nil {
+		t.Fatal("expected an error with the wrong password")
+	}
+}
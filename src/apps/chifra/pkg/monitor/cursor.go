@@ -0,0 +1,132 @@
+package monitor
+
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// CursorKey identifies a position in a named cursor. A cursor keeps one position per address
+// and output mode so that, for example, exporting logs does not advance the cursor for traces.
+type CursorKey struct {
+	Address base.Address
+	Mode    string
+}
+
+// Cursors maps each address and output mode to the last appearance already exported
+type Cursors map[CursorKey]types.AppRecord
+
+var cursorNameRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// IsValidCursorName returns true if the name may be used as the name of a cursor file
+func IsValidCursorName(name string) bool {
+	return cursorNameRegex.MatchString(name)
+}
+
+// PathToCursors returns the path to the file holding the named cursor
+func PathToCursors(chain, name string) string {
+	return filepath.Join(config.PathToCache(chain), "monitors", "cursors", name+".csv")
+}
+
+// ReadCursors reads the named cursor. A cursor that does not yet exist is empty.
+func ReadCursors(chain, name string) (Cursors, error) {
+	cursors := make(Cursors)
+
+	path := PathToCursors(chain, name)
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cursors, nil
+		}
+		return cursors, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "address,") {
+			continue
+		}
+		parts := strings.Split(line, ",")
+		if len(parts) != 4 {
+			return cursors, fmt.Errorf("%s:%d: expected address,mode,blockNumber,transactionIndex", path, lineNo)
+		}
+		bn, err1 := strconv.ParseUint(parts[2], 10, 32)
+		txid, err2 := strconv.ParseUint(parts[3], 10, 32)
+		if !base.IsValidAddress(parts[0]) || err1 != nil || err2 != nil {
+			return cursors, fmt.Errorf("%s:%d: invalid cursor %s", path, lineNo, line)
+		}
+		key := CursorKey{Address: base.HexToAddress(parts[0]), Mode: parts[1]}
+		cursors[key] = types.AppRecord{BlockNumber: uint32(bn), TransactionIndex: uint32(txid)}
+	}
+	return cursors, scanner.Err()
+}
+
+// WriteCursors replaces the named cursor. The file is written to a temporary file and then
+// renamed so that a crash never leaves a partially written cursor behind.
+func WriteCursors(chain, name string, cursors Cursors) error {
+	keys := make([]CursorKey, 0, len(cursors))
+	for key := range cursors {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Address == keys[j].Address {
+			return keys[i].Mode < keys[j].Mode
+		}
+		return keys[i].Address.Hex() < keys[j].Address.Hex()
+	})
+
+	lines := make([]string, 0, len(keys)+1)
+	lines = append(lines, "address,mode,blockNumber,transactionIndex")
+	for _, key := range keys {
+		app := cursors[key]
+		lines = append(lines, fmt.Sprintf("%s,%s,%d,%d", key.Address.Hex(), key.Mode, app.BlockNumber, app.TransactionIndex))
+	}
+
+	path := PathToCursors(chain, name)
+	if err := file.EstablishFolder(filepath.Dir(path)); err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	f, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.WriteString(strings.Join(lines, "\n") + "\n"); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// isPastCursor returns true if the appearance comes after the cursor
+func isPastCursor(app *types.AppRecord, cursor *types.AppRecord) bool {
+	if app.BlockNumber == cursor.BlockNumber {
+		return app.TransactionIndex > cursor.TransactionIndex
+	}
+	return app.BlockNumber > cursor.BlockNumber
+}
@@ -0,0 +1,67 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package monitor
+
+import (
+	"os"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/filter"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+func Test_Cursors_ReadWrite(t *testing.T) {
+	name := "test_cursor"
+	defer os.Remove(PathToCursors("mainnet", name))
+
+	cursors, err := ReadCursors("mainnet", name)
+	if err != nil || len(cursors) != 0 {
+		t.Fatal("expected an empty cursor, got", cursors, err)
+	}
+
+	addr := base.HexToAddress("0x049029dd41661e58f99271a0112dfd34695f7000")
+	cursors[CursorKey{Address: addr, Mode: "logs"}] = types.AppRecord{BlockNumber: 1001002, TransactionIndex: 1}
+	cursors[CursorKey{Address: addr, Mode: "traces"}] = types.AppRecord{BlockNumber: 1001003, TransactionIndex: 2}
+	if err := WriteCursors("mainnet", name, cursors); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadCursors("mainnet", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[CursorKey{Address: addr, Mode: "logs"}].BlockNumber != 1001002 {
+		t.Error("unexpected cursors", got)
+	}
+	if _, err := os.Stat(PathToCursors("mainnet", name) + ".tmp"); err == nil {
+		t.Error("temporary file should have been renamed")
+	}
+}
+
+func Test_Cursors_Names(t *testing.T) {
+	for name, expected := range map[string]bool{"mybook": true, "my-book_2": true, "": false, "../book": false, "my book": false} {
+		if IsValidCursorName(name) != expected {
+			t.Error("IsValidCursorName", name, "expected", expected)
+		}
+	}
+}
+
+func Test_Monitor_ReadAfterCursor(t *testing.T) {
+	mon := GetTestMonitor(t)
+	defer func() {
+		RemoveTestMonitor(&mon, t)
+	}()
+
+	mon.Cursor = &types.AppRecord{BlockNumber: 1001002, TransactionIndex: 1}
+	filt := filter.NewFilter(false, false, []string{}, base.BlockRange{First: 0, Last: base.NOPOSN}, base.RecordRange{First: 0, Last: base.NOPOS})
+	apps, cnt, err := mon.ReadAndFilterAppearances(filt, true /* withCount */)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cnt != 1 || apps[0].BlockNumber != 1001003 {
+		t.Error("expected only the appearance after the cursor, got", apps)
+	}
+}
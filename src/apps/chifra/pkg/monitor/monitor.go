@@ -18,6 +18,7 @@ import (
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/index"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/utils"
 )
 
@@ -36,6 +37,8 @@ type Monitor struct {
 	Staged  bool         `json:"-"`
 	Chain   string       `json:"-"`
 	ReadFp  *os.File     `json:"-"`
+	// Cursor, if not nil, is the last appearance already exported. Earlier appearances are skipped.
+	Cursor *types.AppRecord `json:"-"`
 	Header
 }
 
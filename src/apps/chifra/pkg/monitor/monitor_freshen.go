@@ -373,7 +373,7 @@ const (
 	asciiAddressSize    = 42
 	asciiBlockNumSize   = 9
 	asciiTxIdSize       = 5
-	asciiAppearanceSize = 59
+	asciiAppearanceSize = 64
 	startOfBlockNum     = 42 + 1
 	endOfBlockNum       = 42 + 1 + 9
 	startOfTxId         = 42 + 1 + 9 + 1
@@ -0,0 +1,43 @@
+package notify
+
+import "sync"
+
+// Listener receives the notifications published in this process (for example, by the scraper
+// that chifra daemon runs). It is called on the publisher's goroutine, so it must not block.
+type Listener func(notification any)
+
+var (
+	listeners     = map[int]Listener{}
+	listenerCount int
+	listenerMutex sync.RWMutex
+)
+
+// AddListener adds a listener and returns a function that removes it
+func AddListener(listener Listener) (remove func()) {
+	listenerMutex.Lock()
+	defer listenerMutex.Unlock()
+	listenerCount++
+	id := listenerCount
+	listeners[id] = listener
+	return func() {
+		listenerMutex.Lock()
+		defer listenerMutex.Unlock()
+		delete(listeners, id)
+	}
+}
+
+// HasListeners returns true if there is anyone in this process to publish to
+func HasListeners() bool {
+	listenerMutex.RLock()
+	defer listenerMutex.RUnlock()
+	return len(listeners) > 0
+}
+
+// Publish sends the notification to each of the listeners in this process
+func Publish[T NotificationPayload](notification Notification[T]) {
+	listenerMutex.RLock()
+	defer listenerMutex.RUnlock()
+	for _, listener := range listeners {
+		listener(notification)
+	}
+}
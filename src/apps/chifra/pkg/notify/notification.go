@@ -4,6 +4,10 @@ import "github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
 
 type Message string
 
+// SecretHeader carries the shared secret ([settings.notify] secret) with each notification posted
+// to the configured endpoint
+const SecretHeader = "X-Notify-Secret"
+
 type Notification[T NotificationPayload] struct {
 	Msg     Message         `json:"msg"`
 	Meta    *types.MetaData `json:"meta"`
@@ -14,5 +18,6 @@ type NotificationPayload interface {
 	[]NotificationPayloadAppearance |
 		[]NotificationPayloadChunkWritten |
 		NotificationPayloadChunkWritten |
+		NotificationPayloadReorg |
 		string
 }
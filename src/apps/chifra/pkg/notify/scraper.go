@@ -11,6 +11,7 @@ const (
 	MessageChunkWritten Message = "chunkWritten"
 	MessageStageUpdated Message = "stageUpdated"
 	MessageAppearance   Message = "appearance"
+	MessageReorg        Message = "reorg"
 )
 
 type NotificationPayloadAppearance struct {
@@ -64,3 +65,13 @@ type NotificationPayloadChunkWritten struct {
 	Range  string `json:"range"`
 	Author string `json:"author"`
 }
+
+// NotificationPayloadReorg tells consumers that the chain reorganized. Anything they derived from
+// blocks after ForkBlock (which both chains share) came from orphaned blocks and should be dropped.
+type NotificationPayloadReorg struct {
+	// We use strings for block numbers to ensure they're never
+	// too big
+	ForkBlock string `json:"forkBlock"`
+	// Orphaned is the range of blocks the scraper had seen on the orphaned chain
+	Orphaned string `json:"orphaned"`
+}
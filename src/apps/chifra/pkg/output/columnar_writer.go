@@ -0,0 +1,384 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	"github.com/apache/arrow/go/v17/arrow"
+	"github.com/apache/arrow/go/v17/arrow/array"
+	"github.com/apache/arrow/go/v17/arrow/ipc"
+	"github.com/apache/arrow/go/v17/arrow/memory"
+	"github.com/apache/arrow/go/v17/parquet"
+	"github.com/apache/arrow/go/v17/parquet/compress"
+	"github.com/apache/arrow/go/v17/parquet/pqarrow"
+)
+
+// columnarBatchSize is the number of rows collected before a record batch (arrow) or
+// a row group (parquet) is written to the output. Keeping this bounded means we never
+// hold more than this many rows in memory regardless of the size of the export.
+var columnarBatchSize = 8192
+
+// IsColumnarFormat returns true if the format is one of the binary, column-oriented
+// formats (parquet or arrow).
+func IsColumnarFormat(format string) bool {
+	return format == "parquet" || format == "arrow"
+}
+
+// recordWriter is the part of the parquet and arrow IPC writers we use
+type recordWriter interface {
+	Write(rec arrow.Record) error
+	Close() error
+}
+
+// ColumnarWriter writes Models as parquet or arrow IPC stream data. The schema is the union
+// of the fields of the model type (see ProbeType) and the keys of the models in the first
+// batch, so models that carry different fields (for example, only some are articulated) do
+// not lose columns. Once the schema is written, it cannot change, so a field that was not
+// found by either is left out (with a warning) rather than breaking the file. Big numbers
+// (base.Wei, base.Ether, etc.) and any other non-primitive values are written as strings so
+// that no precision is lost.
+type ColumnarWriter struct {
+	w       io.Writer
+	format  string
+	schema  *arrow.Schema
+	order   []string
+	columns map[string]int
+	pending []types.Model
+	probed  *types.Model
+	dropped map[string]bool
+	builder *array.RecordBuilder
+	writer  recordWriter
+	nRows   int
+	mem     memory.Allocator
+}
+
+// NewColumnarWriter returns a ColumnarWriter for the given format (one of parquet or arrow).
+func NewColumnarWriter(w io.Writer, format string) (*ColumnarWriter, error) {
+	if !IsColumnarFormat(format) {
+		return nil, fmt.Errorf("unknown columnar format %s", format)
+	}
+	return &ColumnarWriter{
+		w:      w,
+		format: format,
+		mem:    memory.NewGoAllocator(),
+	}, nil
+}
+
+// ProbeType finds the full set of fields that models of the given type may carry. Model() leaves
+// out fields that have no value (for example, the articulation of a transaction that was not
+// articulated), so we model an instance of the type in which every field has a value. Call it
+// before the first Write with the model's type and the function that turns it into a Model.
+func (cw *ColumnarWriter) ProbeType(model types.Modeler, toModel func(types.Modeler) types.Model) {
+	// A type that cannot be modeled this way leaves the schema to the first batch
+	defer func() {
+		_ = recover()
+	}()
+
+	t := reflect.TypeOf(model)
+	if t == nil || t.Kind() != reflect.Pointer || t.Elem().Kind() != reflect.Struct {
+		return
+	}
+	instance := reflect.New(t.Elem())
+	fillProbe(instance.Elem(), 0)
+	if probe, ok := instance.Interface().(types.Modeler); ok {
+		probed := toModel(probe)
+		cw.probed = &probed
+	}
+}
+
+// Write adds a single model to the current batch, flushing the batch if it is full. Until
+// the first batch is full, models are held so the schema can be built from all of them.
+func (cw *ColumnarWriter) Write(model types.Model) error {
+	if cw.schema == nil {
+		cw.pending = append(cw.pending, model)
+		if len(cw.pending) < columnarBatchSize {
+			return nil
+		}
+		return cw.open()
+	}
+
+	if err := cw.append(model); err != nil {
+		return err
+	}
+	if cw.nRows >= columnarBatchSize {
+		return cw.flush()
+	}
+	return nil
+}
+
+// Close flushes any pending rows and writes the file's footer (if any).
+func (cw *ColumnarWriter) Close() error {
+	if cw.schema == nil {
+		// an empty (but valid) file if nothing was written
+		if err := cw.open(); err != nil {
+			return err
+		}
+	}
+	defer cw.builder.Release()
+
+	if err := cw.flush(); err != nil {
+		return err
+	}
+	return cw.writer.Close()
+}
+
+// append adds the model's values to the current batch
+func (cw *ColumnarWriter) append(model types.Model) error {
+	for _, key := range model.Order {
+		if _, ok := cw.columns[key]; !ok && !cw.dropped[key] {
+			cw.dropped[key] = true
+			logger.Warn("The field", key, "is not in the", cw.format, "schema and was left out")
+		}
+	}
+	for i, key := range cw.order {
+		appendValue(cw.builder.Field(i), model.Data[key])
+	}
+	cw.nRows++
+	return nil
+}
+
+// open builds the schema from the probed type and the pending models, opens the underlying
+// writer, and adds the pending models to the first batch
+func (cw *ColumnarWriter) open() error {
+	cw.order = []string{}
+	cw.columns = map[string]int{}
+	cw.dropped = map[string]bool{}
+	models := cw.pending
+	if cw.probed != nil {
+		// The probe's order is the type's natural order
+		models = append([]types.Model{*cw.probed}, models...)
+	}
+	for _, model := range models {
+		for _, key := range model.Order {
+			if _, ok := cw.columns[key]; !ok {
+				cw.columns[key] = len(cw.order)
+				cw.order = append(cw.order, key)
+			}
+		}
+	}
+	colTypes := map[string]arrow.DataType{}
+	for _, model := range cw.pending {
+		for _, key := range model.Order {
+			if _, ok := colTypes[key]; !ok && model.Data[key] != nil {
+				colTypes[key] = arrowTypeOf(model.Data[key])
+			}
+		}
+	}
+	if cw.probed != nil {
+		for key, value := range cw.probed.Data {
+			if _, ok := colTypes[key]; !ok && value != nil {
+				colTypes[key] = arrowTypeOf(value)
+			}
+		}
+	}
+
+	fields := make([]arrow.Field, 0, len(cw.order))
+	for _, key := range cw.order {
+		colType, ok := colTypes[key]
+		if !ok {
+			colType = arrowTypeOf(nil)
+		}
+		fields = append(fields, arrow.Field{
+			Name:     key,
+			Type:     colType,
+			Nullable: true,
+		})
+	}
+	cw.schema = arrow.NewSchema(fields, nil)
+	cw.builder = array.NewRecordBuilder(cw.mem, cw.schema)
+
+	switch cw.format {
+	case "parquet":
+		props := parquet.NewWriterProperties(
+			parquet.WithCompression(compress.Codecs.Snappy),
+			parquet.WithMaxRowGroupLength(int64(columnarBatchSize)),
+		)
+		arrowProps := pqarrow.NewArrowWriterProperties(pqarrow.WithStoreSchema())
+		writer, err := pqarrow.NewFileWriter(cw.schema, cw.w, props, arrowProps)
+		if err != nil {
+			return err
+		}
+		cw.writer = writer
+	case "arrow":
+		cw.writer = ipc.NewWriter(cw.w, ipc.WithSchema(cw.schema), ipc.WithAllocator(cw.mem))
+	}
+
+	pending := cw.pending
+	cw.pending = nil
+	for _, model := range pending {
+		if err := cw.append(model); err != nil {
+			return err
+		}
+	}
+	if cw.nRows >= columnarBatchSize {
+		return cw.flush()
+	}
+	return nil
+}
+
+// flush writes the pending rows as a single record batch (or row group)
+func (cw *ColumnarWriter) flush() error {
+	if cw.nRows == 0 {
+		return nil
+	}
+	rec := cw.builder.NewRecord()
+	defer rec.Release()
+	cw.nRows = 0
+	return cw.writer.Write(rec)
+}
+
+// fillProbe gives every field of v a value (so that Model() includes it) going no deeper than
+// a few levels into nested types
+func fillProbe(v reflect.Value, depth int) {
+	if depth > 5 || !v.CanSet() {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		v.Set(reflect.New(v.Type().Elem()))
+		fillProbe(v.Elem(), depth+1)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			fillProbe(v.Field(i), depth+1)
+		}
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fillProbe(v.Index(0), depth+1)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			fillProbe(v.Index(i), depth+1)
+		}
+	case reflect.Map:
+		v.Set(reflect.MakeMap(v.Type()))
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1)
+	case reflect.String:
+		v.SetString("1")
+	}
+}
+
+// arrowTypeOf returns the column type for a model value. Anything that is not a
+// simple integer, float, or boolean becomes a string column.
+func arrowTypeOf(value any) arrow.DataType {
+	if value == nil {
+		return arrow.BinaryTypes.String
+	}
+
+	t := reflect.TypeOf(value)
+	if t.Kind() == reflect.Pointer && t.Elem().Kind() != reflect.Struct {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return arrow.FixedWidthTypes.Boolean
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return arrow.PrimitiveTypes.Int64
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return arrow.PrimitiveTypes.Uint64
+	case reflect.Float32, reflect.Float64:
+		return arrow.PrimitiveTypes.Float64
+	default:
+		return arrow.BinaryTypes.String
+	}
+}
+
+// appendValue appends the value to the column's builder, converting if the value's
+// type differs from the column's type (which may happen, for example, when the first
+// record had a nil in a column). Values that cannot be converted are stored as null.
+func appendValue(b array.Builder, value any) {
+	if value == nil {
+		b.AppendNull()
+		return
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			b.AppendNull()
+			return
+		}
+		if k := v.Elem().Kind(); k != reflect.Struct {
+			v = v.Elem()
+			value = v.Interface()
+		}
+	}
+
+	switch builder := b.(type) {
+	case *array.BooleanBuilder:
+		if v.Kind() == reflect.Bool {
+			builder.Append(v.Bool())
+		} else if parsed, err := strconv.ParseBool(fmt.Sprint(value)); err == nil {
+			builder.Append(parsed)
+		} else {
+			builder.AppendNull()
+		}
+
+	case *array.Int64Builder:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			builder.Append(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			builder.Append(int64(v.Uint()))
+		default:
+			if i, err := strconv.ParseInt(fmt.Sprint(value), 0, 64); err == nil {
+				builder.Append(i)
+			} else {
+				builder.AppendNull()
+			}
+		}
+
+	case *array.Uint64Builder:
+		switch v.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			builder.Append(v.Uint())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			builder.Append(uint64(v.Int()))
+		default:
+			if u, err := strconv.ParseUint(fmt.Sprint(value), 0, 64); err == nil {
+				builder.Append(u)
+			} else {
+				builder.AppendNull()
+			}
+		}
+
+	case *array.Float64Builder:
+		switch v.Kind() {
+		case reflect.Float32, reflect.Float64:
+			builder.Append(v.Float())
+		default:
+			if f, err := strconv.ParseFloat(fmt.Sprint(value), 64); err == nil {
+				builder.Append(f)
+			} else {
+				builder.AppendNull()
+			}
+		}
+
+	case *array.StringBuilder:
+		switch v.Kind() {
+		case reflect.Map, reflect.Slice:
+			if bytes, err := json.Marshal(value); err == nil {
+				builder.Append(string(bytes))
+			} else {
+				builder.Append(fmt.Sprint(value))
+			}
+		default:
+			builder.Append(fmt.Sprint(value))
+		}
+
+	default:
+		b.AppendNull()
+	}
+}
@@ -0,0 +1,307 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+	"github.com/apache/arrow/go/v17/arrow"
+	"github.com/apache/arrow/go/v17/arrow/array"
+	"github.com/apache/arrow/go/v17/arrow/ipc"
+	"github.com/apache/arrow/go/v17/arrow/memory"
+	"github.com/apache/arrow/go/v17/parquet/file"
+	"github.com/apache/arrow/go/v17/parquet/pqarrow"
+)
+
+var bigWei = "115792089237316195423570985008687907853269984665640564039457584007913129639935"
+
+type columnarModel struct {
+	blockNumber base.Blknum
+	value       base.Wei
+	isError     bool
+}
+
+func (c *columnarModel) Model(chain, format string, verbose bool, extraOpts map[string]any) types.Model {
+	return types.Model{
+		Data: map[string]any{
+			"blockNumber": c.blockNumber,
+			"value":       &c.value,
+			"isError":     c.isError,
+		},
+		Order: []string{"blockNumber", "value", "isError"},
+	}
+}
+
+func streamColumnar(t *testing.T, format string, nRows int) []byte {
+	buffer := &bytes.Buffer{}
+	fetchData := func(modelChan chan types.Modeler, errorChan chan error) {
+		value, _ := base.NewWei(0).SetString(bigWei, 10)
+		for i := 0; i < nRows; i++ {
+			modelChan <- &columnarModel{
+				blockNumber: base.Blknum(i),
+				value:       *value,
+				isError:     i%2 == 0,
+			}
+		}
+	}
+	rCtx := NewRenderContext()
+	if err := StreamMany(rCtx, fetchData, OutputOptions{
+		Writer: buffer,
+		Format: format,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func checkColumnarTable(t *testing.T, schema *arrow.Schema, recs []arrow.Record, nRows int) {
+	if len(schema.Fields()) != 3 {
+		t.Fatal("wrong number of fields", schema)
+	}
+	if schema.Field(0).Type.ID() != arrow.UINT64 || schema.Field(1).Type.ID() != arrow.STRING || schema.Field(2).Type.ID() != arrow.BOOL {
+		t.Fatal("unexpected schema", schema)
+	}
+
+	row := 0
+	for _, rec := range recs {
+		blocks := rec.Column(0).(*array.Uint64)
+		values := rec.Column(1).(*array.String)
+		for i := 0; i < int(rec.NumRows()); i++ {
+			if blocks.Value(i) != uint64(row) {
+				t.Fatal("mismatched block number", blocks.Value(i), row)
+			}
+			if values.Value(i) != bigWei {
+				t.Fatal("lost precision for wei value", values.Value(i))
+			}
+			row++
+		}
+	}
+	if row != nRows {
+		t.Fatal("wrong number of rows", row, nRows)
+	}
+}
+
+func TestStreamArrow(t *testing.T) {
+	nRows := columnarBatchSize + 10
+	data := streamColumnar(t, "arrow", nRows)
+
+	reader, err := ipc.NewReader(bytes.NewReader(data), ipc.WithAllocator(memory.DefaultAllocator))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Release()
+
+	recs := []arrow.Record{}
+	for reader.Next() {
+		rec := reader.Record()
+		rec.Retain()
+		defer rec.Release()
+		recs = append(recs, rec)
+	}
+	if len(recs) != 2 {
+		t.Fatal("expected rows to be streamed in two batches, got", len(recs))
+	}
+	checkColumnarTable(t, reader.Schema(), recs, nRows)
+}
+
+func TestStreamParquet(t *testing.T) {
+	nRows := columnarBatchSize + 10
+	data := streamColumnar(t, "parquet", nRows)
+
+	pf, err := file.NewParquetReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pf.Close()
+	if pf.NumRowGroups() != 2 {
+		t.Fatal("expected two row groups, got", pf.NumRowGroups())
+	}
+
+	fr, err := pqarrow.NewFileReader(pf, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, err := fr.ReadTable(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer table.Release()
+
+	tr := array.NewTableReader(table, int64(nRows))
+	defer tr.Release()
+	recs := []arrow.Record{}
+	for tr.Next() {
+		rec := tr.Record()
+		rec.Retain()
+		defer rec.Release()
+		recs = append(recs, rec)
+	}
+	checkColumnarTable(t, table.Schema(), recs, nRows)
+}
+
+func TestStreamParquetEmpty(t *testing.T) {
+	data := streamColumnar(t, "parquet", 0)
+	pf, err := file.NewParquetReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pf.Close()
+	if pf.NumRows() != 0 {
+		t.Fatal("expected empty file")
+	}
+}
+
+func TestColumnarSchemaUnion(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	cw, err := NewColumnarWriter(buffer, "arrow")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only some of the models are articulated, the column must still be present
+	plain := types.Model{
+		Data:  map[string]any{"hash": "0x1", "gasUsed": base.Gas(21000)},
+		Order: []string{"hash", "gasUsed"},
+	}
+	articulated := types.Model{
+		Data:  map[string]any{"hash": "0x2", "gasUsed": base.Gas(50000), "articulatedTx": "transfer"},
+		Order: []string{"hash", "gasUsed", "articulatedTx"},
+	}
+	for _, model := range []types.Model{plain, articulated, plain} {
+		if err = cw.Write(model); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = cw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := ipc.NewReader(bytes.NewReader(buffer.Bytes()), ipc.WithAllocator(memory.DefaultAllocator))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Release()
+
+	schema := reader.Schema()
+	if len(schema.Fields()) != 3 || schema.Field(2).Name != "articulatedTx" || schema.Field(1).Type.ID() != arrow.UINT64 {
+		t.Fatal("unexpected schema", schema)
+	}
+	if !reader.Next() {
+		t.Fatal("expected a record")
+	}
+	col := reader.Record().Column(2).(*array.String)
+	if col.IsValid(0) || col.Value(1) != "transfer" || col.IsValid(2) {
+		t.Fatal("unexpected articulated column", col)
+	}
+}
+
+func TestColumnarSchemaLateField(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	cw, err := NewColumnarWriter(buffer, "parquet")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain := types.Model{
+		Data:  map[string]any{"hash": "0x1"},
+		Order: []string{"hash"},
+	}
+	for i := 0; i < columnarBatchSize; i++ {
+		if err = cw.Write(plain); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The schema has been written, so a field nothing told us about is left out...
+	extra := types.Model{
+		Data:  map[string]any{"hash": "0x2", "extra": "value"},
+		Order: []string{"hash", "extra"},
+	}
+	if err = cw.Write(extra); err != nil {
+		t.Fatal(err)
+	}
+	if err = cw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// ...but the file is complete
+	pf, err := file.NewParquetReader(bytes.NewReader(buffer.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pf.Close()
+	if pf.NumRows() != int64(columnarBatchSize+1) || pf.MetaData().Schema.NumColumns() != 1 {
+		t.Fatal("unexpected file", pf.NumRows(), pf.MetaData().Schema.NumColumns())
+	}
+}
+
+// optionalModel leaves out its articulation unless it has one, as the types in pkg/types do
+type optionalModel struct {
+	Hash        string
+	Articulated *string
+}
+
+func (o *optionalModel) Model(chain, format string, verbose bool, extraOpts map[string]any) types.Model {
+	model := types.Model{
+		Data:  map[string]any{"hash": o.Hash},
+		Order: []string{"hash"},
+	}
+	if o.Articulated != nil {
+		model.Data["articulatedTx"] = *o.Articulated
+		model.Order = append(model.Order, "articulatedTx")
+	}
+	return model
+}
+
+func TestStreamColumnarProbesType(t *testing.T) {
+	// Only the very last record (long after the schema was written) is articulated
+	nRows := columnarBatchSize + 1
+	fetchData := func(modelChan chan types.Modeler, errorChan chan error) {
+		for i := 0; i < nRows; i++ {
+			model := &optionalModel{Hash: fmt.Sprintf("0x%x", i)}
+			if i == nRows-1 {
+				articulated := "transfer"
+				model.Articulated = &articulated
+			}
+			modelChan <- model
+		}
+	}
+	buffer := &bytes.Buffer{}
+	if err := StreamMany(NewRenderContext(), fetchData, OutputOptions{Writer: buffer, Format: "arrow"}); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := ipc.NewReader(bytes.NewReader(buffer.Bytes()), ipc.WithAllocator(memory.DefaultAllocator))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Release()
+	schema := reader.Schema()
+	if len(schema.Fields()) != 2 || schema.Field(1).Name != "articulatedTx" || schema.Field(1).Type.ID() != arrow.STRING {
+		t.Fatal("unexpected schema", schema)
+	}
+
+	values := []string{}
+	for reader.Next() {
+		col := reader.Record().Column(1).(*array.String)
+		for i := 0; i < col.Len(); i++ {
+			values = append(values, col.ValueStr(i))
+		}
+	}
+	if len(values) != nRows || values[0] != array.NullValueStr || values[nRows-1] != "transfer" {
+		t.Fatal("expected the late articulation to be written", len(values))
+	}
+}
+
+func TestStreamModelColumnar(t *testing.T) {
+	model := types.Model{
+		Data:  map[string]any{"hash": "0x1"},
+		Order: []string{"hash"},
+	}
+	if err := StreamModel(&bytes.Buffer{}, model, OutputOptions{Format: "parquet"}); err == nil {
+		t.Fatal("expected an error streaming a single model as parquet")
+	}
+}
@@ -2,6 +2,7 @@ package output
 
 import (
 	"io"
+	"net/http"
 	"os"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
@@ -35,10 +36,35 @@ func (opts *OutputOptions) GetOutputFileWriter() io.Writer {
 
 // IsApiMode return true if `w` is successfully cast into a `http.ResponseWriter`
 func (opts *OutputOptions) IsApiMode() bool {
-	w, ok := opts.Writer.(*JsonWriter)
-	if !ok {
+	switch w := opts.Writer.(type) {
+	case *JsonWriter:
+		return utils.IsServerWriter(*w.GetOutputWriter())
+	case *NdjsonWriter:
+		return utils.IsServerWriter(*w.GetOutputWriter())
+	default:
 		return utils.IsServerWriter(opts.Writer)
 	}
+}
+
+// Flush makes sure everything written to w so far has been delivered: buffered writers are
+// flushed and regular files are synced to disc. It returns an error if delivery failed.
+func Flush(w io.Writer) error {
+	switch ww := w.(type) {
+	case *JsonWriter:
+		w = *ww.GetOutputWriter()
+	case *NdjsonWriter:
+		w = *ww.GetOutputWriter()
+	}
 
-	return utils.IsServerWriter(*w.GetOutputWriter())
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+		return nil
+	} else if f, ok := w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	} else if f, ok := w.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.Mode().IsRegular() {
+			return f.Sync()
+		}
+	}
+	return nil
 }
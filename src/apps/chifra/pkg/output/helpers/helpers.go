@@ -35,6 +35,8 @@ func PreRunWithJsonWriter(cmdName string, getOptions func() *globals.GlobalOptio
 		if opts.Format == "json" {
 			jw := output.NewDefaultJsonWriter(outputWriter, true)
 			opts.Writer = jw
+		} else if opts.Format == "ndjson" {
+			opts.Writer = output.NewNdjsonWriter(outputWriter)
 		} else {
 			// ...or set the default writer as global writer for the current command
 			// invocation
@@ -50,7 +52,11 @@ func PostRunWithJsonWriter(getOptions func() *globals.GlobalOptions) func(cmd *c
 		// Try to cast the global writer to JsonWriter
 		jw, ok := w.(*output.JsonWriter)
 		if !ok {
-			// If it's not JsonWriter, do nothing
+			// If it's an NdjsonWriter, close it (prints the meta line if needed)...
+			if nw, ok := w.(*output.NdjsonWriter); ok {
+				nw.Close()
+			}
+			// ...otherwise, do nothing
 			return
 		}
 		// If it is JsonWriter, close it (print closing brackets)
@@ -61,10 +67,33 @@ func PostRunWithJsonWriter(getOptions func() *globals.GlobalOptions) func(cmd *c
 // SetWriterForCommand sets the writer for currently running command, but only if
 // we are running with --file
 func SetWriterForCommand(cmdName string, opts *globals.GlobalOptions) {
+	// Global writer is NdjsonWriter. There are no brackets to close, but we
+	// may have to write the meta line. If this command also wants NDJSON, we
+	// keep it. Otherwise, we reset the global writer to the default.
+	if nw, ok := opts.Writer.(*output.NdjsonWriter); ok {
+		if opts.Format == "ndjson" {
+			return
+		}
+		nw.Close()
+		opts.Writer = os.Stdout
+		opts.Writer = opts.GetOutputFileWriter()
+	}
+
 	// Try to cast the default writer to JsonWriter
 	jw, ok := opts.Writer.(*output.JsonWriter)
 	wantsJson := (opts.Format == "json")
 
+	// This command wants to output NDJSON, so we close the JsonWriter if
+	// there is one and wrap the default writer
+	if opts.Format == "ndjson" {
+		if ok {
+			jw.Close()
+		}
+		opts.Writer = os.Stdout
+		opts.Writer = output.NewNdjsonWriter(opts.GetOutputFileWriter())
+		return
+	}
+
 	// Global writer is set to JsonWriter, but this command wants to output
 	// a different format. We have to close JsonWriter so that closing brackets
 	// are printed and switch global writer to io.Writer
@@ -105,11 +134,25 @@ func InitJsonWriterApi(cmdName string, w io.Writer, opts *globals.GlobalOptions)
 		}
 		opts.Writer = jw
 	}
+
+	_, ok = opts.Writer.(*output.NdjsonWriter)
+	if opts.Format == "ndjson" && !ok {
+		nw := output.NewNdjsonWriter(w)
+		nw.ShouldWriteMeta = true
+		nw.GetMeta = func() (*types.MetaData, error) {
+			chain := opts.Chain
+			conn := rpc.TempConnection(chain)
+			return conn.GetMetaData(opts.OutputOptions.TestMode)
+		}
+		opts.Writer = nw
+	}
 }
 
-// CloseJsonWriterIfNeededApi will close JsonWriter if the format is json
+// CloseJsonWriterIfNeededApi will close JsonWriter if the format is json (or NdjsonWriter if ndjson)
 func CloseJsonWriterIfNeededApi(cmdName string, err error, opts *globals.GlobalOptions) {
 	if opts.Format == "json" && err == nil {
 		opts.Writer.(*output.JsonWriter).Close()
+	} else if opts.Format == "ndjson" && err == nil {
+		opts.Writer.(*output.NdjsonWriter).Close()
 	}
 }
@@ -0,0 +1,88 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// NdjsonWriter writes newline delimited JSON. Each item is written as a single, self-contained
+// JSON object on its own line and is flushed immediately so that consumers (jq -c, message
+// queues, etc.) can process the records as they are produced. Errors and meta data are written
+// as tagged lines (`{"error": ...}` and `{"meta": ...}`) so they can be told apart from the data.
+type NdjsonWriter struct {
+	// the writer that we will output to
+	outputWriter io.Writer
+	// function to get meta data
+	GetMeta func() (*types.MetaData, error)
+	// flag indicating if we should output `meta` line on Close
+	ShouldWriteMeta bool
+	mutex           sync.Mutex
+}
+
+// NewNdjsonWriter creates NdjsonWriter with some useful defaults
+func NewNdjsonWriter(w io.Writer) *NdjsonWriter {
+	return &NdjsonWriter{
+		outputWriter: w,
+		GetMeta: func() (*types.MetaData, error) {
+			return &types.MetaData{}, nil
+		},
+	}
+}
+
+// Write writes bytes p followed by a newline and flushes the underlying writer. `p`
+// must already be a single line of JSON. In most cases, you should use `WriteItem` instead.
+func (w *NdjsonWriter) Write(p []byte) (n int, err error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if n, err = w.outputWriter.Write(append(p, '\n')); err != nil {
+		return
+	}
+	w.flush()
+	return
+}
+
+// WriteItem writes `value` as a single line
+func (w *NdjsonWriter) WriteItem(value any) (n int, err error) {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return 0, err
+	}
+	return w.Write(bytes)
+}
+
+// WriteError writes the error as a tagged line
+func (w *NdjsonWriter) WriteError(err error) {
+	_, _ = w.WriteItem(map[string]string{"error": err.Error()})
+}
+
+// Close writes the meta data line (if requested)
+func (w *NdjsonWriter) Close() error {
+	if !w.ShouldWriteMeta {
+		return nil
+	}
+	meta, err := w.GetMeta()
+	if err != nil {
+		w.WriteError(err)
+		return nil
+	}
+	_, err = w.WriteItem(map[string]any{"meta": meta})
+	return err
+}
+
+func (w *NdjsonWriter) GetOutputWriter() *io.Writer {
+	return &w.outputWriter
+}
+
+// flush pushes buffered data to the client, for example when serving the API
+func (w *NdjsonWriter) flush() {
+	if f, ok := w.outputWriter.(http.Flusher); ok {
+		f.Flush()
+	} else if f, ok := w.outputWriter.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+}
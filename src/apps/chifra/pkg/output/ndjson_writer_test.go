@@ -0,0 +1,116 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// syncBuffer lets the producer peek at what the consumer has written so far
+type syncBuffer struct {
+	bytes.Buffer
+	mutex sync.Mutex
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.Buffer.Write(p)
+}
+
+func (b *syncBuffer) lines() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return strings.Count(b.Buffer.String(), "\n")
+}
+
+func TestNdjsonStreamMany(t *testing.T) {
+	buffer := &syncBuffer{}
+	nw := NewNdjsonWriter(buffer)
+	nw.ShouldWriteMeta = true
+	nw.GetMeta = func() (*types.MetaData, error) {
+		return &types.MetaData{Latest: 1000}, nil
+	}
+
+	fetchData := func(modelChan chan types.Modeler, errorChan chan error) {
+		modelChan <- &types.Receipt{
+			BlockNumber:     123,
+			TransactionHash: base.HexToHash("0xdeadbeef"),
+			Status:          1,
+		}
+		errorChan <- errors.New("something went wrong")
+		modelChan <- &types.Receipt{
+			BlockNumber:     124,
+			TransactionHash: base.HexToHash("0xdeadbeef2"),
+			Status:          1,
+		}
+		// Each record must be on the wire before the next one is produced
+		if buffer.lines() < 2 {
+			t.Error("records were not flushed as they were produced")
+		}
+	}
+
+	rCtx := NewRenderContext()
+	err := StreamMany(rCtx, fetchData, OutputOptions{
+		Writer: nw,
+		Format: "ndjson",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nw.Close()
+
+	var receipts []types.Receipt
+	var errs []string
+	var meta *types.MetaData
+	scanner := bufio.NewScanner(&buffer.Buffer)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		var tagged map[string]json.RawMessage
+		if err := json.Unmarshal(line, &tagged); err != nil {
+			t.Fatal("each line must be a self-contained JSON object:", string(line))
+		}
+		if msg, ok := tagged["error"]; ok {
+			var s string
+			_ = json.Unmarshal(msg, &s)
+			errs = append(errs, s)
+		} else if m, ok := tagged["meta"]; ok {
+			meta = &types.MetaData{}
+			_ = json.Unmarshal(m, meta)
+		} else {
+			var r types.Receipt
+			_ = json.Unmarshal(line, &r)
+			receipts = append(receipts, r)
+		}
+	}
+
+	if len(receipts) != 2 || receipts[0].BlockNumber != 123 || receipts[1].BlockNumber != 124 {
+		t.Fatal("mismatched data", receipts)
+	}
+	if len(errs) != 1 || errs[0] != "something went wrong" {
+		t.Fatal("mismatched errors", errs)
+	}
+	if meta == nil || meta.Latest != 1000 {
+		t.Fatal("missing meta line")
+	}
+}
+
+func TestNdjsonFlushesServer(t *testing.T) {
+	rec := httptest.NewRecorder()
+	nw := NewNdjsonWriter(rec)
+	_, _ = nw.WriteItem(map[string]any{"blockNumber": 1})
+	if !rec.Flushed {
+		t.Fatal("expected the response writer to be flushed")
+	}
+	if rec.Body.String() != "{\"blockNumber\":1}\n" {
+		t.Fatal("unexpected output", rec.Body.String())
+	}
+}
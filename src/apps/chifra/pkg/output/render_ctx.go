@@ -2,6 +2,7 @@ package output
 
 import (
 	"context"
+	"sync/atomic"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
 )
@@ -11,6 +12,7 @@ type RenderCtx struct {
 	Cancel    context.CancelFunc `json:"-"`
 	ModelChan chan types.Modeler `json:"-"`
 	ErrorChan chan error         `json:"-"`
+	nErrors   atomic.Int64
 }
 
 func NewRenderContext() *RenderCtx {
@@ -37,3 +39,9 @@ func (r *RenderCtx) WasCanceled() bool {
 		return false
 	}
 }
+
+// HadErrors returns true if any of the data streamed with this context reported an error. The
+// command may still succeed, but some of its records were not delivered.
+func (r *RenderCtx) HadErrors() bool {
+	return r.nErrors.Load() > 0
+}
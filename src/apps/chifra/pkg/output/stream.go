@@ -63,6 +63,20 @@ func StreamModel(w io.Writer, model types.Model, options OutputOptions) error {
 		return nil
 	}
 
+	if options.Format == "ndjson" {
+		nw, ok := w.(*NdjsonWriter)
+		if !ok {
+			nw = NewNdjsonWriter(w)
+		}
+		_, err := nw.WriteItem(model.Data)
+		return err
+	}
+
+	if IsColumnarFormat(options.Format) {
+		// Each call would produce a separate file, so columnar output must go through StreamMany
+		return fmt.Errorf("format %s cannot be streamed one record at a time", options.Format)
+	}
+
 	// Store map items as strings. All formats other than JSON need string data
 	strs := make([]string, 0, len(model.Order))
 	for _, key := range model.Order {
@@ -107,7 +121,19 @@ type fetchDataFunc func(modelChan chan types.Modeler, errorChan chan error)
 // StreamMany outputs models as they are acquired
 func StreamMany(rCtx *RenderCtx, fetchData fetchDataFunc, options OutputOptions) error {
 	if rCtx.ModelChan != nil {
-		fetchData(rCtx.ModelChan, rCtx.ErrorChan)
+		// Pass errors on to the caller, counting them as they go by
+		errorChan := make(chan error)
+		done := make(chan struct{})
+		go func() {
+			for err := range errorChan {
+				rCtx.nErrors.Add(1)
+				rCtx.ErrorChan <- err
+			}
+			close(done)
+		}()
+		fetchData(rCtx.ModelChan, errorChan)
+		close(errorChan)
+		<-done
 		return nil
 	}
 
@@ -124,16 +150,29 @@ func StreamMany(rCtx *RenderCtx, fetchData fetchDataFunc, options OutputOptions)
 	}()
 
 	isJson := options.Format == "json"
+	isNdjson := options.Format == "ndjson"
 	var jw *JsonWriter
-	if !isJson {
+	var nw *NdjsonWriter
+	if isJson {
+		jw = options.Writer.(*JsonWriter)
+	} else if isNdjson {
+		var ok bool
+		if nw, ok = options.Writer.(*NdjsonWriter); !ok {
+			nw = NewNdjsonWriter(options.Writer)
+		}
+	} else {
 		defer func() {
 			if len(errsToReport) == 0 {
 				return
 			}
 			logErrors(errsToReport)
 		}()
-	} else {
-		jw = options.Writer.(*JsonWriter)
+	}
+
+	// NDJSON lines carry the same (nested) data as JSON output
+	modelFormat := options.Format
+	if isNdjson {
+		modelFormat = "json"
 	}
 
 	// If user wants custom format, we have to prepare the template
@@ -143,19 +182,39 @@ func StreamMany(rCtx *RenderCtx, fetchData fetchDataFunc, options OutputOptions)
 		return err
 	}
 
+	// Columnar formats (parquet and arrow) write batches of rows as they arrive
+	var cw *ColumnarWriter
+	if IsColumnarFormat(options.Format) {
+		if cw, err = NewColumnarWriter(options.Writer, options.Format); err != nil {
+			return err
+		}
+	}
+
 	errsMutex := sync.Mutex{}
 	for {
 		select {
 		case model, ok := <-modelChan:
 			if !ok {
+				if cw != nil {
+					return cw.Close()
+				}
 				return nil
 			}
 
 			// If the output is JSON and we are printing another item, put `,` in front of it
 			var err error
-			modelValue := model.Model(options.Chain, options.Format, options.Verbose, options.Extra)
+			modelValue := model.Model(options.Chain, modelFormat, options.Verbose, options.Extra)
 			if customFormat {
 				err = StreamWithTemplate(options.Writer, modelValue, tmpl)
+			} else if cw != nil {
+				if first {
+					cw.ProbeType(model, func(m types.Modeler) types.Model {
+						return m.Model(options.Chain, modelFormat, options.Verbose, options.Extra)
+					})
+				}
+				err = cw.Write(modelValue)
+			} else if isNdjson {
+				_, err = nw.WriteItem(modelValue.Data)
 			} else {
 				err = StreamModel(options.Writer, modelValue, OutputOptions{
 					NoHeader:   !first || options.NoHeader,
@@ -172,15 +231,21 @@ func StreamMany(rCtx *RenderCtx, fetchData fetchDataFunc, options OutputOptions)
 			if !ok {
 				continue
 			}
+			rCtx.nErrors.Add(1)
 			errsMutex.Lock()
 			if isJson {
 				jw.WriteError(err)
+			} else if isNdjson {
+				nw.WriteError(err)
 			} else {
 				errsToReport = append(errsToReport, err.Error())
 			}
 			errsMutex.Unlock()
 
 		case <-rCtx.Ctx.Done():
+			if cw != nil {
+				_ = cw.Close()
+			}
 			err = rCtx.Ctx.Err()
 			if err == context.Canceled {
 				return nil
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
 	"text/template"
@@ -214,3 +215,49 @@ func TestApiFormat(t *testing.T) {
 		t.Fatal("Api format is no longer allow. Should error here.")
 	}
 }
+
+func TestStreamManyCountsErrors(t *testing.T) {
+	fetchData := func(modelChan chan types.Modeler, errorChan chan error) {
+		modelChan <- &types.Receipt{BlockNumber: 123}
+		errorChan <- errors.New("something went wrong")
+		modelChan <- &types.Receipt{BlockNumber: 124}
+	}
+
+	// The error is reported, but the stream does not fail...
+	rCtx := NewRenderContext()
+	if err := StreamMany(rCtx, fetchData, OutputOptions{Writer: &bytes.Buffer{}, Format: "csv"}); err != nil {
+		t.Fatal(err)
+	}
+	if !rCtx.HadErrors() {
+		t.Error("expected the context to know that a record failed")
+	}
+
+	// ...nor does it when the models and errors are passed on to the caller
+	rCtx = NewStreamingContext()
+	nModels, nErrors := 0, 0
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case _, ok := <-rCtx.ModelChan:
+				if !ok {
+					return
+				}
+				nModels++
+			case <-rCtx.ErrorChan:
+				nErrors++
+			}
+		}
+	}()
+	_ = StreamMany(rCtx, fetchData, OutputOptions{})
+	close(rCtx.ModelChan)
+	<-done
+	if nModels != 2 || nErrors != 1 || !rCtx.HadErrors() {
+		t.Error("expected two models and one counted error", nModels, nErrors)
+	}
+
+	if rCtx := NewRenderContext(); rCtx.HadErrors() {
+		t.Error("expected a fresh context to have no errors")
+	}
+}
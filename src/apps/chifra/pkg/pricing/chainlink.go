@@ -0,0 +1,90 @@
+package pricing
+
+import (
+	"fmt"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+var (
+	chainlinkEthUsd                = base.HexToAddress("0x5f4ec3df9cbd43714fe2740f5e3616155c5b8419") // ETH / USD aggregator proxy
+	chainlinkEthUsd_deployed       = base.Blknum(10606501)
+	chainlinkFeedRegistry          = base.HexToAddress("0x47fb2585d2c56fe188d0e6ec628a38b74fceeedf")
+	chainlinkFeedRegistry_deployed = base.Blknum(12864088)
+	chainlinkUsdDenomination       = base.HexToAddress("0x0000000000000000000000000000000000000348") // ISO 4217 code for USD
+	chainlinkMaxStaleness          = base.Timestamp(24 * 60 * 60)
+)
+
+const (
+	clLatestRoundData         = "0xfeaf968c" // latestRoundData()
+	clDecimals                = "0x313ce567" // decimals()
+	clRegistryLatestRoundData = "0xbcfd032d" // latestRoundData(address,address)
+	clRegistryDecimals        = "0x58e2d3a8" // decimals(address,address)
+)
+
+// chainlinkSource prices ETH using Chainlink's ETH / USD aggregator and tokens using Chainlink's
+// Feed Registry. Calls are made at the statement's block, so the answer is the latest round as
+// of that block. An answer older than a day is considered stale and is not used.
+type chainlinkSource struct{}
+
+func (s *chainlinkSource) Name() string {
+	return "chainlink"
+}
+
+func (s *chainlinkSource) PriceUsd(conn *rpc.Connection, statement *types.Statement) (Quote, error) {
+	var feed base.Address
+	var roundData, decimals string
+	if statement.IsEth() {
+		if statement.BlockNumber <= chainlinkEthUsd_deployed {
+			return Quote{}, nil
+		}
+		feed = chainlinkEthUsd
+		roundData, decimals = clLatestRoundData, clDecimals
+	} else {
+		if statement.BlockNumber <= chainlinkFeedRegistry_deployed {
+			return Quote{}, nil
+		}
+		feed = chainlinkFeedRegistry
+		args := statement.AssetAddr.Pad32() + chainlinkUsdDenomination.Pad32()
+		roundData, decimals = clRegistryLatestRoundData+args, clRegistryDecimals+args
+	}
+
+	words, err := callContract(conn, feed, roundData, statement.BlockNumber)
+	if err != nil || len(words) < 5 {
+		// the registry reverts if there is no feed for the asset
+		return Quote{}, err
+	}
+
+	answer, updatedAt := toSigned(words[1]), words[3]
+	if answer.Sign() <= 0 || !updatedAt.IsUint64() {
+		return Quote{}, nil
+	}
+	if isStale(base.Timestamp(updatedAt.Uint64()), statement.Timestamp) {
+		logger.TestLog(true, fmt.Sprintf("Chainlink answer for %s at block %d is stale (updated at %d)", statement.AssetSymbol, statement.BlockNumber, updatedAt.Uint64()))
+		return Quote{Source: "chainlink-stale"}, nil
+	}
+
+	dWords, err := callContract(conn, feed, decimals, statement.BlockNumber)
+	if err != nil || len(dWords) == 0 {
+		return Quote{}, err
+	}
+
+	price := base.Float(scaled(answer, dWords[0].Int64()))
+	logger.TestLog(true, "=========================================================")
+	logger.TestLog(true, "===> PRICING FOR", statement.AssetAddr, "("+statement.AssetSymbol+")", "using Chainlink")
+	logger.TestLog(true, "=========================================================")
+	logger.TestLog(true, "blockNumber:        ", statement.BlockNumber)
+	logger.TestLog(true, "feed:               ", feed)
+	logger.TestLog(true, "answer:             ", answer.String())
+	logger.TestLog(true, "price:              ", price)
+
+	return Quote{Price: price, Source: s.Name(), Pool: feed}, nil
+}
+
+// isStale returns true if an answer last updated at `updatedAt` is too old to use at `ts`
+func isStale(updatedAt, ts base.Timestamp) bool {
+	return ts > updatedAt && ts-updatedAt > chainlinkMaxStaleness
+}
@@ -0,0 +1,119 @@
+package pricing
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+var (
+	curveAddressProvider          = base.HexToAddress("0x0000000022d53366457f9d5e68ec105046fc4383")
+	curveAddressProvider_deployed = base.Blknum(11154794)
+	usdtAddress                   = base.HexToAddress("0xdac17f958d2ee523a2206206994597c13d831ec7") // USDT
+)
+
+const (
+	curveGetRegistry      = "0xa262904b" // get_registry()
+	curveFindPoolForCoins = "0xa87df06c" // find_pool_for_coins(address,address)
+	curveGetCoinIndices   = "0xeb85226d" // get_coin_indices(address,address,address)
+	curveGetDy            = "0x5e0d443f" // get_dy(int128,int128,uint256)
+	curveGetDyUnderlying  = "0x07211ef7" // get_dy_underlying(int128,int128,uint256)
+)
+
+// curveQuote is a stable coin against which Curve pools are searched
+type curveQuote struct {
+	address  base.Address
+	decimals int64
+}
+
+var curveQuotes = []curveQuote{
+	{usdcAddress, 6},
+	{usdtAddress, 6},
+	{daiAddress, 18},
+}
+
+// curveSource prices tokens by asking the first Curve pool (found through Curve's registry)
+// that trades the token against a dollar stable coin how much of the stable coin one whole
+// token would buy. Curve's main registry does not list native ETH pools, so ETH is not priced.
+type curveSource struct{}
+
+func (s *curveSource) Name() string {
+	return "curve"
+}
+
+func (s *curveSource) PriceUsd(conn *rpc.Connection, statement *types.Statement) (Quote, error) {
+	if statement.IsEth() || statement.BlockNumber <= curveAddressProvider_deployed {
+		return Quote{}, nil
+	}
+
+	words, err := callContract(conn, curveAddressProvider, curveGetRegistry, statement.BlockNumber)
+	if err != nil || len(words) == 0 {
+		return Quote{}, err
+	}
+	registry := wordToAddress(words[0])
+	if registry.IsZero() {
+		return Quote{}, nil
+	}
+
+	for _, quote := range curveQuotes {
+		if quote.address == statement.AssetAddr {
+			continue
+		}
+
+		pool, dy, err := s.getDy(conn, registry, statement.AssetAddr, int64(statement.Decimals), quote.address, statement.BlockNumber)
+		if err != nil {
+			return Quote{}, err
+		}
+		if dy == nil || dy.Sign() == 0 {
+			continue
+		}
+
+		price := base.Float(scaled(dy, quote.decimals))
+		logger.TestLog(true, "=========================================================")
+		logger.TestLog(true, "===> PRICING FOR", statement.AssetAddr, "("+statement.AssetSymbol+")", "using Curve")
+		logger.TestLog(true, "=========================================================")
+		logger.TestLog(true, "blockNumber:        ", statement.BlockNumber)
+		logger.TestLog(true, "pool:               ", pool)
+		logger.TestLog(true, "quote:              ", quote.address)
+		logger.TestLog(true, "price:              ", price)
+		return Quote{Price: price, Source: s.Name(), Pool: pool}, nil
+	}
+
+	return Quote{}, nil
+}
+
+// getDy returns the pool trading `from` for `to` and the amount of `to` (in its smallest units)
+// that one whole unit of `from` would buy. A nil amount means there is no such pool.
+func (s *curveSource) getDy(conn *rpc.Connection, registry, from base.Address, fromDecimals int64, to base.Address, bn base.Blknum) (base.Address, *big.Int, error) {
+	words, err := callContract(conn, registry, curveFindPoolForCoins+from.Pad32()+to.Pad32(), bn)
+	if err != nil || len(words) == 0 {
+		return base.ZeroAddr, nil, err
+	}
+	pool := wordToAddress(words[0])
+	if pool.IsZero() {
+		return base.ZeroAddr, nil, nil
+	}
+
+	// returns (int128 i, int128 j, bool isUnderlying)
+	words, err = callContract(conn, registry, curveGetCoinIndices+pool.Pad32()+from.Pad32()+to.Pad32(), bn)
+	if err != nil || len(words) < 3 {
+		return base.ZeroAddr, nil, err
+	}
+
+	selector := curveGetDy
+	if words[2].Sign() != 0 {
+		selector = curveGetDyUnderlying
+	}
+
+	dx := new(big.Int).Exp(big.NewInt(10), big.NewInt(fromDecimals), nil)
+	data := selector + fmt.Sprintf("%064x%064x%064x", words[0], words[1], dx)
+	if words, err = callContract(conn, pool, data, bn); err != nil || len(words) == 0 {
+		return base.ZeroAddr, nil, err
+	}
+
+	return pool, words[0], nil
+}
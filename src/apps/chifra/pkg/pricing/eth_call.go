@@ -0,0 +1,82 @@
+package pricing
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc/query"
+)
+
+// callContract calls `to` with the (hex) data at the given block and returns the result as a
+// slice of 32-byte words. A call that reverts returns no words and no error so that callers
+// may treat it as "not available at this block."
+func callContract(conn *rpc.Connection, to base.Address, data string, bn base.Blknum) ([]*big.Int, error) {
+	params := query.Params{
+		map[string]any{
+			"to":   to.Hex(),
+			"data": data,
+		},
+		fmt.Sprintf("0x%x", bn),
+	}
+
+	result, err := query.Query[string](conn.Chain, "eth_call", params)
+	if err != nil {
+		if strings.Contains(strings.ToLower(err.Error()), "revert") {
+			return []*big.Int{}, nil
+		}
+		return nil, err
+	}
+	if result == nil {
+		return []*big.Int{}, nil
+	}
+	return toWords(*result), nil
+}
+
+// toWords splits hex encoded return data into 32-byte words
+func toWords(data string) []*big.Int {
+	data = strings.TrimPrefix(data, "0x")
+	words := make([]*big.Int, 0, len(data)/64)
+	for i := 0; i+64 <= len(data); i += 64 {
+		word, ok := new(big.Int).SetString(data[i:i+64], 16)
+		if !ok {
+			return []*big.Int{}
+		}
+		words = append(words, word)
+	}
+	return words
+}
+
+// toSigned interprets a word as a two's complement signed integer
+func toSigned(word *big.Int) *big.Int {
+	if word.Bit(255) == 0 {
+		return new(big.Int).Set(word)
+	}
+	return new(big.Int).Sub(word, new(big.Int).Lsh(big.NewInt(1), 256))
+}
+
+// wordToAddress returns the address in the low 20 bytes of a word
+func wordToAddress(word *big.Int) base.Address {
+	return base.HexToAddress(fmt.Sprintf("0x%040x", new(big.Int).And(word, addressMask)))
+}
+
+var addressMask = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 160), big.NewInt(1))
+
+// encodeUint returns the value left padded to a 32-byte word (without 0x)
+func encodeUint(value uint64) string {
+	return fmt.Sprintf("%064x", value)
+}
+
+// pow10 returns 10^n as a float
+func pow10(n int64) *big.Float {
+	return new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(n), nil))
+}
+
+// scaled returns value / 10^decimals as a float64
+func scaled(value *big.Int, decimals int64) float64 {
+	f := new(big.Float).SetInt(value)
+	ret, _ := f.Quo(f, pow10(decimals)).Float64()
+	return ret
+}
@@ -17,21 +17,32 @@ var (
 	makerDeployment = base.Blknum(3684349)
 )
 
-func priceUsdMaker(conn *rpc.Connection, statement *types.Statement) (price base.Float, source string, err error) {
+// makerSource prices ETH using the Maker medianizer
+type makerSource struct{}
+
+func (s *makerSource) Name() string {
+	return "maker"
+}
+
+func (s *makerSource) PriceUsd(conn *rpc.Connection, statement *types.Statement) (Quote, error) {
+	if !statement.IsEth() {
+		return Quote{}, nil
+	}
+
 	if statement.BlockNumber <= makerDeployment {
 		msg := fmt.Sprintf("Block %d is prior to deployment (%d) of Maker. No fallback pricing method", statement.BlockNumber, makerDeployment)
 		logger.TestLog(true, msg)
-		return 0.0, "eth-not-priced-pre-maker", nil
+		return Quote{Source: "eth-not-priced-pre-maker"}, nil
 	}
 
-	msg := fmt.Sprintf("Block %d is prior to deployment (%d) of Uniswap V2. Falling back to Maker (%s)", statement.BlockNumber, uniswapFactoryV2_deployed, makerMedianizer)
+	msg := fmt.Sprintf("Block %d. Pricing with Maker (%s)", statement.BlockNumber, makerMedianizer)
 	logger.TestLog(true, msg)
 	theCall := "peek()"
 
 	contractCall, _, err := call.NewContractCall(conn, makerMedianizer, theCall)
 	if err != nil {
 		wrapped := fmt.Errorf("the --call value provided (%s) was not found: %s", theCall, err)
-		return 0.0, "not-priced", wrapped
+		return Quote{}, wrapped
 	}
 
 	contractCall.BlockNumber = statement.BlockNumber
@@ -40,7 +51,7 @@ func priceUsdMaker(conn *rpc.Connection, statement *types.Statement) (price base
 	}
 	result, err := contractCall.Call(artFunc)
 	if err != nil {
-		return 0.0, "not-priced", err
+		return Quote{}, err
 	}
 
 	divisor := new(base.Wei)
@@ -57,8 +68,8 @@ func priceUsdMaker(conn *rpc.Connection, statement *types.Statement) (price base
 
 	bigPrice := new(base.Ether).SetWei(int1)
 	bigPrice = bigPrice.Quo(bigPrice, new(base.Ether).SetInt64(100000))
-	price = base.Float(bigPrice.Float64())
-	source = "maker"
+	price := base.Float(bigPrice.Float64())
+	source := "maker"
 	r := priceDebugger{
 		address:     statement.AssetAddr,
 		symbol:      statement.AssetSymbol,
@@ -78,5 +89,5 @@ func priceUsdMaker(conn *rpc.Connection, statement *types.Statement) (price base
 	}
 	r.report("using Maker")
 
-	return
+	return Quote{Price: price, Source: source, Pool: makerMedianizer}, nil
 }
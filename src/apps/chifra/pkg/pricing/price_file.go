@@ -0,0 +1,118 @@
+package pricing
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// filePrice is a single row of a price file
+type filePrice struct {
+	timestamp base.Timestamp
+	price     base.Float
+}
+
+// priceFileSource prices assets from a local csv file of asset,timestamp,price rows where
+// `asset` is either the asset's address or its symbol (ETH for ether). The price used is the
+// one with the latest timestamp at or before the statement's timestamp.
+type priceFileSource struct {
+	path   string
+	prices map[string][]filePrice // keyed by lower-cased address or symbol, sorted by timestamp
+}
+
+func newPriceFileSource(path string) (*priceFileSource, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("the csv price source requires a priceFile")
+	}
+
+	prices, err := readPriceFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &priceFileSource{path: path, prices: prices}, nil
+}
+
+func (s *priceFileSource) Name() string {
+	return "csv"
+}
+
+func (s *priceFileSource) PriceUsd(conn *rpc.Connection, statement *types.Statement) (Quote, error) {
+	keys := []string{strings.ToLower(statement.AssetAddr.Hex()), strings.ToLower(statement.AssetSymbol)}
+	if statement.IsEth() {
+		keys = append(keys, "eth")
+	}
+
+	for _, key := range keys {
+		if price, ok := s.priceAt(key, statement.Timestamp); ok {
+			return Quote{Price: price, Source: s.Name()}, nil
+		}
+	}
+	return Quote{}, nil
+}
+
+// priceAt returns the latest price for the key at or before the timestamp
+func (s *priceFileSource) priceAt(key string, ts base.Timestamp) (base.Float, bool) {
+	rows := s.prices[key]
+	i := sort.Search(len(rows), func(i int) bool {
+		return rows[i].timestamp > ts
+	})
+	if i == 0 {
+		return 0, false
+	}
+	return rows[i-1].price, true
+}
+
+// readPriceFile reads a csv file of asset,timestamp,price rows. Blank lines, lines starting
+// with '#', and a header line (if present) are ignored.
+func readPriceFile(path string) (map[string][]filePrice, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ret := make(map[string][]filePrice)
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.Split(line, ",")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("%s:%d: expected asset,timestamp,price", path, lineNo)
+		}
+
+		asset := strings.ToLower(strings.TrimSpace(parts[0]))
+		ts, err1 := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64)
+		price, err2 := strconv.ParseFloat(strings.TrimSpace(parts[2]), 64)
+		if err1 != nil || err2 != nil {
+			if lineNo == 1 {
+				// header line
+				continue
+			}
+			return nil, fmt.Errorf("%s:%d: invalid timestamp or price", path, lineNo)
+		}
+
+		ret[asset] = append(ret[asset], filePrice{timestamp: base.Timestamp(ts), price: base.Float(price)})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, rows := range ret {
+		sort.SliceStable(rows, func(i, j int) bool {
+			return rows[i].timestamp < rows[j].timestamp
+		})
+	}
+	return ret, nil
+}
@@ -2,8 +2,12 @@ package pricing
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/configtypes"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
@@ -11,26 +15,136 @@ import (
 
 // TODO: Much of this reporting could be removed as it's only used for debugging
 
-// PriceUsd returns the price of the asset in USD
-func PriceUsd(conn *rpc.Connection, statement *types.Statement) (price base.Float, source string, err error) {
-	if statement.IsStableCoin() {
-		r := priceDebugger{
-			address: statement.AssetAddr,
-			symbol:  statement.AssetSymbol,
-		}
-		r.report("stable-coin")
-		return 1.0, "stable-coin", nil
+// Quote is a US dollar price along with where it came from so that it may be verified
+type Quote struct {
+	Price  base.Float
+	Source string       // the name of the source that produced the price (or, if not priced, why not)
+	Pool   base.Address // the pair, pool, or feed that was queried, if any
+}
+
+// PriceSource is a single source of US dollar prices. A source that does not apply to the
+// statement (the wrong asset, a block prior to the source's deployment, etc.) returns a zero
+// price and, optionally, the reason in the quote's Source. Errors are reserved for failures.
+type PriceSource interface {
+	Name() string
+	PriceUsd(conn *rpc.Connection, statement *types.Statement) (Quote, error)
+}
+
+// defaultSources is the order in which sources are consulted if none is configured. It
+// reproduces the original behavior: stable coins, then Uniswap V2, then (for ETH) Maker.
+var defaultSources = "stable,uniswap,maker"
+
+// NewPriceSource returns the named source. `settings` configures those sources that need it.
+func NewPriceSource(name string, settings configtypes.PricingSettings) (PriceSource, error) {
+	switch name {
+	case "stable":
+		return &stableSource{}, nil
+	case "uniswap":
+		return &uniswapV2Source{}, nil
+	case "maker":
+		return &makerSource{}, nil
+	case "uniswap-v3":
+		return newUniswapV3Source(settings.TwapWindow), nil
+	case "chainlink":
+		return &chainlinkSource{}, nil
+	case "curve":
+		return &curveSource{}, nil
+	case "csv":
+		return newPriceFileSource(settings.PriceFile)
 	}
+	return nil, fmt.Errorf("unknown price source %s", name)
+}
 
-	if statement.BlockNumber <= uniswapFactoryV2_deployed {
-		if statement.IsEth() {
-			return priceUsdMaker(conn, statement)
+// NewPriceSources returns the sources named in the comma separated list in the given order
+func NewPriceSources(names string, settings configtypes.PricingSettings) ([]PriceSource, error) {
+	sources := make([]PriceSource, 0)
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if len(name) == 0 {
+			continue
+		}
+		if source, err := NewPriceSource(name, settings); err != nil {
+			return nil, err
 		} else {
-			msg := fmt.Sprintf("Block %d is prior to deployment (%d) of Uniswap V2. No other source for tokens prior to UniSwap", statement.BlockNumber, uniswapFactoryV2_deployed)
-			logger.TestLog(true, msg)
-			return 0.0, "token-not-priced-pre-uni", nil
+			sources = append(sources, source)
+		}
+	}
+	return sources, nil
+}
+
+var sourcesMutex sync.Mutex
+var sourcesMap = make(map[string][]PriceSource)
+
+// sourcesFor returns the (cached) price sources configured for the chain
+func sourcesFor(chain string) []PriceSource {
+	sourcesMutex.Lock()
+	defer sourcesMutex.Unlock()
+
+	if sources, ok := sourcesMap[chain]; ok {
+		return sources
+	}
+
+	settings := config.GetPricing(chain)
+	names := settings.Sources
+	if len(names) == 0 {
+		names = defaultSources
+	}
+
+	sources, err := NewPriceSources(names, settings)
+	if err != nil {
+		logger.Warn("invalid pricing configuration for", chain, ":", err, "- using", defaultSources)
+		sources, _ = NewPriceSources(defaultSources, settings)
+	}
+	sourcesMap[chain] = sources
+	return sources
+}
+
+// PriceUsd returns the price of the asset in USD from the first of the chain's configured
+// sources that is able to price it
+func PriceUsd(conn *rpc.Connection, statement *types.Statement) (Quote, error) {
+	return priceFromSources(sourcesFor(conn.Chain), conn, statement)
+}
+
+// priceFromSources consults each source in order returning the first non-zero price. If no
+// source prices the asset, the last reason given (or error encountered) is returned.
+func priceFromSources(sources []PriceSource, conn *rpc.Connection, statement *types.Statement) (Quote, error) {
+	notPriced := Quote{Source: "not-priced"}
+	var lastErr error
+	for _, source := range sources {
+		quote, err := source.PriceUsd(conn, statement)
+		if err != nil {
+			logger.TestLog(true, "price source", source.Name(), "failed:", err)
+			notPriced.Source = "not-priced"
+			lastErr = err
+			continue
+		}
+		if quote.Price != 0 {
+			return quote, nil
 		}
+		if len(quote.Source) > 0 {
+			notPriced.Source = quote.Source
+			lastErr = nil
+		}
+	}
+	return notPriced, lastErr
+}
+
+// stableSource prices well known stable coins at one dollar
+type stableSource struct{}
+
+func (s *stableSource) Name() string {
+	return "stable"
+}
+
+func (s *stableSource) PriceUsd(conn *rpc.Connection, statement *types.Statement) (Quote, error) {
+	if !statement.IsStableCoin() {
+		return Quote{}, nil
 	}
 
-	return priceUsdUniswap(conn, statement)
+	r := priceDebugger{
+		address: statement.AssetAddr,
+		symbol:  statement.AssetSymbol,
+	}
+	r.report("stable-coin")
+	return Quote{Price: 1.0, Source: "stable-coin"}, nil
 }
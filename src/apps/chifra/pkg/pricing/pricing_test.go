@@ -0,0 +1,195 @@
+package pricing
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/configtypes"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+type fakeSource struct {
+	name  string
+	quote Quote
+	err   error
+	calls int
+}
+
+func (f *fakeSource) Name() string {
+	return f.name
+}
+
+func (f *fakeSource) PriceUsd(conn *rpc.Connection, statement *types.Statement) (Quote, error) {
+	f.calls++
+	return f.quote, f.err
+}
+
+func TestPriceFromSources(t *testing.T) {
+	pool := base.HexToAddress("0x1234567890123456789012345678901234567890")
+	declines := &fakeSource{name: "declines"}
+	explains := &fakeSource{name: "explains", quote: Quote{Source: "too-early"}}
+	fails := &fakeSource{name: "fails", err: errors.New("boom")}
+	prices := &fakeSource{name: "prices", quote: Quote{Price: 2.5, Source: "prices", Pool: pool}}
+	never := &fakeSource{name: "never", quote: Quote{Price: 99, Source: "never"}}
+
+	stmt := &types.Statement{}
+	quote, err := priceFromSources([]PriceSource{declines, explains, fails, prices, never}, nil, stmt)
+	if err != nil {
+		t.Error("unexpected error", err)
+	}
+	if quote.Price != 2.5 || quote.Source != "prices" || quote.Pool != pool {
+		t.Error("expected the first non-zero price, got", quote)
+	}
+	if never.calls != 0 {
+		t.Error("sources after the first to price should not be consulted")
+	}
+
+	quote, err = priceFromSources([]PriceSource{declines, explains}, nil, stmt)
+	if err != nil || quote.Price != 0 || quote.Source != "too-early" {
+		t.Error("expected the reason given by the last source to explain itself, got", quote, err)
+	}
+
+	quote, err = priceFromSources([]PriceSource{explains, fails}, nil, stmt)
+	if err == nil || quote.Source != "not-priced" {
+		t.Error("expected the last error, got", quote, err)
+	}
+
+	quote, err = priceFromSources([]PriceSource{}, nil, stmt)
+	if err != nil || quote.Source != "not-priced" {
+		t.Error("expected not-priced with no sources, got", quote, err)
+	}
+}
+
+func TestNewPriceSources(t *testing.T) {
+	sources, err := NewPriceSources(" chainlink, uniswap-v3 ,,stable", configtypes.PricingSettings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, s := range sources {
+		names = append(names, s.Name())
+	}
+	if len(names) != 3 || names[0] != "chainlink" || names[1] != "uniswap-v3" || names[2] != "stable" {
+		t.Error("unexpected sources", names)
+	}
+
+	if _, err := NewPriceSources("stable,bogus", configtypes.PricingSettings{}); err == nil {
+		t.Error("expected an error for an unknown source")
+	}
+	if _, err := NewPriceSources("csv", configtypes.PricingSettings{}); err == nil {
+		t.Error("expected an error for a csv source without a file")
+	}
+}
+
+func TestPriceFileSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.csv")
+	contents := `asset,timestamp,price
+# comments are ignored
+ETH,2000,1500.5
+eth,1000,1000
+0x6B175474E89094C44Da98b954EedeAC495271d0F,1000,1.01
+
+UNI,3000,5
+`
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := newPriceFileSource(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		stmt     types.Statement
+		expected base.Float
+	}{
+		{types.Statement{AssetAddr: base.FAKE_ETH_ADDRESS, AssetSymbol: "WEI", Timestamp: 999}, 0},
+		{types.Statement{AssetAddr: base.FAKE_ETH_ADDRESS, AssetSymbol: "WEI", Timestamp: 1000}, 1000},
+		{types.Statement{AssetAddr: base.FAKE_ETH_ADDRESS, AssetSymbol: "WEI", Timestamp: 1999}, 1000},
+		{types.Statement{AssetAddr: base.FAKE_ETH_ADDRESS, AssetSymbol: "WEI", Timestamp: 5000}, 1500.5},
+		{types.Statement{AssetAddr: daiAddress, AssetSymbol: "DAI", Timestamp: 1500}, 1.01},
+		{types.Statement{AssetAddr: wethAddress, AssetSymbol: "UNI", Timestamp: 3000}, 5},
+		{types.Statement{AssetAddr: wethAddress, AssetSymbol: "WETH", Timestamp: 3000}, 0},
+	}
+	for i, test := range tests {
+		quote, err := source.PriceUsd(nil, &test.stmt)
+		if err != nil {
+			t.Error(i, err)
+		}
+		if quote.Price != test.expected {
+			t.Error(i, "expected", test.expected, "got", quote.Price)
+		}
+		if quote.Price != 0 && quote.Source != "csv" {
+			t.Error(i, "expected source csv, got", quote.Source)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte("ETH,1000,1000\nETH,abc,1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newPriceFileSource(path); err == nil {
+		t.Error("expected an error for a malformed row")
+	}
+	if _, err := newPriceFileSource(path + ".missing"); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestTwapTick(t *testing.T) {
+	word := func(n int64) *big.Int {
+		if n < 0 {
+			return new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(n))
+		}
+		return big.NewInt(n)
+	}
+
+	// observe([1800, 0]) returns (int56[] tickCumulatives, uint160[] secondsPerLiquidityCumulativeX128s)
+	words := []*big.Int{word(0x40), word(0xa0), word(2), word(-1000), word(-1000 - 1800*200), word(2), word(0), word(0)}
+	if tick, ok := twapTick(words, 1800); !ok || tick != -200 {
+		t.Error("expected -200, got", tick, ok)
+	}
+
+	// rounds toward negative infinity
+	words[4] = word(-1000 - 1800*200 - 1)
+	if tick, ok := twapTick(words, 1800); !ok || tick != -201 {
+		t.Error("expected -201, got", tick, ok)
+	}
+
+	// reverted (not enough history)
+	if _, ok := twapTick([]*big.Int{}, 1800); ok {
+		t.Error("expected no tick for empty return data")
+	}
+}
+
+func TestTickToPrice(t *testing.T) {
+	// A USDC/WETH pool (token0 is USDC, 6 decimals, token1 is WETH, 18 decimals) at about $2000 / ETH
+	tick := int64(math.Round(math.Log(1e12/2000) / math.Log(1.0001)))
+	ethPrice := tickToPrice(tick, isToken0(wethAddress, usdcAddress), 18, 6)
+	if math.Abs(ethPrice-2000) > 1 {
+		t.Error("expected about 2000, got", ethPrice)
+	}
+
+	usdcPrice := tickToPrice(tick, isToken0(usdcAddress, wethAddress), 6, 18)
+	if math.Abs(usdcPrice-1.0/2000) > 1e-6 {
+		t.Error("expected about 0.0005, got", usdcPrice)
+	}
+}
+
+func TestStaleness(t *testing.T) {
+	if isStale(1000, 1000+24*60*60) {
+		t.Error("a day old answer is not stale")
+	}
+	if !isStale(1000, 1001+24*60*60) {
+		t.Error("an answer older than a day is stale")
+	}
+	if isStale(2000, 1000) {
+		t.Error("an answer from the future is not stale")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/articulate"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/call"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
 )
@@ -18,8 +19,27 @@ var (
 	uniswapFactoryV2_deployed = base.Blknum(10000835) // why query for this immutable value each time we need it?
 )
 
+// uniswapV2Source prices ETH against DAI and tokens against WETH using Uniswap V2 pair reserves
+type uniswapV2Source struct{}
+
+func (s *uniswapV2Source) Name() string {
+	return "uniswap"
+}
+
+func (s *uniswapV2Source) PriceUsd(conn *rpc.Connection, statement *types.Statement) (Quote, error) {
+	if statement.BlockNumber <= uniswapFactoryV2_deployed {
+		if statement.IsEth() {
+			return Quote{}, nil
+		}
+		msg := fmt.Sprintf("Block %d is prior to deployment (%d) of Uniswap V2. No other source for tokens prior to UniSwap", statement.BlockNumber, uniswapFactoryV2_deployed)
+		logger.TestLog(true, msg)
+		return Quote{Source: "token-not-priced-pre-uni"}, nil
+	}
+	return priceUsdUniswap(conn, statement)
+}
+
 // priceUsdUniswap returns the price of the given asset in USD as of the given block number.
-func priceUsdUniswap(conn *rpc.Connection, statement *types.Statement) (price base.Float, source string, err error) {
+func priceUsdUniswap(conn *rpc.Connection, statement *types.Statement) (Quote, error) {
 	multiplier := base.Float(1.0)
 	var first base.Address
 	var second base.Address
@@ -31,10 +51,11 @@ func priceUsdUniswap(conn *rpc.Connection, statement *types.Statement) (price ba
 		temp := *statement
 		temp.AssetAddr = base.FAKE_ETH_ADDRESS
 		temp.AssetSymbol = "WEI"
-		multiplier, _, err = priceUsdUniswap(conn, &temp)
+		ethQuote, err := priceUsdUniswap(conn, &temp)
 		if err != nil {
-			return 0.0, "not-priced", err
+			return Quote{}, err
 		}
+		multiplier = ethQuote.Price
 		first = wethAddress
 		second = statement.AssetAddr
 	}
@@ -51,7 +72,7 @@ func priceUsdUniswap(conn *rpc.Connection, statement *types.Statement) (price ba
 	contractCall, _, err := call.NewContractCall(conn, uniswapFactoryV2, theCall1)
 	if err != nil {
 		wrapped := fmt.Errorf("the --call value provided (%s) was not found: %s", theCall1, err)
-		return 0.0, "not-priced", wrapped
+		return Quote{}, wrapped
 	}
 	contractCall.BlockNumber = statement.BlockNumber
 
@@ -60,23 +81,23 @@ func priceUsdUniswap(conn *rpc.Connection, statement *types.Statement) (price ba
 	}
 	result, err := contractCall.Call(artFunc)
 	if err != nil {
-		return 0.0, "not-priced", err
+		return Quote{}, err
 	}
 	pairAddress := base.HexToAddress(result.Values["val_0"])
 	if pairAddress.IsZero() {
 		msg := fmt.Sprintf("no pair found for %s and %s", first.Hex(), second.Hex())
-		return 0.0, "not-priced", errors.New(msg)
+		return Quote{}, errors.New(msg)
 	}
 	theCall2 := "getReserves()"
 	contractCall, _, err = call.NewContractCall(conn, pairAddress, theCall2)
 	if err != nil {
 		wrapped := fmt.Errorf("the --call value provided (%s) was not found: %s", theCall2, err)
-		return 0.0, "not-priced", wrapped
+		return Quote{}, wrapped
 	}
 	contractCall.BlockNumber = statement.BlockNumber
 	result, err = contractCall.Call(artFunc)
 	if err != nil {
-		return 0.0, "not-priced", err
+		return Quote{}, err
 	}
 	reserve0 := new(base.Ether)
 	if result.Values != nil && (result.Values["_reserve0"] == "" || result.Values["_reserve0"] == "0") {
@@ -93,9 +114,9 @@ func priceUsdUniswap(conn *rpc.Connection, statement *types.Statement) (price ba
 	bigPrice := new(base.Ether)
 	bigPrice = bigPrice.Quo(reserve0, reserve1)
 
-	price = base.Float(bigPrice.Float64())
+	price := base.Float(bigPrice.Float64())
 	price *= multiplier
-	source = "uniswap"
+	source := "uniswap"
 
 	r := priceDebugger{
 		address:     statement.AssetAddr,
@@ -117,5 +138,5 @@ func priceUsdUniswap(conn *rpc.Connection, statement *types.Statement) (price ba
 	}
 	r.report("using Uniswap")
 
-	return price, source, nil
+	return Quote{Price: price, Source: source, Pool: pairAddress}, nil
 }
@@ -0,0 +1,182 @@
+package pricing
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+var (
+	usdcAddress               = base.HexToAddress("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48") // USDC
+	uniswapFactoryV3          = base.HexToAddress("0x1f98431c8ad98523631ae4a59f267346ea31f984")
+	uniswapFactoryV3_deployed = base.Blknum(12369621)
+	uniswapV3Fees             = []uint64{100, 500, 3000, 10000}
+)
+
+const (
+	uniV3GetPool   = "0x1698ee82" // getPool(address,address,uint24)
+	uniV3Slot0     = "0x3850c7bd" // slot0()
+	uniV3Observe   = "0x883bdbfd" // observe(uint32[])
+	uniV3Liquidity = "0x1a686502" // liquidity()
+)
+
+// defaultTwapWindow is the length (in seconds) of the time weighted average if not configured
+const defaultTwapWindow = 1800
+
+// uniswapV3Source prices ETH against USDC and tokens against WETH using the time weighted
+// average tick of the most liquid Uniswap V3 pool. If the pool's oracle does not reach back
+// far enough, the current tick (from slot0) is used instead and the source says so.
+type uniswapV3Source struct {
+	window uint64
+}
+
+func newUniswapV3Source(window uint64) *uniswapV3Source {
+	if window == 0 {
+		window = defaultTwapWindow
+	}
+	return &uniswapV3Source{window: window}
+}
+
+func (s *uniswapV3Source) Name() string {
+	return "uniswap-v3"
+}
+
+func (s *uniswapV3Source) PriceUsd(conn *rpc.Connection, statement *types.Statement) (Quote, error) {
+	if statement.BlockNumber <= uniswapFactoryV3_deployed {
+		return Quote{}, nil
+	}
+
+	ethPrice, ethPool, method, err := s.pairPrice(conn, wethAddress, 18, usdcAddress, 6, statement.BlockNumber)
+	if err != nil || ethPrice == 0 {
+		return Quote{}, err
+	}
+
+	if statement.IsEth() || statement.AssetAddr == wethAddress {
+		s.report(statement, ethPool, ethPrice, method)
+		return Quote{Price: base.Float(ethPrice), Source: s.Name() + "-" + method, Pool: ethPool}, nil
+	}
+
+	tokenPrice, pool, method, err := s.pairPrice(conn, statement.AssetAddr, int64(statement.Decimals), wethAddress, 18, statement.BlockNumber)
+	if err != nil || tokenPrice == 0 {
+		return Quote{}, err
+	}
+
+	price := tokenPrice * ethPrice
+	s.report(statement, pool, price, method)
+	return Quote{Price: base.Float(price), Source: s.Name() + "-" + method, Pool: pool}, nil
+}
+
+// pairPrice returns the price of one whole unit of `asset` in units of `quote` along with the
+// pool used and the method (twap or spot). A zero price means there is no pool.
+func (s *uniswapV3Source) pairPrice(conn *rpc.Connection, asset base.Address, assetDecimals int64, quote base.Address, quoteDecimals int64, bn base.Blknum) (float64, base.Address, string, error) {
+	pool, err := s.bestPool(conn, asset, quote, bn)
+	if err != nil || pool.IsZero() {
+		return 0, base.ZeroAddr, "", err
+	}
+
+	tick, method, err := s.averageTick(conn, pool, bn)
+	if err != nil || method == "" {
+		return 0, base.ZeroAddr, "", err
+	}
+
+	return tickToPrice(tick, isToken0(asset, quote), assetDecimals, quoteDecimals), pool, method, nil
+}
+
+// bestPool returns the pool (across all fee tiers) with the most in-range liquidity
+func (s *uniswapV3Source) bestPool(conn *rpc.Connection, tokenA, tokenB base.Address, bn base.Blknum) (base.Address, error) {
+	best := base.ZeroAddr
+	bestLiquidity := new(big.Int)
+	for _, fee := range uniswapV3Fees {
+		data := uniV3GetPool + tokenA.Pad32() + tokenB.Pad32() + encodeUint(fee)
+		words, err := callContract(conn, uniswapFactoryV3, data, bn)
+		if err != nil {
+			return base.ZeroAddr, err
+		}
+		if len(words) == 0 {
+			continue
+		}
+
+		pool := wordToAddress(words[0])
+		if pool.IsZero() {
+			continue
+		}
+
+		if words, err = callContract(conn, pool, uniV3Liquidity, bn); err != nil {
+			return base.ZeroAddr, err
+		}
+		if len(words) > 0 && words[0].Cmp(bestLiquidity) > 0 {
+			best = pool
+			bestLiquidity = words[0]
+		}
+	}
+	return best, nil
+}
+
+// averageTick returns the time weighted average tick over the window ending at the block or,
+// if the pool's oracle does not have enough history, the current tick
+func (s *uniswapV3Source) averageTick(conn *rpc.Connection, pool base.Address, bn base.Blknum) (int64, string, error) {
+	data := uniV3Observe + encodeUint(0x20) + encodeUint(2) + encodeUint(s.window) + encodeUint(0)
+	words, err := callContract(conn, pool, data, bn)
+	if err != nil {
+		return 0, "", err
+	}
+
+	if tick, ok := twapTick(words, s.window); ok {
+		return tick, "twap", nil
+	}
+
+	if words, err = callContract(conn, pool, uniV3Slot0, bn); err != nil {
+		return 0, "", err
+	}
+	if len(words) < 2 {
+		return 0, "", nil
+	}
+	return toSigned(words[1]).Int64(), "spot", nil
+}
+
+// twapTick decodes the tickCumulatives returned by observe([window, 0]) into the average tick
+func twapTick(words []*big.Int, window uint64) (int64, bool) {
+	if len(words) < 1 || !words[0].IsUint64() || words[0].Uint64()%32 != 0 {
+		return 0, false
+	}
+
+	start := words[0].Uint64() / 32
+	if start+2 >= uint64(len(words)) || words[start].Cmp(big.NewInt(2)) != 0 {
+		return 0, false
+	}
+
+	delta := new(big.Int).Sub(toSigned(words[start+2]), toSigned(words[start+1]))
+	// big.Int's Div rounds toward negative infinity as does Uniswap's OracleLibrary
+	avg := new(big.Int).Div(delta, new(big.Int).SetUint64(window))
+	return avg.Int64(), true
+}
+
+// tickToPrice converts a tick (the log base 1.0001 of token1 per token0 in the tokens' smallest
+// units) into the price of one whole unit of the asset in whole units of the quote token
+func tickToPrice(tick int64, assetIsToken0 bool, assetDecimals, quoteDecimals int64) float64 {
+	raw := math.Pow(1.0001, float64(tick))
+	if !assetIsToken0 {
+		raw = 1 / raw
+	}
+	return raw * math.Pow10(int(assetDecimals-quoteDecimals))
+}
+
+// isToken0 returns true if `a` sorts before `b` (and would therefore be token0 of their pool)
+func isToken0(a, b base.Address) bool {
+	return a.Hex() < b.Hex()
+}
+
+func (s *uniswapV3Source) report(statement *types.Statement, pool base.Address, price float64, method string) {
+	logger.TestLog(true, "=========================================================")
+	logger.TestLog(true, "===> PRICING FOR", statement.AssetAddr, "("+statement.AssetSymbol+")", "using Uniswap V3")
+	logger.TestLog(true, "=========================================================")
+	logger.TestLog(true, "blockNumber:        ", statement.BlockNumber)
+	logger.TestLog(true, "pool:               ", pool)
+	logger.TestLog(true, "method:             ", fmt.Sprintf("%s (%d seconds)", method, s.window))
+	logger.TestLog(true, "price:              ", price)
+}
@@ -14,6 +14,7 @@ const (
 	Error
 	AllDone
 	Cancelled
+	Quarantined
 )
 
 type ProgressMsg struct {
@@ -15,6 +15,7 @@ import (
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc/query"
 	"github.com/ethereum/go-ethereum/ethclient"
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
 )
 
 // GetClientVersion returns the version of the client
@@ -75,7 +76,13 @@ var clientMutex sync.Mutex
 var perProviderClientMap = map[string]*ethclient.Client{}
 
 func (conn *Connection) getClient() (*ethclient.Client, error) {
-	provider := config.GetChain(conn.Chain).RpcProvider
+	// The client's calls go through the chain's pool of RPC endpoints (and so through its fixtures, if any)
+	pool, err := query.PoolFor(conn.Chain)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := pool.Url()
 	if provider == "" || provider == "https://" {
 		var noProvider = `
 
@@ -93,7 +100,11 @@ func (conn *Connection) getClient() (*ethclient.Client, error) {
 	defer clientMutex.Unlock()
 
 	if perProviderClientMap[provider] == nil {
-		ec, err := ethclient.Dial(provider)
+		rc, err := gethrpc.DialOptions(context.Background(), provider, gethrpc.WithHTTPClient(pool.Client))
+		var ec *ethclient.Client
+		if err == nil {
+			ec = ethclient.NewClient(rc)
+		}
 		if err != nil || ec == nil {
 			logger.Error("Missdial("+provider+"):", err)
 			logger.Fatal("")
@@ -6,7 +6,9 @@ import (
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/cache"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/version"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/walk"
 )
 
@@ -58,7 +60,7 @@ func (settings settings) GetRpcConnection() *Connection {
 	var store *cache.Store
 	var err error
 	if store, err = cache.NewStore(&cache.StoreOptions{
-		Location: cache.FsCache,
+		Location: cache.LocationFromString(config.GetSettings().CacheLocation),
 		Chain:    settings.Chain,
 		ReadOnly: forceReadonly,
 	}); err != nil {
@@ -96,6 +98,23 @@ func (conn *Connection) StoreWritable() bool {
 	return !conn.Store.ReadOnly()
 }
 
+// blobsVersion is the first version to cache the blob fields (EIP-4844) of blocks, transactions,
+// and receipts
+var blobsVersion = version.NewVersion("3.5.2")
+
+// readOptions returns the options for reading the blocks, transactions, and receipts cached for
+// block bn. Those cached after Cancun by an earlier version lack the blob fields, so they are
+// invalidated and fetched again. Where we don't know when Cancun activated, we invalidate them
+// for every block. Rollups do not carry blobs.
+func (conn *Connection) readOptions(bn base.Blknum) *cache.ReadOptions {
+	if bn < base.KnownBlock(conn.Chain, base.Cancun) || config.IsRollup(conn.Chain) {
+		return nil
+	}
+	return &cache.ReadOptions{
+		MinVersion: blobsVersion.Uint64(),
+	}
+}
+
 // TestLog prints the enabledMap to the log. Note this routine gets called prior to full initialization, thus it takes the enabledMap
 func (conn *Connection) TestLog(caches map[walk.CacheType]bool) {
 	if conn.StoreWritable() {
@@ -22,7 +22,7 @@ func (conn *Connection) GetBlockBodyByNumber(bn base.Blknum) (types.Block, error
 		lightBlock := &types.LightBlock{
 			BlockNumber: bn,
 		}
-		if err := conn.Store.Read(lightBlock, nil); err == nil {
+		if err := conn.Store.Read(lightBlock, conn.readOptions(bn)); err == nil {
 			// We need to fill in the actual transactions (from cache hopefully, but
 			// if not, then from the RPC)
 			transactions := make([]types.Transaction, 0, len(lightBlock.Transactions))
@@ -39,8 +39,10 @@ func (conn *Connection) GetBlockBodyByNumber(bn base.Blknum) (types.Block, error
 				lightToBody := func(block *types.LightBlock) *types.Block {
 					var ret types.Block
 					ret.BaseFeePerGas = block.BaseFeePerGas
+					ret.BlobGasUsed = block.BlobGasUsed
 					ret.BlockNumber = block.BlockNumber
 					ret.Difficulty = block.Difficulty
+					ret.ExcessBlobGas = block.ExcessBlobGas
 					ret.GasLimit = block.GasLimit
 					ret.GasUsed = block.GasUsed
 					ret.Hash = block.Hash
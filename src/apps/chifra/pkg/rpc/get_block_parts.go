@@ -49,3 +49,13 @@ func (conn *Connection) GetBlockHashByNumber(bn base.Blknum) (base.Hash, error)
 		return block.Hash, err
 	}
 }
+
+// GetBlockHashesFromRpc returns a block's hash and parent hash as the node currently reports them.
+// Unlike GetBlockHeaderByNumber, it does not consult the cache, which may hold an orphaned block.
+func (conn *Connection) GetBlockHashesFromRpc(bn base.Blknum) (base.Hash, base.Hash, error) {
+	if block, err := conn.getLightBlockFromRpc(bn, notAHash); err != nil {
+		return base.Hash{}, base.Hash{}, err
+	} else {
+		return block.Hash, block.ParentHash, nil
+	}
+}
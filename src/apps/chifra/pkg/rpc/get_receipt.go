@@ -38,7 +38,7 @@ func (conn *Connection) GetReceiptNoTimestamp(bn base.Blknum, txid base.Txnum) (
 			BlockNumber:      bn,
 			TransactionIndex: txid,
 		}
-		if err := conn.Store.Read(tx, nil); err == nil {
+		if err := conn.Store.Read(tx, conn.readOptions(bn)); err == nil {
 			// success
 			if tx.Receipt == nil {
 				return receipt, nil
@@ -77,7 +77,7 @@ func (conn *Connection) GetReceiptsByNumber(bn base.Blknum, ts base.Timestamp) (
 			BlockNumber:      bn,
 			TransactionIndex: base.NOPOSN,
 		}
-		if err := conn.Store.Read(receiptGroup, nil); err == nil {
+		if err := conn.Store.Read(receiptGroup, conn.readOptions(bn)); err == nil {
 			receiptMap := make(map[base.Txnum]*types.Receipt, len(receiptGroup.Receipts))
 			for index := 0; index < len(receiptGroup.Receipts); index++ {
 				pReceipt := &receiptGroup.Receipts[index]
@@ -112,12 +112,13 @@ func (conn *Connection) GetReceiptsByNumber(bn base.Blknum, ts base.Timestamp) (
 }
 
 // getBlockReceiptsFromRpc fetches receipts from the RPC using eth_getBlockReceipts. It returns
-// an array of Receipts with the timestamp set to the block timestamp.
+// an array of Receipts with the timestamp set to the block timestamp. Blocks fetched at the same
+// time (for example, by the scraper's workers) are fetched in a single batch.
 func (conn *Connection) getBlockReceiptsFromRpc(bn base.Blknum) ([]types.Receipt, error) {
 	method := "eth_getBlockReceipts"
 	params := query.Params{fmt.Sprintf("0x%x", bn)}
 
-	if receipts, err := query.Query[[]types.Receipt](conn.Chain, method, params); err != nil {
+	if receipts, err := query.Batched[[]types.Receipt](conn.Chain, method, params); err != nil {
 		return []types.Receipt{}, err
 
 	} else if receipts == nil || len(*receipts) == 0 {
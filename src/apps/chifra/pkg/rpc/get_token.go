@@ -26,6 +26,8 @@ const tokenStateDecimals tokenStateSelector = "0x313ce567"
 const tokenStateSymbol tokenStateSelector = "0x95d89b41"
 const tokenStateName tokenStateSelector = "0x06fdde03"
 const tokenStateBalanceOf tokenStateSelector = "0x70a08231"
+const tokenStateOwnerOf tokenStateSelector = "0x6352211e"
+const tokenStateBalanceOfId tokenStateSelector = "0x00fdd58e"
 
 // GetTokenState returns token state for given block. `hexBlockNo` can be "latest" or "" for the latest
 // block or decimal number or hex number with 0x prefix. (search: FromRpc)
@@ -102,22 +104,29 @@ func (conn *Connection) GetTokenState(tokenAddress base.Address, hexBlockNo stri
 		},
 	}
 
+	// Calls the contract reverts (for example, a token without a name) are read as empty
 	results, err := query.QueryBatch[string](conn.Chain, payloads)
-	if err != nil {
+	if err = ignoreReverts(err); err != nil {
 		return
 	}
+	result := func(key string) string {
+		if results[key] == nil {
+			return ""
+		}
+		return *results[key]
+	}
 
-	name, _ := decode.ArticulateStringOrBytes(*results["name"])
-	symbol, _ := decode.ArticulateStringOrBytes(*results["symbol"])
+	name, _ := decode.ArticulateStringOrBytes(result("name"))
+	symbol, _ := decode.ArticulateStringOrBytes(result("symbol"))
 
 	var decimals uint64 = 0
-	strDecimals := *results["decimals"]
+	strDecimals := result("decimals")
 	parsedDecimals, parseErr := strconv.ParseUint(strDecimals, 0, 8)
 	if parseErr == nil {
 		decimals = uint64(parsedDecimals)
 	}
 
-	totalSupply := base.HexToWei(*results["totalSupply"])
+	totalSupply := base.HexToWei(result("totalSupply"))
 
 	// TODO: Maybe reconcsider this
 	// TODO: According to ERC-20, name, symbol and decimals are optional, but such a token
@@ -127,7 +136,7 @@ func (conn *Connection) GetTokenState(tokenAddress base.Address, hexBlockNo stri
 	}
 
 	tokenType := types.TokenErc20
-	erc721, erc721Err := decode.ArticulateBool(*results["erc721"])
+	erc721, erc721Err := decode.ArticulateBool(result("erc721"))
 	if erc721Err == nil && erc721 {
 		tokenType = types.TokenErc721
 	}
@@ -165,8 +174,9 @@ func (conn *Connection) GetBalanceAtToken(token, holder base.Address, hexBlockNo
 		},
 	}}
 
+	// If the call reverts, the holder has none of the token
 	output, err := query.QueryBatch[string](conn.Chain, payloads)
-	if err != nil {
+	if err = ignoreReverts(err); err != nil {
 		return nil, err
 	}
 
@@ -176,3 +186,117 @@ func (conn *Connection) GetBalanceAtToken(token, holder base.Address, hexBlockNo
 
 	return base.HexToWei(*output["balance"]), nil
 }
+
+// GetBalanceAtTokenId returns the balance of a single token id of an NFT for given block. For ERC-1155
+// tokens, this is `balanceOf(holder, id)`. ERC-721 tokens have no per-id balance, so we return one if
+// `ownerOf(id)` is the holder and zero otherwise (including when the token does not exist at that
+// block, in which case the call reverts). Any other error (for example, a node without the historical
+// state) is returned rather than read as zero. `hexBlockNo` is as for GetBalanceAtToken.
+func (conn *Connection) GetBalanceAtTokenId(tokenType types.TokenType, token, holder base.Address, tokenId *base.Wei, hexBlockNo string) (*base.Wei, error) {
+	if hexBlockNo != "" && hexBlockNo != "latest" && !strings.HasPrefix(hexBlockNo, "0x") {
+		hexBlockNo = fmt.Sprintf("0x%x", base.MustParseUint64(hexBlockNo))
+	}
+
+	paddedId := fmt.Sprintf("%064x", tokenId.BigInt())
+	data := tokenStateOwnerOf + paddedId
+	if tokenType.IsErc1155() {
+		data = tokenStateBalanceOfId + holder.Pad32() + paddedId
+	}
+
+	payloads := []query.BatchPayload{{
+		Key: "balance",
+		Payload: &query.Payload{
+			Method: "eth_call",
+			Params: query.Params{
+				map[string]any{
+					"to":   token.Hex(),
+					"data": data,
+				},
+				hexBlockNo,
+			},
+		},
+	}}
+
+	// If the call reverts, the holder has none of the token
+	output, err := query.QueryBatch[string](conn.Chain, payloads)
+	if err = ignoreReverts(err); err != nil {
+		return nil, err
+	}
+
+	if output["balance"] == nil || len(*output["balance"]) < 2 || *output["balance"] == "0x" {
+		return base.NewWei(0), nil
+	}
+
+	if tokenType.IsErc1155() {
+		return base.HexToWei(*output["balance"]), nil
+	}
+
+	if base.HexToAddress(*output["balance"]) == holder {
+		return base.NewWei(1), nil
+	}
+	return base.NewWei(0), nil
+}
+
+// IsErc721 returns true if the token is an ERC-721 (non-fungible) token. We use this to classify
+// Transfer events whose parameters are not indexed (and whose last parameter may therefore be either an
+// amount or a token id). Tokens that predate the final standard (for example, CryptoKitties) do not claim
+// the ERC-721 interface, so we also check if the token reports an owner for the value as a token id.
+// `hexBlockNo` is as for GetBalanceAtToken.
+func (conn *Connection) IsErc721(token base.Address, value *base.Wei, hexBlockNo string) (bool, error) {
+	if hexBlockNo != "" && hexBlockNo != "latest" && !strings.HasPrefix(hexBlockNo, "0x") {
+		hexBlockNo = fmt.Sprintf("0x%x", base.MustParseUint64(hexBlockNo))
+	}
+
+	payloads := []query.BatchPayload{
+		{
+			Key: "erc721",
+			Payload: &query.Payload{
+				Method: "eth_call",
+				Params: query.Params{
+					map[string]any{
+						"to":   token.Hex(),
+						"data": erc721SupportsInterfaceData,
+					},
+					hexBlockNo,
+				},
+			},
+		},
+		{
+			Key: "ownerOf",
+			Payload: &query.Payload{
+				Method: "eth_call",
+				Params: query.Params{
+					map[string]any{
+						"to":   token.Hex(),
+						"data": tokenStateOwnerOf + fmt.Sprintf("%064x", value.BigInt()),
+					},
+					hexBlockNo,
+				},
+			},
+		},
+	}
+
+	// Fungible tokens have no ownerOf, so the call reverts (or returns nothing)
+	output, err := query.QueryBatch[string](conn.Chain, payloads)
+	if err = ignoreReverts(err); err != nil {
+		return false, err
+	}
+
+	if output["erc721"] != nil {
+		if erc721, err := decode.ArticulateBool(*output["erc721"]); err == nil && erc721 {
+			return true, nil
+		}
+	}
+	return output["ownerOf"] != nil && len(strings.TrimPrefix(*output["ownerOf"], "0x")) == 64, nil
+}
+
+// ignoreReverts returns nil if the only errors in a batch are calls whose execution reverted
+// (which the token functions read as empty or zero). Any other error (for example, a node
+// without the historical state or one that limited our rate) is returned.
+func ignoreReverts(err error) error {
+	var batchErr *query.BatchError
+	if errors.As(err, &batchErr) && batchErr.OnlyReverts() {
+		return nil
+	}
+	return err
+}
@@ -195,3 +195,31 @@ func TestGetState_NonStandard(t *testing.T) {
 		t.Fatal("wrong total supply:", token.TotalSupply)
 	}
 }
+
+// CryptoKitties (its Transfer event does not index the token id and it predates the ERC-721 interface id)
+var kittyAddress = base.HexToAddress("0x06012c8cf97bead5deae237070f9587f8e7a266d")
+
+func TestIsErc721(t *testing.T) {
+	blockNumber := "0xd59f80" // 14000000
+	chain := utils.GetTestChain()
+	conn := TempConnection(chain)
+
+	tests := []struct {
+		token    base.Address
+		value    *base.Wei
+		expected bool
+	}{
+		{kittyAddress, base.NewWei(1), true},
+		{nftAddress, base.NewWei(1), true},
+		{tokenAddress, base.NewWei(1000), false},
+	}
+	for _, test := range tests {
+		isErc721, err := conn.IsErc721(test.token, test.value, blockNumber)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if isErc721 != test.expected {
+			t.Error("wrong classification for", test.token.Hex(), isErc721)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package rpc
+
+import (
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc/query"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// The token tests are answered from calls recorded from the devnet's simple scenario (see
+// query.ReplayFixtures to record them again)
+var (
+	testToken  = base.HexToAddress("0xaaaa000000000000000000000000000000000000")
+	accountOne = base.HexToAddress("0x1111111111111111111111111111111111111111")
+	accountTwo = base.HexToAddress("0x2222222222222222222222222222222222222222")
+)
+
+func fixturesConnection(t *testing.T) *Connection {
+	t.Helper()
+	if err := query.ReplayFixtures("fixtures", "testdata/fixtures.json"); err != nil {
+		t.Fatal(err)
+	}
+	return &Connection{Chain: "fixtures"}
+}
+
+func TestGetTokenStateFixtures(t *testing.T) {
+	conn := fixturesConnection(t)
+
+	token, err := conn.GetTokenState(testToken, "3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Name != "Test Token" || token.Symbol != "TT" || token.Decimals != 18 || token.TokenType != types.TokenErc20 {
+		t.Error("unexpected token", token.Name, token.Symbol, token.Decimals, token.TokenType)
+	}
+	if token.TotalSupply.String() != "500" {
+		t.Error("unexpected total supply", token.TotalSupply.String())
+	}
+
+	if _, err := conn.GetTokenState(accountTwo, "3"); err == nil {
+		t.Error("expected an account not to be a token")
+	}
+}
+
+func TestGetBalanceAtTokenFixtures(t *testing.T) {
+	conn := fixturesConnection(t)
+
+	tests := []struct {
+		holder base.Address
+		block  string
+		want   string
+	}{
+		{accountOne, "2", "500"},
+		{accountTwo, "2", "0"},
+		{accountOne, "3", "400"},
+		{accountTwo, "0x3", "100"},
+	}
+	for _, tt := range tests {
+		balance, err := conn.GetBalanceAtToken(testToken, tt.holder, tt.block)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if balance.String() != tt.want {
+			t.Errorf("balance of %s at %s: got %s, want %s", tt.holder.Hex(), tt.block, balance.String(), tt.want)
+		}
+	}
+}
+
+func TestIsErc721Fixtures(t *testing.T) {
+	conn := fixturesConnection(t)
+
+	// The token has no ownerOf, so the call reverts and the token is fungible
+	if erc721, err := conn.IsErc721(testToken, base.NewWei(100), "3"); err != nil || erc721 {
+		t.Error("expected a fungible token", erc721, err)
+	}
+}
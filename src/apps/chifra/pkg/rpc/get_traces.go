@@ -5,10 +5,7 @@
 package rpc
 
 import (
-	"fmt"
-
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
-	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc/query"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/walk"
 )
@@ -29,10 +26,7 @@ func (conn *Connection) GetTracesByBlockNumber(bn base.Blknum) ([]types.Trace, e
 	curTs := conn.GetBlockTimestamp(bn) // same for every trace
 	isFinal := base.IsFinal(conn.LatestBlockTimestamp, curTs)
 
-	method := "trace_block"
-	params := query.Params{fmt.Sprintf("0x%x", bn)}
-
-	if traces, err := query.Query[[]types.Trace](conn.Chain, method, params); err != nil {
+	if traces, err := conn.getBlockTracesFromRpc(bn); err != nil {
 		return []types.Trace{{
 			Action: &types.TraceAction{},
 			Result: &types.TraceResult{},
@@ -89,10 +83,7 @@ func (conn *Connection) GetTracesByTransactionHash(txHash string, transaction *t
 		}
 	}
 
-	method := "trace_transaction"
-	params := query.Params{txHash}
-
-	if traces, err := query.Query[[]types.Trace](conn.Chain, method, params); err != nil {
+	if traces, err := conn.getTransactionTracesFromRpc(txHash, transaction); err != nil {
 		return []types.Trace{{
 			Action: &types.TraceAction{},
 			Result: &types.TraceResult{},
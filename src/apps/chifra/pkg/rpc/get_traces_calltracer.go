@@ -0,0 +1,172 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package rpc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc/query"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// callFrame is a call as reported by geth's callTracer. Each frame carries the calls it made.
+type callFrame struct {
+	Type    string       `json:"type"`
+	From    base.Address `json:"from"`
+	To      base.Address `json:"to"`
+	Value   base.Wei     `json:"value"`
+	Gas     base.Gas     `json:"gas"`
+	GasUsed base.Gas     `json:"gasUsed"`
+	Input   string       `json:"input"`
+	Output  string       `json:"output"`
+	Error   string       `json:"error"`
+	Calls   []callFrame  `json:"calls"`
+}
+
+// txFrame is a transaction's top level call as reported by debug_traceBlockByNumber. Older
+// versions of geth do not report the transaction's hash.
+type txFrame struct {
+	TxHash base.Hash  `json:"txHash"`
+	Result *callFrame `json:"result"`
+	Error  string     `json:"error"`
+}
+
+var callTracer = map[string]any{"tracer": "callTracer"}
+
+// getBlockTracesFromRpc returns the block's traces from the node in the format of trace_block,
+// converting them from callTracer frames if the node does not support the trace_ namespace
+func (conn *Connection) getBlockTracesFromRpc(bn base.Blknum) (*[]types.Trace, error) {
+	if conn.getTraceApi() != traceApiCallTracer {
+		return query.Query[[]types.Trace](conn.Chain, "trace_block", query.Params{fmt.Sprintf("0x%x", bn)})
+	}
+
+	frames, err := query.Query[[]txFrame](conn.Chain, "debug_traceBlockByNumber", query.Params{fmt.Sprintf("0x%x", bn), callTracer})
+	if err != nil || frames == nil {
+		return nil, err
+	}
+
+	block, err := conn.GetBlockHeaderByNumber(bn)
+	if err != nil {
+		return nil, err
+	}
+	if len(block.Transactions) != len(*frames) {
+		return nil, fmt.Errorf("block %d has %d transactions but %d were traced", bn, len(block.Transactions), len(*frames))
+	}
+
+	traces := make([]types.Trace, 0, 4*len(*frames))
+	for i, frame := range *frames {
+		if len(frame.Error) > 0 || frame.Result == nil {
+			return nil, fmt.Errorf("could not trace transaction %d in block %d: %s", i, bn, frame.Error)
+		}
+		start := len(traces)
+		traces = tracesFromFrame(frame.Result, []uint64{}, traces)
+		for j := start; j < len(traces); j++ {
+			traces[j].BlockHash = block.Hash
+			traces[j].BlockNumber = bn
+			traces[j].TransactionHash = base.HexToHash(block.Transactions[i])
+			traces[j].TransactionPosition = base.Txnum(i)
+		}
+	}
+	return &traces, nil
+}
+
+// getTransactionTracesFromRpc returns the transaction's traces from the node in the format of
+// trace_transaction, converting them from callTracer frames if the node does not support the
+// trace_ namespace
+func (conn *Connection) getTransactionTracesFromRpc(txHash string, transaction *types.Transaction) (*[]types.Trace, error) {
+	if conn.getTraceApi() != traceApiCallTracer {
+		return query.Query[[]types.Trace](conn.Chain, "trace_transaction", query.Params{txHash})
+	}
+
+	frame, err := query.Query[callFrame](conn.Chain, "debug_traceTransaction", query.Params{txHash, callTracer})
+	if err != nil || frame == nil {
+		return nil, err
+	}
+
+	if transaction == nil {
+		if transaction, err = conn.getTransactionFromRpc(notAHash, base.HexToHash(txHash), base.NOPOSN, base.NOPOSN); err != nil {
+			return nil, err
+		}
+	}
+
+	traces := tracesFromFrame(frame, []uint64{}, make([]types.Trace, 0, 4))
+	for i := range traces {
+		traces[i].BlockHash = transaction.BlockHash
+		traces[i].BlockNumber = transaction.BlockNumber
+		traces[i].TransactionHash = transaction.Hash
+		traces[i].TransactionPosition = transaction.TransactionIndex
+	}
+	return &traces, nil
+}
+
+// tracesFromFrame appends the frame, then the frames it called (depth first), to the traces as
+// parity would report them. The caller fills in the block and transaction of each trace.
+func tracesFromFrame(frame *callFrame, traceAddress []uint64, traces []types.Trace) []types.Trace {
+	trace := types.Trace{
+		Action:       &types.TraceAction{},
+		Error:        parityError(frame.Error),
+		Subtraces:    uint64(len(frame.Calls)),
+		TraceAddress: traceAddress,
+	}
+
+	switch frameType := strings.ToLower(frame.Type); frameType {
+	case "create", "create2":
+		trace.TraceType = "create"
+		trace.Action.From = frame.From
+		trace.Action.Gas = frame.Gas
+		trace.Action.Init = frame.Input
+		trace.Action.Value = frame.Value
+		trace.Result = &types.TraceResult{
+			Address: frame.To,
+			Code:    frame.Output,
+			GasUsed: frame.GasUsed,
+		}
+	case "selfdestruct":
+		trace.TraceType = "suicide"
+		trace.Action.Address = frame.From
+		trace.Action.RefundAddress = frame.To
+		trace.Action.Balance = frame.Value
+	default:
+		trace.TraceType = "call"
+		trace.Action.CallType = frameType
+		trace.Action.From = frame.From
+		trace.Action.To = frame.To
+		trace.Action.Gas = frame.Gas
+		trace.Action.Input = frame.Input
+		if frameType != "delegatecall" && frameType != "staticcall" {
+			// Value reported with these calls is the caller's, it does not move
+			trace.Action.Value = frame.Value
+		}
+		trace.Result = &types.TraceResult{
+			GasUsed: frame.GasUsed,
+			Output:  frame.Output,
+		}
+	}
+	if len(trace.Error) > 0 {
+		// As with parity, a failed call has no result
+		trace.Result = nil
+	}
+
+	traces = append(traces, trace)
+	for i := range frame.Calls {
+		address := make([]uint64, len(traceAddress), len(traceAddress)+1)
+		copy(address, traceAddress)
+		traces = tracesFromFrame(&frame.Calls[i], append(address, uint64(i)), traces)
+	}
+	return traces
+}
+
+// parityError returns the error as parity would report it
+func parityError(err string) string {
+	switch err {
+	case "execution reverted":
+		return "Reverted"
+	case "out of gas":
+		return "Out of gas"
+	}
+	return err
+}
@@ -0,0 +1,120 @@
+package rpc
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/devnet"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc/query"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+func TestTracesFromFrame(t *testing.T) {
+	// A transaction that calls a proxy, which delegates to an implementation that creates a
+	// contract (which self destructs) and makes a failed call
+	data := `{
+		"type": "CALL", "from": "0x1111111111111111111111111111111111111111", "to": "0x2222222222222222222222222222222222222222",
+		"value": "0x10", "gas": "0x5208", "gasUsed": "0x5000", "input": "0xabcdef01", "output": "0x",
+		"calls": [{
+			"type": "DELEGATECALL", "from": "0x2222222222222222222222222222222222222222", "to": "0x3333333333333333333333333333333333333333",
+			"value": "0x10", "gas": "0x100", "gasUsed": "0x80", "input": "0xabcdef01",
+			"calls": [{
+				"type": "CREATE2", "from": "0x2222222222222222222222222222222222222222", "to": "0x4444444444444444444444444444444444444444",
+				"value": "0x5", "gas": "0x50", "gasUsed": "0x40", "input": "0x6080", "output": "0x6080",
+				"calls": [{
+					"type": "SELFDESTRUCT", "from": "0x4444444444444444444444444444444444444444", "to": "0x1111111111111111111111111111111111111111",
+					"value": "0x5"
+				}]
+			}, {
+				"type": "CALL", "from": "0x2222222222222222222222222222222222222222", "to": "0x5555555555555555555555555555555555555555",
+				"value": "0x1", "gas": "0x10", "gasUsed": "0x10", "input": "0x", "error": "execution reverted"
+			}]
+		}]
+	}`
+	var frame callFrame
+	if err := json.Unmarshal([]byte(data), &frame); err != nil {
+		t.Fatal(err)
+	}
+
+	traces := tracesFromFrame(&frame, []uint64{}, nil)
+	expected := []struct {
+		traceType    string
+		callType     string
+		traceAddress []uint64
+		subtraces    uint64
+		value        string
+		err          string
+	}{
+		{"call", "call", []uint64{}, 1, "16", ""},
+		{"call", "delegatecall", []uint64{0}, 2, "0", ""},
+		{"create", "", []uint64{0, 0}, 1, "5", ""},
+		{"suicide", "", []uint64{0, 0, 0}, 0, "0", ""},
+		{"call", "call", []uint64{0, 1}, 0, "1", "Reverted"},
+	}
+	if len(traces) != len(expected) {
+		t.Fatalf("expected %d traces, got %d", len(expected), len(traces))
+	}
+	for i, want := range expected {
+		trace := traces[i]
+		if trace.TraceType != want.traceType || trace.Action.CallType != want.callType ||
+			!reflect.DeepEqual(trace.TraceAddress, want.traceAddress) || trace.Subtraces != want.subtraces ||
+			trace.Action.Value.String() != want.value || trace.Error != want.err {
+			t.Errorf("trace %d: %s %s %v %d %s %q", i, trace.TraceType, trace.Action.CallType, trace.TraceAddress, trace.Subtraces, trace.Action.Value.String(), trace.Error)
+		}
+	}
+
+	if created := traces[2]; created.Result.Address.Hex() != "0x4444444444444444444444444444444444444444" || created.Action.Init != "0x6080" {
+		t.Errorf("create: %s", created.String())
+	}
+	if destructed := traces[3]; destructed.Action.Address.Hex() != "0x4444444444444444444444444444444444444444" ||
+		destructed.Action.RefundAddress.Hex() != "0x1111111111111111111111111111111111111111" || destructed.Action.Balance.String() != "5" {
+		t.Errorf("selfdestruct: %s", destructed.String())
+	}
+	if failed := traces[4]; failed.Result != nil {
+		t.Errorf("a failed call has no result: %s", failed.String())
+	}
+}
+
+// TestTracesFromFrameMatchParity checks that a node's callTracer frames, once converted, are
+// the traces it reports through the trace_ namespace
+func TestTracesFromFrameMatchParity(t *testing.T) {
+	scenario, err := devnet.LoadScenario("../devnet/testdata/simple.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := devnet.NewServer(scenario)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	for _, bn := range []string{"0x1", "0x3", "0x4"} {
+		parity, err := query.QueryUrl[[]types.Trace](ts.URL, "trace_block", query.Params{bn})
+		if err != nil {
+			t.Fatal(err)
+		}
+		frames, err := query.QueryUrl[[]txFrame](ts.URL, "debug_traceBlockByNumber", query.Params{bn, callTracer})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var converted []types.Trace
+		for _, frame := range *frames {
+			converted = tracesFromFrame(frame.Result, []uint64{}, converted)
+		}
+		if len(converted) != len(*parity) {
+			t.Fatalf("block %s: %d traces converted, %d reported", bn, len(converted), len(*parity))
+		}
+		for i, want := range *parity {
+			got := converted[i]
+			if got.TraceType != want.TraceType || got.Error != want.Error || got.Subtraces != want.Subtraces ||
+				!reflect.DeepEqual(got.TraceAddress, want.TraceAddress) ||
+				got.Action.String() != want.Action.String() || !reflect.DeepEqual(got.Result, want.Result) {
+				t.Errorf("block %s trace %d:\n converted %s\n  reported %s", bn, i, got.String(), want.String())
+			}
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/prefunds"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc/query"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
@@ -20,7 +21,7 @@ func (conn *Connection) GetTransactionByNumberAndId(bn base.Blknum, txid base.Tx
 			BlockNumber:      bn,
 			TransactionIndex: txid,
 		}
-		if err := conn.Store.Read(tx, nil); err == nil {
+		if err := conn.Store.Read(tx, conn.readOptions(bn)); err == nil {
 			// success
 			return tx, nil
 		}
@@ -69,7 +70,7 @@ func (conn *Connection) GetTransactionByAppearance(app *types.Appearance, fetchT
 			BlockNumber:      bn,
 			TransactionIndex: txid,
 		}
-		if err := conn.Store.Read(tx, nil); err == nil {
+		if err := conn.Store.Read(tx, conn.readOptions(bn)); err == nil {
 			// success
 			if fetchTraces {
 				traces, err := conn.GetTracesByTransactionHash(tx.Hash.Hex(), tx)
@@ -271,10 +272,26 @@ func (conn *Connection) GetTransactionRewardByTypeAndApp(rt base.Txnum, theApp *
 						nephewReward = new(base.Wei).Mul(blockReward, base.NewWei(int64(nUncles)))
 						nephewReward.Div(nephewReward, base.NewWei(32))
 					}
-					for _, tx := range block.Transactions {
-						gp := base.NewWei(int64(tx.GasPrice))
-						gu := base.NewWei(int64(tx.Receipt.GasUsed))
-						feeReward = feeReward.Add(feeReward, gp.Mul(gp, gu))
+					switch family := config.GetChainFamily(conn.Chain); family {
+					case config.FamilyOptimism:
+						// The sequencer's fee vault receives only the priority fees. The base fees and
+						// the L1 fees go to other vaults and deposits pay nothing.
+						for _, tx := range block.Transactions {
+							if tx.IsDeposit(family) || tx.GasPrice <= block.BaseFeePerGas {
+								continue
+							}
+							gp := base.NewWei(int64(tx.GasPrice - block.BaseFeePerGas))
+							gu := base.NewWei(int64(tx.Receipt.GasUsed))
+							feeReward = feeReward.Add(feeReward, gp.Mul(gp, gu))
+						}
+					case config.FamilyArbitrum:
+						// Arbitrum pays nothing to the block's coinbase
+					default:
+						for _, tx := range block.Transactions {
+							gp := base.NewWei(int64(tx.GasPrice))
+							gu := base.NewWei(int64(tx.Receipt.GasUsed))
+							feeReward = feeReward.Add(feeReward, gp.Mul(gp, gu))
+						}
 					}
 				} else {
 					blockReward = base.NewWei(0)
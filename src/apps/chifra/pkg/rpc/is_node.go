@@ -1,15 +1,19 @@
 package rpc
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/prefunds"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc/query"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
 )
 
 // TODO: Some of this code may be chain-specific - for example,
@@ -40,15 +44,62 @@ func (conn *Connection) IsNodeArchive() bool {
 	return bal.Cmp(&largest.Balance) == 0
 }
 
-// IsNodeTracing returns true if the node exposes the `block_trace` RPC endpoint.
-// It queries block 1 or a user supplied block (which we presume exists). The function
-// returns false if block_trace returns an error or doesn't exist.
+// IsNodeTracing returns true if the node exposes traces, either through parity's `trace_block`
+// or, failing that, through geth's `debug_traceBlockByNumber` with the callTracer. It queries
+// block 1 or a user supplied block (which we presume exists). The function returns false if
+// both return an error or don't exist.
 func (conn *Connection) IsNodeTracing() (error, bool) {
+	_, err := conn.GetTracesByBlockNumber(conn.firstTraceBlock())
+	return err, err == nil
+}
+
+// firstTraceBlock returns the first block known to have traces (or a user supplied block)
+func (conn *Connection) firstTraceBlock() base.Blknum {
 	firstTrace := base.Max(1, base.KnownBlock(conn.Chain, base.FirstTrace))
 	varName := "TB_" + strings.ToUpper(conn.Chain) + "_FIRSTTRACE"
 	if len(os.Getenv(varName)) > 0 {
 		firstTrace = base.Max(firstTrace, base.MustParseValue(os.Getenv(varName)))
 	}
-	_, err := conn.GetTracesByBlockNumber(firstTrace)
-	return err, err == nil
+	return firstTrace
+}
+
+// traceApi is the API through which a node exposes traces
+type traceApi int
+
+const (
+	// traceApiParity is the trace_ namespace (parity, erigon, nethermind, reth)
+	traceApiParity traceApi = iota
+	// traceApiCallTracer is the debug_ namespace with the callTracer (geth, reth, most L2s)
+	traceApiCallTracer
+)
+
+var (
+	traceApis      = map[string]traceApi{}
+	traceApisMutex sync.Mutex
+)
+
+// getTraceApi returns the API through which the chain's node exposes traces. The first time it
+// is called for a chain, it asks the node for the traces of the first trace block, first with
+// trace_block and then, if that fails, with debug_traceBlockByNumber. If neither works (for
+// example, the node is down), the trace_ namespace is assumed, but the question is asked again
+// next time.
+func (conn *Connection) getTraceApi() traceApi {
+	traceApisMutex.Lock()
+	defer traceApisMutex.Unlock()
+
+	if api, ok := traceApis[conn.Chain]; ok {
+		return api
+	}
+
+	bn := fmt.Sprintf("0x%x", conn.firstTraceBlock())
+	if _, err := query.Query[[]types.Trace](conn.Chain, "trace_block", query.Params{bn}); err == nil {
+		traceApis[conn.Chain] = traceApiParity
+		return traceApiParity
+	}
+	if _, err := query.Query[[]txFrame](conn.Chain, "debug_traceBlockByNumber", query.Params{bn, callTracer}); err == nil {
+		logger.Info("The node for", conn.Chain, "does not support trace_block, using debug_traceBlockByNumber")
+		traceApis[conn.Chain] = traceApiCallTracer
+		return traceApiCallTracer
+	}
+	return traceApiParity
 }
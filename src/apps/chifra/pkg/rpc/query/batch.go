@@ -0,0 +1,287 @@
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/debug"
+)
+
+const (
+	// maxInFlight is the number of batches a pool sends at once. Calls made while that many
+	// are waiting for answers are queued and sent together in the next batch.
+	maxInFlight = 4
+	// defaultBatchSize is the most calls coalesced into a batch if the chain's config does not
+	// set a ceiling
+	defaultBatchSize = 100
+)
+
+func (e *eip1474Error) Error() string {
+	return fmt.Sprintf("%d: %s", e.Code, e.Message)
+}
+
+// BatchError reports the calls of a batch that the node answered with an error, by key. The
+// results of the batch's other calls are returned with it.
+type BatchError struct {
+	Errors map[string]error
+}
+
+func (e *BatchError) Error() string {
+	keys := make([]string, 0, len(e.Errors))
+	for key := range e.Errors {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	msgs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		msgs = append(msgs, key+": "+e.Errors[key].Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// OnlyReverts returns true if each of the failed calls failed because its execution reverted,
+// in which case the calls' results (not the node) are to blame. Any other error (for example,
+// a node without the historical state, a rate limit, or a timeout) means the call may succeed
+// if sent again or elsewhere, so the caller should report it.
+func (e *BatchError) OnlyReverts() bool {
+	for _, err := range e.Errors {
+		if !IsRevert(err) {
+			return false
+		}
+	}
+	return true
+}
+
+// codeExecutionReverted is the error code with which geth reports a call that reverted with a reason
+const codeExecutionReverted = 3
+
+// IsRevert returns true if the error is the node's report that the call's execution reverted (or
+// otherwise failed in the EVM). Nodes do not agree on a code for this, so we also check the message.
+func IsRevert(err error) bool {
+	var rpcErr *eip1474Error
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	if rpcErr.Code == codeExecutionReverted {
+		return true
+	}
+	msg := strings.ToLower(rpcErr.Message)
+	for _, reason := range []string{"revert", "vm execution error", "invalid opcode", "invalid jump"} {
+		if strings.Contains(msg, reason) {
+			return true
+		}
+	}
+	return false
+}
+
+// call is a single call in a batch. Once the batch has been sent, the call holds either its
+// result or its error: an *eip1474Error if the node answered with an error, any other error if
+// the batch could not be sent.
+type call struct {
+	payload rpcPayload
+	result  json.RawMessage
+	err     error
+	done    chan struct{}
+}
+
+func newCall(method string, params Params) *call {
+	return &call{
+		payload: rpcPayload{
+			Jsonrpc: "2.0",
+			Method:  method,
+			Params:  params,
+			ID:      int(atomic.AddUint32(&rpcCounter, 1)),
+		},
+		done: make(chan struct{}),
+	}
+}
+
+// batchResponse is a single response in a batch
+type batchResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *eip1474Error   `json:"error"`
+}
+
+// sendCalls sends the calls to the pool in a single batch and matches the responses to the
+// calls by id. If the node rejects the batch as too large, its halves are sent instead.
+func (p *Pool) sendCalls(headers map[string]string, calls []*call) {
+	url := p.Url()
+	payloads := make([]rpcPayload, 0, len(calls))
+	for _, c := range calls {
+		debug.DebugCurl(rpcDebug{url: url, payload: c.payload, headers: headers})
+		payloads = append(payloads, c.payload)
+	}
+
+	fail := func(err error) {
+		for _, c := range calls {
+			c.err = err
+		}
+	}
+
+	plBytes, err := json.Marshal(payloads)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	request, err := http.NewRequest("POST", url, bytes.NewReader(plBytes))
+	if err != nil {
+		fail(err)
+		return
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := p.Client.Do(request)
+	if err != nil {
+		fail(err)
+		return
+	}
+	defer response.Body.Close()
+
+	theBytes, err := io.ReadAll(response.Body)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	var responses []batchResponse
+	var rejected error
+	switch {
+	case response.StatusCode == http.StatusRequestEntityTooLarge:
+		rejected = fmt.Errorf("%s: %d", response.Status, response.StatusCode)
+	case response.StatusCode != http.StatusOK:
+		fail(fmt.Errorf("%s: %d", response.Status, response.StatusCode))
+		return
+	default:
+		if responses, rejected, err = parseBatch(theBytes); err != nil {
+			fail(err)
+			return
+		}
+	}
+
+	if rejected != nil {
+		if len(calls) == 1 {
+			fail(rejected)
+			return
+		}
+		// The node refused the whole batch (most likely because it is too large), so send it in halves
+		half := len(calls) / 2
+		p.sendCalls(headers, calls[:half])
+		p.sendCalls(headers, calls[half:])
+		return
+	}
+
+	byId := make(map[string]*call, len(calls))
+	for _, c := range calls {
+		byId[strconv.Itoa(c.payload.ID)] = c
+	}
+	for _, r := range responses {
+		c := byId[strings.Trim(string(bytes.TrimSpace(r.ID)), `"`)]
+		if c == nil {
+			continue
+		}
+		if r.Error != nil {
+			c.err = r.Error
+		} else {
+			c.result = r.Result
+		}
+		delete(byId, strconv.Itoa(c.payload.ID))
+	}
+	for _, c := range byId {
+		c.err = fmt.Errorf("no response to call %d (%s)", c.payload.ID, c.payload.Method)
+	}
+}
+
+// parseBatch parses the node's answer to a batch. A node that refuses a batch as a whole
+// answers with a single error (rejected) rather than a response to each call.
+func parseBatch(body []byte) (responses []batchResponse, rejected error, err error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] != '[' {
+		var single batchResponse
+		if err = json.Unmarshal(trimmed, &single); err != nil {
+			return nil, nil, err
+		}
+		if single.Error != nil {
+			return nil, single.Error, nil
+		}
+		return nil, nil, fmt.Errorf("unexpected response to a batch: %s", string(trimmed))
+	}
+
+	if err = json.Unmarshal(trimmed, &responses); err != nil {
+		return nil, nil, err
+	}
+	if len(responses) == 1 && responses[0].Error != nil && isNullId(responses[0].ID) {
+		return nil, responses[0].Error, nil
+	}
+	return responses, nil, nil
+}
+
+func isNullId(id json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(id)
+	return len(trimmed) == 0 || string(trimmed) == "null"
+}
+
+// batcher coalesces the independent calls sent to a pool at about the same time (for example,
+// by the scraper's workers) into batches. A call is sent at once if fewer than maxInFlight
+// batches are waiting for answers, so callers working one call at a time wait no longer.
+type batcher struct {
+	pool     *Pool
+	mutex    sync.Mutex
+	queue    []*call
+	inFlight int
+}
+
+// do sends the calls (perhaps together with others) and waits for all of them to be answered
+func (b *batcher) do(calls []*call) {
+	b.mutex.Lock()
+	b.queue = append(b.queue, calls...)
+	b.dispatch()
+	b.mutex.Unlock()
+
+	for _, c := range calls {
+		<-c.done
+	}
+}
+
+// dispatch sends the queued calls in as many batches as may be in flight. The caller holds the
+// mutex.
+func (b *batcher) dispatch() {
+	size := b.pool.maxBatch
+	if size == 0 {
+		size = defaultBatchSize
+	}
+
+	for len(b.queue) > 0 && b.inFlight < maxInFlight {
+		n := min(len(b.queue), size)
+		batch := b.queue[:n:n]
+		b.queue = b.queue[n:]
+		b.inFlight++
+		go b.send(batch)
+	}
+}
+
+func (b *batcher) send(batch []*call) {
+	b.pool.sendCalls(map[string]string{}, batch)
+	for _, c := range batch {
+		close(c.done)
+	}
+
+	b.mutex.Lock()
+	b.inFlight--
+	b.dispatch()
+	b.mutex.Unlock()
+}
@@ -0,0 +1,236 @@
+package query
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/configtypes"
+)
+
+// fakeNode answers each call it receives with the call's method (or a revert if the method is
+// eth_fail and a missing state error if it is eth_missing), answering batches in reverse order as a node is free to do. It records the size of
+// the batches.
+type fakeNode struct {
+	mutex  sync.Mutex
+	sizes  []int
+	delay  time.Duration
+	reject func(calls []rpcPayload, w http.ResponseWriter) bool
+}
+
+func (n *fakeNode) start(t *testing.T, settings configtypes.RpcSettings) *Pool {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var calls []rpcPayload
+		single := json.Unmarshal(body, &calls) != nil
+		if single {
+			calls = make([]rpcPayload, 1)
+			_ = json.Unmarshal(body, &calls[0])
+		}
+		n.mutex.Lock()
+		n.sizes = append(n.sizes, len(calls))
+		n.mutex.Unlock()
+		time.Sleep(n.delay)
+
+		if n.reject != nil && n.reject(calls, w) {
+			return
+		}
+
+		responses := []map[string]any{}
+		for i := len(calls) - 1; i >= 0; i-- {
+			response := map[string]any{"jsonrpc": "2.0", "id": calls[i].ID}
+			if calls[i].Method == "eth_fail" {
+				response["error"] = map[string]any{"code": 3, "message": "execution reverted"}
+			} else if calls[i].Method == "eth_missing" {
+				response["error"] = map[string]any{"code": -32000, "message": "missing trie node 1f2e (path )"}
+			} else {
+				response["result"] = calls[i].Method
+			}
+			responses = append(responses, response)
+		}
+		if single {
+			_ = json.NewEncoder(w).Encode(responses[0])
+		} else {
+			_ = json.NewEncoder(w).Encode(responses)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	pool, err := NewPool([]string{server.URL}, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pool
+}
+
+func (n *fakeNode) batchSizes() []int {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	sizes := append([]int{}, n.sizes...)
+	sort.Ints(sizes)
+	return sizes
+}
+
+func payloads(methods ...string) []BatchPayload {
+	ret := []BatchPayload{}
+	for _, method := range methods {
+		ret = append(ret, BatchPayload{Key: method + "Key", Payload: &Payload{Method: method, Params: Params{}}})
+	}
+	return ret
+}
+
+func checkResults(t *testing.T, results map[string]*string, methods ...string) {
+	t.Helper()
+	for _, method := range methods {
+		if res := results[method+"Key"]; res == nil || *res != method {
+			t.Error("unexpected result for", method, res)
+		}
+	}
+}
+
+func TestBatchMatchedById(t *testing.T) {
+	node := &fakeNode{}
+	pool := node.start(t, configtypes.RpcSettings{})
+
+	methods := []string{"eth_a", "eth_b", "eth_c"}
+	results, err := sendBatch[string](pool, map[string]string{}, payloads(methods...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkResults(t, results, methods...)
+}
+
+func TestBatchErrors(t *testing.T) {
+	node := &fakeNode{}
+	pool := node.start(t, configtypes.RpcSettings{})
+
+	results, err := sendBatch[string](pool, map[string]string{}, payloads("eth_a", "eth_fail", "eth_b"))
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || len(batchErr.Errors) != 1 || batchErr.Errors["eth_failKey"] == nil {
+		t.Fatal("expected the failed call to be reported, got", err)
+	}
+	if err.Error() != "eth_failKey: 3: execution reverted" {
+		t.Error("unexpected error", err)
+	}
+	if _, ok := results["eth_failKey"]; ok {
+		t.Error("expected no result for the failed call")
+	}
+	checkResults(t, results, "eth_a", "eth_b")
+}
+
+func TestBatchErrorReverts(t *testing.T) {
+	node := &fakeNode{}
+	pool := node.start(t, configtypes.RpcSettings{})
+
+	_, err := sendBatch[string](pool, map[string]string{}, payloads("eth_a", "eth_fail"))
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || !batchErr.OnlyReverts() {
+		t.Fatal("expected only reverts, got", err)
+	}
+
+	_, err = sendBatch[string](pool, map[string]string{}, payloads("eth_fail", "eth_missing"))
+	if !errors.As(err, &batchErr) || batchErr.OnlyReverts() {
+		t.Fatal("expected a missing state error to be reported, got", err)
+	}
+	if IsRevert(batchErr.Errors["eth_missingKey"]) || !IsRevert(batchErr.Errors["eth_failKey"]) {
+		t.Error("misclassified errors", err)
+	}
+
+	for _, e := range []*eip1474Error{
+		{Code: -32000, Message: "execution reverted"},
+		{Code: -32015, Message: "VM execution error."},
+		{Code: -32000, Message: "invalid opcode: INVALID"},
+	} {
+		if !IsRevert(e) {
+			t.Error("expected a revert", e)
+		}
+	}
+	for _, e := range []error{
+		&eip1474Error{Code: -32005, Message: "limit exceeded"},
+		&eip1474Error{Code: -32000, Message: "header not found"},
+		errors.New("context deadline exceeded"),
+	} {
+		if IsRevert(e) {
+			t.Error("expected not a revert", e)
+		}
+	}
+}
+
+func TestBatchSize(t *testing.T) {
+	node := &fakeNode{}
+	pool := node.start(t, configtypes.RpcSettings{MaxBatchSize: 2})
+
+	methods := []string{"eth_a", "eth_b", "eth_c", "eth_d", "eth_e"}
+	results, err := sendBatch[string](pool, map[string]string{}, payloads(methods...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sizes := node.batchSizes(); len(sizes) != 3 || sizes[0] != 1 || sizes[2] != 2 {
+		t.Error("expected the batch to be sent in three parts, got", sizes)
+	}
+	checkResults(t, results, methods...)
+}
+
+func TestBatchTooLarge(t *testing.T) {
+	rejections := map[string]func(w http.ResponseWriter){
+		"error": func(w http.ResponseWriter) {
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"batch too large"}}`))
+		},
+		"status": func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+		},
+	}
+
+	for name, reject := range rejections {
+		node := &fakeNode{}
+		node.reject = func(calls []rpcPayload, w http.ResponseWriter) bool {
+			if len(calls) > 2 {
+				reject(w)
+				return true
+			}
+			return false
+		}
+		pool := node.start(t, configtypes.RpcSettings{})
+
+		methods := []string{"eth_a", "eth_b", "eth_c", "eth_d", "eth_e"}
+		results, err := sendBatch[string](pool, map[string]string{}, payloads(methods...))
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		// 5 is refused, 2 is sent, 3 is refused, 1 and 2 are sent
+		if sizes := node.batchSizes(); len(sizes) != 5 {
+			t.Error(name, "expected the batch to be split, got", sizes)
+		}
+		checkResults(t, results, methods...)
+	}
+}
+
+func TestBatchCoalesced(t *testing.T) {
+	node := &fakeNode{delay: 50 * time.Millisecond}
+	pool := node.start(t, configtypes.RpcSettings{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 40; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results, err := sendBatch[string](pool, map[string]string{}, payloads("eth_a"))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			checkResults(t, results, "eth_a")
+		}()
+	}
+	wg.Wait()
+
+	if sizes := node.batchSizes(); len(sizes) > 2*maxInFlight {
+		t.Error("expected the calls to be coalesced, got", sizes)
+	}
+}
@@ -1,2 +1,16 @@
-// Package query provides access to the RPC server
+// Package query provides access to the RPC server.
+//
+// So that code needing a node may be tested without one, a chain's calls may be recorded to
+// a fixtures file and later answered from it (see the fixtures and fixtureMode settings in the
+// chain's [rpc] config, which may also be set in the environment). For example, to record the
+// calls made by a package's integration tests and then run them offline:
+//
+//	TB_CHAINS_MAINNET_RPC_FIXTUREMODE=record TB_CHAINS_MAINNET_RPC_FIXTURES=$PWD/testdata/rpc.json go test -tags integration ./pkg/ledger/...
+//	TB_CHAINS_MAINNET_RPC_FIXTUREMODE=replay TB_CHAINS_MAINNET_RPC_FIXTURES=$PWD/testdata/rpc.json go test -tags integration ./pkg/ledger/...
+//
+// Unit tests call ReplayFixtures to answer their calls from a package's testdata/fixtures.json.
+// The token, ledger, and uniq fixtures were recorded from the devnet's simple scenario. To record
+// them again, serve that scenario with dev_tools/devnet and name the server:
+//
+//	TB_RECORD_FIXTURES=http://localhost:8545 go test ./pkg/ledger/... ./pkg/uniq/... ./pkg/rpc/...
 package query
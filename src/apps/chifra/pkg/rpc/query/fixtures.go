@@ -0,0 +1,312 @@
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+)
+
+// The fixture modes of a chain's RPC settings
+const (
+	// FixtureRecord sends calls to the chain's endpoints as usual and saves each call and its
+	// answer to the fixtures file
+	FixtureRecord = "record"
+	// FixtureReplay answers calls from the fixtures file only. A call that was not recorded fails.
+	FixtureReplay = "replay"
+)
+
+// fixture is a recorded call and the node's answer to it
+type fixture struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *eip1474Error   `json:"error,omitempty"`
+}
+
+// fixtureCall is a single call as found in a request's body
+type fixtureCall struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// fixtureStore holds the fixtures saved in a file. Every transport using the file shares its
+// store, so that their calls are saved together.
+type fixtureStore struct {
+	path     string
+	mutex    sync.Mutex
+	fixtures map[string]*fixture
+}
+
+var (
+	fixtureStores      = map[string]*fixtureStore{}
+	fixtureStoresMutex sync.Mutex
+)
+
+// openFixtureStore returns the store of the fixtures file, reading the file the first time it is
+// opened. When replaying, the file must exist.
+func openFixtureStore(mode, path string) (*fixtureStore, error) {
+	fixtureStoresMutex.Lock()
+	defer fixtureStoresMutex.Unlock()
+
+	if store := fixtureStores[path]; store != nil {
+		return store, nil
+	}
+
+	store := &fixtureStore{
+		path:     path,
+		fixtures: map[string]*fixture{},
+	}
+
+	if !file.FileExists(path) {
+		if mode == FixtureReplay {
+			return nil, fmt.Errorf("fixtures file %s not found", path)
+		}
+	} else {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var fixtures []*fixture
+		if err := json.Unmarshal(data, &fixtures); err != nil {
+			return nil, fmt.Errorf("invalid fixtures file %s: %w", path, err)
+		}
+		for _, f := range fixtures {
+			store.fixtures[fixtureKey(f.Method, f.Params)] = f
+		}
+	}
+
+	fixtureStores[path] = store
+	return store, nil
+}
+
+// fixtureTransport records the calls sent through it (and their answers) to a fixtures file, or
+// answers them from that file, so that code that needs a node may be tested without one. Calls
+// are matched by method and params, so batches need not be made up the same way each time.
+type fixtureTransport struct {
+	mode  string
+	next  http.RoundTripper
+	store *fixtureStore
+}
+
+func newFixtureTransport(mode, path string, next http.RoundTripper) (*fixtureTransport, error) {
+	if mode != FixtureRecord && mode != FixtureReplay {
+		return nil, fmt.Errorf("unknown fixture mode %s (expected %s or %s)", mode, FixtureRecord, FixtureReplay)
+	}
+	if len(path) == 0 {
+		return nil, fmt.Errorf("fixture mode %s requires a fixtures file", mode)
+	}
+
+	store, err := openFixtureStore(mode, path)
+	if err != nil {
+		return nil, err
+	}
+	return &fixtureTransport{
+		mode:  mode,
+		next:  next,
+		store: store,
+	}, nil
+}
+
+func (t *fixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+
+	calls, isBatch, err := parseCalls(body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse the calls in the request: %w", err)
+	}
+
+	if t.mode == FixtureReplay {
+		return t.replay(req, calls, isBatch)
+	}
+	return t.record(req, body, calls)
+}
+
+// replay answers the calls from the fixtures. Every call must have been recorded.
+func (t *fixtureTransport) replay(req *http.Request, calls []fixtureCall, isBatch bool) (*http.Response, error) {
+	t.store.mutex.Lock()
+	defer t.store.mutex.Unlock()
+
+	responses := make([]map[string]any, 0, len(calls))
+	for _, call := range calls {
+		f := t.store.fixtures[fixtureKey(call.Method, call.Params)]
+		if f == nil {
+			return nil, fmt.Errorf("no fixture for %s %s in %s (record it with fixtureMode %s)", call.Method, string(call.Params), t.store.path, FixtureRecord)
+		}
+		response := map[string]any{"jsonrpc": "2.0", "id": call.ID}
+		if f.Error != nil {
+			response["error"] = f.Error
+		} else {
+			response["result"] = f.Result
+		}
+		responses = append(responses, response)
+	}
+
+	var data []byte
+	var err error
+	if isBatch {
+		data, err = json.Marshal(responses)
+	} else {
+		data, err = json.Marshal(responses[0])
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(data)),
+		ContentLength: int64(len(data)),
+		Request:       req,
+	}, nil
+}
+
+// record sends the calls on and saves the node's answers. Answers that may differ if the call
+// is sent again (failures and rate limits) are not saved.
+func (t *fixtureTransport) record(req *http.Request, body []byte, calls []fixtureCall) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	out.ContentLength = int64(len(body))
+
+	resp, err := t.next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	var responses []batchResponse
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if json.Unmarshal(trimmed, &responses) != nil {
+			return resp, nil
+		}
+	} else {
+		var single batchResponse
+		if json.Unmarshal(trimmed, &single) != nil {
+			return resp, nil
+		}
+		responses = append(responses, single)
+	}
+
+	byId := make(map[string]fixtureCall, len(calls))
+	for _, call := range calls {
+		byId[string(bytes.TrimSpace(call.ID))] = call
+	}
+
+	t.store.mutex.Lock()
+	defer t.store.mutex.Unlock()
+
+	added := false
+	for _, r := range responses {
+		call, ok := byId[string(bytes.TrimSpace(r.ID))]
+		if !ok || (r.Error != nil && isRetryableCode(r.Error.Code)) {
+			continue
+		}
+		t.store.fixtures[fixtureKey(call.Method, call.Params)] = &fixture{
+			Method: call.Method,
+			Params: canonicalParams(call.Params),
+			Result: r.Result,
+			Error:  r.Error,
+		}
+		added = true
+	}
+
+	if added {
+		if err := t.store.save(); err != nil {
+			return nil, fmt.Errorf("cannot save fixtures to %s: %w", t.store.path, err)
+		}
+	}
+	return resp, nil
+}
+
+// save writes the fixtures, ordered by method and params so that the file diffs well. The
+// caller holds the mutex.
+func (s *fixtureStore) save() error {
+	keys := make([]string, 0, len(s.fixtures))
+	for key := range s.fixtures {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fixtures := make([]*fixture, 0, len(keys))
+	for _, key := range keys {
+		fixtures = append(fixtures, s.fixtures[key])
+	}
+
+	data, err := json.MarshalIndent(fixtures, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, s.path)
+}
+
+// parseCalls returns the calls in a (single or batched) request's body
+func parseCalls(body []byte) ([]fixtureCall, bool, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var calls []fixtureCall
+		if err := json.Unmarshal(trimmed, &calls); err != nil {
+			return nil, true, err
+		}
+		if len(calls) == 0 {
+			return nil, true, fmt.Errorf("empty batch")
+		}
+		return calls, true, nil
+	}
+
+	var single fixtureCall
+	if err := json.Unmarshal(trimmed, &single); err != nil {
+		return nil, false, err
+	}
+	return []fixtureCall{single}, false, nil
+}
+
+// fixtureKey identifies a call by its method and params
+func fixtureKey(method string, params json.RawMessage) string {
+	return method + " " + string(canonicalParams(params))
+}
+
+// canonicalParams returns the params with insignificant differences (white space and the
+// order of object keys) removed
+func canonicalParams(params json.RawMessage) json.RawMessage {
+	decoder := json.NewDecoder(bytes.NewReader(params))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil || value == nil {
+		return json.RawMessage("[]")
+	}
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return params
+	}
+	return canonical
+}
@@ -0,0 +1,105 @@
+package query
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/configtypes"
+)
+
+func TestFixturesRecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures.json")
+
+	node := &fakeNode{}
+	recorder := node.start(t, configtypes.RpcSettings{FixtureMode: FixtureRecord, Fixtures: path})
+	if res, err := poolQuery(t, recorder, "eth_a"); err != nil || res != "eth_a" {
+		t.Fatal("unexpected result", res, err)
+	}
+	if _, err := sendBatch[string](recorder, map[string]string{}, payloads("eth_b", "eth_fail")); err == nil {
+		t.Fatal("expected the failed call to be reported")
+	}
+
+	// The replaying pool's endpoint does not exist, so every answer comes from the fixtures
+	replayer, err := NewPool([]string{"http://localhost:1"}, configtypes.RpcSettings{FixtureMode: FixtureReplay, Fixtures: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res, err := poolQuery(t, replayer, "eth_a"); err != nil || res != "eth_a" {
+		t.Error("expected the recorded result, got", res, err)
+	}
+
+	// Batches need not be made up as they were recorded
+	results, err := sendBatch[string](replayer, map[string]string{}, payloads("eth_fail", "eth_a", "eth_b"))
+	if batchErr, ok := err.(*BatchError); !ok || batchErr.Errors["eth_failKey"] == nil {
+		t.Error("expected the recorded error, got", err)
+	}
+	checkResults(t, results, "eth_a", "eth_b")
+
+	if _, err := poolQuery(t, replayer, "eth_missing"); err == nil || !strings.Contains(err.Error(), "no fixture for eth_missing") {
+		t.Error("expected a call that was not recorded to fail, got", err)
+	}
+}
+
+func TestFixturesSettings(t *testing.T) {
+	if _, err := NewPool([]string{"http://localhost:1"}, configtypes.RpcSettings{FixtureMode: "playback", Fixtures: "x"}); err == nil {
+		t.Error("expected an unknown mode to be refused")
+	}
+	if _, err := NewPool([]string{"http://localhost:1"}, configtypes.RpcSettings{FixtureMode: FixtureRecord}); err == nil {
+		t.Error("expected a missing fixtures file name to be refused")
+	}
+	missing := filepath.Join(t.TempDir(), "missing.json")
+	if _, err := NewPool([]string{"http://localhost:1"}, configtypes.RpcSettings{FixtureMode: FixtureReplay, Fixtures: missing}); err == nil {
+		t.Error("expected replaying a missing fixtures file to be refused")
+	}
+}
+
+func TestFixtureKey(t *testing.T) {
+	a := fixtureKey("eth_call", json.RawMessage(`[{"to": "0x1", "data": "0x2"}, "latest"]`))
+	b := fixtureKey("eth_call", json.RawMessage(`[{"data":"0x2","to":"0x1"},"latest"]`))
+	if a != b {
+		t.Error("expected equivalent params to match", a, b)
+	}
+	if fixtureKey("eth_blockNumber", nil) != fixtureKey("eth_blockNumber", json.RawMessage(`[]`)) {
+		t.Error("expected missing params to match empty params")
+	}
+}
+
+func TestFixturesQueryUrl(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures.json")
+	t.Cleanup(func() {
+		poolsMutex.Lock()
+		delete(pools, "recorded")
+		delete(pools, "replayed")
+		poolsMutex.Unlock()
+	})
+
+	node := &fakeNode{}
+	nodeUrl := node.start(t, configtypes.RpcSettings{}).Url()
+	if err := UseFixtures("recorded", FixtureRecord, path, nodeUrl); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := QueryUrl[string](nodeUrl, "eth_a", Params{}); err != nil || *res != "eth_a" {
+		t.Fatal("unexpected result", res, err)
+	}
+
+	// A second pool recording to the same file adds to, rather than replaces, its fixtures
+	other, err := NewPool([]string{nodeUrl}, configtypes.RpcSettings{FixtureMode: FixtureRecord, Fixtures: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res, err := poolQuery(t, other, "eth_b"); err != nil || res != "eth_b" {
+		t.Fatal("unexpected result", res, err)
+	}
+
+	// Calls sent to the url of a replaying pool are answered from its fixtures
+	if err := UseFixtures("replayed", FixtureReplay, path, "http://localhost:1"); err != nil {
+		t.Fatal(err)
+	}
+	for _, method := range []string{"eth_a", "eth_b"} {
+		if res, err := QueryUrl[string]("http://localhost:1", method, Params{}); err != nil || *res != method {
+			t.Error("expected the recorded result, got", res, err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package query
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// limiter is a token bucket that limits the calls sent to an endpoint. Its rate adapts: it is
+// halved each time the endpoint says it is being called too often (down to a sixteenth of the
+// configured rate) and recovers a little with each successful call.
+type limiter struct {
+	mutex  sync.Mutex
+	max    float64
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(perSecond float64) *limiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &limiter{
+		max:    perSecond,
+		rate:   perSecond,
+		tokens: max(perSecond, 1),
+		last:   time.Now(),
+	}
+}
+
+// wait takes n tokens from the bucket, waiting until they are available. A batch larger than
+// the bucket is allowed through once the bucket has refilled enough to pay for it.
+func (l *limiter) wait(ctx context.Context, n int) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mutex.Lock()
+	now := time.Now()
+	l.tokens = min(l.tokens+now.Sub(l.last).Seconds()*l.rate, max(l.rate, 1))
+	l.last = now
+	l.tokens -= float64(n)
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mutex.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// throttled halves the rate after the endpoint has refused a call for calling too often
+func (l *limiter) throttled() {
+	if l == nil {
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.rate = max(l.rate/2, l.max/16)
+}
+
+// succeeded recovers some of the rate lost to throttling
+func (l *limiter) succeeded() {
+	if l == nil {
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.rate = min(l.rate+l.max/50, l.max)
+}
+
+// currentRate returns the calls per second the limiter currently allows (zero if unlimited)
+func (l *limiter) currentRate() float64 {
+	if l == nil {
+		return 0
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.rate
+}
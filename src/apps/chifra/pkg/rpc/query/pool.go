@@ -0,0 +1,513 @@
+package query
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/configtypes"
+)
+
+const (
+	// ewmaWeight is the weight given to the latest call when updating an endpoint's latency and error rate
+	ewmaWeight = 0.2
+	// maxCooldown is the longest an endpoint is passed over after repeated failures
+	maxCooldown = 30 * time.Second
+)
+
+// Pool sends a chain's RPC calls to the best of its endpoints. It tracks each endpoint's latency
+// and error rate, fails over to the next endpoint on transport errors and 5xx responses, and (if
+// a hedge delay is configured) sends slow read-only calls to a second endpoint, using whichever
+// answers first. Each endpoint's calls may be rate limited. Calls that fail on every endpoint
+// for a reason that may pass (including 429 responses and EIP-1474 limit errors) are retried
+// with backoff. Pool is an http.RoundTripper, so any http.Client may use it.
+type Pool struct {
+	endpoints  []*endpoint
+	hedgeDelay time.Duration
+	retries    int
+	maxBatch   int
+	batcher    *batcher
+	transport  http.RoundTripper
+	// Client sends its requests through the pool. The url of the requests is ignored.
+	Client *http.Client
+}
+
+// EndpointStats are the statistics of one of a pool's endpoints
+type EndpointStats struct {
+	Url       string
+	Requests  uint64
+	Errors    uint64
+	Throttled uint64
+	ErrorRate float64
+	RateLimit float64
+	Latency   time.Duration
+	LastError string
+	Healthy   bool
+}
+
+type endpoint struct {
+	url         *url.URL
+	mutex       sync.Mutex
+	requests    uint64
+	errors      uint64
+	throttled   uint64
+	errorRate   float64
+	latency     time.Duration
+	lastError   string
+	consecutive int
+	downUntil   time.Time
+	limiter     *limiter
+}
+
+// NewPool returns a pool of the given endpoints, preferred in the given order until their
+// statistics say otherwise. The settings' hedge delay, rate limit, retries, and fixtures apply
+// (the settings' providers are ignored).
+func NewPool(urls []string, settings configtypes.RpcSettings) (*Pool, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("a pool requires at least one endpoint")
+	}
+
+	pool := &Pool{
+		hedgeDelay: time.Duration(settings.HedgeDelay) * time.Millisecond,
+		retries:    defaultRetries,
+		maxBatch:   int(settings.MaxBatchSize),
+		transport:  http.DefaultTransport,
+	}
+	if settings.MaxRetries > 0 {
+		pool.retries = int(settings.MaxRetries)
+	}
+	for _, u := range urls {
+		parsed, err := url.Parse(u)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endpoint %s: %w", u, err)
+		}
+		pool.endpoints = append(pool.endpoints, &endpoint{
+			url:     parsed,
+			limiter: newLimiter(settings.RequestsPerSecond),
+		})
+	}
+	pool.Client = &http.Client{Transport: pool}
+	pool.batcher = &batcher{pool: pool}
+
+	if len(settings.FixtureMode) > 0 {
+		fixtures, err := newFixtureTransport(settings.FixtureMode, settings.Fixtures, pool)
+		if err != nil {
+			return nil, err
+		}
+		pool.Client = &http.Client{Transport: fixtures}
+	}
+	return pool, nil
+}
+
+var (
+	pools      = map[string]*Pool{}
+	poolsMutex sync.Mutex
+)
+
+// PoolFor returns the (shared) pool of the chain's configured RPC endpoints
+func PoolFor(chain string) (*Pool, error) {
+	poolsMutex.Lock()
+	defer poolsMutex.Unlock()
+
+	if pool := pools[chain]; pool != nil {
+		return pool, nil
+	}
+
+	pool, err := NewPool(config.GetRpcProviders(chain), config.GetRpc(chain))
+	if err != nil {
+		return nil, err
+	}
+	pools[chain] = pool
+	return pool, nil
+}
+
+// UseFixtures sends the chain's calls (and any calls sent to the given urls) through a pool that
+// records them to, or answers them from, the fixtures file, whatever the chain's config says. Tests
+// use this to run offline. When replaying, no url is needed. When recording, the chain's configured
+// endpoints are used if no url is given.
+func UseFixtures(chain, mode, path string, urls ...string) error {
+	if len(urls) == 0 {
+		if mode == FixtureReplay {
+			urls = []string{"http://localhost:0"}
+		} else {
+			urls = config.GetRpcProviders(chain)
+		}
+	}
+
+	pool, err := NewPool(urls, configtypes.RpcSettings{FixtureMode: mode, Fixtures: path})
+	if err != nil {
+		return err
+	}
+
+	poolsMutex.Lock()
+	defer poolsMutex.Unlock()
+	pools[chain] = pool
+	return nil
+}
+
+// ReplayFixtures answers the chain's calls from the fixtures file, so that tests may run without a
+// node. If TB_RECORD_FIXTURES is set to the url of a node, the calls are instead sent to that node
+// and recorded to the file (which is how the file is made).
+func ReplayFixtures(chain, path string) error {
+	if url := os.Getenv("TB_RECORD_FIXTURES"); len(url) > 0 {
+		return UseFixtures(chain, FixtureRecord, path, url)
+	}
+	return UseFixtures(chain, FixtureReplay, path)
+}
+
+// clientFor returns the client with which to send calls to the url. If the url is one of a pool's
+// endpoints, that is the pool's client (so the pool's fixtures apply). Otherwise, if the default
+// chain records or replays fixtures, the calls are recorded to, or answered from, its fixtures file.
+func clientFor(rawUrl string) (*http.Client, error) {
+	poolsMutex.Lock()
+	for _, pool := range pools {
+		for _, ep := range pool.endpoints {
+			if strings.TrimSuffix(ep.url.String(), "/") == strings.TrimSuffix(rawUrl, "/") {
+				poolsMutex.Unlock()
+				return pool.Client, nil
+			}
+		}
+	}
+	poolsMutex.Unlock()
+
+	settings := config.GetRpc(config.GetSettings().DefaultChain)
+	if len(settings.FixtureMode) == 0 {
+		return &http.Client{}, nil
+	}
+	fixtures, err := newFixtureTransport(settings.FixtureMode, settings.Fixtures, http.DefaultTransport)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: fixtures}, nil
+}
+
+// Url returns the url of the pool's preferred endpoint
+func (p *Pool) Url() string {
+	return p.ranked()[0].url.String()
+}
+
+// Stats returns the statistics of each of the pool's endpoints in the order they are configured
+func (p *Pool) Stats() []EndpointStats {
+	now := time.Now()
+	ret := make([]EndpointStats, 0, len(p.endpoints))
+	for _, ep := range p.endpoints {
+		ep.mutex.Lock()
+		ret = append(ret, EndpointStats{
+			Url:       ep.url.String(),
+			Requests:  ep.requests,
+			Errors:    ep.errors,
+			Throttled: ep.throttled,
+			ErrorRate: ep.errorRate,
+			RateLimit: ep.limiter.currentRate(),
+			Latency:   ep.latency,
+			LastError: ep.lastError,
+			Healthy:   now.After(ep.downUntil),
+		})
+		ep.mutex.Unlock()
+	}
+	return ret
+}
+
+// RoundTrip sends the request to the best endpoint, failing over to the others in turn. If
+// every endpoint fails for a reason that may pass, the request is retried after a backoff.
+func (p *Pool) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+
+	methods := callMethods(body)
+	hedge := p.hedgeDelay > 0 && isReadOnly(methods)
+	nCalls := max(len(methods), 1)
+
+	for retry := 0; ; retry++ {
+		resp, err := p.tryEndpoints(req, body, hedge, nCalls)
+		if err == nil || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		var retryable *retryableError
+		isRetryable := errors.As(err, &retryable)
+		if retry >= p.retries {
+			if isRetryable && retryable.resp != nil {
+				// The caller reports the endpoint's own answer
+				return retryable.resp, nil
+			}
+			return nil, err
+		}
+
+		var retryAfter time.Duration
+		if isRetryable {
+			retryAfter = retryable.retryAfter
+		}
+		if err := sleep(req.Context(), backoff(retry, retryAfter)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// tryEndpoints sends the request to each endpoint in turn (best first) until one succeeds
+func (p *Pool) tryEndpoints(req *http.Request, body []byte, hedge bool, nCalls int) (*http.Response, error) {
+	ranked := p.ranked()
+
+	var lastErr error
+	for i := 0; i < len(ranked); i++ {
+		var resp *http.Response
+		var err error
+		if hedge && i+1 < len(ranked) {
+			var used int
+			resp, used, err = p.hedged(req, body, nCalls, ranked[i], ranked[i+1])
+			i += used
+		} else {
+			resp, err = p.send(req, body, nCalls, ranked[i])
+		}
+		if err == nil {
+			return resp, nil
+		}
+		if req.Context().Err() != nil {
+			return nil, err
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// hedged sends the request to first and, if it has not answered after the hedge delay, also to
+// second. It returns the first successful response and the number of extra endpoints it used.
+func (p *Pool) hedged(req *http.Request, body []byte, nCalls int, first, second *endpoint) (*http.Response, int, error) {
+	type result struct {
+		resp *http.Response
+		err  error
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	// The responses are read in full by send, so the loser may be canceled as soon as we return
+	defer cancel()
+
+	results := make(chan result, 2)
+	started := 0
+	start := func(ep *endpoint) {
+		started++
+		go func() {
+			resp, err := p.send(req.WithContext(ctx), body, nCalls, ep)
+			results <- result{resp, err}
+		}()
+	}
+
+	start(first)
+	timer := time.NewTimer(p.hedgeDelay)
+	defer timer.Stop()
+
+	pending := 1
+	var lastErr error
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if started == 1 {
+				start(second)
+				pending++
+			}
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				return res.resp, started - 1, nil
+			}
+			lastErr = res.err
+			if started == 1 {
+				// Don't wait for the hedge delay if the first endpoint has already failed
+				start(second)
+				pending++
+			}
+		}
+	}
+	return nil, started - 1, lastErr
+}
+
+// send sends the request to the endpoint (once its rate limit allows) and records the outcome.
+// Transport errors, 5xx responses, and responses saying the call should be retried are returned
+// as errors so that the caller may fail over. The response's body has been read in full.
+func (p *Pool) send(req *http.Request, body []byte, nCalls int, ep *endpoint) (*http.Response, error) {
+	if err := ep.limiter.wait(req.Context(), nCalls); err != nil {
+		return nil, err
+	}
+
+	out := req.Clone(req.Context())
+	target := *ep.url
+	out.URL = &target
+	out.Host = target.Host
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	out.ContentLength = int64(len(body))
+
+	start := time.Now()
+	resp, err := p.transport.RoundTrip(out)
+	var data []byte
+	if err == nil {
+		data, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	var failure *retryableError
+	if err != nil {
+		failure = &retryableError{cause: err}
+	} else if resp.StatusCode >= 500 {
+		failure = &retryableError{
+			retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+			cause:      fmt.Errorf("%s: %d", resp.Status, resp.StatusCode),
+		}
+	} else {
+		failure = classify(resp, data)
+	}
+
+	if failure != nil && req.Context().Err() != nil {
+		// A canceled (for example, hedged) call says nothing about the endpoint
+		return nil, req.Context().Err()
+	}
+	ep.record(time.Since(start), failure)
+	if failure != nil {
+		return nil, fmt.Errorf("%s: %w", ep.url.Host, failure)
+	}
+	return resp, nil
+}
+
+func (ep *endpoint) record(elapsed time.Duration, failure *retryableError) {
+	ep.mutex.Lock()
+	defer ep.mutex.Unlock()
+
+	ep.requests++
+	failed := 0.0
+	if failure != nil {
+		failed = 1.0
+		ep.errors++
+		ep.lastError = failure.Error()
+		ep.consecutive++
+		cooldown := time.Second << min(ep.consecutive-1, 5)
+		if failure.throttled {
+			ep.throttled++
+			ep.limiter.throttled()
+			if failure.retryAfter > 0 {
+				cooldown = failure.retryAfter
+			}
+		}
+		ep.downUntil = time.Now().Add(min(cooldown, maxCooldown))
+	} else {
+		ep.consecutive = 0
+		ep.limiter.succeeded()
+		if ep.latency == 0 {
+			ep.latency = elapsed
+		} else {
+			ep.latency = time.Duration(ewmaWeight*float64(elapsed) + (1-ewmaWeight)*float64(ep.latency))
+		}
+	}
+	if ep.requests == 1 {
+		ep.errorRate = failed
+	} else {
+		ep.errorRate = ewmaWeight*failed + (1-ewmaWeight)*ep.errorRate
+	}
+}
+
+// score orders the endpoints. Lower is better. Endpoints cooling down after failures come last.
+func (ep *endpoint) score(now time.Time) (bool, float64) {
+	ep.mutex.Lock()
+	defer ep.mutex.Unlock()
+	return now.Before(ep.downUntil), float64(ep.latency) * (1 + 10*ep.errorRate)
+}
+
+// ranked returns the endpoints, best first
+func (p *Pool) ranked() []*endpoint {
+	now := time.Now()
+	type ranking struct {
+		ep    *endpoint
+		down  bool
+		score float64
+	}
+	rankings := make([]ranking, 0, len(p.endpoints))
+	for _, ep := range p.endpoints {
+		down, score := ep.score(now)
+		rankings = append(rankings, ranking{ep, down, score})
+	}
+	sort.SliceStable(rankings, func(i, j int) bool {
+		if rankings[i].down != rankings[j].down {
+			return !rankings[i].down
+		}
+		return rankings[i].score < rankings[j].score
+	})
+
+	ret := make([]*endpoint, 0, len(rankings))
+	for _, r := range rankings {
+		ret = append(ret, r.ep)
+	}
+	return ret
+}
+
+// callMethods returns the methods of the calls in a (single or batched) request body, or nil if
+// the body cannot be parsed
+func callMethods(body []byte) []string {
+	type call struct {
+		Method string `json:"method"`
+	}
+	calls := []call{}
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &calls); err != nil {
+			return nil
+		}
+	} else {
+		var single call
+		if err := json.Unmarshal(trimmed, &single); err != nil {
+			return nil
+		}
+		calls = append(calls, single)
+	}
+
+	methods := make([]string, 0, len(calls))
+	for _, call := range calls {
+		methods = append(methods, call.Method)
+	}
+	return methods
+}
+
+// isReadOnly returns true if none of the calls change state, so sending them to more than one
+// endpoint is harmless
+func isReadOnly(methods []string) bool {
+	if len(methods) == 0 {
+		return false
+	}
+	for _, method := range methods {
+		if len(method) == 0 || !isReadOnlyMethod(method) {
+			return false
+		}
+	}
+	return true
+}
+
+func isReadOnlyMethod(method string) bool {
+	switch method {
+	case "eth_sendRawTransaction", "eth_sendTransaction", "eth_sign", "eth_signTransaction",
+		"eth_newFilter", "eth_newBlockFilter", "eth_newPendingTransactionFilter",
+		"eth_getFilterChanges", "eth_uninstallFilter", "eth_subscribe", "eth_unsubscribe":
+		// Filters live on a single node, so they may not be hedged either
+		return false
+	}
+	for _, prefix := range []string{"personal_", "admin_", "miner_", "debug_set", "txpool_"} {
+		if strings.HasPrefix(method, prefix) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,293 @@
+package query
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/configtypes"
+)
+
+func TestMain(m *testing.M) {
+	// Keep the tests of failed calls quick
+	retryBase = time.Millisecond
+	retryCap = 10 * time.Millisecond
+	m.Run()
+}
+
+// newEndpoint returns a test RPC endpoint that answers every call with result after delay, or
+// with the given status if it is not 200
+func newEndpoint(t *testing.T, status int, delay time.Duration, result string) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = io.ReadAll(r.Body)
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"` + result + `"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func poolQuery(t *testing.T, pool *Pool, method string) (string, error) {
+	t.Helper()
+	res, err := queryWithClient[string](pool.Client, pool.Url(), map[string]string{}, method, Params{})
+	if err != nil {
+		return "", err
+	}
+	return *res, nil
+}
+
+func TestPoolFailover(t *testing.T) {
+	broken, brokenCalls := newEndpoint(t, http.StatusBadGateway, 0, "")
+	good, _ := newEndpoint(t, http.StatusOK, 0, "0x1")
+	closed, _ := newEndpoint(t, http.StatusOK, 0, "")
+	closed.Close()
+
+	pool, err := NewPool([]string{closed.URL, broken.URL, good.URL}, configtypes.RpcSettings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res, err := poolQuery(t, pool, "eth_blockNumber"); err != nil || res != "0x1" {
+		t.Fatal("expected the call to fail over to the good endpoint, got", res, err)
+	}
+
+	stats := pool.Stats()
+	if stats[0].Errors != 1 || stats[0].Healthy || stats[1].Errors != 1 || stats[1].Healthy {
+		t.Error("expected the failed endpoints to be cooling down", stats)
+	}
+	if stats[2].Requests != 1 || stats[2].Errors != 0 || !stats[2].Healthy {
+		t.Error("expected the good endpoint to have answered", stats[2])
+	}
+
+	// The failed endpoints are passed over while they cool down
+	if _, err := poolQuery(t, pool, "eth_blockNumber"); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(brokenCalls) != 1 {
+		t.Error("expected the broken endpoint to be passed over")
+	}
+	if pool.Url() != good.URL {
+		t.Error("expected the good endpoint to be preferred, got", pool.Url())
+	}
+}
+
+func TestPoolAllFail(t *testing.T) {
+	broken, _ := newEndpoint(t, http.StatusServiceUnavailable, 0, "")
+	pool, _ := NewPool([]string{broken.URL}, configtypes.RpcSettings{})
+
+	_, err := poolQuery(t, pool, "eth_blockNumber")
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Error("expected the endpoint's error, got", err)
+	}
+}
+
+func TestPoolClientError(t *testing.T) {
+	// A 4xx response is the caller's problem, not the endpoint's, so it is not failed over
+	unauthorized, _ := newEndpoint(t, http.StatusUnauthorized, 0, "")
+	good, goodCalls := newEndpoint(t, http.StatusOK, 0, "0x1")
+	pool, _ := NewPool([]string{unauthorized.URL, good.URL}, configtypes.RpcSettings{})
+
+	if _, err := poolQuery(t, pool, "eth_blockNumber"); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Error("expected the 401 to be returned, got", err)
+	}
+	if atomic.LoadInt32(goodCalls) != 0 {
+		t.Error("expected no failover")
+	}
+}
+
+func TestPoolHedging(t *testing.T) {
+	slow, _ := newEndpoint(t, http.StatusOK, 2*time.Second, "slow")
+	fast, fastCalls := newEndpoint(t, http.StatusOK, 0, "fast")
+	pool, _ := NewPool([]string{slow.URL, fast.URL}, configtypes.RpcSettings{HedgeDelay: 50})
+
+	start := time.Now()
+	if res, err := poolQuery(t, pool, "eth_getBalance"); err != nil || res != "fast" {
+		t.Fatal("expected the hedged call to answer first, got", res, err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("expected the slow endpoint not to be waited for")
+	}
+
+	// Calls that change state are never sent twice
+	before := atomic.LoadInt32(fastCalls)
+	pool, _ = NewPool([]string{slow.URL, fast.URL}, configtypes.RpcSettings{HedgeDelay: 50})
+	if res, err := poolQuery(t, pool, "eth_sendRawTransaction"); err != nil || res != "slow" {
+		t.Fatal("expected the call to wait for the first endpoint, got", res, err)
+	}
+	if atomic.LoadInt32(fastCalls) != before {
+		t.Error("expected a state changing call not to be hedged")
+	}
+}
+
+func TestIsReadOnly(t *testing.T) {
+	tests := map[string]bool{
+		`{"method":"eth_getBlockByNumber"}`:                           true,
+		`[{"method":"eth_call"},{"method":"eth_getBalance"}]`:         true,
+		`[{"method":"eth_call"},{"method":"eth_sendRawTransaction"}]`: false,
+		`{"method":"personal_unlockAccount"}`:                         false,
+		`{"method":"eth_newFilter"}`:                                  false,
+		`not json`:                                                    false,
+	}
+	for body, expected := range tests {
+		if got := isReadOnly(callMethods([]byte(body))); got != expected {
+			t.Error("isReadOnly", body, "expected", expected, "got", got)
+		}
+	}
+}
+
+// newScriptedEndpoint returns a test RPC endpoint that answers its calls with the given
+// responses in turn (repeating the last one)
+func newScriptedEndpoint(t *testing.T, responses ...func(w http.ResponseWriter)) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&calls, 1))
+		_, _ = io.ReadAll(r.Body)
+		responses[min(n, len(responses))-1](w)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func tooManyRequests(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", "0")
+	w.WriteHeader(http.StatusTooManyRequests)
+}
+
+func rpcError(code int) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":` + strconv.Itoa(code) + `,"message":"no"}}`))
+	}
+}
+
+func rpcResult(w http.ResponseWriter) {
+	_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+}
+
+func TestPoolRetries(t *testing.T) {
+	throttled, calls := newScriptedEndpoint(t, tooManyRequests, rpcError(codeLimitExceeded), rpcResult)
+	pool, _ := NewPool([]string{throttled.URL}, configtypes.RpcSettings{RequestsPerSecond: 1000})
+
+	if res, err := poolQuery(t, pool, "eth_blockNumber"); err != nil || res != "0x1" {
+		t.Fatal("expected the call to be retried until it succeeds, got", res, err)
+	}
+	if atomic.LoadInt32(calls) != 3 {
+		t.Error("expected three calls, got", atomic.LoadInt32(calls))
+	}
+	stats := pool.Stats()[0]
+	if stats.Throttled != 2 || stats.RateLimit >= 1000 {
+		t.Error("expected the endpoint's rate to have been lowered", stats)
+	}
+}
+
+func TestPoolRetriesExhausted(t *testing.T) {
+	internal, calls := newScriptedEndpoint(t, rpcError(codeInternalError))
+	pool, _ := NewPool([]string{internal.URL}, configtypes.RpcSettings{MaxRetries: 2})
+
+	_, err := poolQuery(t, pool, "eth_blockNumber")
+	if err == nil || !strings.Contains(err.Error(), "-32603") {
+		t.Error("expected the endpoint's error, got", err)
+	}
+	if atomic.LoadInt32(calls) != 3 {
+		t.Error("expected the call and two retries, got", atomic.LoadInt32(calls))
+	}
+}
+
+func TestPoolFatalError(t *testing.T) {
+	// A missing method will still be missing later, so it is neither retried nor failed over
+	missing, missingCalls := newScriptedEndpoint(t, rpcError(-32601))
+	good, goodCalls := newScriptedEndpoint(t, rpcResult)
+	pool, _ := NewPool([]string{missing.URL, good.URL}, configtypes.RpcSettings{})
+
+	if _, err := poolQuery(t, pool, "eth_blockNumber"); err == nil || !strings.Contains(err.Error(), "-32601") {
+		t.Error("expected the endpoint's error, got", err)
+	}
+	if atomic.LoadInt32(missingCalls) != 1 || atomic.LoadInt32(goodCalls) != 0 {
+		t.Error("expected a single call")
+	}
+}
+
+func TestPoolRateLimit(t *testing.T) {
+	good, _ := newScriptedEndpoint(t, rpcResult)
+	pool, _ := NewPool([]string{good.URL}, configtypes.RpcSettings{RequestsPerSecond: 20})
+
+	start := time.Now()
+	for i := 0; i < 40; i++ {
+		if _, err := poolQuery(t, pool, "eth_blockNumber"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The first second's calls are in the bucket, the rest wait for it to refill
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Error("expected the calls to be paced, took", elapsed)
+	}
+}
+
+func TestPoolBatchSize(t *testing.T) {
+	var mutex sync.Mutex
+	var sizes []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var calls []rpcPayload
+		_ = json.NewDecoder(r.Body).Decode(&calls)
+		mutex.Lock()
+		sizes = append(sizes, len(calls))
+		mutex.Unlock()
+		results := make([]map[string]any, 0, len(calls))
+		for _, call := range calls {
+			results = append(results, map[string]any{"jsonrpc": "2.0", "id": call.ID, "result": call.Method})
+		}
+		_ = json.NewEncoder(w).Encode(results)
+	}))
+	defer server.Close()
+	pool, _ := NewPool([]string{server.URL}, configtypes.RpcSettings{MaxBatchSize: 2})
+
+	batch := []BatchPayload{}
+	for _, method := range []string{"a", "b", "c", "d", "e"} {
+		batch = append(batch, BatchPayload{Key: method, Payload: &Payload{Method: method, Params: Params{}}})
+	}
+	check := func(results map[string]*string, err error, expected []int) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		// The coalesced parts are sent at the same time, so they may arrive in any order
+		sort.Ints(sizes)
+		if !reflect.DeepEqual(sizes, expected) {
+			t.Error("expected the batch to be sent in parts", expected, "got", sizes)
+		}
+		for _, method := range []string{"a", "b", "c", "d", "e"} {
+			if results[method] == nil || *results[method] != method {
+				t.Error("unexpected result for", method, results[method])
+			}
+		}
+		sizes = nil
+	}
+
+	// Without headers, the calls are coalesced (and may be sent in parallel)
+	results, err := sendBatch[string](pool, map[string]string{}, batch)
+	check(results, err, []int{1, 2, 2})
+
+	// With headers, the batch is sent as given, one part after the other
+	results, err = sendBatch[string](pool, map[string]string{"X-Test": "true"}, batch)
+	check(results, err, []int{1, 2, 2})
+}
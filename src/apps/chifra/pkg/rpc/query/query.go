@@ -10,7 +10,6 @@ import (
 	"strings"
 	"sync/atomic"
 
-	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/debug"
 )
 
@@ -50,10 +49,14 @@ type BatchPayload struct {
 	*Payload
 }
 
-// Query returns a single result for given method and params.
+// Query returns a single result for given method and params. The call is sent to the best of
+// the chain's RPC endpoints (see Pool).
 func Query[T any](chain string, method string, params Params) (*T, error) {
-	url := config.GetChain(chain).RpcProvider
-	return QueryUrl[T](url, method, params)
+	pool, err := PoolFor(chain)
+	if err != nil {
+		return nil, err
+	}
+	return queryWithClient[T](pool.Client, pool.Url(), map[string]string{}, method, params)
 }
 
 // QueryUrl is just like Query, but it does not resolve chain to RPC provider URL
@@ -61,8 +64,17 @@ func QueryUrl[T any](url string, method string, params Params) (*T, error) {
 	return QueryWithHeaders[T](url, map[string]string{}, method, params)
 }
 
-// QueryWithHeaders returns a single result for a given method and params.
+// QueryWithHeaders returns a single result for a given method and params. Like every other call,
+// it is recorded or replayed if fixtures are in use (see clientFor).
 func QueryWithHeaders[T any](url string, headers map[string]string, method string, params Params) (*T, error) {
+	client, err := clientFor(url)
+	if err != nil {
+		return nil, err
+	}
+	return queryWithClient[T](client, url, headers, method, params)
+}
+
+func queryWithClient[T any](client *http.Client, url string, headers map[string]string, method string, params Params) (*T, error) {
 	payloadToSend := rpcPayload{
 		Jsonrpc: "2.0",
 		Method:  method,
@@ -84,10 +96,10 @@ func QueryWithHeaders[T any](url string, headers map[string]string, method strin
 				request.Header.Set(key, value)
 			}
 
-			client := &http.Client{}
 			if response, err := client.Do(request); err != nil {
 				return nil, err
 			} else if response.StatusCode != 200 {
+				response.Body.Close()
 				return nil, fmt.Errorf("%s: %d", response.Status, response.StatusCode)
 			} else {
 				defer response.Body.Close()
@@ -111,61 +123,83 @@ func QueryWithHeaders[T any](url string, headers map[string]string, method strin
 }
 
 // QueryBatch batches requests to the node. Returned values are stored in map, with the same keys as defined
-// in `batchPayload` (this way we don't have to operate on array indices)
+// in `batchPayload` (this way we don't have to operate on array indices). The calls may be sent together
+// with other callers' calls (see batcher). If the node answers some of the calls with an error, the others'
+// results are returned with a *BatchError.
 func QueryBatch[T any](chain string, batchPayload []BatchPayload) (map[string]*T, error) {
 	return QueryBatchWithHeaders[T](chain, map[string]string{}, batchPayload)
 }
 
 func QueryBatchWithHeaders[T any](chain string, headers map[string]string, batchPayload []BatchPayload) (map[string]*T, error) {
-	keys := make([]string, 0, len(batchPayload))
-	payloads := make([]Payload, 0, len(batchPayload))
-	for _, bpl := range batchPayload {
-		keys = append(keys, bpl.Key)
-		payloads = append(payloads, *bpl.Payload)
+	pool, err := PoolFor(chain)
+	if err != nil {
+		return nil, err
 	}
+	return sendBatch[T](pool, headers, batchPayload)
+}
+
+// Batched is just like Query, but the call may be sent to the node in a batch with the other
+// calls made at about the same time
+func Batched[T any](chain string, method string, params Params) (*T, error) {
+	results, err := QueryBatch[T](chain, []BatchPayload{{
+		Key:     method,
+		Payload: &Payload{Method: method, Params: params},
+	}})
+	if batchErr, ok := err.(*BatchError); ok {
+		return nil, batchErr.Errors[method]
+	} else if err != nil {
+		return nil, err
+	}
+	return results[method], nil
+}
 
-	url := config.GetChain(chain).RpcProvider
-	payloadToSend := make([]rpcPayload, 0, len(payloads))
+// sendBatch sends the batch to the pool and collects the results by key. Calls without headers are
+// coalesced with other callers' calls. Others are sent as given, in parts if the batch is larger
+// than the chain's ceiling.
+func sendBatch[T any](pool *Pool, headers map[string]string, batchPayload []BatchPayload) (map[string]*T, error) {
+	calls := make([]*call, 0, len(batchPayload))
+	for _, bpl := range batchPayload {
+		calls = append(calls, newCall(bpl.Method, bpl.Params))
+	}
 
-	for _, payload := range payloads {
-		theLoad := rpcPayload{
-			Jsonrpc: "2.0",
-			Method:  payload.Method,
-			Params:  payload.Params,
-			ID:      int(atomic.AddUint32(&rpcCounter, 1)),
+	if len(headers) == 0 {
+		pool.batcher.do(calls)
+	} else {
+		size := pool.maxBatch
+		if size == 0 {
+			size = len(calls)
+		}
+		for start := 0; start < len(calls); start += size {
+			pool.sendCalls(headers, calls[start:min(start+size, len(calls))])
 		}
-		debug.DebugCurl(rpcDebug{
-			url:     url,
-			payload: theLoad,
-			headers: headers,
-		})
-		payloadToSend = append(payloadToSend, theLoad)
 	}
 
-	plBytes, err := json.Marshal(payloadToSend)
-	if err != nil {
-		return nil, err
-	}
+	results := make(map[string]*T, len(batchPayload))
+	batchErr := &BatchError{Errors: map[string]error{}}
+	for index, bpl := range batchPayload {
+		c := calls[index]
+		if c.err != nil {
+			if _, ok := c.err.(*eip1474Error); !ok {
+				// The batch was not answered, so none of its calls were
+				return nil, c.err
+			}
+			batchErr.Errors[bpl.Key] = c.err
+			continue
+		}
 
-	var result []rpcResponse[T]
-	body := bytes.NewReader(plBytes)
-	if response, err := http.Post(url, "application/json", body); err != nil {
-		return nil, err
-	} else {
-		defer response.Body.Close()
-		if theBytes, err := io.ReadAll(response.Body); err != nil {
-			return nil, err
-		} else {
-			if err = json.Unmarshal(theBytes, &result); err != nil {
+		var result T
+		if len(c.result) > 0 {
+			if err := json.Unmarshal(c.result, &result); err != nil {
 				return nil, err
 			}
-			results := make(map[string]*T, len(batchPayload))
-			for index, key := range keys {
-				results[key] = &result[index].Result
-			}
-			return results, err
 		}
+		results[bpl.Key] = &result
+	}
+
+	if len(batchErr.Errors) > 0 {
+		return results, batchErr
 	}
+	return results, nil
 }
 
 func init() {
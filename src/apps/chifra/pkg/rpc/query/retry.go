@@ -0,0 +1,142 @@
+package query
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	// retryBase is the first delay before retrying a call. It doubles with each retry.
+	retryBase = 250 * time.Millisecond
+	// retryCap is the longest delay between retries (unless an endpoint asks for longer)
+	retryCap = 30 * time.Second
+)
+
+// defaultRetries is the number of times a call is retried if the chain's config does not say
+const defaultRetries = 3
+
+// The EIP-1474 error codes that may succeed if the call is sent again later. The others (parse
+// errors, invalid requests or params, missing methods or resources, rejected transactions, and
+// unsupported versions) will fail again.
+const (
+	codeInternalError       = -32603
+	codeResourceUnavailable = -32002
+	codeLimitExceeded       = -32005
+)
+
+// isRetryableCode returns true if a call that failed with the EIP-1474 error code may succeed
+// if it is sent again later
+func isRetryableCode(code int) bool {
+	switch code {
+	case codeInternalError, codeResourceUnavailable, codeLimitExceeded:
+		return true
+	}
+	return false
+}
+
+// retryableError is a failure that may succeed if the call is sent again later. If the
+// endpoint answered, resp is its (already read) response, returned to the caller if the call
+// is never retried successfully.
+type retryableError struct {
+	resp       *http.Response
+	retryAfter time.Duration
+	throttled  bool
+	cause      error
+}
+
+func (e *retryableError) Error() string {
+	return e.cause.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.cause
+}
+
+// classify returns an error if the endpoint's response says the call should be retried
+// (because of a 429 response or a retryable EIP-1474 error in the response's body). The
+// response's body has been read into body.
+func classify(resp *http.Response, body []byte) *retryableError {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return &retryableError{
+			resp:       resp,
+			retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+			throttled:  true,
+			cause:      fmt.Errorf("%s: %d", resp.Status, resp.StatusCode),
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte(`"error"`)) {
+		return nil
+	}
+
+	type withError struct {
+		Error *eip1474Error `json:"error"`
+	}
+	responses := []withError{}
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if json.Unmarshal(trimmed, &responses) != nil {
+			return nil
+		}
+	} else {
+		var single withError
+		if json.Unmarshal(trimmed, &single) != nil {
+			return nil
+		}
+		responses = append(responses, single)
+	}
+
+	for _, r := range responses {
+		if r.Error != nil && isRetryableCode(r.Error.Code) {
+			return &retryableError{
+				resp:       resp,
+				retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+				throttled:  r.Error.Code == codeLimitExceeded,
+				cause:      fmt.Errorf("%d: %s", r.Error.Code, r.Error.Message),
+			}
+		}
+	}
+	return nil
+}
+
+// parseRetryAfter parses a Retry-After header, which holds either seconds or an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if len(value) == 0 {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		if d := time.Until(when); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// backoff returns the delay before the given retry (counting from zero): a random delay up to
+// an exponentially growing ceiling, but never less than the endpoint asked for
+func backoff(retry int, retryAfter time.Duration) time.Duration {
+	ceiling := min(retryBase<<min(retry, 16), retryCap)
+	delay := time.Duration(rand.Int63n(int64(ceiling)) + 1)
+	return max(delay, retryAfter)
+}
+
+// sleep waits for the delay or until the context is done
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
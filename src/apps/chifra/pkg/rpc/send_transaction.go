@@ -0,0 +1,92 @@
+// Copyright 2021 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc/query"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// GetPendingNonce returns the nonce the address's next transaction must carry, counting
+// transactions still in the node's mempool
+func (conn *Connection) GetPendingNonce(address base.Address) (uint64, error) {
+	method := "eth_getTransactionCount"
+	params := query.Params{address, "pending"}
+
+	if nonce, err := query.Query[string](conn.Chain, method, params); err != nil {
+		return 0, err
+	} else {
+		return base.MustParseUint64(*nonce), nil
+	}
+}
+
+// GetGasTip returns the priority fee (per unit of gas) the node suggests for a new transaction
+func (conn *Connection) GetGasTip() (*base.Wei, error) {
+	method := "eth_maxPriorityFeePerGas"
+	params := query.Params{}
+
+	if tip, err := query.Query[string](conn.Chain, method, params); err != nil {
+		return nil, err
+	} else {
+		return base.HexToWei(*tip), nil
+	}
+}
+
+// EstimateGas returns the amount of gas the node expects a call with the given input to use
+func (conn *Connection) EstimateGas(from, to base.Address, input []byte) (base.Gas, error) {
+	method := "eth_estimateGas"
+	params := query.Params{
+		map[string]any{
+			"from": from.Hex(),
+			"to":   to.Hex(),
+			"data": "0x" + base.Bytes2Hex(input),
+		},
+	}
+
+	if gas, err := query.Query[string](conn.Chain, method, params); err != nil {
+		return 0, err
+	} else {
+		return base.MustParseGas(*gas), nil
+	}
+}
+
+// SendRawTransaction submits an already signed transaction to the node and returns its hash
+func (conn *Connection) SendRawTransaction(raw []byte) (base.Hash, error) {
+	method := "eth_sendRawTransaction"
+	params := query.Params{"0x" + base.Bytes2Hex(raw)}
+
+	if hash, err := query.Query[string](conn.Chain, method, params); err != nil {
+		return base.Hash{}, err
+	} else {
+		return base.HexToHash(*hash), nil
+	}
+}
+
+// WaitForReceipt polls the node for the receipt of the given transaction until it is mined,
+// the node reports an error, or the context is done (which is how callers set a timeout)
+func (conn *Connection) WaitForReceipt(ctx context.Context, hash base.Hash) (*types.Receipt, error) {
+	method := "eth_getTransactionReceipt"
+	params := query.Params{hash.Hex()}
+
+	for {
+		if receipt, err := query.Query[types.Receipt](conn.Chain, method, params); err != nil {
+			return nil, err
+		} else if receipt != nil && receipt.BlockNumber != 0 {
+			receipt.IsError = receipt.Status == 0
+			return receipt, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("transaction %s was not mined: %w", hash.Hex(), ctx.Err())
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+)
+
+func TestWaitForReceiptReportsErrors(t *testing.T) {
+	conn := fixturesConnection(t)
+
+	// The fixtures hold no receipt for this transaction, so the call fails rather than reporting
+	// a pending transaction. The error is returned without waiting for the context.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	start := time.Now()
+	_, err := conn.WaitForReceipt(ctx, base.HexToHash("0xdead"))
+	if err == nil || errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("expected the node's error, got", err)
+	}
+	if time.Since(start) > 30*time.Second {
+		t.Error("expected the error to be returned without waiting")
+	}
+}
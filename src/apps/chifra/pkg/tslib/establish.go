@@ -34,8 +34,19 @@ func EstablishTimestamps(chain string, publisher base.Address) error {
 		return fmt.Errorf("no record found in the Unchained Index for database %s from publisher %s", database, publisher.Hex())
 	}
 
+	// Try each gateway in turn until one of them serves the file or the user cancels
 	tmpTsPath := tsPath + ".tmp"
-	if err, canceled := downloadTimestamps(chain, database, tmpTsPath, cid); err != nil || canceled {
+	for _, gatewayUrl := range config.GetIpfsGatewayUrls(chain) {
+		var canceled bool
+		if err, canceled = downloadTimestamps(chain, database, gatewayUrl, tmpTsPath, cid); canceled {
+			os.Remove(tmpTsPath)
+			return err
+		} else if err == nil {
+			break
+		}
+		logger.Warn("Could not download timestamps from", gatewayUrl, "-", err)
+	}
+	if err != nil {
 		os.Remove(tmpTsPath)
 		return err
 	}
@@ -47,9 +58,7 @@ func EstablishTimestamps(chain string, publisher base.Address) error {
 }
 
 // downloadTimestamps downloads a CID to a binary file
-func downloadTimestamps(chain, database, outputFn, cid string) (error, bool) {
-	gatewayUrl := config.GetChain(chain).IpfsGateway
-
+func downloadTimestamps(chain, database, gatewayUrl, outputFn, cid string) (error, bool) {
 	url, err := url.Parse(gatewayUrl)
 	if err != nil {
 		return err, false
@@ -0,0 +1,125 @@
+package tslib
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+)
+
+// HashRecord holds a block's hash and its parent's hash. The hash file sits beside the timestamp
+// file and, like it, holds one fixed-width record per block, so block n's record is at n times the
+// record's size. Installs that predate the hash file have no hashes for the blocks scraped before
+// it was created. Those records read as zero (see IsKnown) until chifra when --timestamps --update
+// fills them in.
+type HashRecord struct {
+	Bn         uint32    `json:"bn"`
+	Hash       base.Hash `json:"hash"`
+	ParentHash base.Hash `json:"parentHash"`
+}
+
+var hashRecordSize = int64(binary.Size(HashRecord{}))
+
+// IsKnown returns true if the record was written (as opposed to a gap in the file)
+func (r *HashRecord) IsKnown() bool {
+	return !r.Hash.IsZero()
+}
+
+// NHashes returns the number of records in the hash file. This may be less than the number of
+// timestamps, but never more.
+func NHashes(chain string) (base.Blknum, error) {
+	fileStat, err := os.Stat(config.PathToHashes(chain))
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return base.Blknum(fileStat.Size() / hashRecordSize), nil
+}
+
+// FromBnToHash returns the hashes of the given block from the hash file. The record is not
+// known if the block was scraped before the hash file existed.
+func FromBnToHash(chain string, bn base.Blknum) (*HashRecord, error) {
+	records, err := ReadHashes(chain, bn, bn)
+	if err != nil {
+		return &HashRecord{}, err
+	}
+	return &records[0], nil
+}
+
+// ReadHashes returns the hash records of blocks first to last (inclusive)
+func ReadHashes(chain string, first, last base.Blknum) ([]HashRecord, error) {
+	return readHashes(config.PathToHashes(chain), first, last)
+}
+
+// WriteHashes writes each record at its block's position in the hash file. Writing past the end
+// of the file leaves a gap of unknown records.
+func WriteHashes(chain string, records []HashRecord) error {
+	return writeHashes(config.PathToHashes(chain), records)
+}
+
+func readHashes(path string, first, last base.Blknum) ([]HashRecord, error) {
+	if last < first {
+		return []HashRecord{}, nil
+	}
+
+	fp, err := os.OpenFile(path, os.O_RDONLY, 0)
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = errors.New("invalid block number " + fmt.Sprintf("%d of %d", last, 0))
+		}
+		return nil, err
+	}
+	defer fp.Close()
+
+	records := make([]HashRecord, last-first+1)
+	reader := io.NewSectionReader(fp, int64(first)*hashRecordSize, int64(len(records))*hashRecordSize)
+	if err = binary.Read(reader, binary.LittleEndian, records); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			stat, _ := fp.Stat()
+			err = errors.New("invalid block number " + fmt.Sprintf("%d of %d", last, stat.Size()/hashRecordSize))
+		}
+		return nil, err
+	}
+	return records, nil
+}
+
+func writeHashes(path string, records []HashRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	fp, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+
+	for i := range records {
+		if _, err = fp.Seek(int64(records[i].Bn)*hashRecordSize, io.SeekStart); err != nil {
+			return err
+		}
+		if err = binary.Write(fp, binary.LittleEndian, &records[i]); err != nil {
+			return err
+		}
+	}
+	return fp.Sync()
+}
+
+// truncateHashes removes the records of blocks maxBn and later
+func truncateHashes(path string, maxBn base.Blknum) error {
+	fileStat, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if size := int64(maxBn) * hashRecordSize; size < fileStat.Size() {
+		return os.Truncate(path, size)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package tslib
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
+)
+
+func TestHashFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hashes.bin")
+	record := func(bn uint32) HashRecord {
+		return HashRecord{
+			Bn:         bn,
+			Hash:       base.HexToHash(fmt.Sprintf("0x%x", bn+1)),
+			ParentHash: base.HexToHash(fmt.Sprintf("0x%x", bn)),
+		}
+	}
+
+	// Blocks 0 to 2 are missing, as they are for an install that predates the hash file
+	if err := writeHashes(path, []HashRecord{record(3), record(4), record(5)}); err != nil {
+		t.Fatal(err)
+	}
+	if size := file.FileSize(path); size != 6*hashRecordSize {
+		t.Fatalf("file size %d, expected %d", size, 6*hashRecordSize)
+	}
+	records, err := readHashes(path, 2, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records[0].IsKnown() || !reflect.DeepEqual(records[1:], []HashRecord{record(3), record(4)}) {
+		t.Errorf("read %v", records)
+	}
+	if _, err := readHashes(path, 4, 6); err == nil {
+		t.Error("expected an error reading past the end of the file")
+	}
+
+	// Filling in the gap (chifra when --timestamps --update) leaves the other records alone
+	if err := writeHashes(path, []HashRecord{record(0), record(1), record(2)}); err != nil {
+		t.Fatal(err)
+	}
+	if records, err = readHashes(path, 0, 5); err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range records {
+		if r != record(uint32(i)) {
+			t.Errorf("record %d: %v", i, r)
+		}
+	}
+
+	if err := truncateHashes(path, 4); err != nil {
+		t.Fatal(err)
+	}
+	if size := file.FileSize(path); size != 4*hashRecordSize {
+		t.Errorf("truncated size %d, expected %d", size, 4*hashRecordSize)
+	}
+	if err := truncateHashes(path, 10); err != nil || file.FileSize(path) != 4*hashRecordSize {
+		t.Errorf("truncating past the end should do nothing: %v", err)
+	}
+}
@@ -15,7 +15,7 @@ import (
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
 )
 
-// Repair repairs a single timestamp
+// Repair repairs a single timestamp and the block's hashes
 func Repair(chain string, bn base.Blknum) error {
 	cnt, err := NTimestamps(chain)
 	if err != nil {
@@ -54,6 +54,11 @@ func Repair(chain string, bn base.Blknum) error {
 			}
 			_ = fp.Sync() // probably redundant
 
+			hashes := HashRecord{Bn: uint32(block.BlockNumber), Hash: block.Hash, ParentHash: block.ParentHash}
+			if err = WriteHashes(chain, []HashRecord{hashes}); err != nil {
+				return err
+			}
+
 			backup.Clear()
 			return nil
 		} else {
@@ -13,7 +13,12 @@ import (
 
 // var writeMutex sync.Mutex
 
+// Truncate removes the timestamps and hashes of blocks maxBn and later
 func Truncate(chain string, maxBn base.Blknum) error {
+	if err := truncateHashes(config.PathToHashes(chain), maxBn); err != nil {
+		return err
+	}
+
 	cnt, err := NTimestamps(chain)
 	if err != nil {
 		return err
@@ -113,6 +113,10 @@ func (s *Appearance) Model(chain, format string, verbose bool, extraOpts map[str
 			model["date"] = s.Date()
 		}
 	} else {
+		if extraOpts["reasons"] == true && !verbose {
+			model["reason"] = s.Reason
+			order = append(order, "reason")
+		}
 		if verbose {
 			if s.TraceIndex > 0 {
 				model["traceIndex"] = s.TraceIndex
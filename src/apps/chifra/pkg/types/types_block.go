@@ -25,8 +25,10 @@ import (
 
 type Block struct {
 	BaseFeePerGas base.Gas       `json:"baseFeePerGas"`
+	BlobGasUsed   base.Gas       `json:"blobGasUsed,omitempty"`
 	BlockNumber   base.Blknum    `json:"blockNumber"`
 	Difficulty    base.Value     `json:"difficulty"`
+	ExcessBlobGas base.Gas       `json:"excessBlobGas,omitempty"`
 	GasLimit      base.Gas       `json:"gasLimit"`
 	GasUsed       base.Gas       `json:"gasUsed"`
 	Hash          base.Hash      `json:"hash"`
@@ -97,6 +99,10 @@ func (s *Block) Model(chain, format string, verbose bool, extraOpts map[string]a
 			model["uncles"] = s.Uncles
 		}
 		order = append(order, "uncles")
+		if s.BlobGasUsed > 0 || s.ExcessBlobGas > 0 {
+			model["blobGasUsed"] = s.BlobGasUsed
+			model["excessBlobGas"] = s.ExcessBlobGas
+		}
 		if len(s.Withdrawals) > 0 {
 			withs := make([]map[string]any, 0, len(s.Withdrawals))
 			for _, w := range s.Withdrawals {
@@ -112,6 +118,11 @@ func (s *Block) Model(chain, format string, verbose bool, extraOpts map[string]a
 		order = append(order, "transactionsCnt")
 		model["withdrawalsCnt"] = len(s.Withdrawals)
 		order = append(order, "withdrawalsCnt")
+		if s.BlockNumber >= base.KnownBlock(chain, base.Cancun) {
+			model["blobGasUsed"] = s.BlobGasUsed
+			model["excessBlobGas"] = s.ExcessBlobGas
+			order = append(order, []string{"blobGasUsed", "excessBlobGas"}...)
+		}
 	}
 
 	if name, loaded, found := nameAddress(extraOpts, s.Miner); found {
@@ -151,6 +162,11 @@ func (s *Block) MarshalCache(writer io.Writer) (err error) {
 		return err
 	}
 
+	// BlobGasUsed
+	if err = cache.WriteValue(writer, s.BlobGasUsed); err != nil {
+		return err
+	}
+
 	// BlockNumber
 	if err = cache.WriteValue(writer, s.BlockNumber); err != nil {
 		return err
@@ -161,6 +177,11 @@ func (s *Block) MarshalCache(writer io.Writer) (err error) {
 		return err
 	}
 
+	// ExcessBlobGas
+	if err = cache.WriteValue(writer, s.ExcessBlobGas); err != nil {
+		return err
+	}
+
 	// GasLimit
 	if err = cache.WriteValue(writer, s.GasLimit); err != nil {
 		return err
@@ -237,6 +258,15 @@ func (s *Block) UnmarshalCache(vers uint64, reader io.Reader) (err error) {
 		}
 	}
 
+	// BlobGasUsed
+	vBlobGasUsed := version.NewVersion("3.5.1")
+	if vers > vBlobGasUsed.Uint64() {
+		// BlobGasUsed
+		if err = cache.ReadValue(reader, &s.BlobGasUsed, vers); err != nil {
+			return err
+		}
+	}
+
 	// BlockNumber
 	if err = cache.ReadValue(reader, &s.BlockNumber, vers); err != nil {
 		return err
@@ -247,6 +277,15 @@ func (s *Block) UnmarshalCache(vers uint64, reader io.Reader) (err error) {
 		return err
 	}
 
+	// ExcessBlobGas
+	vExcessBlobGas := version.NewVersion("3.5.1")
+	if vers > vExcessBlobGas.Uint64() {
+		// ExcessBlobGas
+		if err = cache.ReadValue(reader, &s.ExcessBlobGas, vers); err != nil {
+			return err
+		}
+	}
+
 	// GasLimit
 	if err = cache.ReadValue(reader, &s.GasLimit, vers); err != nil {
 		return err
@@ -0,0 +1,129 @@
+// Copyright 2016, 2024 The TrueBlocks Authors. All rights reserved.
+// Use of this source code is governed by a license that can
+// be found in the LICENSE file.
+/*
+ * Parts of this file were auto generated. Edit only those parts of
+ * the code inside of 'EXISTING_CODE' tags.
+ */
+
+package types
+
+// EXISTING_CODE
+import (
+	"encoding/json"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+)
+
+// EXISTING_CODE
+
+type Gain struct {
+	AccountedFor      base.Address   `json:"accountedFor"`
+	AcquiredBlock     base.Blknum    `json:"acquiredBlock,omitempty"`
+	AcquiredHash      base.Hash      `json:"acquiredHash,omitempty"`
+	AcquiredTimestamp base.Timestamp `json:"acquiredTimestamp,omitempty"`
+	AssetAddr         base.Address   `json:"assetAddr"`
+	AssetSymbol       string         `json:"assetSymbol"`
+	CostBasis         float64        `json:"costBasis"`
+	Decimals          base.Value     `json:"decimals"`
+	DisposedBlock     base.Blknum    `json:"disposedBlock,omitempty"`
+	DisposedHash      base.Hash      `json:"disposedHash,omitempty"`
+	DisposedTimestamp base.Timestamp `json:"disposedTimestamp,omitempty"`
+	Gain              float64        `json:"gain"`
+	GainType          string         `json:"gainType"`
+	LongTerm          bool           `json:"longTerm,omitempty"`
+	LongTermGain      float64        `json:"longTermGain,omitempty"`
+	Method            string         `json:"method"`
+	Proceeds          float64        `json:"proceeds"`
+	Quantity          base.Wei       `json:"quantity"`
+	ShortTermGain     float64        `json:"shortTermGain,omitempty"`
+	Year              uint64         `json:"year,omitempty"`
+	// EXISTING_CODE
+	// EXISTING_CODE
+}
+
+func (s Gain) String() string {
+	bytes, _ := json.Marshal(s)
+	return string(bytes)
+}
+
+func (s *Gain) Model(chain, format string, verbose bool, extraOpts map[string]any) Model {
+	var model = map[string]any{}
+	var order = []string{}
+
+	// EXISTING_CODE
+	model = map[string]any{
+		"gainType":     s.GainType,
+		"method":       s.Method,
+		"accountedFor": s.AccountedFor,
+		"assetAddr":    s.AssetAddr,
+		"assetSymbol":  s.AssetSymbol,
+		"decimals":     s.Decimals,
+		"quantity":     s.Quantity.String(),
+		"costBasis":    s.CostBasis,
+		"proceeds":     s.Proceeds,
+		"gain":         s.Gain,
+	}
+	order = []string{
+		"gainType",
+		"method",
+		"accountedFor",
+		"assetAddr",
+		"assetSymbol",
+		"decimals",
+	}
+
+	switch s.GainType {
+	case "summary":
+		model["year"] = s.Year
+		model["shortTermGain"] = s.ShortTermGain
+		model["longTermGain"] = s.LongTermGain
+		order = append(order, "year", "quantity", "costBasis", "proceeds", "gain", "shortTermGain", "longTermGain")
+	default:
+		model["acquiredBlock"] = s.AcquiredBlock
+		model["acquiredTimestamp"] = s.AcquiredTimestamp
+		model["acquiredDate"] = s.AcquiredDate()
+		model["acquiredHash"] = s.AcquiredHash
+		order = append(order, "acquiredBlock", "acquiredTimestamp", "acquiredDate", "acquiredHash")
+		if s.GainType == "realized" {
+			model["disposedBlock"] = s.DisposedBlock
+			model["disposedTimestamp"] = s.DisposedTimestamp
+			model["disposedDate"] = s.DisposedDate()
+			model["disposedHash"] = s.DisposedHash
+			model["longTerm"] = s.LongTerm
+			order = append(order, "disposedBlock", "disposedTimestamp", "disposedDate", "disposedHash")
+		}
+		order = append(order, "quantity", "costBasis", "proceeds", "gain")
+		if s.GainType == "realized" {
+			order = append(order, "longTerm")
+		}
+	}
+
+	if extraOpts["ether"] == true {
+		model["quantityEth"] = s.Quantity.ToEtherStr(int(s.Decimals))
+		order = append(order, "quantityEth")
+	}
+	// EXISTING_CODE
+
+	return Model{
+		Data:  model,
+		Order: order,
+	}
+}
+
+// FinishUnmarshal is used by the cache. It may be unused depending on auto-code-gen
+func (s *Gain) FinishUnmarshal() {
+	// EXISTING_CODE
+	// EXISTING_CODE
+}
+
+// EXISTING_CODE
+func (s *Gain) AcquiredDate() string {
+	return base.FormattedDate(s.AcquiredTimestamp)
+}
+
+func (s *Gain) DisposedDate() string {
+	return base.FormattedDate(s.DisposedTimestamp)
+}
+
+// EXISTING_CODE
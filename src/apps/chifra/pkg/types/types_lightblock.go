@@ -25,8 +25,10 @@ import (
 
 type LightBlock struct {
 	BaseFeePerGas base.Gas       `json:"baseFeePerGas"`
+	BlobGasUsed   base.Gas       `json:"blobGasUsed,omitempty"`
 	BlockNumber   base.Blknum    `json:"blockNumber"`
 	Difficulty    base.Value     `json:"difficulty"`
+	ExcessBlobGas base.Gas       `json:"excessBlobGas,omitempty"`
 	GasLimit      base.Gas       `json:"gasLimit"`
 	GasUsed       base.Gas       `json:"gasUsed"`
 	Hash          base.Hash      `json:"hash"`
@@ -75,6 +77,10 @@ func (s *LightBlock) Model(chain, format string, verbose bool, extraOpts map[str
 			}
 			model["withdrawals"] = withs
 		}
+		if s.BlobGasUsed > 0 || s.ExcessBlobGas > 0 {
+			model["blobGasUsed"] = s.BlobGasUsed
+			model["excessBlobGas"] = s.ExcessBlobGas
+		}
 	} else {
 		model["transactionsCnt"] = len(s.Transactions)
 		order = append(order, "transactionsCnt")
@@ -82,6 +88,11 @@ func (s *LightBlock) Model(chain, format string, verbose bool, extraOpts map[str
 			model["withdrawalsCnt"] = len(s.Withdrawals)
 			order = append(order, "withdrawalsCnt")
 		}
+		if s.BlockNumber >= base.KnownBlock(chain, base.Cancun) {
+			model["blobGasUsed"] = s.BlobGasUsed
+			model["excessBlobGas"] = s.ExcessBlobGas
+			order = append(order, []string{"blobGasUsed", "excessBlobGas"}...)
+		}
 	}
 
 	if name, loaded, found := nameAddress(extraOpts, s.Miner); found {
@@ -121,6 +132,11 @@ func (s *LightBlock) MarshalCache(writer io.Writer) (err error) {
 		return err
 	}
 
+	// BlobGasUsed
+	if err = cache.WriteValue(writer, s.BlobGasUsed); err != nil {
+		return err
+	}
+
 	// BlockNumber
 	if err = cache.WriteValue(writer, s.BlockNumber); err != nil {
 		return err
@@ -131,6 +147,11 @@ func (s *LightBlock) MarshalCache(writer io.Writer) (err error) {
 		return err
 	}
 
+	// ExcessBlobGas
+	if err = cache.WriteValue(writer, s.ExcessBlobGas); err != nil {
+		return err
+	}
+
 	// GasLimit
 	if err = cache.WriteValue(writer, s.GasLimit); err != nil {
 		return err
@@ -203,6 +224,15 @@ func (s *LightBlock) UnmarshalCache(vers uint64, reader io.Reader) (err error) {
 		}
 	}
 
+	// BlobGasUsed
+	vBlobGasUsed := version.NewVersion("3.5.1")
+	if vers > vBlobGasUsed.Uint64() {
+		// BlobGasUsed
+		if err = cache.ReadValue(reader, &s.BlobGasUsed, vers); err != nil {
+			return err
+		}
+	}
+
 	// BlockNumber
 	if err = cache.ReadValue(reader, &s.BlockNumber, vers); err != nil {
 		return err
@@ -213,6 +243,15 @@ func (s *LightBlock) UnmarshalCache(vers uint64, reader io.Reader) (err error) {
 		return err
 	}
 
+	// ExcessBlobGas
+	vExcessBlobGas := version.NewVersion("3.5.1")
+	if vers > vExcessBlobGas.Uint64() {
+		// ExcessBlobGas
+		if err = cache.ReadValue(reader, &s.ExcessBlobGas, vers); err != nil {
+			return err
+		}
+	}
+
 	// GasLimit
 	if err = cache.ReadValue(reader, &s.GasLimit, vers); err != nil {
 		return err
@@ -0,0 +1,68 @@
+package types
+
+import (
+	"strings"
+)
+
+// Reason is a bitmask of the ways in which an address appears in a transaction (or, for miners,
+// uncles, and withdrawals, in a block). A chunk written with reasons stores one for each appearance.
+// Zero means the reason is unknown (for example, the chunk was written without reasons).
+type Reason uint16
+
+const (
+	ReasonFrom       Reason = 1 << iota // the sender of the transaction
+	ReasonTo                            // the recipient of the transaction
+	ReasonCreation                      // a contract created by the transaction
+	ReasonInput                         // found in the transaction's input data
+	ReasonGenerator                     // the address that emitted one of the logs
+	ReasonTopic                         // found in the topics of one of the logs
+	ReasonData                          // found in the data of one of the logs
+	ReasonTrace                         // a participant in (or found in the data of) an internal trace
+	ReasonMiner                         // the miner of the block (or the recipient of an external reward)
+	ReasonUncle                         // the miner of an uncle of the block
+	ReasonWithdrawal                    // the recipient of a withdrawal from the consensus layer
+	ReasonGenesis                       // a prefund in the genesis block
+)
+
+// reasonNames are in the order in which they appear in the String of a Reason
+var reasonNames = []struct {
+	reason Reason
+	name   string
+}{
+	{ReasonFrom, "from"},
+	{ReasonTo, "to"},
+	{ReasonCreation, "creation"},
+	{ReasonInput, "input"},
+	{ReasonGenerator, "generator"},
+	{ReasonTopic, "topic"},
+	{ReasonData, "data"},
+	{ReasonTrace, "trace"},
+	{ReasonMiner, "miner"},
+	{ReasonUncle, "uncle"},
+	{ReasonWithdrawal, "withdrawal"},
+	{ReasonGenesis, "genesis"},
+}
+
+// String returns the names of the reasons separated by a pipe (empty if the reason is unknown)
+func (r Reason) String() string {
+	names := make([]string, 0, 2)
+	for _, rn := range reasonNames {
+		if r&rn.reason != 0 {
+			names = append(names, rn.name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// ReasonFromNames returns the reason with a bit set for each of the named reasons. Unknown names are ignored.
+func ReasonFromNames(names []string) Reason {
+	var ret Reason
+	for _, name := range names {
+		for _, rn := range reasonNames {
+			if rn.name == name {
+				ret |= rn.reason
+			}
+		}
+	}
+	return ret
+}
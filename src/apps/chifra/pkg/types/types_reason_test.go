@@ -0,0 +1,36 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestReasonString(t *testing.T) {
+	tests := []struct {
+		reason Reason
+		want   string
+	}{
+		{0, ""},
+		{ReasonFrom, "from"},
+		{ReasonFrom | ReasonTopic, "from|topic"},
+		{ReasonGenesis | ReasonTo | ReasonTrace, "to|trace|genesis"},
+	}
+	for _, tt := range tests {
+		if got := tt.reason.String(); got != tt.want {
+			t.Errorf("Reason(%d).String() = %s, want %s", tt.reason, got, tt.want)
+		}
+	}
+}
+
+func TestReasonFromNames(t *testing.T) {
+	if got := ReasonFromNames([]string{"to", "withdrawal", "unknown"}); got != ReasonTo|ReasonWithdrawal {
+		t.Errorf("ReasonFromNames() = %s", got)
+	}
+	if got := ReasonFromNames(nil); got != 0 {
+		t.Errorf("ReasonFromNames(nil) = %s", got)
+	}
+	for _, rn := range reasonNames {
+		if got := ReasonFromNames([]string{rn.reason.String()}); got != rn.reason {
+			t.Errorf("round trip of %s returned %s", rn.name, got)
+		}
+	}
+}
@@ -18,25 +18,35 @@ import (
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/cache"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/version"
 )
 
 // EXISTING_CODE
 
 type Receipt struct {
+	BlobGasPrice      base.Gas     `json:"blobGasPrice,omitempty"`
+	BlobGasUsed       base.Gas     `json:"blobGasUsed,omitempty"`
 	BlockHash         base.Hash    `json:"blockHash,omitempty"`
 	BlockNumber       base.Blknum  `json:"blockNumber"`
 	ContractAddress   base.Address `json:"contractAddress,omitempty"`
 	CumulativeGasUsed base.Gas     `json:"cumulativeGasUsed,omitempty"`
+	DepositNonce      base.Value   `json:"depositNonce,omitempty"`
 	EffectiveGasPrice base.Gas     `json:"effectiveGasPrice,omitempty"`
 	From              base.Address `json:"from,omitempty"`
 	GasUsed           base.Gas     `json:"gasUsed"`
+	GasUsedForL1      base.Gas     `json:"gasUsedForL1,omitempty"`
 	IsError           bool         `json:"isError,omitempty"`
+	L1BlockNumber     base.Blknum  `json:"l1BlockNumber,omitempty"`
+	L1Fee             base.Wei     `json:"l1Fee,omitempty"`
+	L1GasPrice        base.Gas     `json:"l1GasPrice,omitempty"`
+	L1GasUsed         base.Gas     `json:"l1GasUsed,omitempty"`
 	Logs              []Log        `json:"logs"`
 	Status            base.Value   `json:"status"`
 	To                base.Address `json:"to,omitempty"`
 	TransactionHash   base.Hash    `json:"transactionHash"`
 	TransactionIndex  base.Txnum   `json:"transactionIndex"`
+	ReceiptType       string       `json:"type,omitempty"`
 	// EXISTING_CODE
 	// EXISTING_CODE
 }
@@ -100,6 +110,15 @@ func (s *Receipt) Model(chain, format string, verbose bool, extraOpts map[string
 		if !s.To.IsZero() {
 			model["to"] = s.To
 		}
+		if len(s.ReceiptType) > 0 && s.ReceiptType != "0x0" {
+			model["type"] = s.ReceiptType
+		}
+		for key, value := range s.rollupModel() {
+			model[key] = value
+		}
+		for key, value := range s.blobModel() {
+			model[key] = value
+		}
 
 	} else {
 		model["logsCnt"] = len(s.Logs)
@@ -112,6 +131,12 @@ func (s *Receipt) Model(chain, format string, verbose bool, extraOpts map[string
 			model["contractAddress"] = s.ContractAddress.Hex()
 			order = append(order, "contractAddress")
 		}
+
+		if s.BlockNumber >= base.KnownBlock(chain, base.Cancun) {
+			model["blobGasUsed"] = s.BlobGasUsed
+			model["blobGasPrice"] = s.BlobGasPrice
+			order = append(order, []string{"blobGasUsed", "blobGasPrice"}...)
+		}
 	}
 
 	items := []namer{
@@ -163,6 +188,16 @@ func (s *ReceiptGroup) UnmarshalCache(vers uint64, reader io.Reader) (err error)
 }
 
 func (s *Receipt) MarshalCache(writer io.Writer) (err error) {
+	// BlobGasPrice
+	if err = cache.WriteValue(writer, s.BlobGasPrice); err != nil {
+		return err
+	}
+
+	// BlobGasUsed
+	if err = cache.WriteValue(writer, s.BlobGasUsed); err != nil {
+		return err
+	}
+
 	// BlockHash
 	if err = cache.WriteValue(writer, &s.BlockHash); err != nil {
 		return err
@@ -183,6 +218,11 @@ func (s *Receipt) MarshalCache(writer io.Writer) (err error) {
 		return err
 	}
 
+	// DepositNonce
+	if err = cache.WriteValue(writer, s.DepositNonce); err != nil {
+		return err
+	}
+
 	// EffectiveGasPrice
 	if err = cache.WriteValue(writer, s.EffectiveGasPrice); err != nil {
 		return err
@@ -198,11 +238,36 @@ func (s *Receipt) MarshalCache(writer io.Writer) (err error) {
 		return err
 	}
 
+	// GasUsedForL1
+	if err = cache.WriteValue(writer, s.GasUsedForL1); err != nil {
+		return err
+	}
+
 	// IsError
 	if err = cache.WriteValue(writer, s.IsError); err != nil {
 		return err
 	}
 
+	// L1BlockNumber
+	if err = cache.WriteValue(writer, s.L1BlockNumber); err != nil {
+		return err
+	}
+
+	// L1Fee
+	if err = cache.WriteValue(writer, &s.L1Fee); err != nil {
+		return err
+	}
+
+	// L1GasPrice
+	if err = cache.WriteValue(writer, s.L1GasPrice); err != nil {
+		return err
+	}
+
+	// L1GasUsed
+	if err = cache.WriteValue(writer, s.L1GasUsed); err != nil {
+		return err
+	}
+
 	// Logs
 	logs := make([]cache.Marshaler, 0, len(s.Logs))
 	for _, log := range s.Logs {
@@ -232,6 +297,11 @@ func (s *Receipt) MarshalCache(writer io.Writer) (err error) {
 		return err
 	}
 
+	// ReceiptType
+	if err = cache.WriteValue(writer, s.ReceiptType); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -240,6 +310,24 @@ func (s *Receipt) UnmarshalCache(vers uint64, reader io.Reader) (err error) {
 	// EXISTING_CODE
 	// EXISTING_CODE
 
+	// BlobGasPrice
+	vBlobGasPrice := version.NewVersion("3.5.1")
+	if vers > vBlobGasPrice.Uint64() {
+		// BlobGasPrice
+		if err = cache.ReadValue(reader, &s.BlobGasPrice, vers); err != nil {
+			return err
+		}
+	}
+
+	// BlobGasUsed
+	vBlobGasUsed := version.NewVersion("3.5.1")
+	if vers > vBlobGasUsed.Uint64() {
+		// BlobGasUsed
+		if err = cache.ReadValue(reader, &s.BlobGasUsed, vers); err != nil {
+			return err
+		}
+	}
+
 	// BlockHash
 	if err = cache.ReadValue(reader, &s.BlockHash, vers); err != nil {
 		return err
@@ -270,6 +358,15 @@ func (s *Receipt) UnmarshalCache(vers uint64, reader io.Reader) (err error) {
 		}
 	}
 
+	// DepositNonce
+	vDepositNonce := version.NewVersion("3.5.0")
+	if vers > vDepositNonce.Uint64() {
+		// DepositNonce
+		if err = cache.ReadValue(reader, &s.DepositNonce, vers); err != nil {
+			return err
+		}
+	}
+
 	// EffectiveGasPrice
 	if err = cache.ReadValue(reader, &s.EffectiveGasPrice, vers); err != nil {
 		return err
@@ -285,11 +382,56 @@ func (s *Receipt) UnmarshalCache(vers uint64, reader io.Reader) (err error) {
 		return err
 	}
 
+	// GasUsedForL1
+	vGasUsedForL1 := version.NewVersion("3.5.0")
+	if vers > vGasUsedForL1.Uint64() {
+		// GasUsedForL1
+		if err = cache.ReadValue(reader, &s.GasUsedForL1, vers); err != nil {
+			return err
+		}
+	}
+
 	// IsError
 	if err = cache.ReadValue(reader, &s.IsError, vers); err != nil {
 		return err
 	}
 
+	// L1BlockNumber
+	vL1BlockNumber := version.NewVersion("3.5.0")
+	if vers > vL1BlockNumber.Uint64() {
+		// L1BlockNumber
+		if err = cache.ReadValue(reader, &s.L1BlockNumber, vers); err != nil {
+			return err
+		}
+	}
+
+	// L1Fee
+	vL1Fee := version.NewVersion("3.5.0")
+	if vers > vL1Fee.Uint64() {
+		// L1Fee
+		if err = cache.ReadValue(reader, &s.L1Fee, vers); err != nil {
+			return err
+		}
+	}
+
+	// L1GasPrice
+	vL1GasPrice := version.NewVersion("3.5.0")
+	if vers > vL1GasPrice.Uint64() {
+		// L1GasPrice
+		if err = cache.ReadValue(reader, &s.L1GasPrice, vers); err != nil {
+			return err
+		}
+	}
+
+	// L1GasUsed
+	vL1GasUsed := version.NewVersion("3.5.0")
+	if vers > vL1GasUsed.Uint64() {
+		// L1GasUsed
+		if err = cache.ReadValue(reader, &s.L1GasUsed, vers); err != nil {
+			return err
+		}
+	}
+
 	// Logs
 	s.Logs = make([]Log, 0)
 	if err = cache.ReadValue(reader, &s.Logs, vers); err != nil {
@@ -326,6 +468,15 @@ func (s *Receipt) UnmarshalCache(vers uint64, reader io.Reader) (err error) {
 		return err
 	}
 
+	// ReceiptType
+	vReceiptType := version.NewVersion("3.5.0")
+	if vers > vReceiptType.Uint64() {
+		// ReceiptType
+		if err = cache.ReadValue(reader, &s.ReceiptType, vers); err != nil {
+			return err
+		}
+	}
+
 	s.FinishUnmarshal()
 
 	return nil
@@ -348,4 +499,48 @@ func (s *Receipt) IsDefault() bool {
 	return a && b && c && d
 }
 
+// depositTypes are the transaction types by which each family of rollups brings value in from
+// its parent chain
+var depositTypes = map[string]string{
+	config.FamilyOptimism: "0x7e",
+	config.FamilyArbitrum: "0x64",
+}
+
+// IsDeposit returns true if the receipt is for a deposit from the parent chain of a rollup of the
+// given family
+func (s *Receipt) IsDeposit(family string) bool {
+	txType, ok := depositTypes[family]
+	return ok && s.ReceiptType == txType
+}
+
+// rollupModel returns those fields a rollup adds to its receipts that are present
+func (s *Receipt) rollupModel() map[string]any {
+	model := map[string]any{}
+	if !s.L1Fee.IsZero() {
+		model["l1Fee"] = s.L1Fee.String()
+		model["l1GasPrice"] = s.L1GasPrice
+		model["l1GasUsed"] = s.L1GasUsed
+	}
+	if s.DepositNonce > 0 {
+		model["depositNonce"] = s.DepositNonce
+	}
+	if s.GasUsedForL1 > 0 {
+		model["gasUsedForL1"] = s.GasUsedForL1
+	}
+	if s.L1BlockNumber > 0 {
+		model["l1BlockNumber"] = s.L1BlockNumber
+	}
+	return model
+}
+
+// blobModel returns the blob gas fields (EIP-4844) of a blob transaction's receipt, if any
+func (s *Receipt) blobModel() map[string]any {
+	model := map[string]any{}
+	if s.BlobGasUsed > 0 {
+		model["blobGasUsed"] = s.BlobGasUsed
+		model["blobGasPrice"] = s.BlobGasPrice
+	}
+	return model
+}
+
 // EXISTING_CODE
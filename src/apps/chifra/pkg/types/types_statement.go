@@ -18,7 +18,9 @@ import (
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/cache"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/version"
 )
 
 // EXISTING_CODE
@@ -39,6 +41,7 @@ type Statement struct {
 	GasOut              base.Wei       `json:"gasOut,omitempty"`
 	InternalIn          base.Wei       `json:"internalIn,omitempty"`
 	InternalOut         base.Wei       `json:"internalOut,omitempty"`
+	L1FeeOut            base.Wei       `json:"l1FeeOut,omitempty"`
 	LogIndex            base.Lognum    `json:"logIndex"`
 	MinerBaseRewardIn   base.Wei       `json:"minerBaseRewardIn,omitempty"`
 	MinerNephewRewardIn base.Wei       `json:"minerNephewRewardIn,omitempty"`
@@ -46,6 +49,7 @@ type Statement struct {
 	MinerUncleRewardIn  base.Wei       `json:"minerUncleRewardIn,omitempty"`
 	PrefundIn           base.Wei       `json:"prefundIn,omitempty"`
 	PrevBal             base.Wei       `json:"prevBal,omitempty"`
+	PricePool           base.Address   `json:"pricePool,omitempty"`
 	PriceSource         string         `json:"priceSource"`
 	Recipient           base.Address   `json:"recipient"`
 	SelfDestructIn      base.Wei       `json:"selfDestructIn,omitempty"`
@@ -53,6 +57,7 @@ type Statement struct {
 	Sender              base.Address   `json:"sender"`
 	SpotPrice           base.Float     `json:"spotPrice"`
 	Timestamp           base.Timestamp `json:"timestamp"`
+	TokenId             base.Wei       `json:"tokenId,omitempty"`
 	TransactionHash     base.Hash      `json:"transactionHash"`
 	TransactionIndex    base.Txnum     `json:"transactionIndex"`
 	// EXISTING_CODE
@@ -122,14 +127,29 @@ func (s *Statement) Model(chain, format string, verbose bool, extraOpts map[stri
 	} else if format != "json" {
 		model["prevBal"] = ""
 	}
+	if !s.PricePool.IsZero() {
+		model["pricePool"] = s.PricePool.Hex()
+	} else if format != "json" {
+		model["pricePool"] = ""
+	}
+	if s.IsNft() {
+		model["tokenId"] = s.TokenId.Text(10)
+	} else if format != "json" {
+		model["tokenId"] = ""
+	}
 	order = []string{
 		"blockNumber", "transactionIndex", "logIndex", "transactionHash", "timestamp", "date",
-		"assetAddr", "assetType", "assetSymbol", "decimals", "spotPrice", "priceSource", "accountedFor",
-		"sender", "recipient", "begBal", "amountNet", "endBal", "reconciliationType", "reconciled",
+		"assetAddr", "assetType", "assetSymbol", "decimals", "spotPrice", "priceSource", "pricePool",
+		"accountedFor", "sender", "recipient", "begBal", "amountNet", "endBal", "reconciliationType", "reconciled",
 		"totalIn", "amountIn", "internalIn", "selfDestructIn", "minerBaseRewardIn", "minerNephewRewardIn",
 		"minerTxFeeIn", "minerUncleRewardIn", "prefundIn", "totalOut", "amountOut", "internalOut",
 		"selfDestructOut", "gasOut", "totalOutLessGas", "prevBal", "begBalDiff",
-		"endBalDiff", "endBalCalc", "correctingReason",
+		"endBalDiff", "endBalCalc", "correctingReason", "tokenId",
+	}
+	isRollup := config.IsRollup(chain)
+	if isRollup {
+		model["l1FeeOut"] = s.L1FeeOut.Text(10)
+		order = append(order, "l1FeeOut")
 	}
 
 	asEther := extraOpts["ether"] == true
@@ -169,6 +189,10 @@ func (s *Statement) Model(chain, format string, verbose bool, extraOpts map[stri
 			"totalOutEth", "amountOutEth", "internalOutEth", "correctingOutEth",
 			"selfDestructOutEth", "gasOutEth", "totalOutLessGasEth", "begBalDiffEth",
 			"endBalDiffEth", "endBalCalcEth", "prevBalEth"}...)
+		if isRollup {
+			model["l1FeeOutEth"] = s.L1FeeOut.ToEtherStr(decimals)
+			order = append(order, "l1FeeOutEth")
+		}
 	}
 	// EXISTING_CODE
 
@@ -284,6 +308,11 @@ func (s *Statement) MarshalCache(writer io.Writer) (err error) {
 		return err
 	}
 
+	// L1FeeOut
+	if err = cache.WriteValue(writer, &s.L1FeeOut); err != nil {
+		return err
+	}
+
 	// LogIndex
 	if err = cache.WriteValue(writer, s.LogIndex); err != nil {
 		return err
@@ -319,6 +348,11 @@ func (s *Statement) MarshalCache(writer io.Writer) (err error) {
 		return err
 	}
 
+	// PricePool
+	if err = cache.WriteValue(writer, s.PricePool); err != nil {
+		return err
+	}
+
 	// PriceSource
 	if err = cache.WriteValue(writer, s.PriceSource); err != nil {
 		return err
@@ -354,6 +388,11 @@ func (s *Statement) MarshalCache(writer io.Writer) (err error) {
 		return err
 	}
 
+	// TokenId
+	if err = cache.WriteValue(writer, &s.TokenId); err != nil {
+		return err
+	}
+
 	// TransactionHash
 	if err = cache.WriteValue(writer, &s.TransactionHash); err != nil {
 		return err
@@ -447,6 +486,15 @@ func (s *Statement) UnmarshalCache(vers uint64, reader io.Reader) (err error) {
 		return err
 	}
 
+	// L1FeeOut
+	vL1FeeOut := version.NewVersion("3.5.0")
+	if vers > vL1FeeOut.Uint64() {
+		// L1FeeOut
+		if err = cache.ReadValue(reader, &s.L1FeeOut, vers); err != nil {
+			return err
+		}
+	}
+
 	// LogIndex
 	if err = cache.ReadValue(reader, &s.LogIndex, vers); err != nil {
 		return err
@@ -482,6 +530,15 @@ func (s *Statement) UnmarshalCache(vers uint64, reader io.Reader) (err error) {
 		return err
 	}
 
+	// PricePool
+	vPricePool := version.NewVersion("3.5.0")
+	if vers > vPricePool.Uint64() {
+		// PricePool
+		if err = cache.ReadValue(reader, &s.PricePool, vers); err != nil {
+			return err
+		}
+	}
+
 	// PriceSource
 	if err = cache.ReadValue(reader, &s.PriceSource, vers); err != nil {
 		return err
@@ -517,6 +574,15 @@ func (s *Statement) UnmarshalCache(vers uint64, reader io.Reader) (err error) {
 		return err
 	}
 
+	// TokenId
+	vTokenId := version.NewVersion("3.5.0")
+	if vers > vTokenId.Uint64() {
+		// TokenId
+		if err = cache.ReadValue(reader, &s.TokenId, vers); err != nil {
+			return err
+		}
+	}
+
 	// TransactionHash
 	if err = cache.ReadValue(reader, &s.TransactionHash, vers); err != nil {
 		return err
@@ -569,6 +635,7 @@ func (s *Statement) TotalOut() *base.Wei {
 		s.CorrectingOut,
 		s.SelfDestructOut,
 		s.GasOut,
+		s.L1FeeOut,
 	}
 
 	sum := base.NewWei(0)
@@ -589,7 +656,8 @@ func (s *Statement) AmountNet() *base.Wei {
 
 func (s *Statement) TotalOutLessGas() *base.Wei {
 	val := s.TotalOut()
-	return new(base.Wei).Sub(val, &s.GasOut)
+	val = val.Sub(val, &s.GasOut)
+	return val.Sub(val, &s.L1FeeOut)
 }
 
 func (s *Statement) BegBalDiff() *base.Wei {
@@ -621,6 +689,11 @@ func (s *Statement) IsEth() bool {
 	return s.AssetAddr == base.FAKE_ETH_ADDRESS
 }
 
+// IsNft returns true if the statement reconciles a single token id of an ERC-721 or ERC-1155 token
+func (s *Statement) IsNft() bool {
+	return s.AssetType == "erc721" || s.AssetType == "erc1155"
+}
+
 var (
 	sai  = base.HexToAddress("0x89d24a6b4ccb1b6faa2625fe562bdd9a23260359")
 	dai  = base.HexToAddress("0x6b175474e89094c44da98b954eedeac495271d0f")
@@ -777,6 +850,7 @@ func (s *Statement) DebugStatement(ctx Ledgerer) {
 	reportE("   correctingOut:      ", &s.CorrectingOut)
 	reportE("   selfDestructOut:    ", &s.SelfDestructOut)
 	reportE("   gasOut:             ", &s.GasOut)
+	reportE("   l1FeeOut:           ", &s.L1FeeOut)
 	logger.TestLog(s.CorrectingReason != "", "   correctingReason:   ", s.CorrectingReason)
 	logger.TestLog(true, "   material:           ", s.IsMaterial())
 	logger.TestLog(true, "   reconciled:         ", s.Reconciled())
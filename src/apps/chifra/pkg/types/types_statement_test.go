@@ -0,0 +1,51 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/cache"
+)
+
+func TestStatementCache(t *testing.T) {
+	expected := &StatementGroup{
+		BlockNumber:      120000001,
+		TransactionIndex: 3,
+		Address:          base.HexToAddress("0x977f82a600a1414e583f7f13623f1ac5d58b1c0b"),
+		Statements: []Statement{
+			{
+				AccountedFor: base.HexToAddress("0x977f82a600a1414e583f7f13623f1ac5d58b1c0b"),
+				AssetAddr:    base.HexToAddress("0xbc4ca0eda7647a8ab7c2061c2e118a18a936f13d"),
+				BlockNumber:  120000001,
+				EndBal:       *(base.NewWei(1)),
+				L1FeeOut:     *(base.NewWei(1500)),
+				PricePool:    base.HexToAddress("0xa478c2975ab1ea89e8196811f51a7b7ade33eb11"),
+				TokenId:      *(base.NewWei(7804)),
+			},
+		},
+	}
+
+	store, err := cache.NewStore(&cache.StoreOptions{Location: cache.MemoryCache})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Write(expected, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	readBack := &StatementGroup{
+		BlockNumber:      expected.BlockNumber,
+		TransactionIndex: expected.TransactionIndex,
+		Address:          expected.Address,
+	}
+	if err := store.Read(readBack, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(readBack.Statements) != 1 {
+		t.Fatalf("read %d statements", len(readBack.Statements))
+	}
+	got, want := readBack.Statements[0], expected.Statements[0]
+	if got.TokenId.Cmp(&want.TokenId) != 0 || got.PricePool != want.PricePool || got.L1FeeOut.Cmp(&want.L1FeeOut) != 0 || got.EndBal.Cmp(&want.EndBal) != 0 {
+		t.Fatalf("value mismatch:\n\tgot %+v\n\twant %+v\n", got, want)
+	}
+}
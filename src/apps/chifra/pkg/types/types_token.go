@@ -175,6 +175,7 @@ type TokenType int
 const (
 	TokenErc20 TokenType = iota
 	TokenErc721
+	TokenErc1155
 )
 
 func (t TokenType) IsErc20() bool {
@@ -185,4 +186,8 @@ func (t TokenType) IsErc721() bool {
 	return t == TokenErc721
 }
 
+func (t TokenType) IsErc1155() bool {
+	return t == TokenErc1155
+}
+
 // EXISTING_CODE
@@ -18,6 +18,8 @@ import (
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/cache"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/version"
 )
 
 type StorageSlot struct {
@@ -48,6 +50,7 @@ func NewReward(block, nephew, txFee, uncle *base.Wei) (Rewards, base.Wei) {
 
 type Transaction struct {
 	ArticulatedTx        *Function      `json:"articulatedTx"`
+	BlobVersionedHashes  []base.Hash    `json:"blobVersionedHashes,omitempty"`
 	BlockHash            base.Hash      `json:"blockHash"`
 	BlockNumber          base.Blknum    `json:"blockNumber"`
 	From                 base.Address   `json:"from"`
@@ -58,8 +61,10 @@ type Transaction struct {
 	Hash                 base.Hash      `json:"hash"`
 	Input                string         `json:"input"`
 	IsError              bool           `json:"isError"`
+	MaxFeePerBlobGas     base.Gas       `json:"maxFeePerBlobGas,omitempty"`
 	MaxFeePerGas         base.Gas       `json:"maxFeePerGas"`
 	MaxPriorityFeePerGas base.Gas       `json:"maxPriorityFeePerGas"`
+	Mint                 base.Wei       `json:"mint,omitempty"`
 	Nonce                base.Value     `json:"nonce"`
 	Receipt              *Receipt       `json:"receipt"`
 	Timestamp            base.Timestamp `json:"timestamp"`
@@ -165,6 +170,15 @@ func (s *Transaction) Model(chain, format string, verbose bool, extraOpts map[st
 		if s.MaxPriorityFeePerGas > 0 {
 			model["maxPriorityFeePerGas"] = s.MaxPriorityFeePerGas
 		}
+		if !s.Mint.IsZero() {
+			model["mint"] = s.Mint.String()
+		}
+		if s.MaxFeePerBlobGas > 0 {
+			model["maxFeePerBlobGas"] = s.MaxFeePerBlobGas
+		}
+		if len(s.BlobVersionedHashes) > 0 {
+			model["blobVersionedHashes"] = s.BlobVersionedHashes
+		}
 		if len(s.TransactionType) > 0 && s.TransactionType != "0x0" {
 			model["type"] = s.TransactionType
 		}
@@ -193,6 +207,12 @@ func (s *Transaction) Model(chain, format string, verbose bool, extraOpts map[st
 				"gasUsed":           s.Receipt.GasUsed,
 				"status":            status,
 			}
+			for key, value := range s.Receipt.rollupModel() {
+				receiptModel[key] = value
+			}
+			for key, value := range s.Receipt.blobModel() {
+				receiptModel[key] = value
+			}
 
 			// TODO: We've already made a copy of the data that we've queried from the chain,
 			// TODO: why are we copying it yet again? Can't we use pointers to the one copy of the data?
@@ -281,6 +301,19 @@ func (s *Transaction) Model(chain, format string, verbose bool, extraOpts map[st
 			model["nTraces"] = len(s.Traces)
 			order = append(order, "nTraces")
 		}
+
+		if s.BlockNumber >= base.KnownBlock(chain, base.Cancun) {
+			model["blobsCnt"] = len(s.BlobVersionedHashes)
+			model["maxFeePerBlobGas"] = s.MaxFeePerBlobGas
+			model["blobGasUsed"] = base.Gas(0)
+			model["blobGasPrice"] = base.Gas(0)
+			if s.Receipt != nil {
+				model["blobGasUsed"] = s.Receipt.BlobGasUsed
+				model["blobGasPrice"] = s.Receipt.BlobGasPrice
+			}
+			model["blobFee"] = s.BlobFee().String()
+			order = append(order, []string{"blobsCnt", "maxFeePerBlobGas", "blobGasUsed", "blobGasPrice", "blobFee"}...)
+		}
 	}
 
 	asEther := true // special case for transactions, we always show --ether -- extraOpts["ether"] == true
@@ -338,6 +371,11 @@ func (s *Transaction) MarshalCache(writer io.Writer) (err error) {
 		return err
 	}
 
+	// BlobVersionedHashes
+	if err = cache.WriteValue(writer, s.BlobVersionedHashes); err != nil {
+		return err
+	}
+
 	// BlockHash
 	if err = cache.WriteValue(writer, &s.BlockHash); err != nil {
 		return err
@@ -388,6 +426,11 @@ func (s *Transaction) MarshalCache(writer io.Writer) (err error) {
 		return err
 	}
 
+	// MaxFeePerBlobGas
+	if err = cache.WriteValue(writer, s.MaxFeePerBlobGas); err != nil {
+		return err
+	}
+
 	// MaxFeePerGas
 	if err = cache.WriteValue(writer, s.MaxFeePerGas); err != nil {
 		return err
@@ -398,6 +441,11 @@ func (s *Transaction) MarshalCache(writer io.Writer) (err error) {
 		return err
 	}
 
+	// Mint
+	if err = cache.WriteValue(writer, &s.Mint); err != nil {
+		return err
+	}
+
 	// Nonce
 	if err = cache.WriteValue(writer, s.Nonce); err != nil {
 		return err
@@ -453,6 +501,16 @@ func (s *Transaction) UnmarshalCache(vers uint64, reader io.Reader) (err error)
 	}
 	s.ArticulatedTx = optArticulatedTx.Get()
 
+	// BlobVersionedHashes
+	vBlobVersionedHashes := version.NewVersion("3.5.1")
+	if vers > vBlobVersionedHashes.Uint64() {
+		// BlobVersionedHashes
+		s.BlobVersionedHashes = make([]base.Hash, 0)
+		if err = cache.ReadValue(reader, &s.BlobVersionedHashes, vers); err != nil {
+			return err
+		}
+	}
+
 	// BlockHash
 	if err = cache.ReadValue(reader, &s.BlockHash, vers); err != nil {
 		return err
@@ -503,6 +561,15 @@ func (s *Transaction) UnmarshalCache(vers uint64, reader io.Reader) (err error)
 		return err
 	}
 
+	// MaxFeePerBlobGas
+	vMaxFeePerBlobGas := version.NewVersion("3.5.1")
+	if vers > vMaxFeePerBlobGas.Uint64() {
+		// MaxFeePerBlobGas
+		if err = cache.ReadValue(reader, &s.MaxFeePerBlobGas, vers); err != nil {
+			return err
+		}
+	}
+
 	// MaxFeePerGas
 	if err = cache.ReadValue(reader, &s.MaxFeePerGas, vers); err != nil {
 		return err
@@ -513,6 +580,15 @@ func (s *Transaction) UnmarshalCache(vers uint64, reader io.Reader) (err error)
 		return err
 	}
 
+	// Mint
+	vMint := version.NewVersion("3.5.0")
+	if vers > vMint.Uint64() {
+		// Mint
+		if err = cache.ReadValue(reader, &s.Mint, vers); err != nil {
+			return err
+		}
+	}
+
 	// Nonce
 	if err = cache.ReadValue(reader, &s.Nonce, vers); err != nil {
 		return err
@@ -608,4 +684,62 @@ func (s *Transaction) GasCost() base.Gas {
 	return s.GasPrice * s.Receipt.GasUsed
 }
 
+// IsDeposit returns true if the transaction is a deposit from the parent chain of a rollup of the
+// given family
+func (s *Transaction) IsDeposit(family string) bool {
+	txType, ok := depositTypes[family]
+	return ok && s.TransactionType == txType
+}
+
+// Minted returns the value a deposit brings in from the parent chain. Rollups credit it to the
+// sender before running the deposit. OP Stack chains report it as the deposit's mint. On Arbitrum,
+// the deposit's value is minted.
+func (s *Transaction) Minted(family string) *base.Wei {
+	minted := new(base.Wei)
+	if s.IsDeposit(family) {
+		if family == config.FamilyArbitrum {
+			minted.Add(minted, &s.Value)
+		} else {
+			minted.Add(minted, &s.Mint)
+		}
+	}
+	return minted
+}
+
+// BlobFee returns what the sender of a blob transaction (EIP-4844) paid for its blob gas. The fee
+// is burned, as is the base fee, but the sender pays it all the same.
+func (s *Transaction) BlobFee() *base.Wei {
+	if s.Receipt == nil {
+		return new(base.Wei)
+	}
+	return new(base.Wei).Mul(new(base.Wei).SetUint64(uint64(s.Receipt.BlobGasUsed)), new(base.Wei).SetUint64(uint64(s.Receipt.BlobGasPrice)))
+}
+
+// GasCosts returns what the sender paid to run the transaction (including the fee for its blobs,
+// if any) and, on rollups, the fee it paid for posting the transaction to the parent chain. OP
+// Stack chains report the latter in the receipt. Arbitrum charges it as gas, so it is split out of
+// the gas used. Deposits are paid for on the parent chain.
+func (s *Transaction) GasCosts(family string) (gasCost *base.Wei, l1Fee *base.Wei) {
+	gasCost, l1Fee = new(base.Wei), new(base.Wei)
+	if s.Receipt == nil || s.IsDeposit(family) {
+		return
+	}
+
+	gasUsed, gasPrice := s.Receipt.GasUsed, s.GasPrice
+	switch family {
+	case config.FamilyOptimism:
+		l1Fee.Add(l1Fee, &s.Receipt.L1Fee)
+	case config.FamilyArbitrum:
+		if s.Receipt.EffectiveGasPrice > 0 {
+			gasPrice = s.Receipt.EffectiveGasPrice
+		}
+		gasForL1 := min(s.Receipt.GasUsedForL1, gasUsed)
+		l1Fee.Mul(new(base.Wei).SetUint64(uint64(gasForL1)), new(base.Wei).SetUint64(uint64(gasPrice)))
+		gasUsed -= gasForL1
+	}
+	gasCost.Mul(new(base.Wei).SetUint64(uint64(gasUsed)), new(base.Wei).SetUint64(uint64(gasPrice)))
+	gasCost.Add(gasCost, s.BlobFee())
+	return
+}
+
 // EXISTING_CODE
@@ -1,12 +1,14 @@
 package types
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"testing"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/cache"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 )
 
 func TestTransactionCache(t *testing.T) {
@@ -135,3 +137,180 @@ func TestTransactionCacheArticulated(t *testing.T) {
 		t.Fatalf("value mismatch: got %+v want %+v\n", readBack, expected)
 	}
 }
+
+func TestTransactionCacheRollup(t *testing.T) {
+	expected := &Transaction{
+		BlockHash:       base.HexToHash("0x6c4c3f2e0d5a5bbf3b1c1f4c0e8a0e2f4a3d9bbd8b1c3e7e1a7c1a6c0e9f7a21"),
+		BlockNumber:     120000001,
+		From:            base.HexToAddress("0x977f82a600a1414e583f7f13623f1ac5d58b1c0b"),
+		Gas:             100000,
+		Hash:            base.HexToHash("0x2b3d0c8f6e1a7f4c5d9e8b7a6c5d4e3f2a1b0c9d8e7f6a5b4c3d2e1f0a9b8c7d"),
+		Input:           "0x",
+		Mint:            *(base.NewWei(1000000000000000000)),
+		Timestamp:       1716000000,
+		To:              base.HexToAddress("0x977f82a600a1414e583f7f13623f1ac5d58b1c0b"),
+		TransactionType: "0x7e",
+		Value:           *(base.NewWei(1000000000000000000)),
+		Receipt: &Receipt{
+			DepositNonce: 1234,
+			GasUsed:      46000,
+			L1Fee:        *(base.NewWei(0)),
+			Logs:         []Log{},
+			Status:       1,
+			ReceiptType:  "0x7e",
+		},
+	}
+
+	store, err := cache.NewStore(&cache.StoreOptions{Location: cache.MemoryCache})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Write(expected, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	readBack := &Transaction{
+		BlockNumber:      expected.BlockNumber,
+		TransactionIndex: expected.TransactionIndex,
+	}
+	if err := store.Read(readBack, nil); err != nil {
+		t.Fatal(err)
+	}
+	if readBack.Mint.Cmp(&expected.Mint) != 0 || readBack.Receipt.DepositNonce != 1234 || readBack.Receipt.ReceiptType != "0x7e" {
+		t.Fatalf("value mismatch:\n\tgot %+v\n\twant %+v\n", readBack, expected)
+	}
+}
+
+func TestTransactionCacheBlobs(t *testing.T) {
+	var expected Transaction
+	if err := json.Unmarshal([]byte(`{
+		"hash": "0x5ad7b54e3e0c8d9a8d4d1e3dbe7bd0b3f1c1c5a8e7f3f4b0a2c6d1e9f8a7b6c5",
+		"type": "0x3",
+		"gas": "0x5208",
+		"gasPrice": "0x3b9aca00",
+		"maxFeePerBlobGas": "0x2540be400",
+		"blobVersionedHashes": [
+			"0x01a915e4d060149eb4365960e6a7a45f334393093061116b197e3240065ff2d8",
+			"0x0131ff4c9d3da0e4cf31a0e8ed19ee3e4bde23f0ac9ec2a8d2c49f03d1b7f2c3"
+		]
+	}`), &expected); err != nil {
+		t.Fatal(err)
+	}
+	expected.BlockNumber = 19531251
+	expected.Receipt = &Receipt{
+		BlobGasPrice: 1,
+		BlobGasUsed:  262144,
+		GasUsed:      21000,
+		Logs:         []Log{},
+		Status:       1,
+	}
+	if expected.MaxFeePerBlobGas != 10000000000 || len(expected.BlobVersionedHashes) != 2 {
+		t.Fatalf("blob transaction: %+v", expected)
+	}
+
+	store, err := cache.NewStore(&cache.StoreOptions{Location: cache.MemoryCache})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Write(&expected, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	readBack := &Transaction{
+		BlockNumber:      expected.BlockNumber,
+		TransactionIndex: expected.TransactionIndex,
+	}
+	if err := store.Read(readBack, nil); err != nil {
+		t.Fatal(err)
+	}
+	if readBack.MaxFeePerBlobGas != expected.MaxFeePerBlobGas ||
+		!reflect.DeepEqual(readBack.BlobVersionedHashes, expected.BlobVersionedHashes) ||
+		readBack.Receipt.BlobGasUsed != 262144 || readBack.Receipt.BlobGasPrice != 1 {
+		t.Fatalf("value mismatch:\n\tgot %+v\n\twant %+v\n", readBack, expected)
+	}
+	if fee := readBack.BlobFee(); fee.Uint64() != 262144 {
+		t.Errorf("blob fee %s", fee)
+	}
+}
+
+func TestGasCosts(t *testing.T) {
+	var opReceipt Receipt
+	if err := json.Unmarshal([]byte(`{
+		"type": "0x2",
+		"gasUsed": "0x5208",
+		"effectiveGasPrice": "0x3b9aca00",
+		"l1Fee": "0x2540be400",
+		"l1GasPrice": "0x4a817c800",
+		"l1GasUsed": "0x640"
+	}`), &opReceipt); err != nil {
+		t.Fatal(err)
+	}
+	if opReceipt.L1Fee.Uint64() != 10000000000 || opReceipt.L1GasUsed != 1600 || opReceipt.IsDeposit(config.FamilyOptimism) {
+		t.Fatalf("optimism receipt: %+v", opReceipt)
+	}
+
+	tests := []struct {
+		name     string
+		family   string
+		tx       Transaction
+		gasCost  uint64
+		l1Fee    uint64
+		minted   uint64
+		deposits bool
+	}{
+		{
+			name:    "ethereum",
+			family:  config.FamilyEthereum,
+			tx:      Transaction{GasPrice: 1000000000, Receipt: &Receipt{GasUsed: 21000, L1Fee: *base.NewWei(5)}},
+			gasCost: 21000000000000,
+		},
+		{
+			name:    "optimism",
+			family:  config.FamilyOptimism,
+			tx:      Transaction{GasPrice: 1000000000, TransactionType: "0x2", Receipt: &opReceipt},
+			gasCost: 21000000000000,
+			l1Fee:   10000000000,
+		},
+		{
+			name:     "optimism deposit",
+			family:   config.FamilyOptimism,
+			tx:       Transaction{TransactionType: "0x7e", Mint: *base.NewWei(7), Value: *base.NewWei(3), Receipt: &Receipt{GasUsed: 46000}},
+			minted:   7,
+			deposits: true,
+		},
+		{
+			name:    "blobs",
+			family:  config.FamilyEthereum,
+			tx:      Transaction{GasPrice: 1000000000, TransactionType: "0x3", Receipt: &Receipt{GasUsed: 21000, BlobGasUsed: 262144, BlobGasPrice: 3}},
+			gasCost: 21000000786432,
+		},
+		{
+			name:    "arbitrum",
+			family:  config.FamilyArbitrum,
+			tx:      Transaction{GasPrice: 200000000, Receipt: &Receipt{GasUsed: 300000, GasUsedForL1: 100000, EffectiveGasPrice: 100000000}},
+			gasCost: 20000000000000,
+			l1Fee:   10000000000000,
+		},
+		{
+			name:     "arbitrum deposit",
+			family:   config.FamilyArbitrum,
+			tx:       Transaction{TransactionType: "0x64", Value: *base.NewWei(3), Receipt: &Receipt{}},
+			minted:   3,
+			deposits: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gasCost, l1Fee := tt.tx.GasCosts(tt.family)
+			if gasCost.Uint64() != tt.gasCost || l1Fee.Uint64() != tt.l1Fee {
+				t.Errorf("gas cost %s and l1 fee %s, expected %d and %d", gasCost, l1Fee, tt.gasCost, tt.l1Fee)
+			}
+			if minted := tt.tx.Minted(tt.family); minted.Uint64() != tt.minted {
+				t.Errorf("minted %s, expected %d", minted, tt.minted)
+			}
+			if tt.tx.IsDeposit(tt.family) != tt.deposits {
+				t.Errorf("IsDeposit is %t", !tt.deposits)
+			}
+		})
+	}
+}
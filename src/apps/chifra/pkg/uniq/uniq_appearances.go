@@ -6,30 +6,38 @@ import (
 	"sync"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
 )
 
 // AddMiner adds the miner address (for use with post-merge)
-func AddMiner(chain string, miner base.Address, bn base.Blknum, addrMap AddressBooleanMap) (err error) {
-	addAddressToMaps(miner.Hex(), bn, types.BlockReward, addrMap)
+func AddMiner(chain string, miner base.Address, bn base.Blknum, addrMap AddressReasonMap) (err error) {
+	addAddressToMaps(miner.Hex(), bn, types.BlockReward, types.ReasonMiner, addrMap)
 	return nil
 }
 
 // UniqFromWithdrawals extracts addresses from an array of receipts
-func UniqFromWithdrawals(chain string, withdrawals []types.Withdrawal, bn base.Blknum, addrMap AddressBooleanMap) (err error) {
+func UniqFromWithdrawals(chain string, withdrawals []types.Withdrawal, bn base.Blknum, addrMap AddressReasonMap) (err error) {
 	for _, withdrawal := range withdrawals {
-		addAddressToMaps(withdrawal.Address.Hex(), bn, types.WithdrawalAmt, addrMap)
+		addAddressToMaps(withdrawal.Address.Hex(), bn, types.WithdrawalAmt, types.ReasonWithdrawal, addrMap)
 	}
 	return nil
 }
 
-// UniqFromReceipts extracts addresses from an array of receipts
-func UniqFromReceipts(chain string, receipts []types.Receipt, addrMap AddressBooleanMap) (err error) {
+// UniqFromReceipts extracts addresses from an array of receipts. On rollups, the sender and
+// recipient of each deposit from the parent chain are also extracted, because the node may not
+// trace deposits.
+func UniqFromReceipts(chain string, receipts []types.Receipt, addrMap AddressReasonMap) (err error) {
+	family := config.GetChainFamily(chain)
 	for _, receipt := range receipts {
 		created := receipt.ContractAddress
-		addAddressToMaps(created.Hex(), receipt.BlockNumber, receipt.TransactionIndex, addrMap)
+		addAddressToMaps(created.Hex(), receipt.BlockNumber, receipt.TransactionIndex, types.ReasonCreation, addrMap)
+		if receipt.IsDeposit(family) {
+			addAddressToMaps(receipt.From.Hex(), receipt.BlockNumber, receipt.TransactionIndex, types.ReasonFrom, addrMap)
+			addAddressToMaps(receipt.To.Hex(), receipt.BlockNumber, receipt.TransactionIndex, types.ReasonTo, addrMap)
+		}
 		if err := uniqFromLogs(chain, receipt.Logs, addrMap); err != nil {
 			return err
 		}
@@ -38,12 +46,15 @@ func UniqFromReceipts(chain string, receipts []types.Receipt, addrMap AddressBoo
 }
 
 // uniqFromLogs extracts addresses from the logs
-func uniqFromLogs(chain string, logs []types.Log, addrMap AddressBooleanMap) (err error) {
+func uniqFromLogs(chain string, logs []types.Log, addrMap AddressReasonMap) (err error) {
 	for _, log := range logs {
+		// The generator is always a participant in the transaction's traces (which are processed first), so
+		// it only adds a reason (never an appearance), leaving the index the same with or without reasons
+		updateAddressInMaps(log.Address.Hex(), log.BlockNumber, log.TransactionIndex, types.ReasonGenerator, addrMap)
 		for _, topic := range log.Topics {
 			str := string(topic.Hex()[2:])
 			if IsImplicitAddress(str) {
-				addAddressToMaps(str, log.BlockNumber, log.TransactionIndex, addrMap)
+				addAddressToMaps(str, log.BlockNumber, log.TransactionIndex, types.ReasonTopic, addrMap)
 			}
 		}
 
@@ -52,7 +63,7 @@ func uniqFromLogs(chain string, logs []types.Log, addrMap AddressBooleanMap) (er
 			for i := 0; i < len(inputData)/64; i++ {
 				str := string(inputData[i*64 : (i+1)*64])
 				if IsImplicitAddress(str) {
-					addAddressToMaps(str, log.BlockNumber, log.TransactionIndex, addrMap)
+					addAddressToMaps(str, log.BlockNumber, log.TransactionIndex, types.ReasonData, addrMap)
 				}
 			}
 		}
@@ -62,18 +73,24 @@ func uniqFromLogs(chain string, logs []types.Log, addrMap AddressBooleanMap) (er
 }
 
 // UniqFromTraces extracts addresses from traces
-func UniqFromTraces(chain string, traces []types.Trace, addrMap AddressBooleanMap) (err error) {
+func UniqFromTraces(chain string, traces []types.Trace, addrMap AddressReasonMap) (err error) {
 	conn := rpc.TempConnection(chain)
 
 	for _, trace := range traces {
 		bn := base.Blknum(trace.BlockNumber)
 		txid := trace.TransactionIndex
 
+		// The sender, recipient, and input of the top-level trace are those of the transaction
+		fromReason, toReason, inputReason := types.ReasonTrace, types.ReasonTrace, types.ReasonTrace
+		if len(trace.TraceAddress) == 0 {
+			fromReason, toReason, inputReason = types.ReasonFrom, types.ReasonTo, types.ReasonInput
+		}
+
 		from := trace.Action.From.Hex()
-		addAddressToMaps(from, bn, txid, addrMap)
+		addAddressToMaps(from, bn, txid, fromReason, addrMap)
 
 		to := trace.Action.To.Hex()
-		addAddressToMaps(to, bn, txid, addrMap)
+		addAddressToMaps(to, bn, txid, toReason, addrMap)
 
 		if trace.TraceType == "call" {
 			// If it's a call, get the to and from, we're done
@@ -86,7 +103,7 @@ func UniqFromTraces(chain string, traces []types.Trace, addrMap AddressBooleanMa
 					author = base.SentinalAddr.Hex()
 					fakeId = types.MisconfigReward
 				}
-				addAddressToMaps(author, bn, fakeId, addrMap)
+				addAddressToMaps(author, bn, fakeId, types.ReasonMiner, addrMap)
 
 			} else if trace.Action.RewardType == "uncle" {
 				author := trace.Action.Author.Hex()
@@ -95,12 +112,12 @@ func UniqFromTraces(chain string, traces []types.Trace, addrMap AddressBooleanMa
 					author = base.SentinalAddr.Hex()
 					fakeId = types.MisconfigReward
 				}
-				addAddressToMaps(author, bn, fakeId, addrMap)
+				addAddressToMaps(author, bn, fakeId, types.ReasonUncle, addrMap)
 
 			} else if trace.Action.RewardType == "external" {
 				// This only happens in xDai as far as we know...
 				author := trace.Action.Author.Hex()
-				addAddressToMaps(author, bn, types.ExternalReward, addrMap)
+				addAddressToMaps(author, bn, types.ExternalReward, types.ReasonMiner, addrMap)
 
 			} else {
 				logger.Warn(fmt.Sprintf("Unknown reward type %s for trace: %d.%d.%d", trace.Action.RewardType, trace.BlockNumber, trace.TransactionIndex, trace.TraceIndex))
@@ -110,16 +127,16 @@ func UniqFromTraces(chain string, traces []types.Trace, addrMap AddressBooleanMa
 		} else if trace.TraceType == "suicide" {
 			// add the contract that died, and where it sent it's money
 			refundAddress := trace.Action.RefundAddress.Hex()
-			addAddressToMaps(refundAddress, bn, txid, addrMap)
+			addAddressToMaps(refundAddress, bn, txid, types.ReasonTrace, addrMap)
 
 			address := trace.Action.Address.Hex()
-			addAddressToMaps(address, bn, txid, addrMap)
+			addAddressToMaps(address, bn, txid, types.ReasonTrace, addrMap)
 
 		} else if trace.TraceType == "create" {
 			if trace.Result != nil {
 				// may be both...record the self-destruct instead of the creation since we can only report on one
 				address := trace.Result.Address.Hex()
-				addAddressToMaps(address, bn, txid, addrMap)
+				addAddressToMaps(address, bn, txid, types.ReasonTrace, addrMap)
 			}
 
 			// If it's a top level trace, then the call data is the init,
@@ -130,7 +147,7 @@ func UniqFromTraces(chain string, traces []types.Trace, addrMap AddressBooleanMa
 					for i := 0; i < len(initData)/64; i++ {
 						str := string(initData[i*64 : (i+1)*64])
 						if IsImplicitAddress(str) {
-							addAddressToMaps(str, bn, txid, addrMap)
+							addAddressToMaps(str, bn, txid, types.ReasonInput, addrMap)
 						}
 					}
 				}
@@ -142,7 +159,7 @@ func UniqFromTraces(chain string, traces []types.Trace, addrMap AddressBooleanMa
 					if trace.Error != "" {
 						if receipt, err := conn.GetReceiptNoTimestamp(bn, txid); err == nil {
 							address := receipt.ContractAddress.Hex()
-							addAddressToMaps(address, bn, txid, addrMap)
+							addAddressToMaps(address, bn, txid, types.ReasonTrace, addrMap)
 						}
 					}
 				}
@@ -161,7 +178,7 @@ func UniqFromTraces(chain string, traces []types.Trace, addrMap AddressBooleanMa
 			for i := 0; i < len(inputData)/64; i++ {
 				str := string(inputData[i*64 : (i+1)*64])
 				if IsImplicitAddress(str) {
-					addAddressToMaps(str, bn, txid, addrMap)
+					addAddressToMaps(str, bn, txid, inputReason, addrMap)
 				}
 			}
 		}
@@ -172,7 +189,7 @@ func UniqFromTraces(chain string, traces []types.Trace, addrMap AddressBooleanMa
 			for i := 0; i < len(outputData)/64; i++ {
 				str := string(outputData[i*64 : (i+1)*64])
 				if IsImplicitAddress(str) {
-					addAddressToMaps(str, bn, txid, addrMap)
+					addAddressToMaps(str, bn, txid, types.ReasonTrace, addrMap)
 				}
 			}
 		}
@@ -187,7 +204,7 @@ var mapSync sync.Mutex
 // if we've never seen this appearance before. `appsMap` is used to build the appearance table when writing the
 // chunk. `addrMap` helps eliminate duplicates and is used to build the address table when writing the chunk.
 // Precompiles are ignored. If the given address string does not start with a lead `0x`, it is normalized.
-func addAddressToMaps(address string, bn base.Blknum, txid base.Txnum, addrMap AddressBooleanMap) {
+func addAddressToMaps(address string, bn base.Blknum, txid base.Txnum, reason types.Reason, addrMap AddressReasonMap) {
 	if base.IsPrecompile(address) {
 		return
 	}
@@ -201,5 +218,16 @@ func addAddressToMaps(address string, bn base.Blknum, txid base.Txnum, addrMap A
 	mapSync.Lock()
 	defer mapSync.Unlock()
 
-	addrMap.Insert(address, bn, txid)
+	addrMap.Insert(address, bn, txid, reason)
+}
+
+// updateAddressInMaps adds the reason to an appearance already in `addrMap` (if it is there)
+func updateAddressInMaps(address string, bn base.Blknum, txid base.Txnum, reason types.Reason, addrMap AddressReasonMap) {
+	if base.IsPrecompile(address) {
+		return
+	}
+
+	mapSync.Lock()
+	defer mapSync.Unlock()
+	addrMap.Update(address, bn, txid, reason)
 }
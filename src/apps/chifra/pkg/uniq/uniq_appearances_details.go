@@ -8,6 +8,7 @@ import (
 	"sync"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/config"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/names"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
@@ -17,19 +18,40 @@ import (
 var AppearanceFmt = "%s\t%09d\t%05d"
 
 type UniqProcFunc func(s *types.Appearance) error
-type AddressBooleanMap map[string]bool
 
-// Insert generates item's key according to `AppearanceFmt` and adds the item to the map
-func (a *AddressBooleanMap) Insert(address string, bn base.Blknum, txid base.Txnum) string {
+// AddressReasonMap carries the reasons for each appearance keyed according to `AppearanceFmt`
+type AddressReasonMap map[string]types.Reason
+
+// Insert generates item's key according to `AppearanceFmt` and adds the reason to the item's reasons
+func (a *AddressReasonMap) Insert(address string, bn base.Blknum, txid base.Txnum, reason types.Reason) string {
 	key := fmt.Sprintf(AppearanceFmt, address, bn, txid)
 	v := *a
-	v[key] = true
+	v[key] |= reason
 	return key
 }
 
+// Update adds the reason to the item's reasons only if the item is already in the map
+func (a *AddressReasonMap) Update(address string, bn base.Blknum, txid base.Txnum, reason types.Reason) {
+	key := fmt.Sprintf(AppearanceFmt, address, bn, txid)
+	v := *a
+	if _, ok := v[key]; ok {
+		v[key] |= reason
+	}
+}
+
 func GetUniqAddressesInBlock(chain, flow string, conn *rpc.Connection, procFunc UniqProcFunc, bn base.Blknum) error {
+	return getUniqAddressesInBlock(chain, flow, conn, procFunc, bn, AddressReasonMap{})
+}
+
+// GetReasonsInBlock returns the reasons for each of the appearances in the block
+func GetReasonsInBlock(chain string, conn *rpc.Connection, bn base.Blknum) (AddressReasonMap, error) {
+	addrMap := AddressReasonMap{}
+	err := getUniqAddressesInBlock(chain, "", conn, nil, bn, addrMap)
+	return addrMap, err
+}
+
+func getUniqAddressesInBlock(chain, flow string, conn *rpc.Connection, procFunc UniqProcFunc, bn base.Blknum, addrMap AddressReasonMap) error {
 	ts := conn.GetBlockTimestamp(bn)
-	addrMap := AddressBooleanMap{}
 	traceid := base.NOPOSN
 	if bn == 0 {
 		if namesMap, err := names.LoadNamesMap(chain, types.Prefund, []string{}); err != nil {
@@ -63,19 +85,22 @@ func GetUniqAddressesInBlock(chain, flow string, conn *rpc.Connection, procFunc
 			}
 			streamAppearance(procFunc, flow, "miner", author, bn, fakeId, traceid, ts, addrMap)
 
-			if uncles, err := conn.GetUncleBodiesByNumber(bn); err != nil {
-				return err
-			} else {
-				for _, uncle := range uncles {
-					author := uncle.Miner.Hex()
-					fakeId := types.UncleReward
-					if base.IsPrecompile(author) {
-						// Some blocks have a misconfigured miner setting. We process this block, so that
-						// every block gets a record, but it will be excluded from the index. See #3252.
-						author = base.SentinalAddr.Hex()
-						fakeId = types.MisconfigReward
+			// Rollups have no uncles
+			if !config.IsRollup(chain) {
+				if uncles, err := conn.GetUncleBodiesByNumber(bn); err != nil {
+					return err
+				} else {
+					for _, uncle := range uncles {
+						author := uncle.Miner.Hex()
+						fakeId := types.UncleReward
+						if base.IsPrecompile(author) {
+							// Some blocks have a misconfigured miner setting. We process this block, so that
+							// every block gets a record, but it will be excluded from the index. See #3252.
+							author = base.SentinalAddr.Hex()
+							fakeId = types.MisconfigReward
+						}
+						streamAppearance(procFunc, flow, "uncle", author, bn, fakeId, traceid, ts, addrMap)
 					}
-					streamAppearance(procFunc, flow, "uncle", author, bn, fakeId, traceid, ts, addrMap)
 				}
 			}
 
@@ -97,7 +122,7 @@ func GetUniqAddressesInBlock(chain, flow string, conn *rpc.Connection, procFunc
 	return nil
 }
 
-func GetUniqAddressesInTransaction(chain string, procFunc UniqProcFunc, flow string, trans *types.Transaction, ts base.Timestamp, addrMap AddressBooleanMap, conn *rpc.Connection) error {
+func GetUniqAddressesInTransaction(chain string, procFunc UniqProcFunc, flow string, trans *types.Transaction, ts base.Timestamp, addrMap AddressReasonMap, conn *rpc.Connection) error {
 	bn := trans.BlockNumber
 	txid := trans.TransactionIndex
 	traceid := base.NOPOSN
@@ -118,7 +143,7 @@ func GetUniqAddressesInTransaction(chain string, procFunc UniqProcFunc, flow str
 		for i := 0; i < len(inputData)/64; i++ {
 			str := string(inputData[i*64 : (i+1)*64])
 			if IsImplicitAddress(str) {
-				streamAppearance(procFunc, flow, str, reason, bn, txid, traceid, ts, addrMap)
+				streamAppearance(procFunc, flow, reason, str, bn, txid, traceid, ts, addrMap)
 			}
 		}
 	}
@@ -138,7 +163,7 @@ func GetUniqAddressesInTransaction(chain string, procFunc UniqProcFunc, flow str
 }
 
 // uniqFromLogsDetails extracts addresses from the logs
-func uniqFromLogsDetails(chain string, procFunc UniqProcFunc, flow string, logs []types.Log, ts base.Timestamp, addrMap AddressBooleanMap) (err error) {
+func uniqFromLogsDetails(chain string, procFunc UniqProcFunc, flow string, logs []types.Log, ts base.Timestamp, addrMap AddressReasonMap) (err error) {
 	traceid := base.NOPOSN
 	for l, log := range logs {
 		generator := log.Address.Hex()
@@ -205,7 +230,7 @@ func traceReason(traceId base.Tracenum, trace *types.Trace, r string) string {
 }
 
 // uniqFromTracesDetails extracts addresses from traces
-func uniqFromTracesDetails(chain string, procFunc UniqProcFunc, flow string, traces []types.Trace, ts base.Timestamp, addrMap AddressBooleanMap, conn *rpc.Connection) (err error) {
+func uniqFromTracesDetails(chain string, procFunc UniqProcFunc, flow string, traces []types.Trace, ts base.Timestamp, addrMap AddressReasonMap, conn *rpc.Connection) (err error) {
 	for _, trace := range traces {
 		traceid := trace.TraceIndex
 		bn := base.Blknum(trace.BlockNumber)
@@ -336,7 +361,7 @@ var mapSync2 sync.Mutex
 
 // streamAppearance streams an appearance to the model channel if we've not seen this appearance before. We
 // keep track of appearances we've seen with `appsMap`.
-func streamAppearance(procFunc UniqProcFunc, flow string, reason string, address string, bn base.Blknum, txid base.Txnum, traceid base.Tracenum, ts base.Timestamp, addrMap AddressBooleanMap) {
+func streamAppearance(procFunc UniqProcFunc, flow string, reason string, address string, bn base.Blknum, txid base.Txnum, traceid base.Tracenum, ts base.Timestamp, addrMap AddressReasonMap) {
 	if base.IsPrecompile(address) {
 		return
 	}
@@ -368,11 +393,12 @@ func streamAppearance(procFunc UniqProcFunc, flow string, reason string, address
 		address = addr.Hex()
 	}
 
-	key := fmt.Sprintf("%s\t%09d\t%05d", address, bn, txid)
+	key := fmt.Sprintf(AppearanceFmt, address, bn, txid)
 
 	mapSync2.Lock()
-	if !addrMap[key] {
-		addrMap[key] = true
+	_, seen := addrMap[key]
+	addrMap[key] |= reasonFromDetail(reason)
+	if !seen {
 		mapSync2.Unlock()
 
 		s := &types.Appearance{
@@ -394,3 +420,37 @@ func streamAppearance(procFunc UniqProcFunc, flow string, reason string, address
 		mapSync2.Unlock()
 	}
 }
+
+// reasonFromDetail returns the reason corresponding to the detailed reason given to streamAppearance
+func reasonFromDetail(detail string) types.Reason {
+	switch detail {
+	case "from":
+		return types.ReasonFrom
+	case "to":
+		return types.ReasonTo
+	case "creation":
+		return types.ReasonCreation
+	case "input", "code":
+		return types.ReasonInput
+	case "miner", "external":
+		return types.ReasonMiner
+	case "uncle":
+		return types.ReasonUncle
+	case "withdrawal":
+		return types.ReasonWithdrawal
+	case "genesis":
+		return types.ReasonGenesis
+	}
+
+	if strings.HasPrefix(detail, "log_") {
+		if strings.HasSuffix(detail, "_generator") {
+			return types.ReasonGenerator
+		} else if strings.Contains(detail, "_topic_") {
+			return types.ReasonTopic
+		} else if strings.HasSuffix(detail, "_data") {
+			return types.ReasonData
+		}
+	}
+
+	return types.ReasonTrace
+}
@@ -2,21 +2,68 @@ package uniq
 
 import (
 	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
 )
 
-func TestAddressBooleanMap_Insert(t *testing.T) {
-	addrMap := make(AddressBooleanMap, 0)
+func TestAddressReasonMap_Insert(t *testing.T) {
+	addrMap := make(AddressReasonMap, 0)
 	addrMap.Insert(
 		"0xfffd8963efd1fc6a506488495d951d5263988d25",
 		18509161,
 		132,
+		types.ReasonFrom,
 	)
 	key := "0xfffd8963efd1fc6a506488495d951d5263988d25	018509161	00132"
 
 	if _, ok := addrMap[key]; !ok {
 		t.Fatal("key not found")
 	}
-	if v := addrMap[key]; !v {
-		t.Fatal("value is false")
+	if v := addrMap[key]; v != types.ReasonFrom {
+		t.Fatal("wrong reason", v)
+	}
+
+	addrMap.Insert("0xfffd8963efd1fc6a506488495d951d5263988d25", 18509161, 132, types.ReasonTopic)
+	if v := addrMap[key]; v != types.ReasonFrom|types.ReasonTopic {
+		t.Fatal("reasons not combined", v)
+	}
+}
+
+func TestAddressReasonMap_Update(t *testing.T) {
+	addrMap := make(AddressReasonMap, 0)
+	addrMap.Update("0xfffd8963efd1fc6a506488495d951d5263988d25", 18509161, 132, types.ReasonGenerator)
+	if len(addrMap) != 0 {
+		t.Fatal("update should not insert")
+	}
+
+	key := addrMap.Insert("0xfffd8963efd1fc6a506488495d951d5263988d25", 18509161, 132, types.ReasonTo)
+	addrMap.Update("0xfffd8963efd1fc6a506488495d951d5263988d25", 18509161, 132, types.ReasonGenerator)
+	if v := addrMap[key]; v != types.ReasonTo|types.ReasonGenerator {
+		t.Fatal("wrong reason", v)
+	}
+}
+
+func Test_reasonFromDetail(t *testing.T) {
+	tests := map[string]types.Reason{
+		"from":            types.ReasonFrom,
+		"to":              types.ReasonTo,
+		"creation":        types.ReasonCreation,
+		"input":           types.ReasonInput,
+		"miner":           types.ReasonMiner,
+		"external":        types.ReasonMiner,
+		"uncle":           types.ReasonUncle,
+		"withdrawal":      types.ReasonWithdrawal,
+		"genesis":         types.ReasonGenesis,
+		"log_3_generator": types.ReasonGenerator,
+		"log_3_topic_2":   types.ReasonTopic,
+		"log_3_data":      types.ReasonData,
+		"trace_2_[1]_to":  types.ReasonTrace,
+		"self-destruct":   types.ReasonTrace,
+		"trace_1_output":  types.ReasonTrace,
+	}
+	for detail, want := range tests {
+		if got := reasonFromDetail(detail); got != want {
+			t.Errorf("reasonFromDetail(%s) = %s, want %s", detail, got, want)
+		}
 	}
 }
@@ -0,0 +1,67 @@
+package uniq
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/rpc/query"
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/types"
+)
+
+// TestGetReasonsInBlockFixtures finds the appearances (and their reasons) in the blocks of the
+// devnet's simple scenario. The calls are answered from fixtures (see query.ReplayFixtures to
+// record them again).
+func TestGetReasonsInBlockFixtures(t *testing.T) {
+	if err := query.ReplayFixtures("fixtures", "testdata/fixtures.json"); err != nil {
+		t.Fatal(err)
+	}
+	conn := &rpc.Connection{Chain: "fixtures"}
+
+	one := "0x1111111111111111111111111111111111111111"
+	two := "0x2222222222222222222222222222222222222222"
+	token := "0xaaaa000000000000000000000000000000000000"
+	created := "0xcccc000000000000000000000000000000000000"
+	miner := "0x9999999999999999999999999999999999999999"
+	// Blocks without a miner are credited to the sentinel (see #3252)
+	sentinel := base.SentinalAddr.Hex()
+
+	tests := []struct {
+		bn       base.Blknum
+		expected func(m AddressReasonMap)
+	}{
+		{1, func(m AddressReasonMap) {
+			m.Insert(miner, 1, types.BlockReward, types.ReasonMiner)
+			m.Insert(one, 1, 0, types.ReasonFrom)
+			m.Insert(two, 1, 0, types.ReasonTo)
+		}},
+		{3, func(m AddressReasonMap) {
+			// The token transfer and the internal call name both accounts
+			m.Insert(sentinel, 3, types.MisconfigReward, types.ReasonMiner)
+			m.Insert(one, 3, 0, types.ReasonFrom|types.ReasonTopic|types.ReasonTrace)
+			m.Insert(token, 3, 0, types.ReasonTo|types.ReasonGenerator)
+			m.Insert(two, 3, 0, types.ReasonTopic|types.ReasonTrace)
+		}},
+		{4, func(m AddressReasonMap) {
+			// The withdrawal's index is its transaction id
+			m.Insert(sentinel, 4, types.MisconfigReward, types.ReasonMiner)
+			m.Insert(two, 4, 0, types.ReasonFrom)
+			m.Insert(one, 4, 0, types.ReasonTo|types.ReasonWithdrawal)
+			m.Insert(two, 4, 1, types.ReasonFrom)
+			m.Insert(created, 4, 1, types.ReasonCreation|types.ReasonTrace)
+		}},
+	}
+
+	for _, tt := range tests {
+		got, err := GetReasonsInBlock(conn.Chain, conn, tt.bn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := AddressReasonMap{}
+		tt.expected(want)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("block %d:\n got %v\nwant %v", tt.bn, got, want)
+		}
+	}
+}
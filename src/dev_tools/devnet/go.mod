@@ -0,0 +1,8 @@
+module github.com/TrueBlocks/trueblocks-core/devnet
+
+// Go Version
+go 1.22
+
+replace github.com/TrueBlocks/trueblocks-core/sdk => ../../../sdk
+
+require github.com/TrueBlocks/trueblocks-core/src/apps/chifra v0.0.0-20241029040126-dfdcbfaef4e9
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/devnet"
+)
+
+func main() {
+	port := flag.Int("port", 8545, "the port on which to serve the chain")
+	allocs := flag.String("allocs", "", "if set, write the chain's genesis allocation (chifra's allocs.csv) to this file")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: devnet [--port <port>] [--allocs <file>] <scenario.toml>\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	scenario, err := devnet.LoadScenario(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading %s: %v\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+	if len(*allocs) > 0 {
+		if err := scenario.WriteAllocs(*allocs); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", *allocs, err)
+			os.Exit(1)
+		}
+	}
+
+	server, err := devnet.NewServer(scenario)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error building the chain in %s: %v\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+
+	addr := fmt.Sprintf("localhost:%d", *port)
+	fmt.Printf("Serving chain %d from %s at http://%s\n", scenario.ChainId, flag.Arg(0), addr)
+	if err := http.ListenAndServe(addr, server); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
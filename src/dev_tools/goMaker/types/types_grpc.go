@@ -0,0 +1,303 @@
+package types
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/base"
+)
+
+// grpcGlobals are the global options carried by a gRPC request (depending on the command's
+// capabilities). Their field numbers are well above those of the command's own options.
+var grpcGlobals = []Option{
+	{Num: 1001, LongName: "chain", OptionType: "flag", Description: "the chain to use", DataType: "<string>"},
+	{Num: 1002, LongName: "cache", OptionType: "switch", Description: "force the results of the query into the cache", DataType: "<boolean>"},
+	{Num: 1003, LongName: "ether", OptionType: "switch", Description: "export values in ether", DataType: "<boolean>"},
+}
+
+// IsGrpc returns true if the command is served as a server-streaming method of the gRPC API
+func (c *Command) IsGrpc() bool {
+	return c.IsRoute() && strings.Contains(c.Attributes, "grpc")
+}
+
+// GrpcOptions returns the options that appear as fields in the command's gRPC request
+func (c *Command) GrpcOptions() []Option {
+	ret := []Option{}
+	for _, op := range c.Options {
+		if (op.IsFlag() || op.IsPositional()) && !op.IsApiHidden() && !op.IsConfig() {
+			ret = append(ret, op)
+		}
+	}
+	return ret
+}
+
+// GrpcGlobals returns the global options that appear as fields in the command's gRPC request
+func (c *Command) GrpcGlobals() []Option {
+	capsMap, _ := c.CapsMapAndArray()
+	ret := []Option{}
+	for _, op := range grpcGlobals {
+		switch op.LongName {
+		case "cache":
+			if !capsMap["caching"] {
+				continue
+			}
+		case "ether":
+			if !capsMap["ether"] {
+				continue
+			}
+		}
+		ret = append(ret, op)
+	}
+	return ret
+}
+
+// ProtoNumber returns the option's field number in the gRPC request. It is derived from the
+// option's position in cmd-line-options.csv so that it remains stable as options are added.
+func (op *Option) ProtoNumber() int {
+	if op.cmdPtr == nil {
+		return op.Num
+	}
+	return op.Num - op.cmdPtr.Num
+}
+
+// ProtoType returns the option's protocol buffer type. Lists (including lists of blocks and
+// transaction ids, which may be ranges) are repeated strings.
+func (op *Option) ProtoType() string {
+	if op.IsArray() {
+		return "repeated string"
+	}
+	switch op.DataType {
+	case "<boolean>":
+		return "bool"
+	case "<uint64>", "<blknum>":
+		return "uint64"
+	case "<float64>":
+		return "double"
+	default:
+		return "string"
+	}
+}
+
+// ProtoKind returns the grpcapi field kind corresponding to the option's protocol buffer type
+func (op *Option) ProtoKind() string {
+	switch strings.TrimPrefix(op.ProtoType(), "repeated ") {
+	case "bool":
+		return "kindBool"
+	case "uint64":
+		return "kindUint64"
+	case "double":
+		return "kindDouble"
+	default:
+		return "kindString"
+	}
+}
+
+// ProtoGoType returns the Go type of the option in the typed gRPC request
+func (op *Option) ProtoGoType() string {
+	switch op.ProtoType() {
+	case "repeated string":
+		return "[]string"
+	case "double":
+		return "float64"
+	default:
+		return op.ProtoType()
+	}
+}
+
+// IsProtoRepeated returns true if the option is a repeated field in the gRPC request
+func (op *Option) IsProtoRepeated() bool {
+	return op.IsArray()
+}
+
+// ProtoGoName returns the name of the option's field in the typed gRPC request
+func (op *Option) ProtoGoName() string {
+	return GoName(op.LongName)
+}
+
+// ProtoAdder returns the name of the grpcapi function that adds the option to a query
+func (op *Option) ProtoAdder() string {
+	if op.IsProtoRepeated() {
+		return "addStrings"
+	}
+	switch op.ProtoKind() {
+	case "kindBool":
+		return "addBool"
+	case "kindUint64":
+		return "addUint64"
+	case "kindDouble":
+		return "addDouble"
+	default:
+		return "addString"
+	}
+}
+
+// GrpcStructures returns the structures carried by the gRPC API, those produced by a gRPC
+// command and those they contain. Each becomes a message of its own in chifra.proto.
+func (cb *CodeBase) GrpcStructures() []Structure {
+	grpcRoutes := map[string]bool{}
+	for _, c := range cb.Commands {
+		if c.IsGrpc() {
+			grpcRoutes[c.Route] = true
+		}
+	}
+
+	byClass := map[string]*Structure{}
+	for i := range cb.Structures {
+		byClass[cb.Structures[i].Class] = &cb.Structures[i]
+	}
+
+	wanted := map[string]bool{}
+	var want func(st *Structure)
+	want = func(st *Structure) {
+		if wanted[st.Class] {
+			return
+		}
+		wanted[st.Class] = true
+		for _, m := range st.Members {
+			if nested := byClass[m.ProtoBaseType()]; nested != nil && !m.IsRemoved() {
+				want(nested)
+			}
+		}
+	}
+	for i := range cb.Structures {
+		for _, route := range cb.Structures[i].Producers {
+			if grpcRoutes[route] {
+				want(&cb.Structures[i])
+				break
+			}
+		}
+	}
+
+	ret := []Structure{}
+	for _, st := range cb.Structures {
+		if wanted[st.Class] {
+			ret = append(ret, st)
+		}
+	}
+	return ret
+}
+
+// ProtoNumber returns the structure's field number in the gRPC Record. It is taken from the
+// structure's doc_route, which is unique and does not change as structures are added.
+func (s *Structure) ProtoNumber() int {
+	parts := strings.Split(s.DocRoute, "-")
+	return int(base.MustParseInt64(parts[0]))
+}
+
+// ProtoName returns the name of the structure's field in the gRPC Record
+func (s *Structure) ProtoName() string {
+	return SnakeCase(s.Class)
+}
+
+// ProtoJsonName returns the json name of the structure's field in the gRPC Record
+func (s *Structure) ProtoJsonName() string {
+	return FirstLower(s.Class)
+}
+
+// ProtoMembers returns the members that appear as fields in the structure's gRPC message in
+// the order in which they appear in the structure's field definitions
+func (s *Structure) ProtoMembers() []Member {
+	ret := []Member{}
+	for _, m := range s.Members {
+		if !m.IsRemoved() {
+			ret = append(ret, m)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Num < ret[j].Num
+	})
+	return ret
+}
+
+// ProtoNumber returns the member's field number in the structure's gRPC message. It is derived
+// from the member's position in the structure's field definitions.
+func (m *Member) ProtoNumber() int {
+	return m.Num
+}
+
+// ProtoName returns the name of the member's field in the structure's gRPC message
+func (m *Member) ProtoName() string {
+	return SnakeCase(m.Name)
+}
+
+// ProtoJsonOption returns the json_name option of the member's field if the name protoc would
+// choose for it differs from the member's name
+func (m *Member) ProtoJsonOption() string {
+	if CamelCase(m.ProtoName()) == m.Name {
+		return ""
+	}
+	return " [json_name = \"" + m.Name + "\"]"
+}
+
+// ProtoBaseType returns the member's type (without its array or pointer markers)
+func (m *Member) ProtoBaseType() string {
+	return m.Type
+}
+
+// ProtoMessage returns the name of the message carrying the member's value if the member is a
+// structure carried by the gRPC API
+func (m *Member) ProtoMessage() string {
+	if !m.IsObject() || m.stPtr == nil || m.stPtr.cbPtr == nil {
+		return ""
+	}
+	for _, st := range m.stPtr.cbPtr.Structures {
+		if st.Class == m.ProtoBaseType() {
+			return st.Class
+		}
+	}
+	return ""
+}
+
+// ProtoType returns the member's protocol buffer type. Values that do not fit in a fixed size
+// integer (addresses, hashes, wei, etc.) are strings. Values of no fixed type are carried as a
+// google.protobuf.Value.
+func (m *Member) ProtoType() string {
+	ret := ""
+	switch m.ProtoKind() {
+	case "kindBool":
+		ret = "bool"
+	case "kindUint64":
+		ret = "uint64"
+	case "kindInt64":
+		ret = "int64"
+	case "kindDouble":
+		ret = "double"
+	case "kindMessage":
+		ret = m.ProtoMessage()
+	case "kindValue":
+		ret = "google.protobuf.Value"
+	default:
+		ret = "string"
+	}
+	if m.IsArray {
+		return "repeated " + ret
+	}
+	return ret
+}
+
+// ProtoKind returns the grpcapi field kind corresponding to the member's protocol buffer type
+func (m *Member) ProtoKind() string {
+	switch m.ProtoBaseType() {
+	case "bool":
+		return "kindBool"
+	case "uint64", "uint32", "value", "blknum", "txnum", "lognum", "gas":
+		return "kindUint64"
+	case "int64", "timestamp":
+		return "kindInt64"
+	case "float64", "float":
+		return "kindDouble"
+	case "string", "address", "hash", "bytes", "ipfshash", "datetime", "blkrange", "int256",
+		"uint256", "wei", "ether", "topic":
+		return "kindString"
+	default:
+		if m.ProtoMessage() != "" {
+			return "kindMessage"
+		}
+		return "kindValue"
+	}
+}
+
+// IsProtoRepeated returns true if the member is a repeated field in its gRPC message
+func (m *Member) IsProtoRepeated() bool {
+	return m.IsArray
+}
@@ -235,6 +235,7 @@ func (m *Member) MarshalCode() string {
 
 `
 	} else if m.IsArray &&
+		m.GoName() != "BlobVersionedHashes" &&
 		m.GoName() != "Topics" &&
 		m.GoName() != "Transactions" &&
 		m.GoName() != "TraceAddress" &&
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode"
 
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/file"
 	"github.com/TrueBlocks/trueblocks-core/src/apps/chifra/pkg/logger"
@@ -165,6 +166,20 @@ func CamelCase(s string) string {
 	return strings.ReplaceAll(strings.ToLower(result[0:1])+result[1:], " ", "")
 }
 
+// SnakeCase converts a camel case name (such as blockNumber) to snake case (block_number)
+func SnakeCase(s string) string {
+	result := []rune{}
+	prev := ' '
+	for _, c := range s {
+		if unicode.IsUpper(c) && (unicode.IsLower(prev) || unicode.IsDigit(prev)) {
+			result = append(result, '_')
+		}
+		result = append(result, unicode.ToLower(c))
+		prev = c
+	}
+	return string(result)
+}
+
 func Pad(s string, width int) string {
 	return s + strings.Repeat(" ", width-len(s))
 }
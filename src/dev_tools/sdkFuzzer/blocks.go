@@ -182,6 +182,16 @@ func TestBlocks(which, value, fn string, opts *sdk.BlocksOptions) {
 				ReportOkay(fn)
 			}
 		}
+	case "blobs":
+		if blobs, _, err := opts.BlocksBlobs(); err != nil {
+			ReportError(fn, opts, err)
+		} else {
+			if err := SaveToFile[types.Transaction](fn, blobs); err != nil {
+				ReportError2(fn, err)
+			} else {
+				ReportOkay(fn)
+			}
+		}
 	case "count":
 		if count, _, err := opts.BlocksCount(); err != nil {
 			ReportError(fn, opts, err)
@@ -30,13 +30,12 @@ func DoChunks() {
 	globs := noCache(noEther(globals))
 	check := []bool{false, true}
 	pin := []bool{false, true}
-	publish := []bool{false, true}
 	remote := []bool{false, true}
 	belongs := fuzzBelongs
 	deep := []bool{false, true}
-	rewrite := []bool{false, true}
 	list := []bool{false, true}
 	unpin := []bool{false, true}
+	dryRun := []bool{false, true}
 	// firstBlock is a <blknum> --other
 	// lastBlock is a <blknum> --other
 	// maxAddrs is a <uint64> --other
@@ -50,12 +49,11 @@ func DoChunks() {
 	_ = globs
 	_ = check
 	_ = pin
-	_ = publish
 	_ = remote
 	_ = deep
-	_ = rewrite
 	_ = list
 	_ = unpin
+	_ = dryRun
 	opts = sdk.ChunksOptions{
 		FirstBlock: 0,
 		LastBlock:  base.NOPOSN,
@@ -75,6 +73,7 @@ func DoChunks() {
 	// Unpin      bool         `json:"unpin,omitempty"`
 	// Count      bool         `json:"count,omitempty"`
 	// Sleep      float64      `json:"sleep,omitempty"`
+	// DryRun     bool         `json:"dryRun,omitempty"`
 	// chunks,command,default|
 	baseFn := "chunks/chunks"
 	fn := getFilename(baseFn, &opts.Globals)
@@ -89,6 +88,7 @@ func DoChunks() {
 	// func (opts *ChunksOptions) ChunksTruncate(val base.Blknum) ([]types.Message, *types.MetaData, error) {
 	// func (opts *ChunksOptions) ChunksDiff() ([]types.Message, *types.MetaData, error) {
 	// func (opts *ChunksOptions) ChunksTag(val string) ([]types.Message, *types.MetaData, error) {
+	// func (opts *ChunksOptions) ChunksPublish() ([]types.Message, *types.MetaData, error) {
 	// EXISTING_CODE
 	Wait()
 }
@@ -169,6 +169,16 @@ func TestChunks(which, value, fn string, opts *sdk.ChunksOptions) {
 				ReportOkay(fn)
 			}
 		}
+	case "publish":
+		if publish, _, err := opts.ChunksPublish(); err != nil {
+			ReportError(fn, opts, err)
+		} else {
+			if err := SaveToFile[types.Message](fn, publish); err != nil {
+				ReportError2(fn, err)
+			} else {
+				ReportOkay(fn)
+			}
+		}
 	case "truncate":
 		if truncate, _, err := opts.ChunksTruncate(base.MustParseBlknum(value)); err != nil {
 			ReportError(fn, opts, err)
@@ -189,6 +199,16 @@ func TestChunks(which, value, fn string, opts *sdk.ChunksOptions) {
 				ReportOkay(fn)
 			}
 		}
+	case "rewrite":
+		if rewrite, _, err := opts.ChunksRewrite(); err != nil {
+			ReportError(fn, opts, err)
+		} else {
+			if err := SaveToFile[types.Message](fn, rewrite); err != nil {
+				ReportError2(fn, err)
+			} else {
+				ReportOkay(fn)
+			}
+		}
 	case "count":
 		if count, _, err := opts.ChunksCount(); err != nil {
 			ReportError(fn, opts, err)
@@ -30,6 +30,7 @@ func DoExport() {
 	topics := fuzzTopics
 	fourbytes := fuzzFourbytes
 	accounting := []bool{false, true}
+	// Option 'lotMethod.enum' is an emum
 	articulate := []bool{false, true}
 	cacheTraces := []bool{false, true}
 	relevant := []bool{false, true}
@@ -42,6 +43,8 @@ func DoExport() {
 	unripe := []bool{false, true}
 	reversed := []bool{false, true}
 	noZero := []bool{false, true}
+	// lots is a <string> --other
+	// cursor is a <string> --other
 	// firstBlock is a <blknum> --other
 	// lastBlock is a <blknum> --other
 	// firstRecord is not fuzzed
@@ -164,6 +167,16 @@ func TestExport(which, value, fn string, opts *sdk.ExportOptions) {
 				ReportOkay(fn)
 			}
 		}
+	case "gains":
+		if gains, _, err := opts.ExportGains(); err != nil {
+			ReportError(fn, opts, err)
+		} else {
+			if err := SaveToFile[types.Gain](fn, gains); err != nil {
+				ReportError2(fn, err)
+			} else {
+				ReportOkay(fn)
+			}
+		}
 	case "balances":
 		if balances, _, err := opts.ExportBalances(); err != nil {
 			ReportError(fn, opts, err)
@@ -32,6 +32,8 @@ func DoList() {
 	unripe := []bool{false, true}
 	silent := []bool{false, true}
 	reversed := []bool{false, true}
+	reasons := []bool{false, true}
+	// Option 'as.list<enum>' is an emum
 	// firstBlock is a <blknum> --other
 	// lastBlock is a <blknum> --other
 	// firstRecord is not fuzzed
@@ -41,6 +43,7 @@ func DoList() {
 	// EXISTING_CODE
 	_ = noZero
 	_ = unripe
+	_ = reasons
 	types := []string{"list", "count", "bounds"}
 	publishers := []string{"", "0x02f2b09b33fdbd406ead954a31f98bd29a2a3492"}
 	// list,command,default|
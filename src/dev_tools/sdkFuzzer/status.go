@@ -300,6 +300,16 @@ func TestStatus(which, value, fn string, opts *sdk.StatusOptions) {
 				ReportOkay(fn)
 			}
 		}
+	case "gc":
+		if gc, _, err := opts.StatusGc(); err != nil {
+			ReportError(fn, opts, err)
+		} else {
+			if err := SaveToFile[types.Status](fn, gc); err != nil {
+				ReportError2(fn, err)
+			} else {
+				ReportOkay(fn)
+			}
+		}
 	default:
 		ReportError(fn, opts, fmt.Errorf("unknown which: %s", which))
 		logger.Fatal("Quitting...")